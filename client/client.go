@@ -6,6 +6,7 @@ import (
 
 	clusterRequest "github.com/canonical/lxd/lxd/cluster/request"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/gorilla/websocket"
 
 	"github.com/canonical/microcluster/internal/rest/client"
 )
@@ -29,6 +30,21 @@ func (c *Client) Query(ctx context.Context, method string, path *api.URL, in any
 	return c.QueryStruct(queryCtx, method, client.ExtendedEndpoint, path, in, &out)
 }
 
+// QueryVersion is a helper for initiating a request on the given version of the application API, such as "2.0" for the
+// /2.0 endpoint.
+func (c *Client) QueryVersion(ctx context.Context, version string, method string, path *api.URL, in any, out any) error {
+	queryCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, method, client.EndpointType(version), path, in, &out)
+}
+
+// WebSocket connects to the WebSocket served by an endpoint on the /1.0 endpoint, such as one returning a
+// rest.WebSocketResponse.
+func (c *Client) WebSocket(ctx context.Context, path *api.URL) (*websocket.Conn, error) {
+	return c.Client.WebSocket(ctx, client.ExtendedEndpoint, path)
+}
+
 // UseTarget returns a new client with the query "?target=name" set.
 func (c *Client) UseTarget(name string) *Client {
 	newClient := c.Client.UseTarget(name)
@@ -4,8 +4,13 @@ import (
 	"context"
 	"math/rand"
 	"sync"
+
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
 )
 
+// ClusterMemberFilter selects cluster members by name, dqlite role and status when listing them.
+type ClusterMemberFilter = internalTypes.ClusterMemberFilter
+
 // Cluster is a list of clients belonging to a cluster.
 type Cluster []Client
 
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"github.com/canonical/microcluster/internal/rest/client"
+)
+
+// Error is an error response from the daemon, returned by all client requests that received one. It can be matched
+// with errors.As to get the status and error codes, and with errors.Is against the errors below.
+type Error = client.Error
+
+var (
+	// ErrNotFound is matched by errors for requests to resources that do not exist.
+	ErrNotFound = client.ErrNotFound
+
+	// ErrNotTrusted is matched by errors for requests whose certificate is not trusted by the cluster member.
+	ErrNotTrusted = client.ErrNotTrusted
+
+	// ErrQuorumLost is matched by errors for requests that failed because the cluster has no dqlite leader, usually
+	// because too many voters are offline.
+	ErrQuorumLost = client.ErrQuorumLost
+
+	// ErrNotLeader is matched by errors for requests that must be made to the dqlite leader.
+	ErrNotLeader = client.ErrNotLeader
+
+	// ErrSchemaMismatch is matched by errors for requests that failed because cluster members have different schema
+	// versions, such as during an upgrade.
+	ErrSchemaMismatch = client.ErrSchemaMismatch
+
+	// ErrAlreadyMember is matched by errors for joins whose name, address or certificate is already in use by another
+	// cluster member.
+	ErrAlreadyMember = client.ErrAlreadyMember
+
+	// ErrNotInitialized is matched by errors for requests made before the daemon has joined a cluster.
+	ErrNotInitialized = client.ErrNotInitialized
+
+	// ErrShuttingDown is matched by errors for requests made while the daemon is shutting down.
+	ErrShuttingDown = client.ErrShuttingDown
+
+	// ErrRateLimited is matched by errors for requests rejected for exceeding the rate limit.
+	ErrRateLimited = client.ErrRateLimited
+)
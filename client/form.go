@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/rest/types"
+)
+
+// FormStage is a step in forming a cluster.
+type FormStage string
+
+const (
+	// FormBootstrap is the stage where the first cluster member bootstraps the cluster.
+	FormBootstrap FormStage = "bootstrap"
+
+	// FormToken is the stage where a join token is issued for a cluster member.
+	FormToken FormStage = "token"
+
+	// FormJoin is the stage where a cluster member joins the cluster.
+	FormJoin FormStage = "join"
+)
+
+// FormMember describes a cluster member to bootstrap or join when forming a cluster.
+type FormMember struct {
+	// Client is connected to the control socket of the cluster member's daemon.
+	Client *Client
+
+	Name       string
+	Address    string
+	InitConfig map[string]string
+}
+
+// FormProgress reports that a cluster member has finished a stage of forming a cluster.
+// Err is set if the stage failed.
+type FormProgress struct {
+	Name  string
+	Stage FormStage
+	Err   error
+}
+
+// FormCluster bootstraps a new cluster on the first of the given members, issues join tokens for the rest from the
+// first member, and joins them to the cluster concurrently. As the cluster only accepts one join at a time, joins are
+// retried while the cluster is busy with another member. The progress function, if given, is called once for each
+// completed stage of each member. The timeout applies to each bootstrap and join request.
+func FormCluster(ctx context.Context, members []FormMember, timeout time.Duration, progress func(FormProgress)) error {
+	if len(members) == 0 {
+		return fmt.Errorf("No cluster members given")
+	}
+
+	progressMu := sync.Mutex{}
+	report := func(name string, stage FormStage, err error) {
+		if progress == nil {
+			return
+		}
+
+		progressMu.Lock()
+		defer progressMu.Unlock()
+		progress(FormProgress{Name: name, Stage: stage, Err: err})
+	}
+
+	addrs := make([]types.AddrPort, 0, len(members))
+	for _, member := range members {
+		if member.Client == nil {
+			return fmt.Errorf("Missing client for cluster member %q", member.Name)
+		}
+
+		addr, err := types.ParseAddrPort(member.Address)
+		if err != nil {
+			return fmt.Errorf("Received invalid address %q for cluster member %q: %w", member.Address, member.Name, err)
+		}
+
+		addrs = append(addrs, addr)
+	}
+
+	first := members[0]
+	err := first.Client.ControlDaemon(ctx, internalTypes.Control{Bootstrap: true, Address: addrs[0], Name: first.Name, InitConfig: first.InitConfig}, timeout)
+	report(first.Name, FormBootstrap, err)
+	if err != nil {
+		return fmt.Errorf("Failed to bootstrap cluster member %q: %w", first.Name, err)
+	}
+
+	tokens := make([]string, len(members))
+	for i, member := range members[1:] {
+		tokens[i+1], err = first.Client.RequestToken(ctx, member.Name)
+		report(member.Name, FormToken, err)
+		if err != nil {
+			return fmt.Errorf("Failed to issue join token for cluster member %q: %w", member.Name, err)
+		}
+	}
+
+	errs := make([]error, len(members))
+	wg := sync.WaitGroup{}
+	for i := 1; i < len(members); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			member := members[i]
+			err := joinMember(ctx, member.Client, internalTypes.Control{JoinToken: tokens[i], Address: addrs[i], Name: member.Name, InitConfig: member.InitConfig}, timeout)
+			report(member.Name, FormJoin, err)
+			if err != nil {
+				errs[i] = fmt.Errorf("Failed to join cluster member %q: %w", member.Name, err)
+			}
+		}(i)
+	}
+
+	// Wait for all members to join and check for any errors.
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// joinMember sends the given join request to the daemon, retrying for as long as the cluster is busy with another join.
+func joinMember(ctx context.Context, c *Client, control internalTypes.Control, timeout time.Duration) error {
+	for {
+		err := c.ControlDaemon(ctx, control, timeout)
+		if !api.StatusErrorCheck(err, http.StatusServiceUnavailable) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package cluster
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t api_tokens.mapper.go
+//go:generate mapper reset
+//
+//go:generate mapper stmt -e internal_bearer_token objects table=internal_api_tokens
+//go:generate mapper stmt -e internal_bearer_token objects-by-Name table=internal_api_tokens
+//go:generate mapper stmt -e internal_bearer_token objects-by-Hash table=internal_api_tokens
+//go:generate mapper stmt -e internal_bearer_token id table=internal_api_tokens
+//go:generate mapper stmt -e internal_bearer_token create table=internal_api_tokens
+//go:generate mapper stmt -e internal_bearer_token delete-by-Name table=internal_api_tokens
+//
+//go:generate mapper method -e internal_bearer_token ID table=internal_api_tokens
+//go:generate mapper method -e internal_bearer_token Exists table=internal_api_tokens
+//go:generate mapper method -e internal_bearer_token GetMany table=internal_api_tokens
+//go:generate mapper method -e internal_bearer_token Create table=internal_api_tokens
+//go:generate mapper method -e internal_bearer_token DeleteOne-by-Name table=internal_api_tokens
+
+// InternalBearerToken is the database representation of a bearer token. Only the hash of the token is stored.
+type InternalBearerToken struct {
+	ID         int
+	Name       string `db:"primary=yes"`
+	Hash       string
+	Rules      ACLRules `db:"marshal=yes"`
+	ExpiryDate sql.NullTime
+}
+
+// InternalBearerTokenFilter is the filter struct for filtering results from generated methods.
+type InternalBearerTokenFilter struct {
+	ID   *int
+	Name *string
+	Hash *string
+}
+
+// HashAPIToken returns the hash of the given bearer token, as stored in the database.
+func HashAPIToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// ToAPI returns the api struct for an InternalBearerToken database entity.
+func (t InternalBearerToken) ToAPI() internalTypes.APIToken {
+	token := internalTypes.APIToken{
+		Name:  t.Name,
+		Rules: t.Rules,
+	}
+
+	if t.ExpiryDate.Valid {
+		token.ExpiresAt = t.ExpiryDate.Time
+	}
+
+	return token
+}
+
+// Expired returns whether the token has passed its expiry date. Tokens without an expiry date never expire.
+func (t InternalBearerToken) Expired() bool {
+	return t.ExpiryDate.Valid && time.Now().After(t.ExpiryDate.Time)
+}
+
+// GetInternalBearerTokenByHash returns the bearer token with the given hash.
+func GetInternalBearerTokenByHash(ctx context.Context, tx *sql.Tx, hash string) (*InternalBearerToken, error) {
+	tokens, err := GetInternalBearerTokens(ctx, tx, InternalBearerTokenFilter{Hash: &hash})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, api.StatusErrorf(http.StatusNotFound, "InternalBearerToken not found")
+	}
+
+	return &tokens[0], nil
+}
@@ -0,0 +1,165 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/canonical/lxd/shared/api"
+
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/rest/types"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t certificate_acls.mapper.go
+//go:generate mapper reset
+//
+//go:generate mapper stmt -e internal_restricted_certificate objects table=internal_certificate_acls
+//go:generate mapper stmt -e internal_restricted_certificate objects-by-Name table=internal_certificate_acls
+//go:generate mapper stmt -e internal_restricted_certificate objects-by-Fingerprint table=internal_certificate_acls
+//go:generate mapper stmt -e internal_restricted_certificate id table=internal_certificate_acls
+//go:generate mapper stmt -e internal_restricted_certificate create table=internal_certificate_acls
+//go:generate mapper stmt -e internal_restricted_certificate update table=internal_certificate_acls
+//go:generate mapper stmt -e internal_restricted_certificate delete-by-Name table=internal_certificate_acls
+//
+//go:generate mapper method -e internal_restricted_certificate ID table=internal_certificate_acls
+//go:generate mapper method -e internal_restricted_certificate Exists table=internal_certificate_acls
+//go:generate mapper method -e internal_restricted_certificate GetOne table=internal_certificate_acls
+//go:generate mapper method -e internal_restricted_certificate GetMany table=internal_certificate_acls
+//go:generate mapper method -e internal_restricted_certificate Create table=internal_certificate_acls
+//go:generate mapper method -e internal_restricted_certificate Update table=internal_certificate_acls
+//go:generate mapper method -e internal_restricted_certificate DeleteOne-by-Name table=internal_certificate_acls
+
+// InternalRestrictedCertificate is the database representation of a certificate restricted to a set of API endpoints.
+type InternalRestrictedCertificate struct {
+	ID          int
+	Name        string `db:"primary=yes"`
+	Fingerprint string
+	Certificate string
+	Rules       ACLRules `db:"marshal=yes"`
+}
+
+// InternalRestrictedCertificateFilter is the filter struct for filtering results from generated methods.
+type InternalRestrictedCertificateFilter struct {
+	ID          *int
+	Name        *string
+	Fingerprint *string
+}
+
+// ACLRules is a list of rules restricting access to the API. It is stored in the database as a JSON array.
+type ACLRules []internalTypes.ACLRule
+
+// MarshalDB implements query.Marshaler for ACLRules.
+func (r ACLRules) MarshalDB() (string, error) {
+	if r == nil {
+		r = ACLRules{}
+	}
+
+	data, err := json.Marshal([]internalTypes.ACLRule(r))
+	if err != nil {
+		return "", fmt.Errorf("Failed to encode ACL rules: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// UnmarshalDB implements query.Unmarshaler for ACLRules.
+func (r *ACLRules) UnmarshalDB(data string) error {
+	err := json.Unmarshal([]byte(data), (*[]internalTypes.ACLRule)(r))
+	if err != nil {
+		return fmt.Errorf("Failed to parse ACL rules: %w", err)
+	}
+
+	return nil
+}
+
+// ToAPI returns the api struct for an InternalRestrictedCertificate database entity.
+func (a InternalRestrictedCertificate) ToAPI() (*internalTypes.CertificateACL, error) {
+	cert, err := types.ParseX509Certificate(a.Certificate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &internalTypes.CertificateACL{
+		Name:        a.Name,
+		Fingerprint: a.Fingerprint,
+		Certificate: *cert,
+		Rules:       a.Rules,
+	}, nil
+}
+
+// Allows returns whether any rule of the certificate ACL allows the given HTTP method on the endpoint with the given
+// path.
+func (a InternalRestrictedCertificate) Allows(method string, endpoint string) bool {
+	return ACLRulesAllow(a.Rules, method, endpoint)
+}
+
+// ACLRulesAllow returns whether any of the given rules allows the given HTTP method on the endpoint with the given path.
+func ACLRulesAllow(rules []internalTypes.ACLRule, method string, endpoint string) bool {
+	for _, rule := range rules {
+		match, err := path.Match(rule.Path, endpoint)
+		if err != nil || !match {
+			continue
+		}
+
+		if len(rule.Methods) == 0 {
+			return true
+		}
+
+		for _, allowed := range rule.Methods {
+			if strings.EqualFold(allowed, method) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ValidateACLRules returns an error if any of the given rules has an invalid path pattern.
+func ValidateACLRules(rules []internalTypes.ACLRule) error {
+	for _, rule := range rules {
+		if rule.Path == "" {
+			return fmt.Errorf("ACL rule has no path")
+		}
+
+		_, err := path.Match(rule.Path, "")
+		if err != nil {
+			return fmt.Errorf("Invalid ACL rule path %q: %w", rule.Path, err)
+		}
+	}
+
+	return nil
+}
+
+// GetInternalRestrictedCertificateByFingerprint returns the certificate ACL for the certificate with the given fingerprint.
+func GetInternalRestrictedCertificateByFingerprint(ctx context.Context, tx *sql.Tx, fingerprint string) (*InternalRestrictedCertificate, error) {
+	acls, err := GetInternalRestrictedCertificates(ctx, tx, InternalRestrictedCertificateFilter{Fingerprint: &fingerprint})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(acls) == 0 {
+		return nil, api.StatusErrorf(http.StatusNotFound, "InternalRestrictedCertificate not found")
+	}
+
+	return &acls[0], nil
+}
+
+// UpdateInternalRestrictedCertificateRules replaces the rules of the certificate ACL with the given name.
+func UpdateInternalRestrictedCertificateRules(ctx context.Context, tx *sql.Tx, name string, rules []internalTypes.ACLRule) error {
+	acl, err := GetInternalRestrictedCertificate(ctx, tx, name)
+	if err != nil {
+		return err
+	}
+
+	acl.Rules = rules
+
+	return UpdateInternalRestrictedCertificate(ctx, tx, name, *acl)
+}
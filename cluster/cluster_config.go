@@ -0,0 +1,89 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t cluster_config.mapper.go
+//go:generate mapper reset
+//
+//go:generate mapper stmt -e internal_cluster_config objects table=internal_cluster_config
+//go:generate mapper stmt -e internal_cluster_config objects-by-Key table=internal_cluster_config
+//go:generate mapper stmt -e internal_cluster_config id table=internal_cluster_config
+//go:generate mapper stmt -e internal_cluster_config create table=internal_cluster_config
+//go:generate mapper stmt -e internal_cluster_config update table=internal_cluster_config
+//go:generate mapper stmt -e internal_cluster_config delete-by-Key table=internal_cluster_config
+//
+//go:generate mapper method -e internal_cluster_config ID table=internal_cluster_config
+//go:generate mapper method -e internal_cluster_config Exists table=internal_cluster_config
+//go:generate mapper method -e internal_cluster_config GetMany table=internal_cluster_config
+//go:generate mapper method -e internal_cluster_config Create table=internal_cluster_config
+//go:generate mapper method -e internal_cluster_config Update table=internal_cluster_config
+//go:generate mapper method -e internal_cluster_config DeleteOne-by-Key table=internal_cluster_config
+
+// InternalClusterConfig is the database representation of a key of the cluster-wide config.
+type InternalClusterConfig struct {
+	ID    int
+	Key   string `db:"primary=yes"`
+	Value string
+}
+
+// InternalClusterConfigFilter is the filter struct for filtering results from generated methods.
+type InternalClusterConfigFilter struct {
+	ID  *int
+	Key *string
+}
+
+// GetClusterConfig returns the cluster-wide key/value config.
+func GetClusterConfig(ctx context.Context, tx *sql.Tx) (map[string]string, error) {
+	entries, err := GetInternalClusterConfig(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	config := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		config[entry.Key] = entry.Value
+	}
+
+	return config, nil
+}
+
+// UpdateClusterConfig sets the given keys in the cluster-wide config, leaving other keys untouched.
+// Keys with empty values are removed.
+func UpdateClusterConfig(ctx context.Context, tx *sql.Tx, values map[string]string) error {
+	for key, value := range values {
+		exists, err := InternalClusterConfigExists(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+
+		if value == "" {
+			if exists {
+				err = DeleteInternalClusterConfig(ctx, tx, key)
+				if err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		entry := InternalClusterConfig{Key: key, Value: value}
+		if exists {
+			err = UpdateInternalClusterConfig(ctx, tx, key, entry)
+		} else {
+			_, err = CreateInternalClusterConfig(ctx, tx, entry)
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed to set cluster config key %q: %w", key, err)
+		}
+	}
+
+	return nil
+}
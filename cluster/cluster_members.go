@@ -3,10 +3,13 @@ package cluster
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/api"
 
 	internalTypes "github.com/canonical/microcluster/internal/rest/types"
 	"github.com/canonical/microcluster/rest/types"
@@ -54,8 +57,30 @@ type InternalClusterMemberFilter struct {
 	Name    *string
 }
 
+// OfflineThreshold is the time since the last heartbeat after which a cluster member is considered offline.
+// Cluster members are considered suspect after half of this time.
+var OfflineThreshold = 3 * time.Minute
+
+// Status returns the status of the cluster member based on the time since its last heartbeat.
+// Cluster members that have not yet received a heartbeat are reported as unreachable.
+func (c InternalClusterMember) Status() internalTypes.MemberStatus {
+	if c.Heartbeat.IsZero() {
+		return internalTypes.MemberUnreachable
+	}
+
+	elapsed := time.Since(c.Heartbeat)
+	if elapsed > OfflineThreshold {
+		return internalTypes.MemberOffline
+	}
+
+	if elapsed > OfflineThreshold/2 {
+		return internalTypes.MemberSuspect
+	}
+
+	return internalTypes.MemberOnline
+}
+
 // ToAPI returns the api struct for a ClusterMember database entity.
-// The cluster member's status will be reported as unreachable by default.
 func (c InternalClusterMember) ToAPI() (*internalTypes.ClusterMember, error) {
 	address, err := types.ParseAddrPort(c.Address)
 	if err != nil {
@@ -76,7 +101,7 @@ func (c InternalClusterMember) ToAPI() (*internalTypes.ClusterMember, error) {
 		Role:          string(c.Role),
 		SchemaVersion: c.Schema,
 		LastHeartbeat: c.Heartbeat,
-		Status:        internalTypes.MemberUnreachable,
+		Status:        c.Status(),
 	}, nil
 }
 
@@ -100,6 +125,324 @@ func UpdateClusterMemberSchemaVersion(tx *sql.Tx, version int, address string) e
 	return nil
 }
 
+// UpdateClusterMemberFailureDomain sets the failure domain of the cluster member with the given address.
+func UpdateClusterMemberFailureDomain(ctx context.Context, tx *sql.Tx, address string, domain uint64) error {
+	_, err := tx.ExecContext(ctx, "UPDATE internal_cluster_members SET failure_domain = ? WHERE address = ?", int64(domain), address)
+	if err != nil {
+		return fmt.Errorf("Failed to update failure domain of cluster member with address %q: %w", address, err)
+	}
+
+	return nil
+}
+
+// GetClusterMemberFailureDomains returns the failure domains of all cluster members, keyed by name.
+func GetClusterMemberFailureDomains(ctx context.Context, tx *sql.Tx) (map[string]uint64, error) {
+	domains := map[string]uint64{}
+	dest := func(scan func(dest ...any) error) error {
+		var name string
+		var domain int64
+		err := scan(&name, &domain)
+		if err != nil {
+			return err
+		}
+
+		domains[name] = uint64(domain)
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, "SELECT name, failure_domain FROM internal_cluster_members", dest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"internal_cluster_members\" table: %w", err)
+	}
+
+	return domains, nil
+}
+
+// UpdateClusterMemberAPIExtensions sets the API extensions supported by the cluster member with the given address.
+func UpdateClusterMemberAPIExtensions(ctx context.Context, tx *sql.Tx, address string, extensions []string) error {
+	if extensions == nil {
+		extensions = []string{}
+	}
+
+	extensionsJSON, err := json.Marshal(extensions)
+	if err != nil {
+		return fmt.Errorf("Failed to encode API extensions: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE internal_cluster_members SET api_extensions = ? WHERE address = ?", string(extensionsJSON), address)
+	if err != nil {
+		return fmt.Errorf("Failed to update API extensions of cluster member with address %q: %w", address, err)
+	}
+
+	return nil
+}
+
+// GetClusterMemberAPIExtensions returns the API extensions supported by all cluster members, keyed by name.
+func GetClusterMemberAPIExtensions(ctx context.Context, tx *sql.Tx) (map[string][]string, error) {
+	extensions := map[string][]string{}
+	dest := func(scan func(dest ...any) error) error {
+		var name, extensionsJSON string
+		err := scan(&name, &extensionsJSON)
+		if err != nil {
+			return err
+		}
+
+		memberExtensions := []string{}
+		err = json.Unmarshal([]byte(extensionsJSON), &memberExtensions)
+		if err != nil {
+			return fmt.Errorf("Failed to parse API extensions of cluster member %q: %w", name, err)
+		}
+
+		extensions[name] = memberExtensions
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, "SELECT name, api_extensions FROM internal_cluster_members", dest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"internal_cluster_members\" table: %w", err)
+	}
+
+	return extensions, nil
+}
+
+// UpdateClusterMemberCordoned sets whether the cluster member with the given name is cordoned for maintenance.
+func UpdateClusterMemberCordoned(ctx context.Context, tx *sql.Tx, name string, cordoned bool) error {
+	result, err := tx.ExecContext(ctx, "UPDATE internal_cluster_members SET cordoned = ? WHERE name = ?", cordoned, name)
+	if err != nil {
+		return fmt.Errorf("Failed to update cordon of cluster member %q: %w", name, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Fetch affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return api.StatusErrorf(http.StatusNotFound, "InternalClusterMember not found")
+	}
+
+	return nil
+}
+
+// GetClusterMemberCordons returns whether each cluster member is cordoned for maintenance, keyed by name.
+func GetClusterMemberCordons(ctx context.Context, tx *sql.Tx) (map[string]bool, error) {
+	cordons := map[string]bool{}
+	dest := func(scan func(dest ...any) error) error {
+		var name string
+		var cordoned bool
+		err := scan(&name, &cordoned)
+		if err != nil {
+			return err
+		}
+
+		cordons[name] = cordoned
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, "SELECT name, cordoned FROM internal_cluster_members", dest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"internal_cluster_members\" table: %w", err)
+	}
+
+	return cordons, nil
+}
+
+// GetCordonedClusterMemberAddresses returns the addresses of all cluster members cordoned for maintenance.
+func GetCordonedClusterMemberAddresses(ctx context.Context, tx *sql.Tx) (map[string]bool, error) {
+	addresses := map[string]bool{}
+	dest := func(scan func(dest ...any) error) error {
+		var address string
+		err := scan(&address)
+		if err != nil {
+			return err
+		}
+
+		addresses[address] = true
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, "SELECT address FROM internal_cluster_members WHERE cordoned = 1", dest)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"internal_cluster_members\" table: %w", err)
+	}
+
+	return addresses, nil
+}
+
+// MaxAppStatusSize is the maximum size in bytes of the JSON-encoded application status of a cluster member.
+const MaxAppStatusSize = 4096
+
+// UpdateClusterMemberAppStatus sets the application status fields of the cluster member with the given name.
+func UpdateClusterMemberAppStatus(ctx context.Context, tx *sql.Tx, name string, status map[string]any) error {
+	if status == nil {
+		status = map[string]any{}
+	}
+
+	statusJSON, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("Failed to encode application status: %w", err)
+	}
+
+	if len(statusJSON) > MaxAppStatusSize {
+		return fmt.Errorf("Application status of %d bytes exceeds the maximum of %d bytes", len(statusJSON), MaxAppStatusSize)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE internal_cluster_members SET app_status = ? WHERE name = ?", string(statusJSON), name)
+	if err != nil {
+		return fmt.Errorf("Failed to update application status of cluster member %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// GetClusterMemberAppStatuses returns the application status fields of all cluster members, keyed by name.
+func GetClusterMemberAppStatuses(ctx context.Context, tx *sql.Tx) (map[string]map[string]any, error) {
+	statuses := map[string]map[string]any{}
+	dest := func(scan func(dest ...any) error) error {
+		var name, statusJSON string
+		err := scan(&name, &statusJSON)
+		if err != nil {
+			return err
+		}
+
+		status := map[string]any{}
+		err = json.Unmarshal([]byte(statusJSON), &status)
+		if err != nil {
+			return fmt.Errorf("Failed to parse application status of cluster member %q: %w", name, err)
+		}
+
+		statuses[name] = status
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, "SELECT name, app_status FROM internal_cluster_members", dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+// GetClusterMemberConfig returns the user metadata of the cluster member with the given name.
+func GetClusterMemberConfig(ctx context.Context, tx *sql.Tx, name string) (map[string]string, error) {
+	configs, err := getClusterMemberConfigs(ctx, tx, "SELECT name, config FROM internal_cluster_members WHERE name = ?", name)
+	if err != nil {
+		return nil, err
+	}
+
+	config, ok := configs[name]
+	if !ok {
+		return nil, api.StatusErrorf(http.StatusNotFound, "InternalClusterMember not found")
+	}
+
+	return config, nil
+}
+
+// GetClusterMemberConfigs returns the user metadata of all cluster members, keyed by name.
+func GetClusterMemberConfigs(ctx context.Context, tx *sql.Tx) (map[string]map[string]string, error) {
+	return getClusterMemberConfigs(ctx, tx, "SELECT name, config FROM internal_cluster_members")
+}
+
+func getClusterMemberConfigs(ctx context.Context, tx *sql.Tx, stmt string, args ...any) (map[string]map[string]string, error) {
+	configs := map[string]map[string]string{}
+	dest := func(scan func(dest ...any) error) error {
+		var name, configJSON string
+		err := scan(&name, &configJSON)
+		if err != nil {
+			return err
+		}
+
+		config := map[string]string{}
+		err = json.Unmarshal([]byte(configJSON), &config)
+		if err != nil {
+			return fmt.Errorf("Failed to parse config of cluster member %q: %w", name, err)
+		}
+
+		configs[name] = config
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, stmt, dest, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"internal_cluster_members\" table: %w", err)
+	}
+
+	return configs, nil
+}
+
+// UpdateClusterMemberConfig replaces the user metadata of the cluster member with the given name.
+func UpdateClusterMemberConfig(ctx context.Context, tx *sql.Tx, name string, config map[string]string) error {
+	if config == nil {
+		config = map[string]string{}
+	}
+
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("Failed to encode config: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE internal_cluster_members SET config = ? WHERE name = ?", string(configJSON), name)
+	if err != nil {
+		return fmt.Errorf("Failed to update config of cluster member %q: %w", name, err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Fetch affected rows: %w", err)
+	}
+
+	if n == 0 {
+		return api.StatusErrorf(http.StatusNotFound, "InternalClusterMember not found")
+	}
+
+	return nil
+}
+
+// GetClusterMemberSchemaVersion returns the name and schema version of the cluster member with the given address.
+// This helper is non-generated to work before generated statements are loaded, as we update the schema.
+func GetClusterMemberSchemaVersion(ctx context.Context, tx *sql.Tx, address string) (string, int, error) {
+	var name string
+	var version int
+	err := tx.QueryRowContext(ctx, "SELECT name, schema FROM internal_cluster_members WHERE address=?", address).Scan(&name, &version)
+	if err != nil {
+		return "", 0, err
+	}
+
+	return name, version, nil
+}
+
+// GetClusterMemberSchemaVersionsByName returns the schema versions from all cluster members that are not pending,
+// keyed by cluster member name.
+// This helper is non-generated to work before generated statements are loaded, as we update the schema.
+func GetClusterMemberSchemaVersionsByName(ctx context.Context, tx *sql.Tx) (map[string]int, error) {
+	versions := map[string]int{}
+	dest := func(scan func(dest ...any) error) error {
+		var name string
+		var version int
+		err := scan(&name, &version)
+		if err != nil {
+			return err
+		}
+
+		versions[name] = version
+
+		return nil
+	}
+
+	err := query.Scan(ctx, tx, "SELECT name, schema FROM internal_cluster_members WHERE NOT role='pending'", dest)
+	if err != nil {
+		return nil, err
+	}
+
+	return versions, nil
+}
+
 // GetClusterMemberSchemaVersions returns the schema versions from all cluster members that are not pending.
 // This helper is non-generated to work before generated statements are loaded, as we update the schema.
 func GetClusterMemberSchemaVersions(ctx context.Context, tx *sql.Tx) ([]int, error) {
@@ -0,0 +1,183 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+)
+
+// EventType is the kind of cluster membership change recorded by an event.
+type EventType string
+
+const (
+	// EventMemberJoined is recorded when a cluster member bootstraps or joins the cluster.
+	EventMemberJoined EventType = "member-joined"
+
+	// EventMemberRemoved is recorded when a cluster member is removed from the cluster.
+	EventMemberRemoved EventType = "member-removed"
+
+	// EventMemberRoleChanged is recorded when the dqlite role of a cluster member changes.
+	EventMemberRoleChanged EventType = "member-role-changed"
+
+	// EventMemberOffline is recorded when a cluster member stops responding to heartbeats.
+	EventMemberOffline EventType = "member-offline"
+
+	// EventMemberOnline is recorded when an offline cluster member responds to heartbeats again.
+	EventMemberOnline EventType = "member-online"
+
+	// EventMemberUpgraded is recorded when a cluster member starts with a newer schema version.
+	EventMemberUpgraded EventType = "member-upgraded"
+
+	// EventCertificateExpiring is recorded when a certificate is about to expire. The member of the event is the
+	// entity the certificate belongs to.
+	EventCertificateExpiring EventType = "certificate-expiring"
+
+	// EventCertificateExpired is recorded when a certificate has expired. The member of the event is the entity the
+	// certificate belongs to.
+	EventCertificateExpired EventType = "certificate-expired"
+
+	// EventApplication is recorded by the application on a cluster member.
+	EventApplication EventType = "application"
+)
+
+// EventTypes lists every valid EventType.
+var EventTypes = []EventType{EventMemberJoined, EventMemberRemoved, EventMemberRoleChanged, EventMemberOffline, EventMemberOnline, EventMemberUpgraded, EventCertificateExpiring, EventCertificateExpired, EventApplication}
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t events.mapper.go
+//go:generate mapper reset
+//
+//go:generate mapper stmt -e internal_event objects table=internal_events
+//go:generate mapper stmt -e internal_event objects-by-Type table=internal_events
+//go:generate mapper stmt -e internal_event objects-by-Member table=internal_events
+//go:generate mapper stmt -e internal_event objects-by-Type-and-Member table=internal_events
+//go:generate mapper stmt -e internal_event id table=internal_events
+//go:generate mapper stmt -e internal_event create table=internal_events
+//
+//go:generate mapper method -e internal_event ID table=internal_events
+//go:generate mapper method -e internal_event Exists table=internal_events
+//go:generate mapper method -e internal_event GetMany table=internal_events
+//go:generate mapper method -e internal_event Create table=internal_events
+
+// InternalEvent is the database representation of a cluster membership event. Events are listed in the order they
+// were recorded.
+type InternalEvent struct {
+	ID        int64     `db:"order=yes"`
+	Type      EventType `db:"primary=yes"`
+	Member    string    `db:"primary=yes"`
+	Message   string
+	CreatedAt time.Time `db:"primary=yes"`
+}
+
+// InternalEventFilter is the filter struct for filtering results from generated methods.
+type InternalEventFilter struct {
+	ID     *int64
+	Type   *EventType
+	Member *string
+}
+
+// ToAPI returns the api struct for an InternalEvent database entity.
+func (e InternalEvent) ToAPI() internalTypes.Event {
+	return internalTypes.Event{
+		ID:        e.ID,
+		Type:      string(e.Type),
+		Member:    e.Member,
+		Message:   e.Message,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+// GetInternalEventsAfter returns the events matching the filter that were recorded after the event with the given
+// ID and after the given time, oldest first. A zero ID or time does not limit the results.
+func GetInternalEventsAfter(ctx context.Context, tx *sql.Tx, filter InternalEventFilter, afterID int64, since time.Time) ([]InternalEvent, error) {
+	clauses := []string{}
+	args := []any{}
+	if filter.Type != nil {
+		clauses = append(clauses, "internal_events.type = ?")
+		args = append(args, string(*filter.Type))
+	}
+
+	if filter.Member != nil {
+		clauses = append(clauses, "internal_events.member = ?")
+		args = append(args, *filter.Member)
+	}
+
+	if afterID > 0 {
+		clauses = append(clauses, "internal_events.id > ?")
+		args = append(args, afterID)
+	}
+
+	if !since.IsZero() {
+		clauses = append(clauses, "internal_events.created_at > ?")
+		args = append(args, since)
+	}
+
+	where := ""
+	if len(clauses) > 0 {
+		where = "\n  WHERE " + strings.Join(clauses, " AND ")
+	}
+
+	stmt := fmt.Sprintf("SELECT %s\n  FROM internal_events%s\n  ORDER BY internal_events.id", internalEventColumns(), where)
+
+	return getInternalEventsRaw(ctx, tx, stmt, args...)
+}
+
+var internalEventsLastID = RegisterStmt(`
+SELECT COALESCE(MAX(internal_events.id), 0) FROM internal_events
+`)
+
+var internalEventsDeleteBefore = RegisterStmt(`
+DELETE FROM internal_events WHERE created_at < ?
+`)
+
+// GetInternalEventsLastID returns the ID of the most recently recorded event, or zero if there is none.
+func GetInternalEventsLastID(ctx context.Context, tx *sql.Tx) (int64, error) {
+	stmt, err := Stmt(tx, internalEventsLastID)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get \"internalEventsLastID\" prepared statement: %w", err)
+	}
+
+	var id int64
+	err = stmt.QueryRowContext(ctx).Scan(&id)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to fetch last \"internal_events\" entry ID: %w", err)
+	}
+
+	return id, nil
+}
+
+// NewInternalEvent returns an event of the given type for the given cluster member, recorded now.
+func NewInternalEvent(eventType EventType, member string, message string) InternalEvent {
+	return InternalEvent{
+		Type:      eventType,
+		Member:    member,
+		Message:   message,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// DeleteInternalEventsBefore deletes all events recorded before the given time, and returns the number of deleted
+// events.
+func DeleteInternalEventsBefore(ctx context.Context, tx *sql.Tx, before time.Time) (int64, error) {
+	stmt, err := Stmt(tx, internalEventsDeleteBefore)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get \"internalEventsDeleteBefore\" prepared statement: %w", err)
+	}
+
+	result, err := stmt.ExecContext(ctx, before)
+	if err != nil {
+		return -1, fmt.Errorf("Delete \"internal_events\": %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return -1, fmt.Errorf("Fetch affected rows: %w", err)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,118 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"hash/fnv"
+
+	"github.com/canonical/lxd/shared"
+
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t feature_flags.mapper.go
+//go:generate mapper reset
+//
+//go:generate mapper stmt -e internal_feature_flag objects table=internal_feature_flags
+//go:generate mapper stmt -e internal_feature_flag objects-by-Name table=internal_feature_flags
+//go:generate mapper stmt -e internal_feature_flag id table=internal_feature_flags
+//go:generate mapper stmt -e internal_feature_flag create table=internal_feature_flags
+//go:generate mapper stmt -e internal_feature_flag update table=internal_feature_flags
+//go:generate mapper stmt -e internal_feature_flag delete-by-Name table=internal_feature_flags
+//
+//go:generate mapper method -e internal_feature_flag ID table=internal_feature_flags
+//go:generate mapper method -e internal_feature_flag Exists table=internal_feature_flags
+//go:generate mapper method -e internal_feature_flag GetOne table=internal_feature_flags
+//go:generate mapper method -e internal_feature_flag GetMany table=internal_feature_flags
+//go:generate mapper method -e internal_feature_flag Create table=internal_feature_flags
+//go:generate mapper method -e internal_feature_flag Update table=internal_feature_flags
+//go:generate mapper method -e internal_feature_flag DeleteOne-by-Name table=internal_feature_flags
+
+// InternalFeatureFlag is the database representation of a cluster-wide feature flag.
+type InternalFeatureFlag struct {
+	ID         int
+	Name       string `db:"primary=yes"`
+	Enabled    bool
+	Members    FeatureFlagMembers `db:"marshal=yes"`
+	Percentage int
+}
+
+// InternalFeatureFlagFilter is the filter struct for filtering results from generated methods.
+type InternalFeatureFlagFilter struct {
+	ID   *int
+	Name *string
+}
+
+// FeatureFlagMembers is the list of cluster members a feature flag is explicitly enabled for. It is stored in the
+// database as a JSON array.
+type FeatureFlagMembers []string
+
+// MarshalDB implements query.Marshaler for FeatureFlagMembers.
+func (m FeatureFlagMembers) MarshalDB() (string, error) {
+	if m == nil {
+		m = FeatureFlagMembers{}
+	}
+
+	data, err := json.Marshal([]string(m))
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// UnmarshalDB implements query.Unmarshaler for FeatureFlagMembers.
+func (m *FeatureFlagMembers) UnmarshalDB(data string) error {
+	return json.Unmarshal([]byte(data), (*[]string)(m))
+}
+
+// EnabledFor returns whether the feature flag is enabled for the cluster member with the given name.
+// A flag is enabled for a member if it is enabled cluster-wide, if the member is explicitly listed, or if the member
+// falls within the rollout percentage. Percentage rollout is deterministic for a given flag and member name.
+func (f InternalFeatureFlag) EnabledFor(member string) bool {
+	if f.Enabled || shared.ValueInSlice(member, f.Members) {
+		return true
+	}
+
+	if f.Percentage <= 0 {
+		return false
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(f.Name + "/" + member))
+
+	return int(h.Sum32()%100) < f.Percentage
+}
+
+// ToAPI returns the api struct for an InternalFeatureFlag database entity.
+func (f InternalFeatureFlag) ToAPI() internalTypes.FeatureFlag {
+	return internalTypes.FeatureFlag{
+		Name:       f.Name,
+		Enabled:    f.Enabled,
+		Members:    f.Members,
+		Percentage: f.Percentage,
+	}
+}
+
+// UpsertInternalFeatureFlag creates the given feature flag, or replaces it if a flag with the same name exists.
+func UpsertInternalFeatureFlag(ctx context.Context, tx *sql.Tx, flag InternalFeatureFlag) error {
+	if flag.Members == nil {
+		flag.Members = FeatureFlagMembers{}
+	}
+
+	exists, err := InternalFeatureFlagExists(ctx, tx, flag.Name)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return UpdateInternalFeatureFlag(ctx, tx, flag.Name, flag)
+	}
+
+	_, err = CreateInternalFeatureFlag(ctx, tx, flag)
+
+	return err
+}
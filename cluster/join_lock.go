@@ -0,0 +1,72 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t join_lock.mapper.go
+//go:generate mapper reset
+//
+//go:generate mapper stmt -e internal_join_lock objects table=internal_join_lock
+//go:generate mapper stmt -e internal_join_lock objects-by-Name table=internal_join_lock
+//go:generate mapper stmt -e internal_join_lock id table=internal_join_lock
+//go:generate mapper stmt -e internal_join_lock create table=internal_join_lock
+//go:generate mapper stmt -e internal_join_lock delete-by-Name table=internal_join_lock
+//
+//go:generate mapper method -e internal_join_lock ID table=internal_join_lock
+//go:generate mapper method -e internal_join_lock Exists table=internal_join_lock
+//go:generate mapper method -e internal_join_lock GetMany table=internal_join_lock
+//go:generate mapper method -e internal_join_lock Create table=internal_join_lock
+//go:generate mapper method -e internal_join_lock DeleteMany-by-Name table=internal_join_lock
+
+// InternalJoinLock is the database representation of the join lock held by a joining cluster member.
+type InternalJoinLock struct {
+	ID         int
+	Name       string `db:"primary=yes"`
+	ExpiryDate time.Time
+}
+
+// InternalJoinLockFilter is the filter struct for filtering results from generated methods.
+type InternalJoinLockFilter struct {
+	ID   *int
+	Name *string
+}
+
+// AcquireJoinLock records that the cluster member with the given name is joining the cluster, so that other cluster
+// members cannot join until it has finished or the lock has expired. The lock may be taken again by the same cluster
+// member, for instance when retrying a join. If another cluster member holds the lock, a 503 error is returned.
+func AcquireJoinLock(ctx context.Context, tx *sql.Tx, name string, expiry time.Duration) error {
+	locks, err := GetInternalJoinLocks(ctx, tx)
+	if err != nil {
+		return err
+	}
+
+	for _, lock := range locks {
+		if lock.Name != name && time.Now().Before(lock.ExpiryDate) {
+			return api.StatusErrorf(http.StatusServiceUnavailable, "Cluster member %q is currently joining the cluster, retry later", lock.Name)
+		}
+	}
+
+	for _, lock := range locks {
+		err = DeleteInternalJoinLocks(ctx, tx, lock.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = CreateInternalJoinLock(ctx, tx, InternalJoinLock{Name: name, ExpiryDate: time.Now().Add(expiry).UTC()})
+
+	return err
+}
+
+// ReleaseJoinLock releases the join lock if it is held by the cluster member with the given name.
+func ReleaseJoinLock(ctx context.Context, tx *sql.Tx, name string) error {
+	return DeleteInternalJoinLocks(ctx, tx, name)
+}
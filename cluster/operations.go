@@ -0,0 +1,96 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+)
+
+// OperationStatus is the result of a completed operation.
+type OperationStatus string
+
+const (
+	// OperationSuccess indicates that the operation completed without error.
+	OperationSuccess OperationStatus = "success"
+
+	// OperationFailed indicates that the operation returned an error.
+	OperationFailed OperationStatus = "failed"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t operations.mapper.go
+//go:generate mapper reset
+//
+//go:generate mapper stmt -e internal_operation objects table=internal_operations
+//go:generate mapper stmt -e internal_operation objects-by-Type table=internal_operations
+//go:generate mapper stmt -e internal_operation objects-by-Status table=internal_operations
+//go:generate mapper stmt -e internal_operation objects-by-Type-and-Status table=internal_operations
+//go:generate mapper stmt -e internal_operation id table=internal_operations
+//go:generate mapper stmt -e internal_operation create table=internal_operations
+//
+//go:generate mapper method -e internal_operation ID table=internal_operations
+//go:generate mapper method -e internal_operation Exists table=internal_operations
+//go:generate mapper method -e internal_operation GetMany table=internal_operations
+//go:generate mapper method -e internal_operation Create table=internal_operations
+
+// InternalOperation is the database representation of a completed operation. Operations are listed in the order
+// they were started.
+type InternalOperation struct {
+	ID        int
+	Type      string `db:"primary=yes"`
+	Initiator string
+	Member    string    `db:"primary=yes"`
+	StartedAt time.Time `db:"primary=yes&order=yes"`
+	Duration  time.Duration
+	Status    OperationStatus
+	Error     string
+}
+
+// InternalOperationFilter is the filter struct for filtering results from generated methods.
+type InternalOperationFilter struct {
+	ID     *int
+	Type   *string
+	Status *OperationStatus
+}
+
+// ToAPI returns the api struct for an InternalOperation database entity.
+func (o InternalOperation) ToAPI() internalTypes.Operation {
+	return internalTypes.Operation{
+		Type:      o.Type,
+		Initiator: o.Initiator,
+		Member:    o.Member,
+		StartedAt: o.StartedAt,
+		Duration:  o.Duration,
+		Status:    string(o.Status),
+		Error:     o.Error,
+	}
+}
+
+var internalOperationsDeleteBefore = RegisterStmt(`
+DELETE FROM internal_operations WHERE started_at < ?
+`)
+
+// DeleteInternalOperationsBefore deletes all operations started before the given time, and returns the number of
+// deleted operations.
+func DeleteInternalOperationsBefore(ctx context.Context, tx *sql.Tx, before time.Time) (int64, error) {
+	stmt, err := Stmt(tx, internalOperationsDeleteBefore)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get \"internalOperationsDeleteBefore\" prepared statement: %w", err)
+	}
+
+	result, err := stmt.ExecContext(ctx, before)
+	if err != nil {
+		return -1, fmt.Errorf("Delete \"internal_operations\": %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return -1, fmt.Errorf("Fetch affected rows: %w", err)
+	}
+
+	return n, nil
+}
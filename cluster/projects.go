@@ -0,0 +1,128 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+)
+
+// DefaultProject is the name of the project that always exists, and is used when no project is specified.
+const DefaultProject = "default"
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t projects.mapper.go
+//go:generate mapper reset
+//
+//go:generate mapper stmt -e internal_project objects table=internal_projects
+//go:generate mapper stmt -e internal_project objects-by-Name table=internal_projects
+//go:generate mapper stmt -e internal_project id table=internal_projects
+//go:generate mapper stmt -e internal_project create table=internal_projects
+//go:generate mapper stmt -e internal_project update table=internal_projects
+//go:generate mapper stmt -e internal_project delete-by-Name table=internal_projects
+//
+//go:generate mapper method -e internal_project ID table=internal_projects
+//go:generate mapper method -e internal_project Exists table=internal_projects
+//go:generate mapper method -e internal_project GetOne table=internal_projects
+//go:generate mapper method -e internal_project GetMany table=internal_projects
+//go:generate mapper method -e internal_project Create table=internal_projects
+//go:generate mapper method -e internal_project Update table=internal_projects
+//go:generate mapper method -e internal_project DeleteOne-by-Name table=internal_projects
+//
+//go:generate mapper stmt -e internal_project_config objects table=internal_projects_config
+//go:generate mapper stmt -e internal_project_config objects-by-ProjectID table=internal_projects_config
+//go:generate mapper stmt -e internal_project_config id table=internal_projects_config
+//go:generate mapper stmt -e internal_project_config create table=internal_projects_config
+//go:generate mapper stmt -e internal_project_config delete-by-ProjectID table=internal_projects_config
+//
+//go:generate mapper method -e internal_project_config ID table=internal_projects_config
+//go:generate mapper method -e internal_project_config Exists table=internal_projects_config
+//go:generate mapper method -e internal_project_config GetMany table=internal_projects_config
+//go:generate mapper method -e internal_project_config Create table=internal_projects_config
+//go:generate mapper method -e internal_project_config DeleteMany-by-ProjectID table=internal_projects_config
+
+// InternalProject is the database representation of a project.
+// Application tables can be scoped to a project with a "project_id" column referencing "internal_projects (id)".
+type InternalProject struct {
+	ID          int
+	Name        string `db:"primary=yes"`
+	Description string
+}
+
+// InternalProjectFilter is the filter struct for filtering results from generated methods.
+type InternalProjectFilter struct {
+	ID   *int
+	Name *string
+}
+
+// InternalProjectConfig is the database representation of a config key of a project.
+type InternalProjectConfig struct {
+	ID        int
+	ProjectID int    `db:"primary=yes"`
+	Key       string `db:"primary=yes"`
+	Value     string
+}
+
+// InternalProjectConfigFilter is the filter struct for filtering results from generated methods.
+type InternalProjectConfigFilter struct {
+	ID        *int
+	ProjectID *int
+	Key       *string
+}
+
+// ToAPI returns the api struct for an InternalProject database entity, with the given config.
+func (p InternalProject) ToAPI(config map[string]string) internalTypes.Project {
+	return internalTypes.Project{
+		Name:        p.Name,
+		Description: p.Description,
+		Config:      config,
+	}
+}
+
+// GetProjectConfig returns the config of the project with the given name.
+func GetProjectConfig(ctx context.Context, tx *sql.Tx, name string) (map[string]string, error) {
+	id, err := GetInternalProjectID(ctx, tx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	projectID := int(id)
+	entries, err := GetInternalProjectConfig(ctx, tx, InternalProjectConfigFilter{ProjectID: &projectID})
+	if err != nil {
+		return nil, err
+	}
+
+	config := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		config[entry.Key] = entry.Value
+	}
+
+	return config, nil
+}
+
+// UpdateProjectConfig replaces the config of the project with the given name.
+func UpdateProjectConfig(ctx context.Context, tx *sql.Tx, name string, config map[string]string) error {
+	id, err := GetInternalProjectID(ctx, tx, name)
+	if err != nil {
+		return err
+	}
+
+	err = DeleteInternalProjectConfig(ctx, tx, int(id))
+	if err != nil {
+		return err
+	}
+
+	for key, value := range config {
+		if value == "" {
+			continue
+		}
+
+		_, err = CreateInternalProjectConfig(ctx, tx, InternalProjectConfig{ProjectID: int(id), Key: key, Value: value})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t secrets.mapper.go
+//go:generate mapper reset
+//
+//go:generate mapper stmt -e internal_secret objects table=internal_secrets
+//go:generate mapper stmt -e internal_secret objects-by-Name table=internal_secrets
+//go:generate mapper stmt -e internal_secret id table=internal_secrets
+//go:generate mapper stmt -e internal_secret create table=internal_secrets
+//go:generate mapper stmt -e internal_secret update table=internal_secrets
+//go:generate mapper stmt -e internal_secret delete-by-Name table=internal_secrets
+//
+//go:generate mapper method -e internal_secret ID table=internal_secrets
+//go:generate mapper method -e internal_secret Exists table=internal_secrets
+//go:generate mapper method -e internal_secret GetOne table=internal_secrets
+//go:generate mapper method -e internal_secret GetMany table=internal_secrets
+//go:generate mapper method -e internal_secret Create table=internal_secrets
+//go:generate mapper method -e internal_secret Update table=internal_secrets
+//go:generate mapper method -e internal_secret DeleteOne-by-Name table=internal_secrets
+
+// InternalSecret is the database representation of a cluster-wide secret. The value is encrypted with the secrets key
+// of the cluster, and never stored in plaintext.
+type InternalSecret struct {
+	ID        int
+	Name      string `db:"primary=yes"`
+	Value     string
+	UpdatedAt time.Time
+}
+
+// InternalSecretFilter is the filter struct for filtering results from generated methods.
+type InternalSecretFilter struct {
+	ID   *int
+	Name *string
+}
+
+// SetInternalSecret sets the encrypted value of the secret with the given name, creating it if it does not exist.
+func SetInternalSecret(ctx context.Context, tx *sql.Tx, name string, value string) error {
+	secret := InternalSecret{Name: name, Value: value, UpdatedAt: time.Now().UTC()}
+
+	exists, err := InternalSecretExists(ctx, tx, name)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return UpdateInternalSecret(ctx, tx, name, secret)
+	}
+
+	_, err = CreateInternalSecret(ctx, tx, secret)
+
+	return err
+}
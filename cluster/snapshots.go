@@ -0,0 +1,190 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t snapshots.mapper.go
+//go:generate mapper reset
+//
+//go:generate mapper stmt -e internal_snapshot objects table=internal_snapshots
+//go:generate mapper stmt -e internal_snapshot objects-by-TakenAt table=internal_snapshots
+//go:generate mapper stmt -e internal_snapshot id table=internal_snapshots
+//go:generate mapper stmt -e internal_snapshot create table=internal_snapshots
+//
+//go:generate mapper method -e internal_snapshot ID table=internal_snapshots
+//go:generate mapper method -e internal_snapshot Exists table=internal_snapshots
+//go:generate mapper method -e internal_snapshot GetMany table=internal_snapshots
+//go:generate mapper method -e internal_snapshot Create table=internal_snapshots
+
+// InternalSnapshot is the database representation of a snapshot of the cluster membership and configuration.
+type InternalSnapshot struct {
+	ID      int
+	TakenAt time.Time            `db:"primary=yes"`
+	Data    InternalSnapshotData `db:"marshal=yes"`
+}
+
+// InternalSnapshotFilter is the filter struct for filtering results from generated methods.
+type InternalSnapshotFilter struct {
+	ID      *int
+	TakenAt *time.Time
+}
+
+// InternalSnapshotData is the content of a snapshot. It is stored in the database as JSON.
+type InternalSnapshotData internalTypes.Snapshot
+
+// MarshalDB implements query.Marshaler for InternalSnapshotData.
+func (d InternalSnapshotData) MarshalDB() (string, error) {
+	data, err := json.Marshal(internalTypes.Snapshot(d))
+	if err != nil {
+		return "", fmt.Errorf("Failed to encode snapshot: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// UnmarshalDB implements query.Unmarshaler for InternalSnapshotData.
+func (d *InternalSnapshotData) UnmarshalDB(data string) error {
+	err := json.Unmarshal([]byte(data), (*internalTypes.Snapshot)(d))
+	if err != nil {
+		return fmt.Errorf("Failed to parse snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// TakeSnapshot returns the current cluster membership and configuration.
+func TakeSnapshot(ctx context.Context, tx *sql.Tx) (*internalTypes.Snapshot, error) {
+	snapshot := &internalTypes.Snapshot{TakenAt: time.Now().UTC()}
+
+	config, err := GetClusterConfig(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.Config = config
+
+	clusterMembers, err := GetInternalClusterMembers(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	configs, err := GetClusterMemberConfigs(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	domains, err := GetClusterMemberFailureDomains(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.Members = make([]internalTypes.SnapshotMember, 0, len(clusterMembers))
+	for _, clusterMember := range clusterMembers {
+		apiClusterMember, err := clusterMember.ToAPI()
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot.Members = append(snapshot.Members, internalTypes.SnapshotMember{
+			Name:          apiClusterMember.Name,
+			Address:       apiClusterMember.Address,
+			Role:          apiClusterMember.Role,
+			SchemaVersion: apiClusterMember.SchemaVersion,
+			FailureDomain: domains[clusterMember.Name],
+			Config:        configs[clusterMember.Name],
+		})
+	}
+
+	flags, err := GetInternalFeatureFlags(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.FeatureFlags = make([]internalTypes.FeatureFlag, 0, len(flags))
+	for _, flag := range flags {
+		snapshot.FeatureFlags = append(snapshot.FeatureFlags, flag.ToAPI())
+	}
+
+	projects, err := GetInternalProjects(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.Projects = make([]internalTypes.Project, 0, len(projects))
+	for _, project := range projects {
+		config, err := GetProjectConfig(ctx, tx, project.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot.Projects = append(snapshot.Projects, project.ToAPI(config))
+	}
+
+	return snapshot, nil
+}
+
+var internalSnapshotObjectsAt = RegisterStmt(`
+SELECT internal_snapshots.id, internal_snapshots.taken_at, internal_snapshots.data
+  FROM internal_snapshots
+  WHERE internal_snapshots.taken_at <= ?
+  ORDER BY internal_snapshots.taken_at DESC
+  LIMIT 1
+`)
+
+var internalSnapshotsDeleteBefore = RegisterStmt(`
+DELETE FROM internal_snapshots WHERE taken_at < ?
+`)
+
+// GetInternalSnapshotAt returns the most recent snapshot taken at or before the given time.
+func GetInternalSnapshotAt(ctx context.Context, tx *sql.Tx, at time.Time) (*internalTypes.Snapshot, error) {
+	stmt, err := Stmt(tx, internalSnapshotObjectsAt)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get \"internalSnapshotObjectsAt\" prepared statement: %w", err)
+	}
+
+	snapshots, err := getInternalSnapshots(ctx, stmt, at)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to fetch from \"internal_snapshots\" table: %w", err)
+	}
+
+	if len(snapshots) == 0 {
+		return nil, api.StatusErrorf(http.StatusNotFound, "InternalSnapshot not found")
+	}
+
+	snapshot := internalTypes.Snapshot(snapshots[0].Data)
+
+	return &snapshot, nil
+}
+
+// DeleteInternalSnapshotsBefore deletes all snapshots taken before the given time, and returns the number of deleted
+// snapshots.
+func DeleteInternalSnapshotsBefore(ctx context.Context, tx *sql.Tx, before time.Time) (int64, error) {
+	stmt, err := Stmt(tx, internalSnapshotsDeleteBefore)
+	if err != nil {
+		return -1, fmt.Errorf("Failed to get \"internalSnapshotsDeleteBefore\" prepared statement: %w", err)
+	}
+
+	result, err := stmt.ExecContext(ctx, before)
+	if err != nil {
+		return -1, fmt.Errorf("Delete \"internal_snapshots\": %w", err)
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return -1, fmt.Errorf("Fetch affected rows: %w", err)
+	}
+
+	return n, nil
+}
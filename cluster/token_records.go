@@ -1,7 +1,10 @@
 package cluster
 
 import (
+	"crypto"
 	"crypto/x509"
+	"database/sql"
+	"time"
 
 	"github.com/canonical/lxd/shared"
 	internalTypes "github.com/canonical/microcluster/internal/rest/types"
@@ -28,9 +31,11 @@ import (
 
 // InternalTokenRecord is the database representation of a join token record.
 type InternalTokenRecord struct {
-	ID     int
-	Secret string `db:"primary=yes"`
-	Name   string
+	ID         int
+	Secret     string `db:"primary=yes"`
+	Name       string
+	ExpiryDate sql.NullTime
+	Issuer     string
 }
 
 // InternalTokenRecordFilter is the filter struct for filtering results from generated methods.
@@ -40,12 +45,23 @@ type InternalTokenRecordFilter struct {
 	Name   *string
 }
 
-// ToAPI converts the InternalTokenRecord to a full token and returns an API compatible struct.
-func (t *InternalTokenRecord) ToAPI(clusterCert *x509.Certificate, joinAddresses []types.AddrPort) (*internalTypes.TokenRecord, error) {
+// ToAPI converts the InternalTokenRecord to a full token signed by the given cluster key, and returns an API
+// compatible struct.
+func (t *InternalTokenRecord) ToAPI(clusterCert *x509.Certificate, clusterKey crypto.Signer, joinAddresses []types.AddrPort, joinHostnames []string) (*internalTypes.TokenRecord, error) {
 	token := internalTypes.Token{
 		Secret:        t.Secret,
 		Fingerprint:   shared.CertFingerprint(clusterCert),
 		JoinAddresses: joinAddresses,
+		JoinHostnames: joinHostnames,
+	}
+
+	if t.ExpiryDate.Valid {
+		token.ExpiresAt = t.ExpiryDate.Time
+	}
+
+	err := token.Sign(clusterKey)
+	if err != nil {
+		return nil, err
 	}
 
 	tokenString, err := token.String()
@@ -54,7 +70,14 @@ func (t *InternalTokenRecord) ToAPI(clusterCert *x509.Certificate, joinAddresses
 	}
 
 	return &internalTypes.TokenRecord{
-		Token: tokenString,
-		Name:  t.Name,
+		Token:     tokenString,
+		Name:      t.Name,
+		ExpiresAt: token.ExpiresAt,
+		Issuer:    t.Issuer,
 	}, nil
 }
+
+// Expired returns whether the token record has passed its expiry date. Records without an expiry date never expire.
+func (t *InternalTokenRecord) Expired() bool {
+	return t.ExpiryDate.Valid && time.Now().After(t.ExpiryDate.Time)
+}
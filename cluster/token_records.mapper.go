@@ -17,13 +17,13 @@ import (
 var _ = api.ServerEnvironment{}
 
 var internalTokenRecordObjects = RegisterStmt(`
-SELECT internal_token_records.id, internal_token_records.secret, internal_token_records.name
+SELECT internal_token_records.id, internal_token_records.secret, internal_token_records.name, internal_token_records.expiry_date, internal_token_records.issuer
   FROM internal_token_records
   ORDER BY internal_token_records.secret
 `)
 
 var internalTokenRecordObjectsBySecret = RegisterStmt(`
-SELECT internal_token_records.id, internal_token_records.secret, internal_token_records.name
+SELECT internal_token_records.id, internal_token_records.secret, internal_token_records.name, internal_token_records.expiry_date, internal_token_records.issuer
   FROM internal_token_records
   WHERE ( internal_token_records.secret = ? )
   ORDER BY internal_token_records.secret
@@ -35,8 +35,8 @@ SELECT internal_token_records.id FROM internal_token_records
 `)
 
 var internalTokenRecordCreate = RegisterStmt(`
-INSERT INTO internal_token_records (secret, name)
-  VALUES (?, ?)
+INSERT INTO internal_token_records (secret, name, expiry_date, issuer)
+  VALUES (?, ?, ?, ?)
 `)
 
 var internalTokenRecordDeleteByName = RegisterStmt(`
@@ -104,7 +104,7 @@ func GetInternalTokenRecord(ctx context.Context, tx *sql.Tx, secret string) (*In
 // internalTokenRecordColumns returns a string of column names to be used with a SELECT statement for the entity.
 // Use this function when building statements to retrieve database entries matching the InternalTokenRecord entity.
 func internalTokenRecordColumns() string {
-	return "internal_token_records.id, internal_token_records.secret, internal_token_records.name"
+	return "internal_token_records.id, internal_token_records.secret, internal_token_records.name, internal_token_records.expiry_date, internal_token_records.issuer"
 }
 
 // getInternalTokenRecords can be used to run handwritten sql.Stmts to return a slice of objects.
@@ -113,7 +113,7 @@ func getInternalTokenRecords(ctx context.Context, stmt *sql.Stmt, args ...any) (
 
 	dest := func(scan func(dest ...any) error) error {
 		i := InternalTokenRecord{}
-		err := scan(&i.ID, &i.Secret, &i.Name)
+		err := scan(&i.ID, &i.Secret, &i.Name, &i.ExpiryDate, &i.Issuer)
 		if err != nil {
 			return err
 		}
@@ -137,7 +137,7 @@ func getInternalTokenRecordsRaw(ctx context.Context, tx *sql.Tx, sql string, arg
 
 	dest := func(scan func(dest ...any) error) error {
 		i := InternalTokenRecord{}
-		err := scan(&i.ID, &i.Secret, &i.Name)
+		err := scan(&i.ID, &i.Secret, &i.Name, &i.ExpiryDate, &i.Issuer)
 		if err != nil {
 			return err
 		}
@@ -235,11 +235,13 @@ func CreateInternalTokenRecord(ctx context.Context, tx *sql.Tx, object InternalT
 		return -1, api.StatusErrorf(http.StatusConflict, "This \"internal_token_records\" entry already exists")
 	}
 
-	args := make([]any, 2)
+	args := make([]any, 4)
 
 	// Populate the statement arguments.
 	args[0] = object.Secret
 	args[1] = object.Name
+	args[2] = object.ExpiryDate
+	args[3] = object.Issuer
 
 	// Prepared statement to use.
 	stmt, err := Stmt(tx, internalTokenRecordCreate)
@@ -0,0 +1,123 @@
+package cluster
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+)
+
+// TrustAuditAction is the kind of trust store change recorded in the trust audit trail.
+type TrustAuditAction string
+
+const (
+	// TrustAuditAdd is recorded when a certificate is trusted.
+	TrustAuditAdd TrustAuditAction = "add"
+
+	// TrustAuditUpdate is recorded when the certificate of a trusted entity is replaced.
+	TrustAuditUpdate TrustAuditAction = "update"
+
+	// TrustAuditRemove is recorded when a certificate is no longer trusted.
+	TrustAuditRemove TrustAuditAction = "remove"
+)
+
+// TrustAuditType is the kind of entity whose certificate trust changed.
+type TrustAuditType string
+
+const (
+	// TrustAuditClusterMember is a cluster member in the trust store.
+	TrustAuditClusterMember TrustAuditType = "cluster-member"
+
+	// TrustAuditCertificateACL is a certificate trusted with a restricted set of API endpoints.
+	TrustAuditCertificateACL TrustAuditType = "certificate-acl"
+
+	// TrustAuditAPIToken is a bearer token trusted with a restricted set of API endpoints.
+	TrustAuditAPIToken TrustAuditType = "api-token"
+)
+
+// Code generation directives.
+//
+//go:generate -command mapper lxd-generate db mapper -t trust_audit.mapper.go
+//go:generate mapper reset
+//
+//go:generate mapper stmt -e internal_trust_audit objects table=internal_trust_audit
+//go:generate mapper stmt -e internal_trust_audit objects-by-Name table=internal_trust_audit
+//go:generate mapper stmt -e internal_trust_audit id table=internal_trust_audit
+//go:generate mapper stmt -e internal_trust_audit create table=internal_trust_audit
+//
+//go:generate mapper method -e internal_trust_audit ID table=internal_trust_audit
+//go:generate mapper method -e internal_trust_audit Exists table=internal_trust_audit
+//go:generate mapper method -e internal_trust_audit GetMany table=internal_trust_audit
+//go:generate mapper method -e internal_trust_audit Create table=internal_trust_audit
+
+// InternalTrustAudit is the database representation of a change to the set of trusted certificates. Changes are
+// listed in the order they were recorded.
+type InternalTrustAudit struct {
+	ID          int64            `db:"order=yes"`
+	Action      TrustAuditAction `db:"primary=yes"`
+	Type        TrustAuditType   `db:"primary=yes"`
+	Name        string           `db:"primary=yes"`
+	Fingerprint string
+	Requestor   string
+	CreatedAt   time.Time `db:"primary=yes"`
+}
+
+// InternalTrustAuditFilter is the filter struct for filtering results from generated methods.
+type InternalTrustAuditFilter struct {
+	ID   *int64
+	Name *string
+}
+
+// ToAPI returns the api struct for an InternalTrustAudit database entity.
+func (a InternalTrustAudit) ToAPI() internalTypes.TrustAudit {
+	return internalTypes.TrustAudit{
+		ID:          a.ID,
+		Action:      string(a.Action),
+		Type:        string(a.Type),
+		Name:        a.Name,
+		Fingerprint: a.Fingerprint,
+		Requestor:   a.Requestor,
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+// GetInternalTrustAuditsSince returns the recorded trust store changes matching the filter that were made after the
+// given time, oldest first. A zero time does not limit the results.
+func GetInternalTrustAuditsSince(ctx context.Context, tx *sql.Tx, filter InternalTrustAuditFilter, since time.Time) ([]InternalTrustAudit, error) {
+	clauses := []string{}
+	args := []any{}
+	if filter.Name != nil {
+		clauses = append(clauses, "internal_trust_audit.name = ?")
+		args = append(args, *filter.Name)
+	}
+
+	if !since.IsZero() {
+		clauses = append(clauses, "internal_trust_audit.created_at > ?")
+		args = append(args, since)
+	}
+
+	where := ""
+	if len(clauses) > 0 {
+		where = "\n  WHERE " + strings.Join(clauses, " AND ")
+	}
+
+	stmt := fmt.Sprintf("SELECT %s\n  FROM internal_trust_audit%s\n  ORDER BY internal_trust_audit.id", internalTrustAuditColumns(), where)
+
+	return getInternalTrustAuditsRaw(ctx, tx, stmt, args...)
+}
+
+// NewInternalTrustAudit returns a change to the trust of the certificate with the given fingerprint, made now on
+// behalf of the given requestor.
+func NewInternalTrustAudit(action TrustAuditAction, auditType TrustAuditType, name string, fingerprint string, requestor string) InternalTrustAudit {
+	return InternalTrustAudit{
+		Action:      action,
+		Type:        auditType,
+		Name:        name,
+		Fingerprint: fingerprint,
+		Requestor:   requestor,
+		CreatedAt:   time.Now().UTC(),
+	}
+}
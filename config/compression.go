@@ -0,0 +1,21 @@
+package config
+
+import (
+	"io"
+)
+
+// Compression is an algorithm that database backups can be compressed with. Gzip is built in, while others, such as
+// zstd, can be provided by the application.
+type Compression struct {
+	// Name identifies the algorithm, and is used as its HTTP content coding, such as "zstd".
+	Name string
+
+	// Extension is appended to the names of backup archives compressed with the algorithm, such as ".zst".
+	Extension string
+
+	// NewWriter returns a writer compressing to the given writer. It is closed once the backup has been written.
+	NewWriter func(w io.Writer) (io.WriteCloser, error)
+
+	// NewReader returns a reader decompressing from the given reader.
+	NewReader func(r io.Reader) (io.ReadCloser, error)
+}
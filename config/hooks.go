@@ -1,6 +1,8 @@
 package config
 
 import (
+	"net/http"
+
 	"github.com/canonical/microcluster/internal/state"
 )
 
@@ -17,6 +19,10 @@ type Hooks struct {
 	// their 'OnNewMember' hooks.
 	PostJoin func(s *state.State, initConfig map[string]string) error
 
+	// ValidateJoin is run on the leader before a new cluster member is accepted, with the name and init config supplied
+	// by the joining member. Returning an error rejects the join.
+	ValidateJoin func(s *state.State, name string, joinConfig map[string]string) error
+
 	// PreJoin is run after the daemon is initialized and joined the cluster but before existing members triggered
 	// their 'OnNewMember' hooks.
 	PreJoin func(s *state.State, initConfig map[string]string) error
@@ -30,6 +36,35 @@ type Hooks struct {
 	// OnHeartbeat is run after a successful heartbeat round.
 	OnHeartbeat func(s *state.State) error
 
+	// HeartbeatStatus is run on each cluster member during a heartbeat round. The returned fields are recorded
+	// against the cluster member, and included in the cluster member listing.
+	HeartbeatStatus func(s *state.State) (map[string]any, error)
+
 	// OnNewMember is run on each peer after a new cluster member has joined and executed their 'PreJoin' hook.
 	OnNewMember func(s *state.State) error
+
+	// OnFeatureChange is run on each peer after the feature flag with the given name is created, updated, or deleted.
+	OnFeatureChange func(s *state.State, name string) error
+
+	// OnConfigChange is run on each peer after the cluster-wide config changes, with the sorted list of changed keys.
+	OnConfigChange func(s *state.State, keys []string) error
+
+	// OnMemberDemoted is run after a cluster member is automatically demoted from voter to spare, with the reason for
+	// the demotion. It is run on the leader for offline members, and on the member itself if it is low on disk space.
+	OnMemberDemoted func(s *state.State, name string, reason string) error
+
+	// OnHandlerPanic is run on the cluster member whose API handler panicked while serving the given request, with
+	// the request ID reported to the client and the recovered panic value.
+	OnHandlerPanic func(s *state.State, r *http.Request, requestID string, value any) error
+
+	// OnMemberPromoted is run on a cluster member after it automatically promotes itself back to voter, with the
+	// reason for the promotion.
+	OnMemberPromoted func(s *state.State, name string, reason string) error
+
+	// Upgrade is run on each cluster member in turn during a rolling upgrade, and should upgrade and restart the
+	// application. The next cluster member is upgraded once this one is back online.
+	Upgrade func(s *state.State) error
+
+	// ProjectAccess is run before any request to a project-scoped endpoint. Returning an error denies the request.
+	ProjectAccess func(s *state.State, r *http.Request, project string) error
 }
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"github.com/canonical/microcluster/internal/state"
+)
+
+// PatchStage determines at which point during daemon startup a patch is applied.
+type PatchStage int
+
+const (
+	// PatchPreDatabase patches are applied once the local database is available, before the cluster database is opened.
+	PatchPreDatabase PatchStage = iota
+
+	// PatchPostDatabase patches are applied once the cluster database is open.
+	PatchPostDatabase
+)
+
+// Patch represents a one-time corrective action applied on each cluster member. Unlike schema updates, patches are
+// not versioned and are tracked per cluster member in the local database, so each patch is run once on every member.
+type Patch struct {
+	// Name uniquely identifies the patch.
+	Name string
+
+	// Stage determines when the patch is applied.
+	Stage PatchStage
+
+	// Run performs the patch.
+	Run func(s *state.State) error
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/canonical/microcluster/config"
+)
+
+// benchClusterSize is the number of members of the clusters formed by the benchmarks.
+const benchClusterSize = 3
+
+// startBenchCluster starts and forms a cluster of the given size in a temporary directory. The cluster is shut down when
+// the benchmark ends.
+func startBenchCluster(b *testing.B, size int, hooks *config.Hooks) []*member {
+	b.Helper()
+
+	stateDir := b.TempDir()
+	members := make([]*member, 0, size)
+	b.Cleanup(func() { stopMembers(members) })
+
+	for i := 0; i < size; i++ {
+		m, err := startMember(context.Background(), stateDir, fmt.Sprintf("member%02d", i+1), hooks, false, false)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		members = append(members, m)
+	}
+
+	err := members[0].app.NewCluster(members[0].name, members[0].address, nil, time.Minute)
+	if err != nil {
+		b.Fatalf("Failed to bootstrap cluster member %q: %v", members[0].name, err)
+	}
+
+	for _, m := range members[1:] {
+		err = joinMember(members[0], m)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return members
+}
+
+// BenchmarkTransactions measures write transactions through the API of the bootstrap member of a cluster.
+func BenchmarkTransactions(b *testing.B) {
+	members := startBenchCluster(b, benchClusterSize, nil)
+
+	b.ResetTimer()
+	perSecond, latency, err := runTransactions(members[0].app, b.N, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportMetric(perSecond, "tx/s")
+	b.ReportMetric(latency, "ms/tx")
+}
+
+// BenchmarkHeartbeatRound measures the duration of the heartbeat rounds of a cluster.
+func BenchmarkHeartbeatRound(b *testing.B) {
+	heartbeatCh := make(chan time.Duration, 1)
+	startBenchCluster(b, benchClusterSize, heartbeatHooks(heartbeatCh))
+
+	// Discard any round that started before the cluster was fully formed.
+	select {
+	case <-heartbeatCh:
+	default:
+	}
+
+	var heartbeatTime time.Duration
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		select {
+		case duration := <-heartbeatCh:
+			heartbeatTime += duration
+		case <-time.After(5 * time.Minute):
+			b.Fatal("Timed out waiting for a heartbeat round")
+		}
+	}
+
+	b.ReportMetric(milliseconds(heartbeatTime/time.Duration(b.N)), "ms/round")
+}
+
+// BenchmarkJoin measures how long it takes a member to join a single member cluster.
+func BenchmarkJoin(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		stateDir := b.TempDir()
+		members := make([]*member, 0, 2)
+		for _, name := range []string{"member01", "member02"} {
+			m, err := startMember(context.Background(), stateDir, name, nil, false, false)
+			if err != nil {
+				stopMembers(members)
+				b.Fatal(err)
+			}
+
+			members = append(members, m)
+		}
+
+		err := members[0].app.NewCluster(members[0].name, members[0].address, nil, time.Minute)
+		if err != nil {
+			stopMembers(members)
+			b.Fatalf("Failed to bootstrap cluster member %q: %v", members[0].name, err)
+		}
+
+		b.StartTimer()
+		err = joinMember(members[0], members[1])
+		b.StopTimer()
+
+		stopMembers(members)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
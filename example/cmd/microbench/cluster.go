@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/config"
+	"github.com/canonical/microcluster/microcluster"
+	"github.com/canonical/microcluster/state"
+)
+
+// member is a daemon started by the benchmark.
+type member struct {
+	name    string
+	address string
+	app     *microcluster.MicroCluster
+	doneCh  chan error
+}
+
+// benchmark starts a cluster of the given size in a subdirectory of the state directory, measures it, and shuts it down.
+func (c *cmdBench) benchmark(ctx context.Context, stateDir string, size int) (*Result, error) {
+	result := &Result{Size: size}
+
+	heartbeatCh := make(chan time.Duration, 1)
+	hooks := heartbeatHooks(heartbeatCh)
+
+	members := make([]*member, 0, size)
+	defer func() { stopMembers(members) }()
+
+	for i := 0; i < size; i++ {
+		m, err := startMember(ctx, filepath.Join(stateDir, fmt.Sprintf("size%d", size)), fmt.Sprintf("member%02d", i+1), hooks, c.flagLogVerbose, c.flagLogDebug)
+		if err != nil {
+			return nil, err
+		}
+
+		members = append(members, m)
+	}
+
+	start := time.Now()
+	err := members[0].app.NewCluster(members[0].name, members[0].address, nil, time.Minute)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to bootstrap cluster member %q: %w", members[0].name, err)
+	}
+
+	result.BootstrapTime = milliseconds(time.Since(start))
+
+	var joinTime time.Duration
+	for _, m := range members[1:] {
+		start := time.Now()
+		err := joinMember(members[0], m)
+		if err != nil {
+			return nil, err
+		}
+
+		joinTime += time.Since(start)
+	}
+
+	if size > 1 {
+		result.JoinTime = milliseconds(joinTime / time.Duration(size-1))
+	}
+
+	result.Transactions = c.flagTransactions
+	result.TransactionsPerSecond, result.TransactionLatency, err = runTransactions(members[0].app, c.flagTransactions, c.flagParallel)
+	if err != nil {
+		return nil, err
+	}
+
+	// Discard any round that started before the cluster was fully formed.
+	select {
+	case <-heartbeatCh:
+	default:
+	}
+
+	var heartbeatTime time.Duration
+	for i := 0; i < c.flagHeartbeatRounds; i++ {
+		fmt.Fprintf(os.Stderr, "Waiting for heartbeat round %d of %d\n", i+1, c.flagHeartbeatRounds)
+
+		select {
+		case duration := <-heartbeatCh:
+			heartbeatTime += duration
+		case <-time.After(5 * time.Minute):
+			return nil, fmt.Errorf("Timed out waiting for a heartbeat round")
+		}
+	}
+
+	result.HeartbeatRounds = c.flagHeartbeatRounds
+	if c.flagHeartbeatRounds > 0 {
+		result.HeartbeatRoundTime = milliseconds(heartbeatTime / time.Duration(c.flagHeartbeatRounds))
+	}
+
+	return result, nil
+}
+
+// joinMember joins the given member to the cluster of the bootstrap member.
+func joinMember(bootstrap *member, m *member) error {
+	token, err := bootstrap.app.NewJoinToken(m.name)
+	if err != nil {
+		return fmt.Errorf("Failed to create join token for cluster member %q: %w", m.name, err)
+	}
+
+	err = m.app.JoinCluster(m.name, m.address, token, nil, time.Minute)
+	if err != nil {
+		return fmt.Errorf("Failed to join cluster member %q: %w", m.name, err)
+	}
+
+	return nil
+}
+
+// heartbeatHooks returns hooks that send the duration of each heartbeat round to the given channel, dropping rounds
+// while the channel is full.
+func heartbeatHooks(heartbeatCh chan time.Duration) *config.Hooks {
+	// Heartbeat rounds are reported by the leader once the round has finished and its own heartbeat timestamp, set when
+	// the round started, has been recorded.
+	return &config.Hooks{
+		OnHeartbeat: func(s *state.State) error {
+			var started time.Time
+			err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+				clusterMember, err := cluster.GetInternalClusterMember(ctx, tx, s.Name())
+				if err != nil {
+					return err
+				}
+
+				started = clusterMember.Heartbeat
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			select {
+			case heartbeatCh <- time.Since(started):
+			default:
+			}
+
+			return nil
+		},
+	}
+}
+
+// startMember starts a daemon with its own state directory and a free loopback port, and waits for it to be ready.
+func startMember(ctx context.Context, stateDir string, name string, hooks *config.Hooks, verbose bool, debug bool) (*member, error) {
+	port, err := freePort()
+	if err != nil {
+		return nil, err
+	}
+
+	app, err := microcluster.App(ctx, microcluster.Args{StateDir: filepath.Join(stateDir, name), Verbose: verbose, Debug: debug})
+	if err != nil {
+		return nil, err
+	}
+
+	m := &member{
+		name:    name,
+		address: fmt.Sprintf("127.0.0.1:%d", port),
+		app:     app,
+		doneCh:  make(chan error, 1),
+	}
+
+	go func() {
+		m.doneCh <- app.Start(nil, nil, hooks)
+	}()
+
+	err = app.Ready(30)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to wait for cluster member %q to start: %w", name, err)
+	}
+
+	return m, nil
+}
+
+// stopMembers shuts down the cluster formed by the given members, and waits for every daemon to stop.
+func stopMembers(members []*member) {
+	if len(members) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	for _, m := range members {
+		c, err := m.app.LocalClient()
+		if err != nil {
+			continue
+		}
+
+		// Shutting down the whole cluster from one member stops the others in an order that keeps quorum until the
+		// end. Members that never joined the cluster are stopped individually.
+		err = c.ShutdownCluster(ctx)
+		if err != nil {
+			_ = c.ShutdownDaemon(ctx)
+		}
+
+		break
+	}
+
+	for _, m := range members {
+		select {
+		case <-m.doneCh:
+		case <-ctx.Done():
+			fmt.Fprintf(os.Stderr, "Timed out waiting for cluster member %q to stop\n", m.name)
+			return
+		}
+	}
+}
+
+// runTransactions runs the given number of write transactions through the API of the given daemon, spread across the
+// given number of concurrent clients. It returns the throughput in transactions per second, and the mean latency of a
+// transaction in milliseconds.
+func runTransactions(app *microcluster.MicroCluster, count int, parallel int) (float64, float64, error) {
+	_, _, err := app.SQL("CREATE TABLE IF NOT EXISTS microbench (id INTEGER PRIMARY KEY AUTOINCREMENT, value TEXT NOT NULL)")
+	if err != nil {
+		return 0, 0, fmt.Errorf("Failed to create benchmark table: %w", err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var latency time.Duration
+	var errs []error
+
+	start := time.Now()
+	for worker := 0; worker < parallel; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+
+			for i := worker; i < count; i += parallel {
+				txStart := time.Now()
+				_, _, err := app.SQL(fmt.Sprintf("INSERT INTO microbench (value) VALUES ('transaction-%d')", i))
+
+				mu.Lock()
+				latency += time.Since(txStart)
+				if err != nil {
+					errs = append(errs, err)
+				}
+
+				mu.Unlock()
+
+				if err != nil {
+					return
+				}
+			}
+		}(worker)
+	}
+
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	if len(errs) > 0 {
+		return 0, 0, fmt.Errorf("Failed to run benchmark transaction: %w", errs[0])
+	}
+
+	return float64(count) / elapsed.Seconds(), milliseconds(latency / time.Duration(count)), nil
+}
+
+// freePort returns a TCP port that is currently free on the loopback interface.
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("Failed to find a free port: %w", err)
+	}
+
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// milliseconds returns the given duration in fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
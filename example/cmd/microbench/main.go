@@ -0,0 +1,142 @@
+// Package microbench provides a benchmark runner for the MicroCluster database and API layers.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microcluster/example/version"
+)
+
+type cmdBench struct {
+	flagSizes           []int
+	flagTransactions    int
+	flagParallel        int
+	flagHeartbeatRounds int
+	flagOutput          string
+	flagBaseline        string
+	flagTolerance       float64
+	flagStateDir        string
+	flagLogVerbose      bool
+	flagLogDebug        bool
+}
+
+func (c *cmdBench) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "microbench",
+		Short: "Benchmark a local multi-member MicroCluster",
+		Long: `Benchmark a local multi-member MicroCluster
+
+For each cluster size, this starts the given number of daemons on the loopback interface, forms a cluster, and measures:
+  - The time taken for each new member to join the cluster.
+  - The throughput of write transactions sent through the API.
+  - The time taken by heartbeat rounds, which are sent about once every minute.
+
+The results are written as JSON, and can be compared against a baseline written by an earlier run, in which case any
+result worse than the baseline by more than the tolerance is reported as a regression.`,
+		Version:           version.Version,
+		SilenceUsage:      true,
+		CompletionOptions: cobra.CompletionOptions{DisableDefaultCmd: true},
+	}
+
+	cmd.Flags().IntSliceVar(&c.flagSizes, "sizes", []int{1, 3, 5}, "Cluster sizes to benchmark")
+	cmd.Flags().IntVar(&c.flagTransactions, "transactions", 1000, "Number of write transactions to run against each cluster")
+	cmd.Flags().IntVar(&c.flagParallel, "parallel", 1, "Number of concurrent clients running write transactions")
+	cmd.Flags().IntVar(&c.flagHeartbeatRounds, "heartbeat-rounds", 1, "Number of heartbeat rounds to measure for each cluster")
+	cmd.Flags().StringVar(&c.flagOutput, "output", "", "Path to write the JSON results to, instead of stdout"+"``")
+	cmd.Flags().StringVar(&c.flagBaseline, "baseline", "", "Path to the JSON results of an earlier run to compare against"+"``")
+	cmd.Flags().Float64Var(&c.flagTolerance, "tolerance", 20, "Percentage by which a result may be worse than the baseline")
+	cmd.Flags().StringVar(&c.flagStateDir, "state-dir", "", "Directory to store the state of the benchmarked daemons in, instead of a temporary directory"+"``")
+	cmd.Flags().BoolVarP(&c.flagLogVerbose, "verbose", "v", false, "Show all information messages")
+	cmd.Flags().BoolVarP(&c.flagLogDebug, "debug", "d", false, "Show all debug messages")
+
+	cmd.RunE = c.Run
+
+	return cmd
+}
+
+func (c *cmdBench) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	if c.flagTransactions < 1 || c.flagParallel < 1 || c.flagHeartbeatRounds < 0 {
+		return fmt.Errorf("Transactions and parallel clients must be positive, and heartbeat rounds must not be negative")
+	}
+
+	var baseline *Report
+	if c.flagBaseline != "" {
+		var err error
+		baseline, err = readReport(c.flagBaseline)
+		if err != nil {
+			return err
+		}
+	}
+
+	stateDir := c.flagStateDir
+	if stateDir == "" {
+		var err error
+		stateDir, err = os.MkdirTemp("", "microbench")
+		if err != nil {
+			return fmt.Errorf("Failed to create state directory: %w", err)
+		}
+
+		defer func() { _ = os.RemoveAll(stateDir) }()
+	}
+
+	report := Report{
+		Version:   version.Version,
+		GoVersion: runtime.Version(),
+		StartedAt: time.Now().UTC(),
+	}
+
+	for _, size := range c.flagSizes {
+		if size < 1 {
+			return fmt.Errorf("Invalid cluster size %d", size)
+		}
+
+		fmt.Fprintf(os.Stderr, "Benchmarking cluster of %d member(s)\n", size)
+
+		result, err := c.benchmark(context.Background(), stateDir, size)
+		if err != nil {
+			return fmt.Errorf("Failed to benchmark cluster of %d member(s): %w", size, err)
+		}
+
+		report.Results = append(report.Results, *result)
+	}
+
+	err := writeReport(c.flagOutput, report)
+	if err != nil {
+		return err
+	}
+
+	if baseline == nil {
+		return nil
+	}
+
+	regressions := compareReports(*baseline, report, c.flagTolerance)
+	for _, regression := range regressions {
+		fmt.Fprintln(os.Stderr, regression)
+	}
+
+	if len(regressions) > 0 {
+		return fmt.Errorf("Found %d regression(s) compared to the baseline", len(regressions))
+	}
+
+	return nil
+}
+
+func main() {
+	benchCmd := cmdBench{}
+	app := benchCmd.Command()
+
+	err := app.Execute()
+	if err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Report is the JSON output of a benchmark run.
+type Report struct {
+	Version   string    `json:"version"`
+	GoVersion string    `json:"go_version"`
+	StartedAt time.Time `json:"started_at"`
+	Results   []Result  `json:"results"`
+}
+
+// Result holds the measurements for a single cluster size. Durations are in milliseconds.
+type Result struct {
+	Size                  int     `json:"size"`
+	BootstrapTime         float64 `json:"bootstrap_ms"`
+	JoinTime              float64 `json:"join_ms"`
+	Transactions          int     `json:"transactions"`
+	TransactionsPerSecond float64 `json:"transactions_per_second"`
+	TransactionLatency    float64 `json:"transaction_latency_ms"`
+	HeartbeatRounds       int     `json:"heartbeat_rounds"`
+	HeartbeatRoundTime    float64 `json:"heartbeat_round_ms"`
+}
+
+// readReport reads a report written by an earlier run.
+func readReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read baseline %q: %w", path, err)
+	}
+
+	report := &Report{}
+	err = json.Unmarshal(data, report)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse baseline %q: %w", path, err)
+	}
+
+	return report, nil
+}
+
+// writeReport writes the report to the given path, or to stdout if the path is empty.
+func writeReport(path string, report Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Failed to encode results: %w", err)
+	}
+
+	data = append(data, '\n')
+	if path == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to write results to %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// compareReports returns a description of every result in the current report that is worse than the result for the same
+// cluster size in the baseline by more than the given percentage. Measurements missing from either report are skipped.
+func compareReports(baseline Report, current Report, tolerance float64) []string {
+	baseResults := make(map[int]Result, len(baseline.Results))
+	for _, result := range baseline.Results {
+		baseResults[result.Size] = result
+	}
+
+	var regressions []string
+	for _, result := range current.Results {
+		base, ok := baseResults[result.Size]
+		if !ok {
+			continue
+		}
+
+		check := func(name string, baseValue float64, value float64, higherIsBetter bool) {
+			if baseValue == 0 || value == 0 {
+				return
+			}
+
+			change := (value - baseValue) / baseValue * 100
+			if higherIsBetter {
+				change = -change
+			}
+
+			if change > tolerance {
+				regressions = append(regressions, fmt.Sprintf("Cluster of %d member(s): %s regressed by %.1f%% (baseline %.2f, current %.2f)", result.Size, name, change, baseValue, value))
+			}
+		}
+
+		check("bootstrap time", base.BootstrapTime, result.BootstrapTime, false)
+		check("join time", base.JoinTime, result.JoinTime, false)
+		check("transaction throughput", base.TransactionsPerSecond, result.TransactionsPerSecond, true)
+		check("transaction latency", base.TransactionLatency, result.TransactionLatency, false)
+		check("heartbeat round time", base.HeartbeatRoundTime, result.HeartbeatRoundTime, false)
+	}
+
+	return regressions
+}
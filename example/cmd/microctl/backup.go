@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/canonical/microcluster/microcluster"
+	"github.com/spf13/cobra"
+)
+
+type cmdBackup struct {
+	common *CmdControl
+
+	flagCompression []string
+}
+
+func (c *cmdBackup) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backup <file>",
+		Short: "Write a compressed archive of the database to a file",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().StringSliceVar(&c.flagCompression, "compression", nil, "Compression algorithms to ask the daemon for, in order of preference")
+
+	return cmd
+}
+
+func (c *cmdBackup) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 1 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(context.Background(), microcluster.Args{StateDir: c.common.FlagStateDir, Verbose: c.common.FlagLogVerbose, Debug: c.common.FlagLogDebug})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(args[0])
+	if err != nil {
+		return fmt.Errorf("Failed to create backup file: %w", err)
+	}
+
+	defer f.Close()
+
+	compression, err := m.DatabaseBackup(context.Background(), f, c.flagCompression...)
+	if err != nil {
+		return err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return fmt.Errorf("Failed to write backup file: %w", err)
+	}
+
+	fmt.Printf("Wrote %q compressed database backup to %q\n", compression, args[0])
+
+	return nil
+}
@@ -36,6 +36,9 @@ func (c *cmdClusterMembers) Run(cmd *cobra.Command, args []string) error {
 
 type cmdClusterMembersList struct {
 	common *CmdControl
+
+	flagRole   string
+	flagStatus string
 }
 
 func (c *cmdClusterMembersList) Command() *cobra.Command {
@@ -45,6 +48,9 @@ func (c *cmdClusterMembersList) Command() *cobra.Command {
 		RunE:  c.Run,
 	}
 
+	cmd.Flags().StringVar(&c.flagRole, "role", "", "Only list cluster members with the given dqlite role")
+	cmd.Flags().StringVar(&c.flagStatus, "status", "", "Only list cluster members with the given status")
+
 	return cmd
 }
 
@@ -59,6 +65,8 @@ func (c *cmdClusterMembersList) Run(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	filter := client.ClusterMemberFilter{Role: c.flagRole, Status: c.flagStatus}
+
 	var client *client.Client
 
 	// Get a local client connected to the unix socket if no address is specified.
@@ -74,7 +82,7 @@ func (c *cmdClusterMembersList) Run(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	clusterMembers, err := client.GetClusterMembers(context.Background())
+	clusterMembers, err := client.FilterClusterMembers(context.Background(), filter)
 	if err != nil {
 		return err
 	}
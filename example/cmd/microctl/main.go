@@ -53,6 +53,12 @@ func main() {
 	var cmdSQL = cmdSQL{common: &commonCmd}
 	app.AddCommand(cmdSQL.Command())
 
+	var cmdBackup = cmdBackup{common: &commonCmd}
+	app.AddCommand(cmdBackup.Command())
+
+	var cmdTrustStore = cmdTrustStore{common: &commonCmd}
+	app.AddCommand(cmdTrustStore.Command())
+
 	var cmdSecrets = cmdSecrets{common: &commonCmd}
 	app.AddCommand(cmdSecrets.Command())
 
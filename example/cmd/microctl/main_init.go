@@ -1,8 +1,11 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"time"
 
@@ -16,27 +19,34 @@ type cmdInit struct {
 	flagBootstrap bool
 	flagToken     string
 	flagConfig    []string
+	flagPreseed   string
+	flagTOFU      bool
 }
 
 func (c *cmdInit) Command() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "init <name> <address>",
+		Use:   "init <name> [<address>]",
 		Short: "Initialize the network endpoint and create or join a new cluster",
 		RunE:  c.Run,
 		Example: `  microctl init member1 127.0.0.1:8443 --bootstrap
-    microctl init member1 127.0.0.1:8443 --token <token>`,
+    microctl init member1 127.0.0.1:8443 --token <token>
+    microctl init member1 127.0.0.1:8443 --token <token> --tofu
+    microctl init member1 --preseed preseed.yaml`,
 	}
 
 	cmd.Flags().BoolVar(&c.flagBootstrap, "bootstrap", false, "Configure a new cluster with this daemon")
 	cmd.Flags().StringVar(&c.flagToken, "token", "", "Join a cluster with a join token")
 	cmd.Flags().StringSliceVar(&c.flagConfig, "config", nil, "Extra configuration to be applied during bootstrap")
-	cmd.MarkFlagsMutuallyExclusive("bootstrap", "token")
+	cmd.Flags().StringVar(&c.flagPreseed, "preseed", "", "Create or join a cluster as described by a YAML preseed file"+"``")
+	cmd.Flags().BoolVar(&c.flagTOFU, "tofu", false, "Confirm and pin the cluster certificate fingerprint on first contact when joining")
+	cmd.MarkFlagsMutuallyExclusive("bootstrap", "token", "preseed")
+	cmd.MarkFlagsMutuallyExclusive("config", "preseed")
 
 	return cmd
 }
 
 func (c *cmdInit) Run(cmd *cobra.Command, args []string) error {
-	if len(args) != 2 {
+	if c.flagPreseed != "" && len(args) != 1 || c.flagPreseed == "" && len(args) != 2 {
 		return cmd.Help()
 	}
 
@@ -45,6 +55,20 @@ func (c *cmdInit) Run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("Unable to configure MicroCluster: %w", err)
 	}
 
+	if c.flagPreseed != "" {
+		data, err := os.ReadFile(c.flagPreseed)
+		if err != nil {
+			return fmt.Errorf("Failed to read preseed file: %w", err)
+		}
+
+		preseed, err := microcluster.ParsePreseed(data)
+		if err != nil {
+			return err
+		}
+
+		return m.Preseed(args[0], *preseed, time.Minute*10)
+	}
+
 	conf := make(map[string]string, len(c.flagConfig))
 	for _, setting := range c.flagConfig {
 		key, value, ok := strings.Cut(setting, "=")
@@ -60,8 +84,40 @@ func (c *cmdInit) Run(cmd *cobra.Command, args []string) error {
 	}
 
 	if c.flagToken != "" {
-		return m.JoinCluster(args[0], args[1], c.flagToken, conf, time.Second*30)
+		token := c.flagToken
+		if c.flagTOFU {
+			token, err = c.confirmClusterCertificate(token)
+			if err != nil {
+				return err
+			}
+		}
+
+		return m.JoinCluster(args[0], args[1], token, conf, time.Second*30)
+	}
+
+	return fmt.Errorf("Option must be one of bootstrap, token or preseed")
+}
+
+// confirmClusterCertificate shows the fingerprint of the cluster certificate presented by the first reachable join
+// address of the token, and returns the token pinned to that fingerprint once the operator confirms it.
+func (c *cmdInit) confirmClusterCertificate(token string) (string, error) {
+	address, fingerprint, err := microcluster.FetchClusterFingerprint(token)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Printf("Cluster member %q presented a certificate with fingerprint:\n  %s\n", address, fingerprint)
+	fmt.Print("Trust this certificate and join the cluster? (yes/no) [default=no]: ")
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("Failed to read answer: %w", err)
+	}
+
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	if answer != "yes" && answer != "y" {
+		return "", fmt.Errorf("Cluster certificate was not trusted")
 	}
 
-	return fmt.Errorf("Option must be one of bootstrap or token")
+	return microcluster.PinJoinToken(token, fingerprint)
 }
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+
+	"github.com/canonical/microcluster/microcluster"
+)
+
+type cmdTrustStore struct {
+	common *CmdControl
+
+	flagAll    bool
+	flagRepair bool
+}
+
+func (c *cmdTrustStore) Command() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "truststore",
+		Short: "Check the trust store against the cluster members in the database",
+		RunE:  c.Run,
+	}
+
+	cmd.Flags().BoolVar(&c.flagAll, "all", false, "Check the trust stores of all cluster members")
+	cmd.Flags().BoolVar(&c.flagRepair, "repair", false, "Replace any trust store that differs with the cluster members in the database")
+
+	return cmd
+}
+
+func (c *cmdTrustStore) Run(cmd *cobra.Command, args []string) error {
+	if len(args) != 0 {
+		return cmd.Help()
+	}
+
+	m, err := microcluster.App(context.Background(), microcluster.Args{StateDir: c.common.FlagStateDir, Verbose: c.common.FlagLogVerbose, Debug: c.common.FlagLogDebug})
+	if err != nil {
+		return err
+	}
+
+	checks, err := m.TrustStoreCheck(c.flagAll, c.flagRepair)
+	if err != nil {
+		return err
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(false)
+	table.SetHeader([]string{"NAME", "OK", "MISSING", "STALE", "ORPHANED", "REPAIRED", "ERROR"})
+	for _, check := range checks {
+		table.Append([]string{
+			check.Name,
+			fmt.Sprintf("%v", check.OK),
+			strings.Join(check.Missing, ", "),
+			strings.Join(check.Stale, ", "),
+			strings.Join(check.Orphaned, ", "),
+			fmt.Sprintf("%v", check.Repaired),
+			check.Error,
+		})
+	}
+
+	table.Render()
+
+	return nil
+}
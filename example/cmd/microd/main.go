@@ -2,9 +2,13 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"fmt"
+	"io"
 	"math/rand"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/canonical/lxd/shared/logger"
@@ -44,8 +48,19 @@ func (c *cmdGlobal) Run(cmd *cobra.Command, args []string) error {
 type cmdDaemon struct {
 	global *cmdGlobal
 
-	flagStateDir    string
-	flagSocketGroup string
+	flagStateDir        string
+	flagSocketGroup     string
+	flagListenAddresses []string
+
+	flagKeyPassphraseFile string
+	flagMachineBoundKeys  bool
+	flagInsecure          bool
+	flagControlUIDs       []uint
+	flagControlGIDs       []uint
+	flagTrustedProxies    []string
+	flagConsumerAddress   string
+	flagAppSocket         string
+	flagProxyProtocol     bool
 }
 
 func (c *cmdDaemon) Command() *cobra.Command {
@@ -61,7 +76,18 @@ func (c *cmdDaemon) Command() *cobra.Command {
 }
 
 func (c *cmdDaemon) Run(cmd *cobra.Command, args []string) error {
-	m, err := microcluster.App(context.Background(), microcluster.Args{StateDir: c.flagStateDir, SocketGroup: c.flagSocketGroup, Verbose: c.global.flagLogVerbose, Debug: c.global.flagLogDebug})
+	if c.flagKeyPassphraseFile != "" && c.flagMachineBoundKeys {
+		return fmt.Errorf("--key-passphrase-file and --machine-bound-keys are mutually exclusive")
+	}
+
+	var keyPassphrase func() ([]byte, error)
+	if c.flagMachineBoundKeys {
+		keyPassphrase = microcluster.MachineKey
+	} else if c.flagKeyPassphraseFile != "" {
+		keyPassphrase = c.readKeyPassphrase
+	}
+
+	m, err := microcluster.App(context.Background(), microcluster.Args{StateDir: c.flagStateDir, SocketGroup: c.flagSocketGroup, ListenAddresses: c.flagListenAddresses, ConsumerAddress: c.flagConsumerAddress, ApplicationSocket: c.flagAppSocket, Verbose: c.global.flagLogVerbose, Debug: c.global.flagLogDebug, KeyPassphrase: keyPassphrase, Insecure: c.flagInsecure, ControlSocketUIDs: toUint32(c.flagControlUIDs), ControlSocketGIDs: toUint32(c.flagControlGIDs), TrustedProxies: c.flagTrustedProxies, ProxyProtocol: c.flagProxyProtocol})
 	if err != nil {
 		return err
 	}
@@ -146,6 +172,31 @@ func (c *cmdDaemon) Run(cmd *cobra.Command, args []string) error {
 	return m.Start(api.Endpoints, database.SchemaExtensions, exampleHooks)
 }
 
+// readKeyPassphrase reads the private key passphrase from the first line of the passphrase file, or of stdin if the
+// file is "-".
+func (c *cmdDaemon) readKeyPassphrase() ([]byte, error) {
+	var reader io.Reader = os.Stdin
+	if c.flagKeyPassphraseFile != "-" {
+		file, err := os.Open(c.flagKeyPassphraseFile)
+		if err != nil {
+			return nil, err
+		}
+
+		defer func() { _ = file.Close() }()
+
+		reader = file
+	} else {
+		fmt.Fprint(os.Stderr, "Private key passphrase: ")
+	}
+
+	line, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return []byte(strings.TrimRight(line, "\r\n")), nil
+}
+
 func init() {
 	rand.Seed(time.Now().UnixNano())
 }
@@ -163,6 +214,21 @@ func main() {
 
 	app.PersistentFlags().StringVar(&daemonCmd.flagStateDir, "state-dir", "", "Path to store state information"+"``")
 	app.PersistentFlags().StringVar(&daemonCmd.flagSocketGroup, "socket-group", "", "Group to set socket's group ownership to")
+	app.PersistentFlags().StringSliceVar(&daemonCmd.flagListenAddresses, "listen-address", nil, "Addresses to bind the cluster API to, if different from the advertised address"+"``")
+
+	app.PersistentFlags().StringVar(&daemonCmd.flagConsumerAddress, "consumer-address", "", "Address to serve the consumer API on, separately from the cluster API"+"``")
+
+	app.PersistentFlags().StringVar(&daemonCmd.flagAppSocket, "app-socket", "", "Path of a unix socket serving only the application API"+"``")
+
+	app.PersistentFlags().StringVar(&daemonCmd.flagKeyPassphraseFile, "key-passphrase-file", "", "File containing the passphrase to encrypt private keys with, or - to read it from stdin"+"``")
+	app.PersistentFlags().BoolVar(&daemonCmd.flagMachineBoundKeys, "machine-bound-keys", false, "Encrypt private keys with a key bound to this machine")
+
+	app.PersistentFlags().BoolVar(&daemonCmd.flagInsecure, "insecure", false, "Disable certificate verification for local development. Never use this in production")
+
+	app.PersistentFlags().UintSliceVar(&daemonCmd.flagControlUIDs, "control-uid", nil, "User IDs allowed to use the control socket, in addition to root and the daemon user"+"``")
+	app.PersistentFlags().UintSliceVar(&daemonCmd.flagControlGIDs, "control-gid", nil, "Group IDs allowed to use the control socket, in addition to root and the daemon user"+"``")
+	app.PersistentFlags().StringSliceVar(&daemonCmd.flagTrustedProxies, "trusted-proxy", nil, "CIDR of a reverse proxy whose X-Forwarded-For header is trusted"+"``")
+	app.PersistentFlags().BoolVar(&daemonCmd.flagProxyProtocol, "proxy-protocol", false, "Require a PROXY protocol header on connections from trusted proxies")
 
 	app.SetVersionTemplate("{{.Version}}\n")
 
@@ -171,3 +237,13 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// toUint32 converts IDs given on the command line to the type used for process credentials.
+func toUint32(ids []uint) []uint32 {
+	converted := make([]uint32, 0, len(ids))
+	for _, id := range ids {
+		converted = append(converted, uint32(id))
+	}
+
+	return converted
+}
@@ -0,0 +1,226 @@
+// Package backup writes and reads compressed archives of the database, with the checksum of each file embedded.
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/canonical/microcluster/config"
+)
+
+// Identity is the name of the compression that leaves backups uncompressed.
+const Identity = "identity"
+
+// checksumsFile is the name of the file in each backup archive that holds the SHA256 checksum of every other file.
+const checksumsFile = "SHA256SUMS"
+
+// DefaultCompression is the name of the compression used for backups, unless the client asks for another one.
+var DefaultCompression = "gzip"
+
+// compressions are the algorithms that backups can be compressed with, by name.
+var compressions = map[string]config.Compression{
+	Identity: {
+		Name: Identity,
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return nopWriteCloser{Writer: w}, nil
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return io.NopCloser(r), nil
+		},
+	},
+	"gzip": {
+		Name:      "gzip",
+		Extension: ".gz",
+		NewWriter: func(w io.Writer) (io.WriteCloser, error) {
+			return gzip.NewWriter(w), nil
+		},
+		NewReader: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	},
+}
+
+// nopWriteCloser adds a no-op Close method to an io.Writer.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+// Close implements io.Closer.
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// File is a file of the database held in a backup.
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Register adds an algorithm that backups can be compressed with, replacing any existing one of the same name.
+func Register(compression config.Compression) error {
+	if compression.Name == "" || strings.ContainsAny(compression.Name, ",; ") {
+		return fmt.Errorf("Invalid compression name %q", compression.Name)
+	}
+
+	if compression.NewWriter == nil || compression.NewReader == nil {
+		return fmt.Errorf("Compression %q must implement both compression and decompression", compression.Name)
+	}
+
+	compressions[compression.Name] = compression
+
+	return nil
+}
+
+// Lookup returns the compression with the given name.
+func Lookup(name string) (config.Compression, error) {
+	compression, ok := compressions[name]
+	if !ok {
+		return config.Compression{}, fmt.Errorf("Unsupported compression %q", name)
+	}
+
+	return compression, nil
+}
+
+// Names returns the names of all supported compressions, sorted.
+func Names() []string {
+	names := make([]string, 0, len(compressions))
+	for name := range compressions {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Negotiate returns the compression to use for a backup, from the value of the Accept-Encoding header of a request.
+// The first supported compression listed is used, or DefaultCompression if there is none, or any is accepted.
+func Negotiate(acceptEncoding string) (config.Compression, error) {
+	for _, coding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(coding, ";")
+		name = strings.TrimSpace(name)
+		if name == "" || name == "*" || strings.ReplaceAll(params, " ", "") == "q=0" {
+			continue
+		}
+
+		compression, ok := compressions[name]
+		if ok {
+			return compression, nil
+		}
+	}
+
+	return Lookup(DefaultCompression)
+}
+
+// WriteArchive writes a tarball of the given files to w, compressed with the given compression. The archive also
+// holds a SHA256SUMS file with the checksum of each file, so that it can be verified when it is read back.
+func WriteArchive(w io.Writer, compression config.Compression, files []File) error {
+	cw, err := compression.NewWriter(w)
+	if err != nil {
+		return fmt.Errorf("Failed to start %q compression: %w", compression.Name, err)
+	}
+
+	tw := tar.NewWriter(cw)
+
+	now := time.Now()
+	sums := &strings.Builder{}
+	addFile := func(name string, data []byte) error {
+		err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(data)), ModTime: now})
+		if err != nil {
+			return err
+		}
+
+		_, err = tw.Write(data)
+
+		return err
+	}
+
+	for _, file := range files {
+		err := addFile(file.Name, file.Data)
+		if err != nil {
+			return fmt.Errorf("Failed to write %q to backup archive: %w", file.Name, err)
+		}
+
+		fmt.Fprintf(sums, "%x  %s\n", sha256.Sum256(file.Data), file.Name)
+	}
+
+	err = addFile(checksumsFile, []byte(sums.String()))
+	if err != nil {
+		return fmt.Errorf("Failed to write checksums to backup archive: %w", err)
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return fmt.Errorf("Failed to finish backup archive: %w", err)
+	}
+
+	err = cw.Close()
+	if err != nil {
+		return fmt.Errorf("Failed to finish %q compression: %w", compression.Name, err)
+	}
+
+	return nil
+}
+
+// ReadArchive reads the files of a backup archive compressed with the given compression from r, and verifies each of
+// them against the checksums held in the archive.
+func ReadArchive(r io.Reader, compression config.Compression) ([]File, error) {
+	cr, err := compression.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to start %q decompression: %w", compression.Name, err)
+	}
+
+	defer cr.Close()
+
+	var files []File
+	var sums []byte
+	tr := tar.NewReader(cr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read backup archive: %w", err)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read %q from backup archive: %w", header.Name, err)
+		}
+
+		if header.Name == checksumsFile {
+			sums = data
+			continue
+		}
+
+		files = append(files, File{Name: header.Name, Data: data})
+	}
+
+	if sums == nil {
+		return nil, fmt.Errorf("Backup archive has no %s file", checksumsFile)
+	}
+
+	expected := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(string(sums)), "\n") {
+		sum, name, ok := strings.Cut(line, "  ")
+		if ok {
+			expected[name] = sum
+		}
+	}
+
+	for _, file := range files {
+		if fmt.Sprintf("%x", sha256.Sum256(file.Data)) != expected[file.Name] {
+			return nil, fmt.Errorf("Checksum mismatch for %q in backup archive", file.Name)
+		}
+	}
+
+	return files, nil
+}
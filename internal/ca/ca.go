@@ -0,0 +1,364 @@
+// Package ca validates certificates issued by an external certificate authority.
+package ca
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+	"golang.org/x/crypto/ocsp"
+)
+
+// Authority is a bundle of external certificate authorities, along with their certificate revocation lists.
+type Authority struct {
+	roots *x509.CertPool
+	crls  []*x509.RevocationList
+
+	// ocspCache holds the OCSP status of certificates by their fingerprint.
+	ocspCache   map[string]*ocspEntry
+	ocspCacheMu sync.Mutex
+}
+
+// ocspEntry is the OCSP status of a certificate.
+type ocspEntry struct {
+	// resp is the last response from the OCSP responder, if any.
+	resp *ocsp.Response
+
+	// err is the error of the last request to the OCSP responder, if it failed.
+	err error
+
+	// checked is when the OCSP responder was last asked for the status.
+	checked time.Time
+
+	// fetching is closed once the request in progress to the OCSP responder completes, and is nil if there is none.
+	fetching chan struct{}
+}
+
+// current is the certificate authority enforced by the daemon, or nil if certificates are trusted by fingerprint.
+var current *Authority
+var currentMu sync.RWMutex
+
+// ocspTimeout is how long to wait for an OCSP responder.
+const ocspTimeout = 5 * time.Second
+
+// ocspRecheck is how long a response without a next update is used for, and how long to wait before asking the OCSP
+// responder again after a failed request.
+const ocspRecheck = time.Minute
+
+// ocspGrace is how long a response is still used for after its next update, while a new one can not be fetched.
+const ocspGrace = time.Hour
+
+// ocspCacheMax is the number of certificates whose OCSP status is cached.
+const ocspCacheMax = 1000
+
+// Current returns the certificate authority enforced by the daemon, or nil if certificates are trusted by fingerprint.
+func Current() *Authority {
+	currentMu.RLock()
+	defer currentMu.RUnlock()
+
+	return current
+}
+
+// SetCurrent sets the certificate authority enforced by the daemon. If nil, certificates are trusted by fingerprint.
+func SetCurrent(authority *Authority) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+
+	current = authority
+}
+
+// Load reads the PEM encoded certificate authority bundle at certPath, and any PEM encoded certificate revocation lists
+// at crlPath. If there is no bundle at certPath, nil is returned.
+func Load(certPath string, crlPath string) (*Authority, error) {
+	data, err := os.ReadFile(certPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Failed to read certificate authority bundle: %w", err)
+	}
+
+	authority := &Authority{
+		roots:     x509.NewCertPool(),
+		ocspCache: map[string]*ocspEntry{},
+	}
+
+	count := 0
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse certificate authority bundle: %w", err)
+		}
+
+		authority.roots.AddCert(cert)
+		count++
+	}
+
+	if count == 0 {
+		return nil, fmt.Errorf("Certificate authority bundle %q contains no certificates", certPath)
+	}
+
+	data, err = os.ReadFile(crlPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("Failed to read certificate revocation list: %w", err)
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		if block.Type != "X509 CRL" {
+			continue
+		}
+
+		crl, err := x509.ParseRevocationList(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to parse certificate revocation list: %w", err)
+		}
+
+		authority.crls = append(authority.crls, crl)
+	}
+
+	return authority, nil
+}
+
+// Verify checks that the given certificate chain, leaf first, was issued by one of the certificate authorities, and
+// that none of its certificates have been revoked.
+func (a *Authority) Verify(chain []*x509.Certificate) error {
+	if len(chain) == 0 {
+		return fmt.Errorf("No certificate to verify")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	leaf := chain[0]
+	verified, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         a.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("Certificate %q was not issued by a trusted certificate authority: %w", shared.CertFingerprint(leaf), err)
+	}
+
+	// Check each certificate against its issuer in the first verified chain, up to the root.
+	path := verified[0]
+	for i := 0; i < len(path)-1; i++ {
+		err = a.checkRevoked(path[i], path[i+1])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkRevoked returns an error if the given certificate has been revoked by its issuer, either in a certificate
+// revocation list or by the OCSP responder listed in the certificate. If the OCSP responder can not be reached and
+// there is no recent response from it, the certificate is considered revoked.
+func (a *Authority) checkRevoked(cert *x509.Certificate, issuer *x509.Certificate) error {
+	fingerprint := shared.CertFingerprint(cert)
+	for _, crl := range a.crls {
+		err := crl.CheckSignatureFrom(issuer)
+		if err != nil {
+			continue
+		}
+
+		if !crl.NextUpdate.IsZero() && time.Now().After(crl.NextUpdate) {
+			logger.Warn("Certificate revocation list has expired", logger.Ctx{"issuer": issuer.Subject.String(), "next_update": crl.NextUpdate})
+		}
+
+		for _, revoked := range crl.RevokedCertificates {
+			if revoked.SerialNumber.Cmp(cert.SerialNumber) == 0 {
+				return fmt.Errorf("Certificate %q has been revoked", fingerprint)
+			}
+		}
+	}
+
+	if len(cert.OCSPServer) == 0 {
+		return nil
+	}
+
+	resp, err := a.ocspStatus(cert, issuer)
+	if err != nil {
+		return fmt.Errorf("Failed to check status of certificate %q with OCSP responder: %w", fingerprint, err)
+	}
+
+	if resp.Status == ocsp.Revoked {
+		return fmt.Errorf("Certificate %q has been revoked", fingerprint)
+	}
+
+	return nil
+}
+
+// ocspStatus returns the OCSP response for the given certificate. Cached responses are used until their next update,
+// after which they are refreshed in the background, and still used for up to ocspGrace while that fails. The OCSP
+// responder is only waited on for certificates without a usable response, and only by one caller at a time for each
+// certificate.
+func (a *Authority) ocspStatus(cert *x509.Certificate, issuer *x509.Certificate) (*ocsp.Response, error) {
+	fingerprint := shared.CertFingerprint(cert)
+	now := time.Now()
+
+	a.ocspCacheMu.Lock()
+	entry := a.ocspCache[fingerprint]
+	if entry == nil {
+		a.pruneOCSPCache(now)
+		entry = &ocspEntry{}
+		a.ocspCache[fingerprint] = entry
+	}
+
+	nextUpdate := entry.checked.Add(ocspRecheck)
+	if entry.resp != nil && !entry.resp.NextUpdate.IsZero() {
+		nextUpdate = entry.resp.NextUpdate
+	}
+
+	if entry.resp != nil && now.Before(nextUpdate) {
+		resp := entry.resp
+		a.ocspCacheMu.Unlock()
+
+		return resp, nil
+	}
+
+	// Ask the OCSP responder again, unless it has only just failed.
+	if entry.fetching == nil && (entry.err == nil || now.Sub(entry.checked) > ocspRecheck) {
+		entry.fetching = make(chan struct{})
+		go a.fetchOCSP(entry, cert, issuer)
+	}
+
+	fetching := entry.fetching
+	if entry.resp != nil && now.Before(nextUpdate.Add(ocspGrace)) {
+		resp := entry.resp
+		a.ocspCacheMu.Unlock()
+
+		return resp, nil
+	}
+
+	a.ocspCacheMu.Unlock()
+
+	if fetching != nil {
+		<-fetching
+	}
+
+	a.ocspCacheMu.Lock()
+	defer a.ocspCacheMu.Unlock()
+
+	if entry.err != nil {
+		return nil, entry.err
+	}
+
+	if entry.resp == nil {
+		return nil, fmt.Errorf("No response from OCSP responder")
+	}
+
+	return entry.resp, nil
+}
+
+// pruneOCSPCache makes room in the OCSP cache for a new certificate if it is full, by forgetting the responses that
+// can no longer be used, or any other one if there are none.
+func (a *Authority) pruneOCSPCache(now time.Time) {
+	if len(a.ocspCache) < ocspCacheMax {
+		return
+	}
+
+	for fingerprint, entry := range a.ocspCache {
+		if entry.fetching == nil && (entry.resp == nil || now.After(entry.resp.NextUpdate.Add(ocspGrace))) {
+			delete(a.ocspCache, fingerprint)
+		}
+	}
+
+	for fingerprint, entry := range a.ocspCache {
+		if len(a.ocspCache) < ocspCacheMax {
+			return
+		}
+
+		if entry.fetching == nil {
+			delete(a.ocspCache, fingerprint)
+		}
+	}
+}
+
+// fetchOCSP asks the OCSP responders of the given certificate for its status, and records the result in the entry.
+func (a *Authority) fetchOCSP(entry *ocspEntry, cert *x509.Certificate, issuer *x509.Certificate) {
+	resp, err := a.requestOCSP(cert, issuer)
+	if err != nil {
+		logger.Warn("Failed to check certificate status with OCSP responder", logger.Ctx{"fingerprint": shared.CertFingerprint(cert), "error": err})
+	}
+
+	a.ocspCacheMu.Lock()
+	defer a.ocspCacheMu.Unlock()
+
+	if err == nil {
+		entry.resp = resp
+	}
+
+	entry.err = err
+	entry.checked = time.Now()
+	close(entry.fetching)
+	entry.fetching = nil
+}
+
+// requestOCSP asks the OCSP responders of the given certificate for its status, in order, until one responds.
+func (a *Authority) requestOCSP(cert *x509.Certificate, issuer *x509.Certificate) (*ocsp.Response, error) {
+	req, err := ocsp.CreateRequest(cert, issuer, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create OCSP request: %w", err)
+	}
+
+	client := &http.Client{Timeout: ocspTimeout}
+
+	var lastErr error
+	for _, server := range cert.OCSPServer {
+		httpResp, err := client.Post(server, "application/ocsp-request", bytes.NewReader(req))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		body, err := io.ReadAll(httpResp.Body)
+		_ = httpResp.Body.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if httpResp.StatusCode != http.StatusOK {
+			lastErr = fmt.Errorf("OCSP responder %q returned status %d", server, httpResp.StatusCode)
+			continue
+		}
+
+		resp, err := ocsp.ParseResponseForCert(body, cert, issuer)
+		if err != nil {
+			lastErr = fmt.Errorf("Failed to parse response from OCSP responder %q: %w", server, err)
+			continue
+		}
+
+		return resp, nil
+	}
+
+	return nil, lastErr
+}
@@ -0,0 +1,98 @@
+package cryptopolicy
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+)
+
+// Policy is a set of restrictions on the cryptographic algorithms used for certificates and TLS.
+type Policy string
+
+const (
+	// PolicyDefault applies no restrictions beyond the defaults.
+	PolicyDefault Policy = ""
+
+	// PolicyFIPS restricts certificates and TLS to FIPS 140-2 approved algorithms.
+	// As TLS 1.3 cipher suites can not be restricted, TLS is limited to version 1.2.
+	PolicyFIPS Policy = "fips"
+)
+
+// Current is the crypto policy enforced by the daemon.
+var Current = PolicyDefault
+
+// fipsCipherSuites are the FIPS approved TLS 1.2 cipher suites.
+var fipsCipherSuites = []uint16{
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+}
+
+// fipsCurves are the FIPS approved elliptic curves for key exchange.
+var fipsCurves = []tls.CurveID{tls.CurveP384, tls.CurveP256, tls.CurveP521}
+
+// Parse returns the policy with the given name.
+func Parse(name string) (Policy, error) {
+	switch Policy(name) {
+	case PolicyDefault, PolicyFIPS:
+		return Policy(name), nil
+	}
+
+	return "", fmt.Errorf("Unknown crypto policy %q", name)
+}
+
+// ApplyTLS restricts the given TLS configuration according to the current policy.
+func ApplyTLS(config *tls.Config) {
+	ApplyTLSPolicy(config, Current)
+}
+
+// ApplyTLSPolicy restricts the given TLS configuration according to the given policy.
+func ApplyTLSPolicy(config *tls.Config, policy Policy) {
+	if policy != PolicyFIPS {
+		return
+	}
+
+	config.MinVersion = tls.VersionTLS12
+	config.MaxVersion = tls.VersionTLS12
+	config.CipherSuites = fipsCipherSuites
+	config.CurvePreferences = fipsCurves
+}
+
+// CheckCertificate returns an error if the given certificate's key or signature algorithm violates the current
+// policy.
+func CheckCertificate(cert *x509.Certificate) error {
+	if Current != PolicyFIPS {
+		return nil
+	}
+
+	switch cert.SignatureAlgorithm {
+	case x509.SHA256WithRSA, x509.SHA384WithRSA, x509.SHA512WithRSA,
+		x509.SHA256WithRSAPSS, x509.SHA384WithRSAPSS, x509.SHA512WithRSAPSS,
+		x509.ECDSAWithSHA256, x509.ECDSAWithSHA384, x509.ECDSAWithSHA512:
+	default:
+		return fmt.Errorf("Signature algorithm %q is not allowed by the %q crypto policy", cert.SignatureAlgorithm, Current)
+	}
+
+	switch key := cert.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256(), elliptic.P384(), elliptic.P521():
+		default:
+			return fmt.Errorf("Elliptic curve %q is not allowed by the %q crypto policy", key.Curve.Params().Name, Current)
+		}
+
+	case *rsa.PublicKey:
+		if key.N.BitLen() < 2048 {
+			return fmt.Errorf("RSA key size of %d bits is not allowed by the %q crypto policy", key.N.BitLen(), Current)
+		}
+
+	default:
+		return fmt.Errorf("Public key algorithm %q is not allowed by the %q crypto policy", cert.PublicKeyAlgorithm, Current)
+	}
+
+	return nil
+}
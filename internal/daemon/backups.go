@@ -0,0 +1,185 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/robfig/cron/v3"
+
+	"github.com/canonical/microcluster/internal/backup"
+	"github.com/canonical/microcluster/internal/rest/resources"
+)
+
+// BackupSchedule is a cron expression for when the leader takes a backup of the global database.
+// Scheduled backups are disabled if empty.
+var BackupSchedule string
+
+// BackupDir is the directory in which backup archives are written.
+// Defaults to the "backups" directory in the state directory.
+var BackupDir string
+
+// BackupTarget is an optional HTTP(S) URL to which each backup archive is uploaded with a PUT request, with the
+// archive name appended to the path. Pre-signed S3-compatible URLs can be used.
+var BackupTarget string
+
+// BackupCount is the number of backup archives kept in BackupDir. Older archives are removed.
+// A value of 0 or less keeps all archives.
+var BackupCount = 7
+
+// loopBackups takes a backup of the global database on the leader according to BackupSchedule.
+func (d *Daemon) loopBackups() {
+	if BackupSchedule == "" {
+		return
+	}
+
+	schedule, err := cron.ParseStandard(BackupSchedule)
+	if err != nil {
+		logger.Error("Invalid backup schedule, scheduled backups are disabled", logger.Ctx{"schedule": BackupSchedule, "error": err})
+		return
+	}
+
+	for {
+		select {
+		case <-d.ShutdownCtx.Done():
+			return
+		case <-time.After(time.Until(schedule.Next(time.Now()))):
+		}
+
+		if !d.db.IsOpen() {
+			continue
+		}
+
+		leader, err := d.isLeader()
+		if err != nil {
+			logger.Warn("Failed to determine dqlite leader for backup", logger.Ctx{"error": err})
+			continue
+		}
+
+		if !leader {
+			continue
+		}
+
+		err = d.State().RunOperation(resources.BackupOperation, "schedule", d.backup)
+		if err != nil {
+			logger.Error("Failed to back up database", logger.Ctx{"error": err})
+		}
+	}
+}
+
+// backup writes a compressed archive of the global database to BackupDir, uploads it to BackupTarget if set, and
+// removes archives beyond BackupCount.
+func (d *Daemon) backup(ctx context.Context) error {
+	compression, err := backup.Lookup(backup.DefaultCompression)
+	if err != nil {
+		return err
+	}
+
+	dump, err := d.db.Dump(ctx)
+	if err != nil {
+		return err
+	}
+
+	files := make([]backup.File, 0, len(dump))
+	for _, file := range dump {
+		files = append(files, backup.File{Name: file.Name, Data: file.Data})
+	}
+
+	buf := &bytes.Buffer{}
+	err = backup.WriteArchive(buf, compression, files)
+	if err != nil {
+		return fmt.Errorf("Failed to create backup archive: %w", err)
+	}
+
+	dir := BackupDir
+	if dir == "" {
+		dir = filepath.Join(d.os.StateDir, "backups")
+	}
+
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return fmt.Errorf("Failed to create backup directory: %w", err)
+	}
+
+	name := fmt.Sprintf("backup-%s.tar%s", time.Now().UTC().Format("20060102T150405Z"), compression.Extension)
+	err = os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to write backup archive: %w", err)
+	}
+
+	err = rotateBackups(dir, BackupCount)
+	if err != nil {
+		return err
+	}
+
+	if BackupTarget != "" {
+		err = uploadBackup(ctx, strings.TrimSuffix(BackupTarget, "/")+"/"+name, buf.Bytes(), compression.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rotateBackups removes the oldest backup archives in the given directory, keeping the given number.
+func rotateBackups(dir string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	archives, err := filepath.Glob(filepath.Join(dir, "backup-*.tar*"))
+	if err != nil {
+		return fmt.Errorf("Failed to list backup archives: %w", err)
+	}
+
+	// Archive names sort chronologically.
+	sort.Strings(archives)
+	for len(archives) > keep {
+		err = os.Remove(archives[0])
+		if err != nil {
+			return fmt.Errorf("Failed to remove old backup archive: %w", err)
+		}
+
+		archives = archives[1:]
+	}
+
+	return nil
+}
+
+// uploadBackup uploads the given backup archive, with the given compression, to the given URL with a PUT request.
+func uploadBackup(ctx context.Context, url string, data []byte, compression string) error {
+	uploadCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(uploadCtx, http.MethodPut, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("Failed to prepare backup upload: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-tar")
+	if compression != backup.Identity {
+		req.Header.Set("Content-Encoding", compression)
+	}
+	req.ContentLength = int64(len(data))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed to upload backup archive: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Failed to upload backup archive: Unexpected status %q", resp.Status)
+	}
+
+	return nil
+}
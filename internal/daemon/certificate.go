@@ -0,0 +1,228 @@
+package daemon
+
+import (
+	"bytes"
+	"crypto"
+	"fmt"
+	"path/filepath"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/canonical/microcluster/internal/ca"
+	"github.com/canonical/microcluster/internal/endpoints"
+	internalClient "github.com/canonical/microcluster/internal/rest/client"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/sys"
+	"github.com/canonical/microcluster/rest/types"
+)
+
+// setClusterCert replaces the cluster certificate used by the daemon, the database and the network listener, without
+// restarting any of them.
+func (d *Daemon) setClusterCert(cert *sys.CertInfo) error {
+	err := d.checkCryptoPolicy(cert)
+	if err != nil {
+		return err
+	}
+
+	d.certMu.Lock()
+	d.clusterCert = cert
+	d.certMu.Unlock()
+
+	d.db.SetClusterCert(cert)
+
+	if ConsumerAddress != "" {
+		err = d.endpoints.UpdateCert(endpoints.EndpointConsumer, cert)
+		if err != nil {
+			return err
+		}
+	}
+
+	return d.endpoints.UpdateCert(endpoints.EndpointNetwork, cert)
+}
+
+// acceptAlternateClusterCert accepts the pending or previous cluster certificate from other cluster members in place
+// of the current one, if the daemon stopped during a cluster certificate rotation.
+func (d *Daemon) acceptAlternateClusterCert() error {
+	alternate, err := d.os.PendingClusterCert()
+	if err != nil {
+		return err
+	}
+
+	if alternate == nil {
+		alternate, err = d.os.PreviousClusterCert()
+		if err != nil {
+			return err
+		}
+	}
+
+	if alternate == nil {
+		return nil
+	}
+
+	current, err := d.ClusterCert().PublicKeyX509()
+	if err != nil {
+		return fmt.Errorf("Failed to parse cluster certificate: %w", err)
+	}
+
+	alternateCert, err := alternate.PublicKeyX509()
+	if err != nil {
+		return fmt.Errorf("Failed to parse alternate cluster certificate: %w", err)
+	}
+
+	internalClient.SetAlternateRemoteCert(current, alternateCert)
+
+	return nil
+}
+
+// loadCertificateAuthority loads the external certificate authority bundle and revocation lists from the state
+// directory, and reloads them whenever either file changes. If there is no bundle, certificates are trusted by
+// fingerprint.
+func (d *Daemon) loadCertificateAuthority() error {
+	reload := func() error {
+		authority, err := ca.Load(d.os.CAPath(), d.os.CRLPath())
+		if err != nil {
+			return err
+		}
+
+		if authority != nil && ca.Current() == nil {
+			logger.Info("Validating certificates against external certificate authority", logger.Ctx{"path": d.os.CAPath()})
+		} else if authority == nil && ca.Current() != nil {
+			logger.Info("Trusting certificates by fingerprint as the external certificate authority was removed")
+		}
+
+		ca.SetCurrent(authority)
+
+		return nil
+	}
+
+	err := reload()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range []string{d.os.CAPath(), d.os.CRLPath()} {
+		d.fsWatcher.Watch(path, path, func(path string, event fsnotify.Op) error {
+			return reload()
+		})
+	}
+
+	return nil
+}
+
+// watchCertificates reloads the cluster and server certificates whenever they are replaced in the state directory, so
+// that the network listeners serve the new certificates to new connections without restarting the daemon.
+func (d *Daemon) watchCertificates() {
+	for _, prefix := range []string{"cluster", "server"} {
+		prefix := prefix
+		for _, ext := range []string{".crt", ".key"} {
+			path := filepath.Join(d.os.StateDir, prefix+ext)
+			d.fsWatcher.Watch(path, path, func(path string, event fsnotify.Op) error {
+				return d.reloadCert(prefix)
+			})
+		}
+	}
+}
+
+// reloadCert loads the keypair with the given prefix from the state directory, and swaps it in if it differs from the
+// one in use. Keypairs that are removed or only partially replaced are ignored until both files are in place.
+func (d *Daemon) reloadCert(prefix string) error {
+	current := d.ServerCert()
+	if prefix == "cluster" {
+		// The cluster certificate is only served once the daemon is initialized.
+		current = d.ClusterCert()
+		if current == nil {
+			return nil
+		}
+	}
+
+	if !shared.PathExists(filepath.Join(d.os.StateDir, prefix+".crt")) || !shared.PathExists(filepath.Join(d.os.StateDir, prefix+".key")) {
+		return nil
+	}
+
+	cert, err := d.os.LoadKeyPair(prefix)
+	if err != nil {
+		return fmt.Errorf("Failed to reload %q certificate: %w", prefix, err)
+	}
+
+	if bytes.Equal(cert.PublicKey(), current.PublicKey()) {
+		return nil
+	}
+
+	logger.Info("Reloading certificate", logger.Ctx{"name": prefix})
+
+	if prefix == "cluster" {
+		return d.setClusterCert(cert)
+	}
+
+	return d.setServerCert(cert)
+}
+
+// setServerCert replaces the server certificate used by the daemon, the database and the network listener, without
+// restarting any of them. Once the daemon is initialized, the new certificate is first pushed to the other cluster
+// members, as they would otherwise no longer trust this cluster member.
+func (d *Daemon) setServerCert(cert *sys.CertInfo) error {
+	err := d.checkCryptoPolicy(cert)
+	if err != nil {
+		return err
+	}
+
+	if d.ClusterCert() != nil {
+		err = d.pushServerCert(cert)
+		if err != nil {
+			return err
+		}
+	}
+
+	d.certMu.Lock()
+	d.serverCert = cert
+	clusterCert := d.clusterCert
+	d.certMu.Unlock()
+
+	d.db.SetServerCert(cert)
+
+	// Until the daemon is initialized, the network listener, if any, serves the server certificate to all clients.
+	if clusterCert == nil {
+		_ = d.endpoints.UpdateCert(endpoints.EndpointNetwork, cert)
+		_ = d.endpoints.UpdateMemberCert(endpoints.EndpointNetwork, cert)
+
+		return nil
+	}
+
+	return d.endpoints.UpdateMemberCert(endpoints.EndpointNetwork, cert)
+}
+
+// pushServerCert replaces the certificate of this cluster member in the database and in the trust store of every
+// cluster member, through the control socket. The request is signed with the new key, to prove that this cluster member
+// holds it. It must be called while the current certificate is still in use, as the other cluster members only trust
+// that one until they are notified.
+func (d *Daemon) pushServerCert(cert *sys.CertInfo) error {
+	x509Cert, err := cert.PublicKeyX509()
+	if err != nil {
+		return fmt.Errorf("Failed to parse server certificate: %w", err)
+	}
+
+	signer, ok := cert.KeyPair().PrivateKey.(crypto.Signer)
+	if !ok {
+		return fmt.Errorf("Server key can not be used for signing")
+	}
+
+	req := internalTypes.ClusterMemberCertificate{Certificate: types.X509Certificate{Certificate: x509Cert}}
+	err = req.Sign(d.Name(), signer)
+	if err != nil {
+		return err
+	}
+
+	c, err := internalClient.New(d.os.ControlSocket(), nil, nil, false)
+	if err != nil {
+		return err
+	}
+
+	err = c.UpdateClusterMemberCertificate(d.ShutdownCtx, d.Name(), req)
+	if err != nil {
+		return fmt.Errorf("Failed to update the certificate of this cluster member on the cluster: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package daemon
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/cluster"
+)
+
+// loopTransferFromCordoned periodically transfers dqlite leadership away from this cluster member if it is the leader
+// while cordoned for maintenance, as it may have been elected after it was cordoned.
+func (d *Daemon) loopTransferFromCordoned() {
+	for {
+		select {
+		case <-d.ShutdownCtx.Done():
+			return
+		case <-time.After(30 * time.Second):
+		}
+
+		if !d.db.IsOpen() {
+			continue
+		}
+
+		leader, err := d.isLeader()
+		if err != nil {
+			logger.Warn("Failed to determine dqlite leader for cordoned member check", logger.Ctx{"error": err})
+			continue
+		}
+
+		if !leader {
+			continue
+		}
+
+		var cordoned map[string]bool
+		err = d.db.Transaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+			var err error
+			cordoned, err = cluster.GetCordonedClusterMemberAddresses(ctx, tx)
+			return err
+		})
+		if err != nil {
+			logger.Warn("Failed to get cordoned cluster members", logger.Ctx{"error": err})
+			continue
+		}
+
+		if !cordoned[d.address.URL.Host] {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(d.ShutdownCtx, 30*time.Second)
+		err = d.db.TransferLeadership(ctx, cordoned)
+		cancel()
+		if err != nil {
+			logger.Warn("Failed to transfer leadership away from cordoned cluster member", logger.Ctx{"error": err})
+		}
+	}
+}
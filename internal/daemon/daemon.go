@@ -2,18 +2,18 @@ package daemon
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
-	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/canonical/lxd/lxd/db/schema"
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
-	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
@@ -23,6 +23,7 @@ import (
 	"github.com/canonical/microcluster/client"
 	"github.com/canonical/microcluster/cluster"
 	"github.com/canonical/microcluster/config"
+	"github.com/canonical/microcluster/internal/cryptopolicy"
 	"github.com/canonical/microcluster/internal/db"
 	"github.com/canonical/microcluster/internal/endpoints"
 	internalREST "github.com/canonical/microcluster/internal/rest"
@@ -40,12 +41,14 @@ import (
 type Daemon struct {
 	project string // The project refers to the name of the go-project that is calling MicroCluster.
 
-	address api.URL // Listen Address.
+	address api.URL // Address advertised to other cluster members.
 	name    string  // Name of the cluster member.
 
 	os          *sys.OS
-	serverCert  *shared.CertInfo
-	clusterCert *shared.CertInfo
+	certMu      sync.RWMutex // Protects serverCert and clusterCert, which are replaced when reloaded.
+	serverCert  *sys.CertInfo
+	clusterCert *sys.CertInfo
+	secretsKey  []byte
 
 	endpoints *endpoints.Endpoints
 	db        *db.DB
@@ -53,7 +56,8 @@ type Daemon struct {
 	fsWatcher  *sys.Watcher
 	trustStore *trust.Store
 
-	hooks config.Hooks // Hooks to be called upon various daemon actions.
+	hooks   config.Hooks   // Hooks to be called upon various daemon actions.
+	patches []config.Patch // One-time patches to apply on this cluster member.
 
 	ReadyChan      chan struct{}      // Closed when the daemon is fully ready.
 	ShutdownCtx    context.Context    // Cancelled when shutdown starts.
@@ -61,6 +65,52 @@ type Daemon struct {
 	ShutdownCancel context.CancelFunc // Cancels the shutdownCtx to indicate shutdown starting.
 }
 
+// ListenAddresses are the addresses the cluster API binds to, if they differ from the address advertised to other
+// cluster members, such as behind NAT or a proxy, or to listen on both IPv4 and IPv6. The advertised address is still
+// the one registered with dqlite and the trust store.
+var ListenAddresses []string
+
+// HTTPReadTimeout and HTTPWriteTimeout bound the time to read a whole request and to write its response, on the HTTP
+// servers of all endpoints. Connections hijacked for websockets or the database are not affected. Zero means no limit.
+var HTTPReadTimeout, HTTPWriteTimeout time.Duration
+
+// HTTPIdleTimeout is how long idle keep-alive connections are kept open, for both HTTP/1.1 and HTTP/2.
+var HTTPIdleTimeout = 2 * time.Minute
+
+// HTTPReadHeaderTimeout bounds the time to read the headers of a request.
+var HTTPReadHeaderTimeout = 30 * time.Second
+
+// HTTPMaxHeaderBytes is the maximum size of the headers of a request.
+var HTTPMaxHeaderBytes = http.DefaultMaxHeaderBytes
+
+// ApplicationSocket is the path of a separate unix socket for the application API, which serves only the endpoints
+// registered by the application. If empty, the application API is only served on the control socket and the network.
+var ApplicationSocket string
+
+// ApplicationSocketOwner and ApplicationSocketGroup are the user and group that own the application socket. If
+// empty, the user and group of the daemon are used.
+var ApplicationSocketOwner, ApplicationSocketGroup string
+
+// ApplicationSocketMode is the file mode of the application socket.
+var ApplicationSocketMode os.FileMode = 0660
+
+// ConsumerAddress is the address of a separate listener for the consumer API, which serves the public and application
+// endpoints but not the cluster-internal ones. If empty, the consumer API is only served on the cluster address.
+var ConsumerAddress string
+
+// ConsumerCryptoPolicy is the crypto policy applied to TLS on the consumer API listener.
+var ConsumerCryptoPolicy = cryptopolicy.PolicyDefault
+
+// Middleware wraps the endpoints registered by the application, with the first middleware outermost.
+var Middleware []rest.Middleware
+
+// APIVersions are the versions of the application API served in addition to, or amending, the endpoints given to Init,
+// which are served under /1.0.
+var APIVersions []rest.APIVersion
+
+// NotFoundHandler handles requests that match no endpoint, in place of the default 404 response.
+var NotFoundHandler http.Handler
+
 // NewDaemon initializes the Daemon context and channels.
 func NewDaemon(ctx context.Context, project string) *Daemon {
 	ctx, cancel := context.WithCancel(ctx)
@@ -74,7 +124,7 @@ func NewDaemon(ctx context.Context, project string) *Daemon {
 }
 
 // Init initializes the Daemon with the given configuration, and starts the database.
-func (d *Daemon) Init(listenPort string, stateDir string, socketGroup string, extendedEndpoints []rest.Endpoint, schemaExtensions map[int]schema.Update, hooks *config.Hooks) error {
+func (d *Daemon) Init(listenPort string, stateDir string, socketGroup string, extendedEndpoints []rest.Endpoint, schemaExtensions map[int]schema.Update, hooks *config.Hooks, patches []config.Patch) error {
 	if stateDir == "" {
 		stateDir = os.Getenv(sys.StateDir)
 	}
@@ -94,6 +144,8 @@ func (d *Daemon) Init(listenPort string, stateDir string, socketGroup string, ex
 		return fmt.Errorf("Failed to initialize directory structure: %w", err)
 	}
 
+	d.patches = patches
+
 	err = d.init(listenPort, extendedEndpoints, schemaExtensions, hooks)
 	if err != nil {
 		return fmt.Errorf("Daemon failed to start: %w", err)
@@ -118,7 +170,7 @@ func (d *Daemon) init(listenPort string, extendedEndpoints []rest.Endpoint, sche
 		return fmt.Errorf("Failed to assign default system name: %w", err)
 	}
 
-	d.serverCert, err = util.LoadServerCert(d.os.StateDir)
+	d.serverCert, err = d.os.LoadKeyPair("server")
 	if err != nil {
 		return err
 	}
@@ -128,12 +180,42 @@ func (d *Daemon) init(listenPort string, extendedEndpoints []rest.Endpoint, sche
 		return fmt.Errorf("Failed to initialize trust store: %w", err)
 	}
 
+	err = d.checkCryptoPolicy(d.serverCert)
+	if err != nil {
+		return err
+	}
+
 	d.db = db.NewDB(d.ShutdownCtx, d.serverCert, d.os)
+	d.db.SetRemotes(d.trustStore.Remotes)
+
+	err = d.db.OpenLocal()
+	if err != nil {
+		return fmt.Errorf("Failed to open local database: %w", err)
+	}
+
+	// A cluster member without a database yet will have all patches marked as applied when it bootstraps or joins.
+	_, err = os.Stat(filepath.Join(d.os.DatabaseDir, "info.yaml"))
+	if err == nil {
+		err = d.applyPatches(config.PatchPreDatabase)
+		if err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
 
 	// Apply extensions to API/Schema.
 	resources.ExtendedEndpoints.Endpoints = append(resources.ExtendedEndpoints.Endpoints, extendedEndpoints...)
+	resources.EnableOpenAPIEndpoint()
+	resources.EnableHealthEndpoints()
+	for _, version := range APIVersions {
+		err = resources.AddAPIVersion(version)
+		if err != nil {
+			return err
+		}
+	}
 
-	ctlServer := d.initServer(resources.UnixEndpoints, resources.InternalEndpoints, resources.PublicEndpoints, resources.ExtendedEndpoints)
+	ctlServer := d.initServer(false, resources.UnixEndpoints, resources.InternalEndpoints, resources.PublicEndpoints, resources.ExtendedEndpoints)
 	ctl := endpoints.NewSocket(d.ShutdownCtx, ctlServer, d.os.ControlSocket(), d.os.SocketGroup)
 	d.endpoints = endpoints.NewEndpoints(d.ShutdownCtx, ctl)
 	err = d.endpoints.Up()
@@ -141,10 +223,20 @@ func (d *Daemon) init(listenPort string, extendedEndpoints []rest.Endpoint, sche
 		return err
 	}
 
+	if ApplicationSocket != "" {
+		appServer := d.initServer(false, resources.ExtendedEndpoints)
+		appSocket := endpoints.NewApplicationSocket(d.ShutdownCtx, appServer, ApplicationSocket, ApplicationSocketOwner, ApplicationSocketGroup, ApplicationSocketMode)
+		err = d.endpoints.Add(appSocket)
+		if err != nil {
+			return fmt.Errorf("Failed to start application socket: %w", err)
+		}
+	}
+
 	if listenPort != "" {
-		server := d.initServer(resources.PublicEndpoints, resources.ExtendedEndpoints)
+		server := d.initServer(ConsumerAddress == "", resources.PublicEndpoints, resources.ExtendedEndpoints)
+
 		url := api.NewURL().Host(fmt.Sprintf(":%s", listenPort))
-		network := endpoints.NewNetwork(d.ShutdownCtx, endpoints.EndpointNetwork, server, *url, d.serverCert)
+		network := endpoints.NewNetwork(d.ShutdownCtx, endpoints.EndpointNetwork, server, *url, d.serverCert, d.serverCert)
 		err = d.endpoints.Add(network)
 		if err != nil {
 			return err
@@ -153,16 +245,34 @@ func (d *Daemon) init(listenPort string, extendedEndpoints []rest.Endpoint, sche
 
 	d.db.SetSchema(schemaExtensions)
 
+	interruptedJoin, err := d.recoverInterruptedJoin()
+	if err != nil {
+		return err
+	}
+
 	err = d.reloadIfBootstrapped()
 	if err != nil {
 		return err
 	}
 
+	if interruptedJoin != nil {
+		go d.retryJoin(*interruptedJoin)
+	}
+
 	err = d.trustStore.Refresh()
 	if err != nil {
 		return err
 	}
 
+	go d.loopPruneOperations()
+	go d.loopPruneEvents()
+	go d.loopSnapshots()
+	go d.loopBackups()
+	go d.loopDemoteOffline()
+	go d.loopDiskSpace()
+	go d.loopTransferFromCordoned()
+	go d.loopCertificateWarnings()
+
 	return nil
 }
 
@@ -171,6 +281,13 @@ func (d *Daemon) applyHooks(hooks *config.Hooks) {
 	noOpHook := func(s *state.State) error { return nil }
 	noOpRemoveHook := func(s *state.State, force bool) error { return nil }
 	noOpInitHook := func(s *state.State, initConfig map[string]string) error { return nil }
+	noOpFeatureHook := func(s *state.State, name string) error { return nil }
+	noOpJoinHook := func(s *state.State, name string, joinConfig map[string]string) error { return nil }
+	noOpDemotedHook := func(s *state.State, name string, reason string) error { return nil }
+	noOpConfigHook := func(s *state.State, keys []string) error { return nil }
+	noOpProjectHook := func(s *state.State, r *http.Request, project string) error { return nil }
+	noOpPanicHook := func(s *state.State, r *http.Request, requestID string, value any) error { return nil }
+	noOpStatusHook := func(s *state.State) (map[string]any, error) { return nil, nil }
 
 	if hooks == nil {
 		d.hooks = config.Hooks{}
@@ -190,6 +307,10 @@ func (d *Daemon) applyHooks(hooks *config.Hooks) {
 		d.hooks.PreJoin = noOpInitHook
 	}
 
+	if d.hooks.ValidateJoin == nil {
+		d.hooks.ValidateJoin = noOpJoinHook
+	}
+
 	if d.hooks.OnStart == nil {
 		d.hooks.OnStart = noOpHook
 	}
@@ -198,6 +319,10 @@ func (d *Daemon) applyHooks(hooks *config.Hooks) {
 		d.hooks.OnHeartbeat = noOpHook
 	}
 
+	if d.hooks.HeartbeatStatus == nil {
+		d.hooks.HeartbeatStatus = noOpStatusHook
+	}
+
 	if d.hooks.OnNewMember == nil {
 		d.hooks.OnNewMember = noOpHook
 	}
@@ -209,6 +334,34 @@ func (d *Daemon) applyHooks(hooks *config.Hooks) {
 	if d.hooks.PostRemove == nil {
 		d.hooks.PostRemove = noOpRemoveHook
 	}
+
+	if d.hooks.OnFeatureChange == nil {
+		d.hooks.OnFeatureChange = noOpFeatureHook
+	}
+
+	if d.hooks.OnMemberDemoted == nil {
+		d.hooks.OnMemberDemoted = noOpDemotedHook
+	}
+
+	if d.hooks.OnMemberPromoted == nil {
+		d.hooks.OnMemberPromoted = noOpDemotedHook
+	}
+
+	if d.hooks.OnConfigChange == nil {
+		d.hooks.OnConfigChange = noOpConfigHook
+	}
+
+	if d.hooks.OnHandlerPanic == nil {
+		d.hooks.OnHandlerPanic = noOpPanicHook
+	}
+
+	if d.hooks.Upgrade == nil {
+		d.hooks.Upgrade = noOpHook
+	}
+
+	if d.hooks.ProjectAccess == nil {
+		d.hooks.ProjectAccess = noOpProjectHook
+	}
 }
 
 func (d *Daemon) reloadIfBootstrapped() error {
@@ -257,10 +410,32 @@ func (d *Daemon) initStore() error {
 		return err
 	}
 
+	err = d.loadCertificateAuthority()
+	if err != nil {
+		return err
+	}
+
+	d.watchCertificates()
+
 	return nil
 }
 
-func (d *Daemon) initServer(resources ...*resources.Resources) *http.Server {
+// initServer returns an HTTP server for the given API resources. If cors is true, the public and application endpoints
+// answer cross-origin requests from the allowed origins, while the internal and control endpoints never do.
+func (d *Daemon) initServer(cors bool, apiResources ...*resources.Resources) *http.Server {
+	// The other versions of the application API are served wherever version 1.0 is.
+	versions := []string{"/" + string(internalClient.ExtendedEndpoint)}
+	for _, endpoints := range apiResources {
+		if endpoints == resources.ExtendedEndpoints {
+			for _, version := range resources.ExtendedVersions {
+				apiResources = append(apiResources, version)
+				versions = append(versions, "/"+string(version.Path))
+			}
+
+			break
+		}
+	}
+
 	/* Setup the web server */
 	mux := mux.NewRouter()
 	mux.StrictSlash(false)
@@ -269,39 +444,68 @@ func (d *Daemon) initServer(resources ...*resources.Resources) *http.Server {
 
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		err := response.SyncResponse(true, []string{"/1.0"}).Render(w)
+		err := response.SyncResponse(true, versions).Render(w)
 		if err != nil {
 			logger.Error("Failed to write HTTP response", logger.Ctx{"url": r.URL, "err": err})
 		}
 	})
 
-	mux.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		logger.Info("Sending top level 404", logger.Ctx{"url": r.URL})
-		w.Header().Set("Content-Type", "application/json")
-		err := response.NotFound(nil).Render(w)
-		if err != nil {
-			logger.Error("Failed to write HTTP response", logger.Ctx{"url": r.URL, "err": err})
-		}
-	})
+	mux.NotFoundHandler = NotFoundHandler
+	if mux.NotFoundHandler == nil {
+		mux.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger.Info("Sending top level 404", logger.Ctx{"url": r.URL})
+			w.Header().Set("Content-Type", "application/json")
+			err := response.NotFound(nil).Render(w)
+			if err != nil {
+				logger.Error("Failed to write HTTP response", logger.Ctx{"url": r.URL, "err": err})
+			}
+		})
+	}
 
 	state := d.State()
-	for _, endpoints := range resources {
+	for _, endpoints := range apiResources {
+		var appMiddleware []rest.Middleware
+		if cors && endpoints != resources.UnixEndpoints && endpoints != resources.InternalEndpoints {
+			appMiddleware = append(appMiddleware, internalREST.CORS)
+		}
+
+		if endpoints == resources.ExtendedEndpoints || shared.ValueInSlice(endpoints, resources.ExtendedVersions) {
+			appMiddleware = append(appMiddleware, Middleware...)
+		}
+
 		for _, e := range endpoints.Endpoints {
-			internalREST.HandleEndpoint(state, mux, string(endpoints.Path), e)
+			middleware := appMiddleware
+			if endpoints.Deprecated || e.Deprecated {
+				middleware = append([]rest.Middleware{internalREST.Deprecation(endpoints.Sunset)}, appMiddleware...)
+			}
+
+			internalREST.HandleEndpoint(state, mux, string(endpoints.Path), e, middleware...)
 
 			for _, alias := range e.Aliases {
 				ae := e
 				ae.Name = alias.Name
 				ae.Path = alias.Path
 
-				internalREST.HandleEndpoint(state, mux, string(endpoints.Path), ae)
+				aliasMiddleware := middleware
+				if alias.Deprecated {
+					aliasMiddleware = append([]rest.Middleware{internalREST.DeprecatedAlias(string(endpoints.Path), alias.Path, e.Path)}, middleware...)
+				}
+
+				internalREST.HandleEndpoint(state, mux, string(endpoints.Path), ae, aliasMiddleware...)
 			}
 		}
 	}
 
 	return &http.Server{
-		Handler:     mux,
-		ConnContext: request.SaveConnectionInContext,
+		Handler:           mux,
+		ReadTimeout:       HTTPReadTimeout,
+		ReadHeaderTimeout: HTTPReadHeaderTimeout,
+		WriteTimeout:      HTTPWriteTimeout,
+		IdleTimeout:       HTTPIdleTimeout,
+		MaxHeaderBytes:    HTTPMaxHeaderBytes,
+		ConnContext: func(ctx context.Context, conn net.Conn) context.Context {
+			return internalREST.VerificationContext(request.SaveConnectionInContext(ctx, conn))
+		},
 	}
 }
 
@@ -319,7 +523,7 @@ func (d *Daemon) StartAPI(bootstrap bool, initConfig map[string]string, newConfi
 		return fmt.Errorf("Cannot start network API without valid daemon configuration")
 	}
 
-	serverCert, err := d.serverCert.PublicKeyX509()
+	serverCert, err := d.ServerCert().PublicKeyX509()
 	if err != nil {
 		return fmt.Errorf("Failed to parse server certificate when bootstrapping API: %w", err)
 	}
@@ -341,13 +545,36 @@ func (d *Daemon) StartAPI(bootstrap bool, initConfig map[string]string, newConfi
 		}
 	}
 
-	d.clusterCert, err = util.LoadClusterCert(d.os.StateDir)
+	clusterCert, err := d.os.LoadKeyPair("cluster")
 	if err != nil {
 		return err
 	}
 
-	server := d.initServer(resources.InternalEndpoints, resources.PublicEndpoints, resources.ExtendedEndpoints)
-	network := endpoints.NewNetwork(d.ShutdownCtx, endpoints.EndpointNetwork, server, d.address, d.clusterCert)
+	err = d.checkCryptoPolicy(clusterCert)
+	if err != nil {
+		return err
+	}
+
+	d.certMu.Lock()
+	d.clusterCert = clusterCert
+	d.certMu.Unlock()
+
+	err = d.acceptAlternateClusterCert()
+	if err != nil {
+		return err
+	}
+
+	// The secrets key is only needed for the secrets API, so do not prevent the daemon from starting without it.
+	d.secretsKey, err = d.os.LoadSecretsKey(bootstrap, clusterCert.PrivateKey())
+	if err != nil {
+		logger.Warn("Failed to load secrets key", logger.Ctx{"error": err})
+	}
+
+	// Without a separate listener, the consumer API is served on the cluster address.
+	server := d.initServer(ConsumerAddress == "", resources.InternalEndpoints, resources.PublicEndpoints, resources.ExtendedEndpoints)
+
+	network := endpoints.NewNetwork(d.ShutdownCtx, endpoints.EndpointNetwork, server, d.address, clusterCert, d.ServerCert())
+	network.SetAddresses(d.listenAddresses()...)
 	err = d.endpoints.Down(endpoints.EndpointNetwork)
 	if err != nil {
 		return err
@@ -358,6 +585,11 @@ func (d *Daemon) StartAPI(bootstrap bool, initConfig map[string]string, newConfi
 		return err
 	}
 
+	err = d.startConsumerAPI()
+	if err != nil {
+		return err
+	}
+
 	// If bootstrapping the first node, just open the database and create an entry for ourselves.
 	if bootstrap {
 		clusterMember := cluster.InternalClusterMember{
@@ -374,11 +606,34 @@ func (d *Daemon) StartAPI(bootstrap bool, initConfig map[string]string, newConfi
 			return err
 		}
 
+		err = d.db.UpdateMemberInfo(d.ShutdownCtx)
+		if err != nil {
+			return fmt.Errorf("Failed to update cluster member information: %w", err)
+		}
+
+		err = d.db.Transaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+			_, err := cluster.CreateInternalEvent(ctx, tx, cluster.NewInternalEvent(cluster.EventMemberJoined, localNode.Name, "Bootstrapped the cluster"))
+			if err != nil {
+				return err
+			}
+
+			_, err = cluster.CreateInternalTrustAudit(ctx, tx, cluster.NewInternalTrustAudit(cluster.TrustAuditAdd, cluster.TrustAuditClusterMember, localNode.Name, shared.CertFingerprint(localNode.Certificate.Certificate), rest.Identity{Type: rest.IdentityLocal}.String()))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to record bootstrap event: %w", err)
+		}
+
 		err = d.trustStore.Refresh()
 		if err != nil {
 			return err
 		}
 
+		err = d.markPatchesApplied()
+		if err != nil {
+			return err
+		}
+
 		return d.hooks.OnBootstrap(d.State(), initConfig)
 	}
 
@@ -388,17 +643,56 @@ func (d *Daemon) StartAPI(bootstrap bool, initConfig map[string]string, newConfi
 			return fmt.Errorf("Failed to join cluster: %w", err)
 		}
 	} else {
-		err = d.db.StartWithCluster(d.project, d.address, d.trustStore.Remotes().Addresses(), d.clusterCert)
+		d.pullTrustStore()
+
+		err = d.db.StartWithCluster(d.project, d.address, d.trustStore.Remotes().Addresses(), d.ClusterCert())
 		if err != nil {
 			return fmt.Errorf("Failed to re-establish cluster connection: %w", err)
 		}
 	}
 
+	err = d.db.UpdateMemberInfo(d.ShutdownCtx)
+	if err != nil {
+		return fmt.Errorf("Failed to update cluster member information: %w", err)
+	}
+
 	err = d.trustStore.Refresh()
 	if err != nil {
 		return err
 	}
 
+	// Now that dqlite has been reconfigured, let the next cluster member join.
+	if len(joinAddresses) > 0 {
+		err = d.db.Transaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+			err := cluster.ReleaseJoinLock(ctx, tx, localNode.Name)
+			if err != nil {
+				return err
+			}
+
+			_, err = cluster.CreateInternalEvent(ctx, tx, cluster.NewInternalEvent(cluster.EventMemberJoined, localNode.Name, "Joined the cluster"))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to release join lock: %w", err)
+		}
+
+		// The join can no longer be retried from scratch once dqlite has been joined.
+		err = os.Remove(d.os.JoinStatePath())
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Failed to remove join request record: %w", err)
+		}
+	}
+
+	if len(joinAddresses) > 0 {
+		err = d.markPatchesApplied()
+	} else {
+		err = d.applyPatches(config.PatchPostDatabase)
+	}
+
+	if err != nil {
+		return err
+	}
+
 	// Get a client for every other cluster member in the newly refreshed local store.
 	cluster := make(client.Cluster, 0, d.trustStore.Remotes().Count()-1)
 	for _, addr := range d.trustStore.Remotes().Addresses() {
@@ -429,32 +723,15 @@ func (d *Daemon) StartAPI(bootstrap bool, initConfig map[string]string, newConfi
 
 	// Send notification that this node is upgraded to all other cluster members.
 	err = cluster.Query(d.ShutdownCtx, true, func(ctx context.Context, c *client.Client) error {
-		path := c.URL()
-		parts := strings.Split(string(internalClient.InternalEndpoint), "/")
-		parts = append(parts, "database")
-		path = *path.Path(parts...)
-		upgradeRequest, err := http.NewRequest("PATCH", path.String(), nil)
-		if err != nil {
-			return err
-		}
-
-		upgradeRequest.Header.Set("X-Dqlite-Version", fmt.Sprintf("%d", 1))
-		upgradeRequest = upgradeRequest.WithContext(ctx)
-
-		resp, err := c.Client.Do(upgradeRequest)
-		if err != nil {
-			logger.Error("Failed to send database upgrade request", logger.Ctx{"error": err})
-			return nil
-		}
-
-		defer resp.Body.Close()
-		_, err = io.Copy(io.Discard, resp.Body)
+		err := c.NotifyUpgraded(ctx)
 		if err != nil {
-			logger.Error("Failed to read upgrade notification response body", logger.Ctx{"error": err})
-		}
+			_, found := api.StatusErrorMatch(err)
+			if !found {
+				logger.Error("Failed to send database upgrade request", logger.Ctx{"error": err})
+				return nil
+			}
 
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("Database upgrade notification failed: %s", resp.Status)
+			return err
 		}
 
 		if len(joinAddresses) > 0 {
@@ -478,13 +755,58 @@ func (d *Daemon) StartAPI(bootstrap bool, initConfig map[string]string, newConfi
 	return nil
 }
 
+// startConsumerAPI starts the separate listener for the consumer API, if ConsumerAddress is set. It presents the
+// cluster certificate, so that clients trust it as they would the cluster address.
+func (d *Daemon) startConsumerAPI() error {
+	if ConsumerAddress == "" {
+		return nil
+	}
+
+	server := d.initServer(true, resources.PublicEndpoints, resources.ExtendedEndpoints)
+	connContext := server.ConnContext
+	server.ConnContext = func(ctx context.Context, conn net.Conn) context.Context {
+		return internalREST.ConsumerContext(connContext(ctx, conn))
+	}
+
+	url := api.NewURL().Scheme("https").Host(ConsumerAddress)
+	consumer := endpoints.NewNetwork(d.ShutdownCtx, endpoints.EndpointConsumer, server, *url, d.ClusterCert(), nil)
+	consumer.SetCryptoPolicy(ConsumerCryptoPolicy)
+
+	err := d.endpoints.Down(endpoints.EndpointConsumer)
+	if err != nil {
+		return err
+	}
+
+	err = d.endpoints.Add(consumer)
+	if err != nil {
+		return fmt.Errorf("Failed to start consumer API listener: %w", err)
+	}
+
+	return nil
+}
+
 // ClusterCert ensures both the daemon and state have the same cluster cert.
-func (d *Daemon) ClusterCert() *shared.CertInfo {
+func (d *Daemon) ClusterCert() *sys.CertInfo {
+	d.certMu.RLock()
+	defer d.certMu.RUnlock()
+
 	return d.clusterCert
 }
 
+// SecretsKey returns the key that cluster-wide secrets are encrypted with.
+func (d *Daemon) SecretsKey() ([]byte, error) {
+	if d.secretsKey == nil {
+		return nil, fmt.Errorf("Secrets key is not available")
+	}
+
+	return d.secretsKey, nil
+}
+
 // ServerCert ensures both the daemon and state have the same server cert.
-func (d *Daemon) ServerCert() *shared.CertInfo {
+func (d *Daemon) ServerCert() *sys.CertInfo {
+	d.certMu.RLock()
+	defer d.certMu.RUnlock()
+
 	return d.serverCert
 }
 
@@ -494,6 +816,21 @@ func (d *Daemon) Address() *api.URL {
 	return &copyURL
 }
 
+// listenAddresses returns the addresses the cluster API binds to, which is the advertised address unless
+// ListenAddresses is set.
+func (d *Daemon) listenAddresses() []api.URL {
+	if len(ListenAddresses) == 0 {
+		return []api.URL{d.address}
+	}
+
+	addresses := make([]api.URL, 0, len(ListenAddresses))
+	for _, address := range ListenAddresses {
+		addresses = append(addresses, *api.NewURL().Scheme("https").Host(address))
+	}
+
+	return addresses
+}
+
 // Name ensures both the daemon and state have the same name.
 func (d *Daemon) Name() string {
 	return d.name
@@ -504,7 +841,16 @@ func (d *Daemon) State() *state.State {
 	state.PreRemoveHook = d.hooks.PreRemove
 	state.PostRemoveHook = d.hooks.PostRemove
 	state.OnHeartbeatHook = d.hooks.OnHeartbeat
+	state.HeartbeatStatusHook = d.hooks.HeartbeatStatus
+	state.ValidateJoinHook = d.hooks.ValidateJoin
 	state.OnNewMemberHook = d.hooks.OnNewMember
+	state.OnFeatureChangeHook = d.hooks.OnFeatureChange
+	state.OnConfigChangeHook = d.hooks.OnConfigChange
+	state.OnMemberDemotedHook = d.hooks.OnMemberDemoted
+	state.OnMemberPromotedHook = d.hooks.OnMemberPromoted
+	state.OnHandlerPanicHook = d.hooks.OnHandlerPanic
+	state.UpgradeHook = d.hooks.Upgrade
+	state.ProjectAccessHook = d.hooks.ProjectAccess
 	state.StopListeners = func() error {
 		err := d.fsWatcher.Close()
 		if err != nil {
@@ -521,9 +867,12 @@ func (d *Daemon) State() *state.State {
 		OS:             d.os,
 		Address:        d.Address,
 		Name:           d.Name,
+		SetName:        d.setName,
 		Endpoints:      d.endpoints,
 		ServerCert:     d.ServerCert,
 		ClusterCert:    d.ClusterCert,
+		SetClusterCert: d.setClusterCert,
+		SecretsKey:     d.SecretsKey,
 		Database:       d.db,
 		Remotes:        d.trustStore.Remotes,
 		StartAPI:       d.StartAPI,
@@ -545,6 +894,39 @@ func (d *Daemon) Stop() error {
 	return d.endpoints.Down()
 }
 
+// setName changes the name of the daemon, persisting it to the daemon configuration.
+func (d *Daemon) setName(name string) error {
+	addrPort, err := types.ParseAddrPort(d.address.URL.Host)
+	if err != nil {
+		return fmt.Errorf("Failed to parse daemon address: %w", err)
+	}
+
+	return d.setDaemonConfig(&trust.Location{Name: name, Address: addrPort})
+}
+
+// checkCryptoPolicy returns an error if the given certificate, or any certificate in the trust store, violates the
+// current crypto policy.
+func (d *Daemon) checkCryptoPolicy(cert *sys.CertInfo) error {
+	publicKey, err := cert.PublicKeyX509()
+	if err != nil {
+		return fmt.Errorf("Failed to parse certificate: %w", err)
+	}
+
+	err = cryptopolicy.CheckCertificate(publicKey)
+	if err != nil {
+		return fmt.Errorf("Certificate %q violates the crypto policy: %w", shared.CertFingerprint(publicKey), err)
+	}
+
+	for name, remote := range d.trustStore.Remotes().RemotesByName() {
+		err = cryptopolicy.CheckCertificate(remote.Certificate.Certificate)
+		if err != nil {
+			return fmt.Errorf("Certificate of trusted cluster member %q violates the crypto policy: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
 // setDaemonConfig sets the daemon's address and name from the given location information. If none is supplied, the file
 // at `state-dir/daemon.yaml` will be read for the information.
 func (d *Daemon) setDaemonConfig(config *trust.Location) error {
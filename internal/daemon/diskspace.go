@@ -0,0 +1,275 @@
+package daemon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"time"
+
+	dqliteClient "github.com/canonical/go-dqlite/client"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/canonical/lxd/shared/units"
+	"golang.org/x/sys/unix"
+
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/db"
+	"github.com/canonical/microcluster/internal/state"
+)
+
+// LowDiskThreshold is the free space in bytes in the database directory below which this cluster member demotes itself
+// from voter to spare. It is promoted back once the free space is 10% above the threshold. A value of 0 disables it.
+var LowDiskThreshold uint64
+
+// voterDrainedKey is the node-local config key recording that this cluster member was demoted from voter because it
+// was low on disk space, so that it is only promoted back if it was a voter before, even across restarts.
+const voterDrainedKey = "internal.voter_drained"
+
+// loopDiskSpace periodically checks the free space in the database directory, and moves the voter role away from this
+// cluster member while it is low on space so that raft does not run out of space mid-write.
+func (d *Daemon) loopDiskSpace() {
+	drained, err := d.voterDrained()
+	if err != nil {
+		logger.Warn("Failed to check whether cluster member was demoted for low disk space", logger.Ctx{"error": err})
+	}
+
+	for {
+		select {
+		case <-d.ShutdownCtx.Done():
+			return
+		case <-time.After(time.Minute):
+		}
+
+		if LowDiskThreshold == 0 || !d.db.IsOpen() {
+			continue
+		}
+
+		free, err := freeSpace(d.os.DatabaseDir)
+		if err != nil {
+			logger.Warn("Failed to check free space in database directory", logger.Ctx{"error": err})
+			continue
+		}
+
+		if !drained && free < LowDiskThreshold {
+			reason := fmt.Sprintf("Free space in database directory is %s, below %s", units.GetByteSizeString(int64(free), 1), units.GetByteSizeString(int64(LowDiskThreshold), 1))
+			demoted, err := d.drainVoter(reason)
+			if err != nil {
+				logger.Warn("Failed to demote cluster member low on disk space", logger.Ctx{"error": err})
+			}
+
+			// Members that were not voters are left alone, and must not be promoted once space recovers.
+			if !demoted {
+				continue
+			}
+
+			err = d.setVoterDrained(true)
+			if err != nil {
+				logger.Warn("Failed to record demotion for low disk space", logger.Ctx{"error": err})
+			}
+
+			drained = true
+		} else if drained && free >= LowDiskThreshold+LowDiskThreshold/10 {
+			reason := fmt.Sprintf("Free space in database directory recovered to %s", units.GetByteSizeString(int64(free), 1))
+			err = d.restoreVoter(reason)
+			if err != nil {
+				logger.Warn("Failed to promote cluster member after disk space recovered", logger.Ctx{"error": err})
+				continue
+			}
+
+			err = d.setVoterDrained(false)
+			if err != nil {
+				logger.Warn("Failed to clear demotion for low disk space", logger.Ctx{"error": err})
+			}
+
+			drained = false
+		}
+	}
+}
+
+// freeSpace returns the space in bytes available to unprivileged users in the filesystem containing the given path.
+func freeSpace(path string) (uint64, error) {
+	stat := unix.Statfs_t{}
+	err := unix.Statfs(path, &stat)
+	if err != nil {
+		return 0, err
+	}
+
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
+
+// voterDrained returns whether this cluster member was demoted from voter for low disk space, and not yet promoted.
+func (d *Daemon) voterDrained() (bool, error) {
+	var config map[string]string
+	err := d.db.LocalTransaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		config, err = db.GetLocalConfig(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return config[voterDrainedKey] == "true", nil
+}
+
+// setVoterDrained records whether this cluster member is demoted from voter for low disk space.
+func (d *Daemon) setVoterDrained(drained bool) error {
+	value := ""
+	if drained {
+		value = "true"
+	}
+
+	return d.db.LocalTransaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+		return db.UpdateLocalConfig(tx, map[string]string{voterDrainedKey: value})
+	})
+}
+
+// drainVoter demotes this cluster member to spare, first transferring leadership away if it is the leader. Its dqlite
+// weight is raised so that dqlite does not pick it when promoting other nodes to voter. Returns whether the member was
+// demoted, which it is not if it was not a voter, even if an error is returned.
+func (d *Daemon) drainVoter(reason string) (bool, error) {
+	ctx, cancel := context.WithTimeout(d.ShutdownCtx, 30*time.Second)
+	defer cancel()
+
+	leader, err := d.db.Leader(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	// The leader may change below, so close whichever client is current.
+	defer func() { _ = leader.Close() }()
+
+	info, err := d.db.Cluster(ctx, leader)
+	if err != nil {
+		return false, err
+	}
+
+	var local *dqliteClient.NodeInfo
+	voters := 0
+	for i, node := range info {
+		if node.Role == dqliteClient.Voter {
+			voters++
+		}
+
+		if node.Address == d.address.URL.Host {
+			local = &info[i]
+		}
+	}
+
+	if local == nil || local.Role != dqliteClient.Voter {
+		return false, nil
+	}
+
+	if voters <= 1 {
+		return false, fmt.Errorf("Cannot demote cluster member %q as it is the only voter", d.Name())
+	}
+
+	err = d.db.SetWeight(ctx, math.MaxUint64)
+	if err != nil {
+		return false, err
+	}
+
+	leaderInfo, err := leader.Leader(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if leaderInfo.ID == local.ID {
+		err = leader.Transfer(ctx, 0)
+		if err != nil {
+			return false, fmt.Errorf("Failed to transfer dqlite leadership: %w", err)
+		}
+
+		_ = leader.Close()
+		leader, err = d.db.Leader(ctx)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	err = leader.Assign(ctx, local.ID, dqliteClient.Spare)
+	if err != nil {
+		return false, fmt.Errorf("Failed to demote cluster member %q: %w", d.Name(), err)
+	}
+
+	logger.Warn("Demoted cluster member low on disk space to spare", logger.Ctx{"name": d.Name(), "reason": reason})
+
+	err = d.setRole(dqliteClient.Spare)
+	if err != nil {
+		return true, err
+	}
+
+	err = state.OnMemberDemotedHook(d.State(), d.Name(), reason)
+	if err != nil {
+		return true, fmt.Errorf("Failed to run member demotion hook: %w", err)
+	}
+
+	return true, nil
+}
+
+// restoreVoter resets the dqlite weight of this cluster member, and promotes it back to voter.
+func (d *Daemon) restoreVoter(reason string) error {
+	ctx, cancel := context.WithTimeout(d.ShutdownCtx, 30*time.Second)
+	defer cancel()
+
+	err := d.db.SetWeight(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	leader, err := d.db.Leader(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer leader.Close()
+
+	info, err := d.db.Cluster(ctx, leader)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range info {
+		if node.Address != d.address.URL.Host {
+			continue
+		}
+
+		if node.Role == dqliteClient.Voter {
+			return nil
+		}
+
+		err = leader.Assign(ctx, node.ID, dqliteClient.Voter)
+		if err != nil {
+			return fmt.Errorf("Failed to promote cluster member %q: %w", d.Name(), err)
+		}
+
+		logger.Info("Promoted cluster member to voter", logger.Ctx{"name": d.Name(), "reason": reason})
+
+		err = d.setRole(dqliteClient.Voter)
+		if err != nil {
+			return err
+		}
+
+		err = state.OnMemberPromotedHook(d.State(), d.Name(), reason)
+		if err != nil {
+			return fmt.Errorf("Failed to run member promotion hook: %w", err)
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
+// setRole records the given dqlite role of this cluster member in the database.
+func (d *Daemon) setRole(role dqliteClient.NodeRole) error {
+	return d.db.Transaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+		clusterMember, err := cluster.GetInternalClusterMember(ctx, tx, d.Name())
+		if err != nil {
+			return err
+		}
+
+		clusterMember.Role = cluster.Role(role.String())
+		return cluster.UpdateInternalClusterMember(ctx, tx, clusterMember.Name, *clusterMember)
+	})
+}
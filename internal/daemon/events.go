@@ -0,0 +1,45 @@
+package daemon
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/cluster"
+)
+
+// EventRetention is how long cluster membership events are kept in the event history.
+// A value of 0 or less keeps events indefinitely.
+var EventRetention = 30 * 24 * time.Hour
+
+// loopPruneEvents periodically removes events older than EventRetention from the event history.
+func (d *Daemon) loopPruneEvents() {
+	for {
+		select {
+		case <-d.ShutdownCtx.Done():
+			return
+		case <-time.After(time.Hour):
+		}
+
+		if EventRetention <= 0 || !d.db.IsOpen() {
+			continue
+		}
+
+		var pruned int64
+		err := d.db.Transaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+			var err error
+			pruned, err = cluster.DeleteInternalEventsBefore(ctx, tx, time.Now().UTC().Add(-EventRetention))
+			return err
+		})
+		if err != nil {
+			logger.Warn("Failed to prune event history", logger.Ctx{"error": err})
+			continue
+		}
+
+		if pruned > 0 {
+			logger.Debug("Pruned event history", logger.Ctx{"count": pruned, "retention": EventRetention})
+		}
+	}
+}
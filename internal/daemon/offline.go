@@ -0,0 +1,128 @@
+package daemon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	dqliteClient "github.com/canonical/go-dqlite/client"
+	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/state"
+)
+
+// OfflineDemotionThreshold is the time since the last heartbeat after which the leader demotes a voter to spare.
+// A value of 0 or less disables automatic demotion.
+var OfflineDemotionThreshold time.Duration
+
+// loopDemoteOffline periodically demotes voters whose last heartbeat is older than OfflineDemotionThreshold, so that
+// dead cluster members do not silently degrade quorum.
+func (d *Daemon) loopDemoteOffline() {
+	for {
+		select {
+		case <-d.ShutdownCtx.Done():
+			return
+		case <-time.After(time.Minute):
+		}
+
+		if OfflineDemotionThreshold <= 0 || !d.db.IsOpen() {
+			continue
+		}
+
+		leader, err := d.isLeader()
+		if err != nil {
+			logger.Warn("Failed to determine dqlite leader for offline member demotion", logger.Ctx{"error": err})
+			continue
+		}
+
+		if !leader {
+			continue
+		}
+
+		err = d.demoteOffline()
+		if err != nil {
+			logger.Warn("Failed to demote offline cluster members", logger.Ctx{"error": err})
+		}
+	}
+}
+
+// demoteOffline demotes all voters whose last heartbeat is older than OfflineDemotionThreshold to spare, keeping at
+// least one voter.
+func (d *Daemon) demoteOffline() error {
+	var clusterMembers []cluster.InternalClusterMember
+	err := d.db.Transaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		clusterMembers, err = cluster.GetInternalClusterMembers(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(d.ShutdownCtx, 30*time.Second)
+	defer cancel()
+
+	leader, err := d.db.Leader(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer leader.Close()
+
+	info, err := d.db.Cluster(ctx, leader)
+	if err != nil {
+		return err
+	}
+
+	nodes := make(map[string]dqliteClient.NodeInfo, len(info))
+	voters := 0
+	for _, node := range info {
+		nodes[node.Address] = node
+		if node.Role == dqliteClient.Voter {
+			voters++
+		}
+	}
+
+	for _, clusterMember := range clusterMembers {
+		node, ok := nodes[clusterMember.Address]
+		if !ok || node.Role != dqliteClient.Voter || clusterMember.Heartbeat.IsZero() {
+			continue
+		}
+
+		elapsed := time.Since(clusterMember.Heartbeat)
+		if elapsed < OfflineDemotionThreshold || clusterMember.Address == d.address.URL.Host {
+			continue
+		}
+
+		if voters <= 1 {
+			return fmt.Errorf("Cannot demote offline cluster member %q as it is the only voter", clusterMember.Name)
+		}
+
+		err = leader.Assign(ctx, node.ID, dqliteClient.Spare)
+		if err != nil {
+			return fmt.Errorf("Failed to demote offline cluster member %q: %w", clusterMember.Name, err)
+		}
+
+		voters--
+
+		reason := fmt.Sprintf("No heartbeat for %s", elapsed.Truncate(time.Second))
+		logger.Warn("Demoted offline cluster member to spare", logger.Ctx{"name": clusterMember.Name, "reason": reason})
+
+		err = d.db.Transaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+			clusterMember.Role = cluster.Role(dqliteClient.Spare.String())
+			return cluster.UpdateInternalClusterMember(ctx, tx, clusterMember.Name, clusterMember)
+		})
+		if err != nil {
+			return err
+		}
+
+		err = state.OnMemberDemotedHook(d.State(), clusterMember.Name, reason)
+		if err != nil {
+			return fmt.Errorf("Failed to run member demotion hook: %w", err)
+		}
+	}
+
+	return nil
+}
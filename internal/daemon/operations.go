@@ -0,0 +1,45 @@
+package daemon
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/cluster"
+)
+
+// OperationRetention is how long completed operations are kept in the operation history.
+// A value of 0 or less keeps operations indefinitely.
+var OperationRetention = 7 * 24 * time.Hour
+
+// loopPruneOperations periodically removes operations older than OperationRetention from the operation history.
+func (d *Daemon) loopPruneOperations() {
+	for {
+		select {
+		case <-d.ShutdownCtx.Done():
+			return
+		case <-time.After(time.Hour):
+		}
+
+		if OperationRetention <= 0 || !d.db.IsOpen() {
+			continue
+		}
+
+		var pruned int64
+		err := d.db.Transaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+			var err error
+			pruned, err = cluster.DeleteInternalOperationsBefore(ctx, tx, time.Now().Add(-OperationRetention))
+			return err
+		})
+		if err != nil {
+			logger.Warn("Failed to prune operation history", logger.Ctx{"error": err})
+			continue
+		}
+
+		if pruned > 0 {
+			logger.Debug("Pruned operation history", logger.Ctx{"count": pruned, "retention": OperationRetention})
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package daemon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/config"
+	"github.com/canonical/microcluster/internal/db"
+)
+
+// applyPatches runs any patches for the given stage that have not yet been applied on this cluster member, and
+// records them in the local database.
+func (d *Daemon) applyPatches(stage config.PatchStage) error {
+	var applied []string
+	err := d.db.LocalTransaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		applied, err = db.GetAppliedPatches(ctx, tx)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to get applied patches: %w", err)
+	}
+
+	for _, patch := range d.patches {
+		if patch.Stage != stage || shared.ValueInSlice(patch.Name, applied) {
+			continue
+		}
+
+		logger.Info("Applying patch", logger.Ctx{"name": patch.Name})
+		err := patch.Run(d.State())
+		if err != nil {
+			return fmt.Errorf("Failed to apply patch %q: %w", patch.Name, err)
+		}
+
+		err = d.db.LocalTransaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+			return db.MarkPatchApplied(ctx, tx, patch.Name)
+		})
+		if err != nil {
+			return fmt.Errorf("Failed to mark patch %q as applied: %w", patch.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// markPatchesApplied records every patch as applied on this cluster member without running it. Patches only fix up
+// existing state, so a freshly bootstrapped or joined cluster member has nothing to apply them to.
+func (d *Daemon) markPatchesApplied() error {
+	return d.db.LocalTransaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+		applied, err := db.GetAppliedPatches(ctx, tx)
+		if err != nil {
+			return fmt.Errorf("Failed to get applied patches: %w", err)
+		}
+
+		for _, patch := range d.patches {
+			if shared.ValueInSlice(patch.Name, applied) {
+				continue
+			}
+
+			err := db.MarkPatchApplied(ctx, tx, patch.Name)
+			if err != nil {
+				return fmt.Errorf("Failed to mark patch %q as applied: %w", patch.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
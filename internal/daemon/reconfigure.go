@@ -0,0 +1,312 @@
+package daemon
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dqlite "github.com/canonical/go-dqlite"
+	dqliteClient "github.com/canonical/go-dqlite/client"
+	"github.com/canonical/lxd/shared"
+	"github.com/google/renameio"
+	"gopkg.in/yaml.v2"
+
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/sys"
+	"github.com/canonical/microcluster/internal/trust"
+	"github.com/canonical/microcluster/rest/types"
+)
+
+// ReconfigureAddresses changes the addresses of the given cluster members, keyed by name, in the local state of a
+// stopped cluster member. It must be run with the same addresses on every cluster member while all of them are
+// stopped. The trust store, dqlite node store and raft configuration are updated immediately, while the
+// `internal_cluster_members` table is updated the next time the daemon starts, at which point it will also listen on
+// its new address.
+func ReconfigureAddresses(filesystem *sys.OS, addresses map[string]types.AddrPort) error {
+	if len(addresses) == 0 {
+		return fmt.Errorf("No cluster member addresses given")
+	}
+
+	remotes := &trust.Remotes{}
+	err := remotes.Load(filesystem.TrustDir)
+	if err != nil {
+		return fmt.Errorf("Failed to load trust store: %w", err)
+	}
+
+	remotesByName := remotes.RemotesByName()
+	oldAddresses := make(map[string]string, len(addresses))
+	for name, address := range addresses {
+		remote, ok := remotesByName[name]
+		if !ok {
+			return fmt.Errorf("No cluster member found with name %q", name)
+		}
+
+		for otherName, otherRemote := range remotesByName {
+			_, renumbered := addresses[otherName]
+			if otherName != name && !renumbered && otherRemote.Address.String() == address.String() {
+				return fmt.Errorf("Address %q is already in use by cluster member %q", address.String(), otherName)
+			}
+		}
+
+		oldAddresses[remote.Address.String()] = address.String()
+	}
+
+	members := make([]internalTypes.ClusterMember, 0, len(remotesByName))
+	for name, remote := range remotesByName {
+		address, ok := addresses[name]
+		if !ok {
+			address = remote.Address
+		}
+
+		members = append(members, internalTypes.ClusterMember{
+			ClusterMemberLocal: internalTypes.ClusterMemberLocal{
+				Name:        name,
+				Address:     address,
+				Certificate: remote.Certificate,
+			},
+		})
+	}
+
+	err = reconfigureDqlite(filesystem.DatabaseDir, oldAddresses)
+	if err != nil {
+		return err
+	}
+
+	err = reconfigureDaemonConfig(filesystem.StateDir, addresses)
+	if err != nil {
+		return err
+	}
+
+	err = writeAddressPatch(filesystem.DatabasePatchPath(), addresses)
+	if err != nil {
+		return err
+	}
+
+	err = remotes.Replace(filesystem.TrustDir, members...)
+	if err != nil {
+		return fmt.Errorf("Failed to update trust store: %w", err)
+	}
+
+	return nil
+}
+
+// reconfigureDqlite rewrites the dqlite node store, node information, and raft configuration in the given directory
+// with the new addresses, keyed by old address.
+func reconfigureDqlite(dir string, addresses map[string]string) error {
+	store, err := dqliteClient.NewYamlNodeStore(filepath.Join(dir, "cluster.yaml"))
+	if err != nil {
+		return fmt.Errorf("Failed to open dqlite node store: %w", err)
+	}
+
+	nodes, err := store.Get(context.Background())
+	if err != nil {
+		return fmt.Errorf("Failed to get dqlite nodes: %w", err)
+	}
+
+	for i, node := range nodes {
+		newAddress, ok := addresses[node.Address]
+		if ok {
+			nodes[i].Address = newAddress
+		}
+	}
+
+	infoPath := filepath.Join(dir, "info.yaml")
+	data, err := os.ReadFile(infoPath)
+	if err != nil {
+		return fmt.Errorf("Failed to read dqlite node information: %w", err)
+	}
+
+	info := dqliteClient.NodeInfo{}
+	err = yaml.Unmarshal(data, &info)
+	if err != nil {
+		return fmt.Errorf("Failed to parse dqlite node information: %w", err)
+	}
+
+	newAddress, ok := addresses[info.Address]
+	if ok {
+		info.Address = newAddress
+		data, err := yaml.Marshal(info)
+		if err != nil {
+			return fmt.Errorf("Failed to encode dqlite node information: %w", err)
+		}
+
+		err = renameio.WriteFile(infoPath, data, 0600)
+		if err != nil {
+			return fmt.Errorf("Failed to write dqlite node information: %w", err)
+		}
+	}
+
+	err = dqlite.ReconfigureMembershipExt(dir, nodes)
+	if err != nil {
+		return fmt.Errorf("Failed to reconfigure dqlite membership: %w", err)
+	}
+
+	err = store.Set(context.Background(), nodes)
+	if err != nil {
+		return fmt.Errorf("Failed to update dqlite node store: %w", err)
+	}
+
+	return nil
+}
+
+// reconfigureDaemonConfig updates the address in `state-dir/daemon.yaml` if the local cluster member is renumbered.
+func reconfigureDaemonConfig(stateDir string, addresses map[string]types.AddrPort) error {
+	path := filepath.Join(stateDir, "daemon.yaml")
+	if !shared.PathExists(path) {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Failed to find daemon configuration: %w", err)
+	}
+
+	config := trust.Location{}
+	err = yaml.Unmarshal(data, &config)
+	if err != nil {
+		return fmt.Errorf("Failed to parse daemon config from yaml: %w", err)
+	}
+
+	address, ok := addresses[config.Name]
+	if !ok {
+		return nil
+	}
+
+	config.Address = address
+	data, err = yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("Failed to parse daemon config to yaml: %w", err)
+	}
+
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to write daemon configuration yaml: %w", err)
+	}
+
+	return nil
+}
+
+// writeAddressPatch writes the statements updating the addresses in the `internal_cluster_members` table to the given
+// path, to be run against the global database on the next start.
+func writeAddressPatch(path string, addresses map[string]types.AddrPort) error {
+	var stmts strings.Builder
+	for name, address := range addresses {
+		fmt.Fprintf(&stmts, "UPDATE internal_cluster_members SET address = %s WHERE name = %s;\n", quoteSQL(address.String()), quoteSQL(name))
+	}
+
+	return appendPatch(path, stmts.String())
+}
+
+// appendPatch appends the given statements to the database patch file at the given path.
+func appendPatch(path string, stmts string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to open database patch file: %w", err)
+	}
+
+	defer file.Close()
+
+	_, err = file.WriteString(stmts)
+	if err != nil {
+		return fmt.Errorf("Failed to write database patch file: %w", err)
+	}
+
+	return nil
+}
+
+// quoteSQL returns the given string as an SQL string literal.
+func quoteSQL(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// EnableClustering moves a stopped single-member cluster, typically bootstrapped on a loopback address, to the given
+// network address so that other cluster members can join it. The server and cluster certificates are regenerated to
+// cover the current host names and addresses, and join tokens issued for the old cluster certificate are revoked. The
+// `internal_cluster_members` table is updated the next time the daemon starts, at which point it will listen on the new
+// address and accept joins.
+func EnableClustering(filesystem *sys.OS, address types.AddrPort) error {
+	remotes := &trust.Remotes{}
+	err := remotes.Load(filesystem.TrustDir)
+	if err != nil {
+		return fmt.Errorf("Failed to load trust store: %w", err)
+	}
+
+	remotesByName := remotes.RemotesByName()
+	if len(remotesByName) != 1 {
+		return fmt.Errorf("Clustering can only be enabled on a cluster with a single member, found %d", len(remotesByName))
+	}
+
+	var local trust.Remote
+	for _, remote := range remotesByName {
+		local = remote
+	}
+
+	err = reconfigureDqlite(filesystem.DatabaseDir, map[string]string{local.Address.String(): address.String()})
+	if err != nil {
+		return err
+	}
+
+	err = reconfigureDaemonConfig(filesystem.StateDir, map[string]types.AddrPort{local.Name: address})
+	if err != nil {
+		return err
+	}
+
+	serverCert, err := regenerateCert(filesystem, "server")
+	if err != nil {
+		return err
+	}
+
+	_, err = regenerateCert(filesystem, "cluster")
+	if err != nil {
+		return err
+	}
+
+	certificate := types.X509Certificate{Certificate: serverCert}
+	stmts := fmt.Sprintf("UPDATE internal_cluster_members SET address = %s, certificate = %s WHERE name = %s;\nDELETE FROM internal_token_records;\n", quoteSQL(address.String()), quoteSQL(certificate.String()), quoteSQL(local.Name))
+	err = appendPatch(filesystem.DatabasePatchPath(), stmts)
+	if err != nil {
+		return err
+	}
+
+	err = remotes.Replace(filesystem.TrustDir, internalTypes.ClusterMember{
+		ClusterMemberLocal: internalTypes.ClusterMemberLocal{
+			Name:        local.Name,
+			Address:     address,
+			Certificate: certificate,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to update trust store: %w", err)
+	}
+
+	return nil
+}
+
+// regenerateCert replaces the keypair with the given prefix in the state directory with a new one covering the current
+// host names and addresses, and returns the new certificate.
+func regenerateCert(filesystem *sys.OS, prefix string) (*x509.Certificate, error) {
+	certPEM, keyPEM, err := shared.GenerateMemCert(false, true)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to regenerate %q certificate: %w", prefix, err)
+	}
+
+	err = filesystem.WriteKeyPair(prefix, certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := sys.NewCertInfo(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load %q certificate: %w", prefix, err)
+	}
+
+	publicKey, err := cert.PublicKeyX509()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse %q certificate: %w", prefix, err)
+	}
+
+	return publicKey, nil
+}
@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared/logger"
+	"gopkg.in/yaml.v2"
+
+	internalClient "github.com/canonical/microcluster/internal/rest/client"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+)
+
+// recoverInterruptedJoin checks for a join left unfinished by a crash. If one is found, the partial dqlite state and
+// daemon configuration written by that join are removed so that the daemon starts uninitialized, and the join request
+// is returned so that it can be retried. The node-local database is kept.
+func (d *Daemon) recoverInterruptedJoin() (*internalTypes.Control, error) {
+	data, err := os.ReadFile(d.os.JoinStatePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Failed to read join request record: %w", err)
+	}
+
+	req := &internalTypes.Control{}
+	err = yaml.Unmarshal(data, req)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse join request record: %w", err)
+	}
+
+	logger.Warn("Found a cluster join that did not complete, resetting partial state to retry it", logger.Ctx{"name": req.Name, "address": req.Address.String()})
+
+	entries, err := os.ReadDir(d.os.DatabaseDir)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read database directory: %w", err)
+	}
+
+	localDB := filepath.Base(d.os.LocalDatabasePath())
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), localDB) {
+			continue
+		}
+
+		err = os.RemoveAll(filepath.Join(d.os.DatabaseDir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("Failed to remove partial database state %q: %w", entry.Name(), err)
+		}
+	}
+
+	err = os.Remove(filepath.Join(d.os.StateDir, "daemon.yaml"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("Failed to remove partial daemon configuration: %w", err)
+	}
+
+	return req, nil
+}
+
+// retryJoin resubmits the given join request over the control socket until it succeeds, or gives up after 10 minutes.
+func (d *Daemon) retryJoin(req internalTypes.Control) {
+	deadline := time.Now().Add(10 * time.Minute)
+	for {
+		c, err := internalClient.New(d.os.ControlSocket(), nil, nil, false)
+		if err == nil {
+			err = c.ControlDaemon(d.ShutdownCtx, req, time.Minute)
+			if err == nil {
+				logger.Info("Retried cluster join succeeded", logger.Ctx{"name": req.Name})
+				return
+			}
+		}
+
+		if time.Now().After(deadline) {
+			logger.Error("Giving up on retrying cluster join", logger.Ctx{"name": req.Name, "error": err})
+			return
+		}
+
+		logger.Warn("Failed to retry cluster join", logger.Ctx{"name": req.Name, "error": err})
+
+		select {
+		case <-d.ShutdownCtx.Done():
+			return
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
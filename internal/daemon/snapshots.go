@@ -0,0 +1,94 @@
+package daemon
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/cluster"
+)
+
+// SnapshotInterval is how often the leader records a snapshot of the cluster membership and configuration.
+// A value of 0 or less disables snapshots.
+var SnapshotInterval = time.Hour
+
+// SnapshotRetention is how long snapshots are kept in the snapshot history.
+// A value of 0 or less keeps snapshots indefinitely.
+var SnapshotRetention = 30 * 24 * time.Hour
+
+// loopSnapshots periodically records a snapshot of the cluster membership and configuration on the leader, and
+// removes snapshots older than SnapshotRetention.
+func (d *Daemon) loopSnapshots() {
+	for {
+		interval := SnapshotInterval
+		if interval <= 0 {
+			interval = time.Hour
+		}
+
+		select {
+		case <-d.ShutdownCtx.Done():
+			return
+		case <-time.After(interval):
+		}
+
+		if SnapshotInterval <= 0 || !d.db.IsOpen() {
+			continue
+		}
+
+		leader, err := d.isLeader()
+		if err != nil {
+			logger.Warn("Failed to determine dqlite leader for snapshot", logger.Ctx{"error": err})
+			continue
+		}
+
+		if !leader {
+			continue
+		}
+
+		err = d.db.Transaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+			snapshot, err := cluster.TakeSnapshot(ctx, tx)
+			if err != nil {
+				return err
+			}
+
+			_, err = cluster.CreateInternalSnapshot(ctx, tx, cluster.InternalSnapshot{TakenAt: snapshot.TakenAt, Data: cluster.InternalSnapshotData(*snapshot)})
+			if err != nil {
+				return err
+			}
+
+			if SnapshotRetention > 0 {
+				_, err = cluster.DeleteInternalSnapshotsBefore(ctx, tx, time.Now().Add(-SnapshotRetention))
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Warn("Failed to record snapshot", logger.Ctx{"error": err})
+		}
+	}
+}
+
+// isLeader returns whether this cluster member is the dqlite leader.
+func (d *Daemon) isLeader() (bool, error) {
+	ctx, cancel := context.WithTimeout(d.ShutdownCtx, 30*time.Second)
+	defer cancel()
+
+	leaderClient, err := d.db.Leader(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	defer leaderClient.Close()
+
+	leaderInfo, err := leaderClient.Leader(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return leaderInfo.Address == d.address.URL.Host, nil
+}
@@ -0,0 +1,70 @@
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+
+	internalClient "github.com/canonical/microcluster/internal/rest/client"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+)
+
+// pullTrustStore replaces the local trust store with the trust store of the first other cluster member that responds,
+// so that cluster members changed while this member was offline can be found when re-establishing the cluster
+// connection. If no cluster member responds, the local trust store is kept.
+func (d *Daemon) pullTrustStore() {
+	publicKey, err := d.ClusterCert().PublicKeyX509()
+	if err != nil {
+		logger.Warn("Failed to parse cluster certificate", logger.Ctx{"error": err})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(d.ShutdownCtx, 10*time.Second)
+	defer cancel()
+
+	for name, addr := range d.trustStore.Remotes().Addresses() {
+		if d.address.URL.Host == addr.String() {
+			continue
+		}
+
+		url := api.NewURL().Scheme("https").Host(addr.String())
+		c, err := internalClient.New(*url, d.ServerCert(), publicKey, false)
+		if err != nil {
+			continue
+		}
+
+		members, err := c.GetMemberTrustStore(ctx)
+		if err != nil || len(members) == 0 {
+			logger.Debug("Failed to pull trust store from cluster member", logger.Ctx{"name": name, "error": err})
+			continue
+		}
+
+		// Only accept a trust store that still includes this cluster member.
+		found := false
+		clusterMembers := make([]internalTypes.ClusterMember, 0, len(members))
+		for _, member := range members {
+			found = found || member.Address.String() == d.address.URL.Host
+			clusterMembers = append(clusterMembers, internalTypes.ClusterMember{ClusterMemberLocal: member})
+		}
+
+		if !found {
+			logger.Warn("Ignoring trust store without this cluster member", logger.Ctx{"name": name})
+			continue
+		}
+
+		if d.trustStore.Remotes().Matches(clusterMembers...) {
+			return
+		}
+
+		logger.Info("Updating trust store from cluster member", logger.Ctx{"name": name, "members": len(members)})
+
+		err = d.trustStore.Remotes().Replace(d.os.TrustDir, clusterMembers...)
+		if err != nil {
+			logger.Warn("Failed to update trust store", logger.Ctx{"error": err})
+		}
+
+		return
+	}
+}
@@ -0,0 +1,78 @@
+package daemon
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/resources"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+)
+
+// loopCertificateWarnings periodically records an event on the leader for every certificate that has expired or
+// expires within resources.CertificateExpiryWarning. An event is recorded at most once per certificate within that
+// period.
+func (d *Daemon) loopCertificateWarnings() {
+	for {
+		select {
+		case <-d.ShutdownCtx.Done():
+			return
+		case <-time.After(time.Hour):
+		}
+
+		if !d.db.IsOpen() {
+			continue
+		}
+
+		leader, err := d.isLeader()
+		if err != nil {
+			logger.Warn("Failed to determine dqlite leader for certificate expiry check", logger.Ctx{"error": err})
+			continue
+		}
+
+		if !leader {
+			continue
+		}
+
+		warnings, err := resources.CertificateWarnings(d.State())
+		if err != nil {
+			logger.Warn("Failed to check certificate expiry", logger.Ctx{"error": err})
+			continue
+		}
+
+		err = d.db.Transaction(d.ShutdownCtx, func(ctx context.Context, tx *sql.Tx) error {
+			since := time.Now().UTC().Add(-resources.CertificateExpiryWarning)
+			for _, warning := range warnings {
+				eventType := cluster.EventCertificateExpiring
+				if warning.Type == internalTypes.WarningCertificateExpired {
+					eventType = cluster.EventCertificateExpired
+				}
+
+				entity := warning.Entity
+				events, err := cluster.GetInternalEventsAfter(ctx, tx, cluster.InternalEventFilter{Type: &eventType, Member: &entity}, 0, since)
+				if err != nil {
+					return err
+				}
+
+				if len(events) > 0 {
+					continue
+				}
+
+				logger.Warn("Certificate is expiring", logger.Ctx{"entity": warning.Entity, "expires_at": warning.ExpiresAt})
+
+				_, err = cluster.CreateInternalEvent(ctx, tx, cluster.NewInternalEvent(eventType, warning.Entity, warning.Message))
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			logger.Warn("Failed to record certificate expiry events", logger.Ctx{"error": err})
+		}
+	}
+}
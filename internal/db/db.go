@@ -34,22 +34,40 @@ func (db *DB) Open(bootstrap bool, project string) error {
 		return err
 	}
 
+	if SlowQueryThreshold > 0 {
+		db.db, err = traceSlowQueries(db.db, db.dbName)
+		if err != nil {
+			return fmt.Errorf("Failed to enable slow query tracing: %w", err)
+		}
+	}
+
 	otherNodesBehind := false
 	newSchema := db.Schema()
 	if !bootstrap {
 		checkVersions := func(ctx context.Context, current int, tx *sql.Tx) error {
 			schemaVersion := newSchema.Version()
+			if db.upgraded == nil {
+				name, version, err := cluster.GetClusterMemberSchemaVersion(ctx, tx, db.listenAddr.URL.Host)
+				if err != nil {
+					return fmt.Errorf("Failed to get schema version of this cluster member: %w", err)
+				}
+
+				if version < schemaVersion {
+					db.upgraded = &SchemaUpgradeWait{Member: name, From: version, To: schemaVersion}
+				}
+			}
+
 			err = cluster.UpdateClusterMemberSchemaVersion(tx, schemaVersion, db.listenAddr.URL.Host)
 			if err != nil {
 				return fmt.Errorf("Failed to update schema version when joining cluster: %w", err)
 			}
 
-			versions, err := cluster.GetClusterMemberSchemaVersions(ctx, tx)
+			versions, err := cluster.GetClusterMemberSchemaVersionsByName(ctx, tx)
 			if err != nil {
 				return fmt.Errorf("Failed to get other members' schema versions: %w", err)
 			}
 
-			for _, version := range versions {
+			for name, version := range versions {
 				if schemaVersion == version {
 					// Versions are equal, there's hope for the
 					// update. Let's check the next node.
@@ -61,6 +79,8 @@ func (db *DB) Open(bootstrap bool, project string) error {
 					// and wait for other nodes to be upgraded and
 					// restarted.
 					otherNodesBehind = true
+					db.setUpgradeWait(&SchemaUpgradeWait{Member: name, From: version, To: schemaVersion})
+
 					return schema.ErrGracefulAbort
 				}
 
@@ -100,6 +120,8 @@ func (db *DB) Open(bootstrap bool, project string) error {
 		return err
 	}
 
+	db.setUpgradeWait(nil)
+
 	err = cluster.PrepareStmts(db.db, project, false)
 	if err != nil {
 		return err
@@ -107,9 +129,50 @@ func (db *DB) Open(bootstrap bool, project string) error {
 
 	db.openCanceller.Cancel()
 
+	if db.upgraded != nil {
+		upgraded := *db.upgraded
+		err = db.Transaction(db.ctx, func(ctx context.Context, tx *sql.Tx) error {
+			_, err := cluster.CreateInternalEvent(ctx, tx, cluster.NewInternalEvent(cluster.EventMemberUpgraded, upgraded.Member, fmt.Sprintf("Schema upgraded from version %d to %d", upgraded.From, upgraded.To)))
+			return err
+		})
+		if err != nil {
+			logger.Warn("Failed to record schema upgrade event", logger.Ctx{"member": upgraded.Member, "error": err})
+		}
+
+		db.upgraded = nil
+	}
+
 	return nil
 }
 
+// SchemaUpgradeWait describes a cluster member whose outdated schema version is preventing the database from opening.
+type SchemaUpgradeWait struct {
+	Member string
+	From   int
+	To     int
+}
+
+// String returns a human-readable description of the upgrade wait.
+func (w SchemaUpgradeWait) String() string {
+	return fmt.Sprintf("Waiting for cluster member %q to upgrade, schema %d → %d", w.Member, w.From, w.To)
+}
+
+// UpgradeWait returns the cluster member the database is waiting on to upgrade its schema, or nil if the database
+// is not waiting for an upgrade.
+func (db *DB) UpgradeWait() *SchemaUpgradeWait {
+	db.upgradeMu.RLock()
+	defer db.upgradeMu.RUnlock()
+
+	return db.upgradeWait
+}
+
+func (db *DB) setUpgradeWait(wait *SchemaUpgradeWait) {
+	db.upgradeMu.Lock()
+	defer db.upgradeMu.Unlock()
+
+	db.upgradeWait = wait
+}
+
 // Transaction handles performing a transaction on the dqlite database.
 func (db *DB) Transaction(ctx context.Context, f func(context.Context, *sql.Tx) error) error {
 	return db.retry(func() error {
@@ -126,6 +189,31 @@ func (db *DB) Transaction(ctx context.Context, f func(context.Context, *sql.Tx)
 	})
 }
 
+// TransactionOnce performs a transaction on the dqlite database like Transaction, but runs the given function at most
+// once, for functions with side effects outside of the transaction. Only failures to begin the transaction are retried.
+func (db *DB) TransactionOnce(ctx context.Context, f func(context.Context, *sql.Tx) error) error {
+	var txErr error
+	err := db.retry(func() error {
+		ran := false
+		err := query.Transaction(ctx, db.db, func(ctx context.Context, tx *sql.Tx) error {
+			ran = true
+			return f(ctx, tx)
+		})
+		if ran {
+			// Stop retrying, and return the result of the attempt that ran the function.
+			txErr = err
+			return nil
+		}
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return txErr
+}
+
 func (db *DB) retry(f func() error) error {
 	if db.ctx.Err() != nil {
 		return f()
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -13,6 +14,8 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -20,7 +23,6 @@ import (
 	dqliteClient "github.com/canonical/go-dqlite/client"
 	"github.com/canonical/lxd/lxd/db/schema"
 	"github.com/canonical/lxd/lxd/revert"
-	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/cancel"
 	"github.com/canonical/lxd/shared/logger"
@@ -28,33 +30,64 @@ import (
 
 	"github.com/canonical/microcluster/cluster"
 	"github.com/canonical/microcluster/internal/db/update"
+	"github.com/canonical/microcluster/internal/extensions"
 	"github.com/canonical/microcluster/internal/rest/client"
 	internalClient "github.com/canonical/microcluster/internal/rest/client"
 	internalTypes "github.com/canonical/microcluster/internal/rest/types"
 	"github.com/canonical/microcluster/internal/sys"
+	"github.com/canonical/microcluster/internal/trust"
 	"github.com/canonical/microcluster/rest/types"
 )
 
+// FailureDomain is the failure domain of this cluster member. Dqlite spreads voters across cluster members with
+// different failure domains, so that losing all members in one domain does not lose quorum.
+var FailureDomain uint64
+
+// DqliteSocket is the path of the socket used internally by dqlite. If empty, the DQLITE_SOCKET environment variable
+// is used, and otherwise a random abstract unix socket.
+var DqliteSocket string
+
+// dqliteSocket returns the path of the socket used internally by dqlite.
+func dqliteSocket() string {
+	if DqliteSocket != "" {
+		return DqliteSocket
+	}
+
+	return os.Getenv(sys.DqliteSocket)
+}
+
 // DB holds all information internal to the dqlite database.
 type DB struct {
-	clusterCert *shared.CertInfo // Cluster certificate for dqlite authentication.
-	serverCert  *shared.CertInfo // Server certificate for dqlite authentication.
-	listenAddr  api.URL          // Listen address for this dqlite node.
+	clusterCert *sys.CertInfo // Cluster certificate for dqlite authentication.
+	certMu      sync.RWMutex
+	serverCert  *sys.CertInfo // Server certificate for dqlite authentication.
+	listenAddr  api.URL       // Listen address for this dqlite node.
+
+	remotes       func() *trust.Remotes // Trust store, for the server certificates of other cluster members.
+	pinnedMembers map[string]bool       // Addresses of cluster members that have presented their own certificate.
+	pinnedMu      sync.Mutex
 
 	dbName string // This is db.bin.
 	os     *sys.OS
 
 	db        *sql.DB
+	localDB   *sql.DB // Node-local database, not replicated by dqlite.
 	dqlite    *dqlite.App
 	acceptCh  chan net.Conn
 	upgradeCh chan struct{}
 
+	upgradeMu   sync.RWMutex
+	upgradeWait *SchemaUpgradeWait // Set while waiting for another cluster member to upgrade.
+	upgraded    *SchemaUpgradeWait // Set once this cluster member's schema version is raised, until recorded as an event.
+
 	openCanceller *cancel.Canceller
 
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	heartbeatLock sync.Mutex
+	heartbeatLock        sync.Mutex
+	heartbeatPausedUntil time.Time // Heartbeats are neither sent nor answered until this time.
+	heartbeatPauseMu     sync.RWMutex
 
 	schema *update.SchemaUpdate
 }
@@ -65,7 +98,7 @@ func (db *DB) Accept(conn net.Conn) {
 }
 
 // NewDB creates an empty db struct with no dqlite connection.
-func NewDB(ctx context.Context, serverCert *shared.CertInfo, os *sys.OS) *DB {
+func NewDB(ctx context.Context, serverCert *sys.CertInfo, os *sys.OS) *DB {
 	shutdownCtx, shutdownCancel := context.WithCancel(ctx)
 
 	return &DB{
@@ -84,21 +117,33 @@ func (db *DB) SetSchema(schemaExtensions map[int]schema.Update) {
 	s := update.NewSchema()
 	s.AppendSchema(schemaExtensions)
 	db.schema = s.Schema()
+	db.schema.File(db.os.DatabasePatchPath())
 }
 
 func (db *DB) Schema() *update.SchemaUpdate {
 	return db.schema
 }
 
+// SchemaVersions returns the schema version of the internal tables, and of the tables added by schema extensions.
+func (db *DB) SchemaVersions() (internal int, extended int) {
+	internal = update.InternalVersion()
+	if db.schema == nil {
+		return internal, 0
+	}
+
+	return internal, db.schema.ExtensionVersion()
+}
+
 // Bootstrap dqlite.
-func (db *DB) Bootstrap(project string, addr api.URL, clusterCert *shared.CertInfo, clusterRecord cluster.InternalClusterMember) error {
+func (db *DB) Bootstrap(project string, addr api.URL, clusterCert *sys.CertInfo, clusterRecord cluster.InternalClusterMember) error {
 	var err error
 	db.listenAddr = addr
 	db.clusterCert = clusterCert
 	db.dqlite, err = dqlite.New(db.os.DatabaseDir,
 		dqlite.WithAddress(db.listenAddr.URL.Host),
 		dqlite.WithExternalConn(db.dialFunc(), db.acceptCh),
-		dqlite.WithUnixSocket(os.Getenv(sys.DqliteSocket)))
+		dqlite.WithFailureDomain(FailureDomain),
+		dqlite.WithUnixSocket(dqliteSocket()))
 	if err != nil {
 		return fmt.Errorf("Failed to bootstrap dqlite: %w", err)
 	}
@@ -111,7 +156,6 @@ func (db *DB) Bootstrap(project string, addr api.URL, clusterCert *shared.CertIn
 	err = db.Transaction(db.ctx, func(ctx context.Context, tx *sql.Tx) error {
 
 		_, err := cluster.CreateInternalClusterMember(ctx, tx, clusterRecord)
-
 		return err
 	})
 	if err != nil {
@@ -124,7 +168,7 @@ func (db *DB) Bootstrap(project string, addr api.URL, clusterCert *shared.CertIn
 }
 
 // Join a dqlite cluster with the address of a member.
-func (db *DB) Join(project string, addr api.URL, clusterCert *shared.CertInfo, joinAddresses ...string) error {
+func (db *DB) Join(project string, addr api.URL, clusterCert *sys.CertInfo, joinAddresses ...string) error {
 	for {
 		var err error
 		db.clusterCert = clusterCert
@@ -133,7 +177,8 @@ func (db *DB) Join(project string, addr api.URL, clusterCert *shared.CertInfo, j
 			dqlite.WithCluster(joinAddresses),
 			dqlite.WithAddress(db.listenAddr.URL.Host),
 			dqlite.WithExternalConn(db.dialFunc(), db.acceptCh),
-			dqlite.WithUnixSocket(os.Getenv(sys.DqliteSocket)))
+			dqlite.WithFailureDomain(FailureDomain),
+			dqlite.WithUnixSocket(dqliteSocket()))
 		if err != nil {
 			return fmt.Errorf("Failed to join dqlite cluster %w", err)
 		}
@@ -162,8 +207,21 @@ func (db *DB) Join(project string, addr api.URL, clusterCert *shared.CertInfo, j
 	return nil
 }
 
+// UpdateMemberInfo records the failure domain and the API extensions of this cluster member in the database. It is
+// run on every start once the database is open, so that the recorded extensions follow upgrades of the binary.
+func (db *DB) UpdateMemberInfo(ctx context.Context) error {
+	return db.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		err := cluster.UpdateClusterMemberFailureDomain(ctx, tx, db.listenAddr.URL.Host, FailureDomain)
+		if err != nil {
+			return err
+		}
+
+		return cluster.UpdateClusterMemberAPIExtensions(ctx, tx, db.listenAddr.URL.Host, extensions.Supported())
+	})
+}
+
 // StartWithCluster starts up dqlite and joins the cluster.
-func (db *DB) StartWithCluster(project string, addr api.URL, clusterMembers map[string]types.AddrPort, clusterCert *shared.CertInfo) error {
+func (db *DB) StartWithCluster(project string, addr api.URL, clusterMembers map[string]types.AddrPort, clusterCert *sys.CertInfo) error {
 	allClusterAddrs := []string{}
 	for _, clusterMemberAddrs := range clusterMembers {
 		allClusterAddrs = append(allClusterAddrs, clusterMemberAddrs.String())
@@ -177,6 +235,23 @@ func (db *DB) Leader(ctx context.Context) (*dqliteClient.Client, error) {
 	return db.dqlite.Leader(ctx)
 }
 
+// Dump returns the files making up the current state of the global database, as reported by the dqlite leader.
+func (db *DB) Dump(ctx context.Context) ([]dqliteClient.File, error) {
+	leader, err := db.dqlite.Leader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer leader.Close()
+
+	files, err := leader.Dump(ctx, db.dbName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to dump database: %w", err)
+	}
+
+	return files, nil
+}
+
 // Cluster returns information about dqlite cluster members.
 func (db *DB) Cluster(ctx context.Context, client *dqliteClient.Client) ([]dqliteClient.NodeInfo, error) {
 	members, err := client.Cluster(ctx)
@@ -187,6 +262,64 @@ func (db *DB) Cluster(ctx context.Context, client *dqliteClient.Client) ([]dqlit
 	return members, nil
 }
 
+// TransferLeadership transfers dqlite leadership to a voter whose address is not in the given set of excluded
+// addresses. It does nothing if the current leader is not excluded.
+func (db *DB) TransferLeadership(ctx context.Context, excluded map[string]bool) error {
+	leader, err := db.dqlite.Leader(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer leader.Close()
+
+	leaderInfo, err := leader.Leader(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !excluded[leaderInfo.Address] {
+		return nil
+	}
+
+	nodes, err := leader.Cluster(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to get dqlite cluster information: %w", err)
+	}
+
+	for _, node := range nodes {
+		if node.Role != dqliteClient.Voter || excluded[node.Address] {
+			continue
+		}
+
+		err = leader.Transfer(ctx, node.ID)
+		if err != nil {
+			return fmt.Errorf("Failed to transfer dqlite leadership to %q: %w", node.Address, err)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("No eligible voter to transfer dqlite leadership to")
+}
+
+// SetWeight sets the weight of the local dqlite node. Nodes with a lower weight are preferred when dqlite picks which
+// nodes to promote to voter.
+func (db *DB) SetWeight(ctx context.Context, weight uint64) error {
+	client, err := db.dqlite.Client(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to local dqlite node: %w", err)
+	}
+
+	defer client.Close()
+
+	err = client.Weight(ctx, weight)
+	if err != nil {
+		return fmt.Errorf("Failed to set weight of local dqlite node: %w", err)
+	}
+
+	return nil
+}
+
 // IsOpen returns true only if the DB has been opened and the schema loaded.
 func (db *DB) IsOpen() bool {
 	if db == nil {
@@ -230,6 +363,11 @@ func (db *DB) heartbeat(ctx context.Context) {
 		return
 	}
 
+	if db.HeartbeatsPaused() {
+		logger.Debug("Heartbeats are paused, skipping heartbeat", logger.Ctx{"address": db.listenAddr.String()})
+		return
+	}
+
 	// Use the heartbeat lock to prevent another heartbeat attempt if we are currently initiating one.
 	db.heartbeatLock.Lock()
 	defer db.heartbeatLock.Unlock()
@@ -250,18 +388,155 @@ func (db *DB) heartbeat(ctx context.Context) {
 	return
 }
 
+// PauseHeartbeats stops this cluster member from sending or answering heartbeats for the given duration.
+func (db *DB) PauseHeartbeats(duration time.Duration) {
+	db.heartbeatPauseMu.Lock()
+	defer db.heartbeatPauseMu.Unlock()
+
+	db.heartbeatPausedUntil = time.Now().Add(duration)
+}
+
+// HeartbeatsPaused returns whether heartbeats are currently paused on this cluster member.
+func (db *DB) HeartbeatsPaused() bool {
+	db.heartbeatPauseMu.RLock()
+	defer db.heartbeatPauseMu.RUnlock()
+
+	return time.Now().Before(db.heartbeatPausedUntil)
+}
+
+// SetClusterCert replaces the cluster certificate used to authenticate dqlite connections to other cluster members.
+func (db *DB) SetClusterCert(clusterCert *sys.CertInfo) {
+	db.certMu.Lock()
+	defer db.certMu.Unlock()
+
+	db.clusterCert = clusterCert
+}
+
+// SetServerCert replaces the server certificate presented on dqlite connections to other cluster members.
+func (db *DB) SetServerCert(serverCert *sys.CertInfo) {
+	db.certMu.Lock()
+	defer db.certMu.Unlock()
+
+	db.serverCert = serverCert
+}
+
+// SetRemotes sets the trust store, so that dqlite connections verify the server certificate of the cluster member they
+// reach.
+func (db *DB) SetRemotes(remotes func() *trust.Remotes) {
+	db.remotes = remotes
+}
+
+// memberCert returns the server certificate of the cluster member with the given address from the trust store, or nil
+// if it is not known.
+func (db *DB) memberCert(addr string) *x509.Certificate {
+	if db.remotes == nil {
+		return nil
+	}
+
+	addrPort, err := types.ParseAddrPort(addr)
+	if err != nil {
+		return nil
+	}
+
+	remote := db.remotes().RemoteByAddress(addrPort)
+	if remote == nil {
+		return nil
+	}
+
+	return remote.Certificate.Certificate
+}
+
+// pinnedMembersKey is the node-local config key recording the addresses of the cluster members that have presented
+// their own certificate, so that the cluster certificate is not accepted from them again after a restart.
+const pinnedMembersKey = "internal.pinned_members"
+
+// pinnedMember returns whether the cluster member with the given address has presented its own certificate on a dqlite
+// connection, so that the cluster certificate is no longer accepted from it.
+func (db *DB) pinnedMember(addr string) bool {
+	db.pinnedMu.Lock()
+	defer db.pinnedMu.Unlock()
+
+	return db.pinnedMembers[addr]
+}
+
+// pinMember records that the cluster member with the given address presented its own certificate.
+func (db *DB) pinMember(addr string) {
+	db.pinnedMu.Lock()
+	defer db.pinnedMu.Unlock()
+
+	if db.pinnedMembers == nil {
+		db.pinnedMembers = map[string]bool{}
+	}
+
+	if db.pinnedMembers[addr] {
+		return
+	}
+
+	db.pinnedMembers[addr] = true
+
+	addrs := make([]string, 0, len(db.pinnedMembers))
+	for pinned := range db.pinnedMembers {
+		addrs = append(addrs, pinned)
+	}
+
+	sort.Strings(addrs)
+	err := db.LocalTransaction(db.ctx, func(ctx context.Context, tx *sql.Tx) error {
+		return UpdateLocalConfig(tx, map[string]string{pinnedMembersKey: strings.Join(addrs, ",")})
+	})
+	if err != nil {
+		logger.Warn("Failed to record pinned cluster member", logger.Ctx{"address": addr, "error": err})
+	}
+}
+
+// loadPinnedMembers loads the addresses of the cluster members that have presented their own certificate from the
+// node-local database.
+func (db *DB) loadPinnedMembers() error {
+	var config map[string]string
+	err := db.LocalTransaction(db.ctx, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		config, err = GetLocalConfig(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Failed to load pinned cluster members: %w", err)
+	}
+
+	db.pinnedMu.Lock()
+	defer db.pinnedMu.Unlock()
+
+	db.pinnedMembers = map[string]bool{}
+	for _, addr := range strings.Split(config[pinnedMembersKey], ",") {
+		if addr != "" {
+			db.pinnedMembers[addr] = true
+		}
+	}
+
+	return nil
+}
+
 // dqliteNetworkDial creates a connection to the internal database endpoint.
 func dqliteNetworkDial(ctx context.Context, addr string, db *DB) (net.Conn, error) {
-	peerCert, err := db.clusterCert.PublicKeyX509()
+	db.certMu.RLock()
+	clusterCert := db.clusterCert
+	serverCert := db.serverCert
+	db.certMu.RUnlock()
+
+	peerCert, err := clusterCert.PublicKeyX509()
 	if err != nil {
 		return nil, err
 	}
 
-	config, err := client.TLSClientConfig(db.serverCert, peerCert)
+	config, err := client.TLSClientConfig(serverCert, peerCert)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to parse TLS config: %w", err)
 	}
 
+	// Verify that the connection reaches the expected cluster member, rather than any holder of the cluster key.
+	memberCert := db.memberCert(addr)
+	if memberCert != nil && !internalClient.Insecure {
+		config = internalClient.PinMemberCert(config, memberCert, func() bool { return db.pinnedMember(addr) }, func() { db.pinMember(addr) })
+	}
+
 	// Establish the connection
 	request := &http.Request{
 		Method:     "POST",
@@ -360,5 +635,12 @@ func (db *DB) Stop() error {
 		}
 	}
 
+	if db.localDB != nil {
+		err := db.localDB.Close()
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
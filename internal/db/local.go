@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/canonical/lxd/lxd/db/query"
+	"github.com/canonical/lxd/shared/logger"
+	_ "github.com/mattn/go-sqlite3" // Used by the node-local database.
+
+	"github.com/canonical/microcluster/internal/db/update"
+)
+
+// OpenLocal opens the node-local sqlite database and applies its schema updates.
+// The local database is not replicated, and is available before the cluster has been bootstrapped or joined.
+func (db *DB) OpenLocal() error {
+	// Tune the transaction BEGIN behavior rather than locking the whole database connection.
+	openPath := fmt.Sprintf("%s?_busy_timeout=%d&_txlock=exclusive", db.os.LocalDatabasePath(), 5000)
+
+	localDB, err := sql.Open("sqlite3", openPath)
+	if err != nil {
+		return fmt.Errorf("Failed to open local database: %w", err)
+	}
+
+	initial, err := update.NewLocalSchema().Schema().Ensure(localDB)
+	if err != nil {
+		_ = localDB.Close()
+		return fmt.Errorf("Failed to update local database schema: %w", err)
+	}
+
+	logger.Debug("Opened local database", logger.Ctx{"path": db.os.LocalDatabasePath(), "initialVersion": initial})
+
+	db.localDB = localDB
+
+	return db.loadPinnedMembers()
+}
+
+// LocalTransaction handles performing a transaction on the node-local database.
+func (db *DB) LocalTransaction(ctx context.Context, f func(context.Context, *sql.Tx) error) error {
+	if db.localDB == nil {
+		return fmt.Errorf("Local database is not yet open")
+	}
+
+	return query.Retry(func() error {
+		return query.Transaction(ctx, db.localDB, f)
+	})
+}
+
+// GetLocalConfig returns all key/value pairs from the node-local config table.
+func GetLocalConfig(ctx context.Context, tx *sql.Tx) (map[string]string, error) {
+	return query.SelectConfig(ctx, tx, "config", "")
+}
+
+// UpdateLocalConfig sets the given key/value pairs in the node-local config table. Keys with empty values are removed.
+func UpdateLocalConfig(tx *sql.Tx, values map[string]string) error {
+	return query.UpdateConfig(tx, "config", values)
+}
+
+// GetAppliedPatches returns the names of all patches already applied on this cluster member.
+func GetAppliedPatches(ctx context.Context, tx *sql.Tx) ([]string, error) {
+	return query.SelectStrings(ctx, tx, "SELECT name FROM patches")
+}
+
+// MarkPatchApplied records that the patch with the given name has been applied on this cluster member.
+func MarkPatchApplied(ctx context.Context, tx *sql.Tx, name string) error {
+	_, err := tx.ExecContext(ctx, "INSERT INTO patches (name, applied_at) VALUES (?, ?)", name, time.Now().UTC())
+	return err
+}
+
+// IntegrityCheck runs an integrity check against this cluster member's copy of the global database, and the
+// node-local database. Each result is a list of problems found, or a single "ok" entry if the database is consistent.
+func (db *DB) IntegrityCheck(ctx context.Context) (global []string, local []string, err error) {
+	global, err = db.replicaIntegrityCheck(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to check global database integrity: %w", err)
+	}
+
+	err = db.LocalTransaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		local, err = query.SelectStrings(ctx, tx, "PRAGMA integrity_check")
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to check local database integrity: %w", err)
+	}
+
+	return global, local, nil
+}
+
+// replicaIntegrityCheck runs an integrity check against the copy of the global database held by the local dqlite
+// node, rather than the leader's copy that queries are sent to. The files of the database are dumped from the local
+// node into a temporary directory, and checked there.
+func (db *DB) replicaIntegrityCheck(ctx context.Context) ([]string, error) {
+	client, err := db.dqlite.Client(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to connect to local dqlite node: %w", err)
+	}
+
+	defer client.Close()
+
+	files, err := client.Dump(ctx, db.dbName)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to dump local copy of the database: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "microcluster-integrity-")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create temporary directory: %w", err)
+	}
+
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	for _, file := range files {
+		err = os.WriteFile(filepath.Join(dir, filepath.Base(file.Name)), file.Data, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to write database file %q: %w", file.Name, err)
+		}
+	}
+
+	replica, err := sql.Open("sqlite3", filepath.Join(dir, db.dbName))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open local copy of the database: %w", err)
+	}
+
+	defer func() { _ = replica.Close() }()
+
+	tx, err := replica.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to begin transaction on local copy of the database: %w", err)
+	}
+
+	defer func() { _ = tx.Rollback() }()
+
+	return query.SelectStrings(ctx, tx, "PRAGMA integrity_check")
+}
@@ -0,0 +1,260 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// SlowQueryThreshold is the duration after which a database statement is considered slow and is logged.
+// A value of 0 disables slow query tracing.
+var SlowQueryThreshold time.Duration
+
+// SlowQueryHandler is an optional function called for every slow database statement, in addition to logging it.
+var SlowQueryHandler func(query string, duration time.Duration, caller string)
+
+// traceSlowQueries replaces the given sql.DB with one whose connections log any statement taking longer than
+// SlowQueryThreshold. The original sql.DB is closed.
+func traceSlowQueries(db *sql.DB, name string) (*sql.DB, error) {
+	driverCtx, ok := db.Driver().(driver.DriverContext)
+	if !ok {
+		return nil, fmt.Errorf("Database driver does not support connectors")
+	}
+
+	connector, err := driverCtx.OpenConnector(name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return sql.OpenDB(slowQueryConnector{Connector: connector}), nil
+}
+
+// observeQuery logs the given statement if it took longer than SlowQueryThreshold since start.
+func observeQuery(query string, start time.Time) {
+	duration := time.Since(start)
+	if SlowQueryThreshold <= 0 || duration < SlowQueryThreshold {
+		return
+	}
+
+	caller := queryCaller()
+	logger.Warn("Slow database query", logger.Ctx{"query": query, "duration": duration, "caller": caller})
+
+	if SlowQueryHandler != nil {
+		SlowQueryHandler(query, duration, caller)
+	}
+}
+
+// queryCaller returns the location of the first caller outside of the database packages.
+func queryCaller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "database/sql") &&
+			!strings.HasPrefix(frame.Function, "github.com/canonical/lxd/lxd/db/query") &&
+			!strings.HasPrefix(frame.Function, "github.com/canonical/microcluster/internal/db.") {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+// slowQueryConnector wraps a driver.Connector, tracing all statements on its connections.
+type slowQueryConnector struct {
+	driver.Connector
+}
+
+// Connect implements driver.Connector.
+func (c slowQueryConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &slowQueryConn{Conn: conn}, nil
+}
+
+// slowQueryConn wraps a driver.Conn, tracing all statements.
+type slowQueryConn struct {
+	driver.Conn
+}
+
+// PrepareContext implements driver.ConnPrepareContext.
+func (c *slowQueryConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	prepare, ok := c.Conn.(driver.ConnPrepareContext)
+	if ok {
+		stmt, err = prepare.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &slowQueryStmt{Stmt: stmt, query: query}, nil
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *slowQueryConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	defer observeQuery(query, time.Now())
+
+	return execer.ExecContext(ctx, query, args)
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *slowQueryConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	defer observeQuery(query, time.Now())
+
+	return queryer.QueryContext(ctx, query, args)
+}
+
+// BeginTx implements driver.ConnBeginTx.
+func (c *slowQueryConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	begin, ok := c.Conn.(driver.ConnBeginTx)
+	if ok {
+		return begin.BeginTx(ctx, opts)
+	}
+
+	return c.Conn.Begin() //nolint:staticcheck
+}
+
+// Ping implements driver.Pinger.
+func (c *slowQueryConn) Ping(ctx context.Context) error {
+	pinger, ok := c.Conn.(driver.Pinger)
+	if !ok {
+		return nil
+	}
+
+	return pinger.Ping(ctx)
+}
+
+// ResetSession implements driver.SessionResetter.
+func (c *slowQueryConn) ResetSession(ctx context.Context) error {
+	resetter, ok := c.Conn.(driver.SessionResetter)
+	if !ok {
+		return nil
+	}
+
+	return resetter.ResetSession(ctx)
+}
+
+// IsValid implements driver.Validator.
+func (c *slowQueryConn) IsValid() bool {
+	validator, ok := c.Conn.(driver.Validator)
+	if !ok {
+		return true
+	}
+
+	return validator.IsValid()
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (c *slowQueryConn) CheckNamedValue(value *driver.NamedValue) error {
+	checker, ok := c.Conn.(driver.NamedValueChecker)
+	if !ok {
+		return driver.ErrSkip
+	}
+
+	return checker.CheckNamedValue(value)
+}
+
+// slowQueryStmt wraps a prepared driver.Stmt, tracing its executions.
+type slowQueryStmt struct {
+	driver.Stmt
+	query string
+}
+
+// ExecContext implements driver.StmtExecContext.
+func (s *slowQueryStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	defer observeQuery(s.query, time.Now())
+
+	execer, ok := s.Stmt.(driver.StmtExecContext)
+	if ok {
+		return execer.ExecContext(ctx, args)
+	}
+
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Stmt.Exec(values) //nolint:staticcheck
+}
+
+// QueryContext implements driver.StmtQueryContext.
+func (s *slowQueryStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	defer observeQuery(s.query, time.Now())
+
+	queryer, ok := s.Stmt.(driver.StmtQueryContext)
+	if ok {
+		return queryer.QueryContext(ctx, args)
+	}
+
+	values, err := namedValuesToValues(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Stmt.Query(values) //nolint:staticcheck
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (s *slowQueryStmt) CheckNamedValue(value *driver.NamedValue) error {
+	checker, ok := s.Stmt.(driver.NamedValueChecker)
+	if !ok {
+		return driver.ErrSkip
+	}
+
+	return checker.CheckNamedValue(value)
+}
+
+// ColumnConverter implements driver.ColumnConverter.
+func (s *slowQueryStmt) ColumnConverter(idx int) driver.ValueConverter {
+	converter, ok := s.Stmt.(driver.ColumnConverter) //nolint:staticcheck
+	if !ok {
+		return driver.DefaultParameterConverter
+	}
+
+	return converter.ColumnConverter(idx)
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("Named parameters are not supported by the database driver")
+		}
+
+		values[i] = arg.Value
+	}
+
+	return values, nil
+}
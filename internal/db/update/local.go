@@ -0,0 +1,52 @@
+package update
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/canonical/lxd/lxd/db/schema"
+)
+
+// NewLocalSchema returns a SchemaUpdateManager for the node-local database. These updates are never replicated, and
+// are applied independently of the global database schema.
+func NewLocalSchema() *SchemaUpdateManager {
+	return &SchemaUpdateManager{
+		legacy:     2,
+		extensions: map[int]schema.Update{},
+		updates: map[int]schema.Update{
+			1: localUpdateFromV0,
+			2: localUpdateFromV1,
+		},
+	}
+}
+
+func localUpdateFromV0(ctx context.Context, tx *sql.Tx) error {
+	stmt := fmt.Sprintf(`
+%s
+
+CREATE TABLE config (
+  id      INTEGER  PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
+  key     TEXT     NOT      NULL,
+  value   TEXT     NOT      NULL,
+  UNIQUE  (key)
+);
+`, CreateSchema)
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func localUpdateFromV1(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE patches (
+  id          INTEGER   PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
+  name        TEXT      NOT      NULL,
+  applied_at  DATETIME  NOT      NULL,
+  UNIQUE      (name)
+);
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
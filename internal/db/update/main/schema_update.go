@@ -25,5 +25,5 @@ CREATE TABLE internal_token_records (
   UNIQUE       (token)
 );
 
-INSERT INTO schemas (version, updated_at) VALUES (1, strftime("%s"))
+INSERT INTO schemas (version, type, updated_at) VALUES (1, 0, strftime("%s"))
 `
@@ -14,12 +14,21 @@ import (
 	"github.com/canonical/lxd/shared"
 )
 
+// Update types recorded in the schemas table. Internal updates and the updates appended by the application have
+// separate version counters, so that adding an internal update never renumbers the application's updates.
+const (
+	updateTypeInternal  = 0
+	updateTypeExtension = 1
+)
+
 type SchemaUpdate struct {
-	updates []schema.Update // Ordered series of updates making up the schema
-	hook    schema.Hook     // Optional hook to execute whenever a update gets applied
-	fresh   string          // Optional SQL statement used to create schema from scratch
-	check   schema.Check    // Optional callback invoked before doing any update
-	path    string          // Optional path to a file containing extra queries to run
+	updates    []schema.Update // Ordered series of updates making up the schema
+	extensions []schema.Update // Ordered series of updates appended by the application, versioned separately
+	legacy     int             // Number of internal updates that shared a version counter with the extensions
+	hook       schema.Hook     // Optional hook to execute whenever a update gets applied
+	fresh      string          // Optional SQL statement used to create schema from scratch
+	check      schema.Check    // Optional callback invoked before doing any update
+	path       string          // Optional path to a file containing extra queries to run
 }
 
 // Fresh sets a statement that will be used to create the schema from scratch
@@ -37,8 +46,21 @@ func (s *SchemaUpdate) Check(check schema.Check) {
 	s.check = check
 }
 
+// File sets the path of a file containing extra queries. If the file exists, all SQL queries in it
+// will be executed transactionally at the very start of Ensure(), before
+// anything else is done.
+func (s *SchemaUpdate) File(path string) {
+	s.path = path
+}
+
+// Version returns the combined number of internal updates and updates appended by the application.
 func (s *SchemaUpdate) Version() int {
-	return len(s.updates)
+	return len(s.updates) + len(s.extensions)
+}
+
+// ExtensionVersion returns the number of updates appended by the application.
+func (s *SchemaUpdate) ExtensionVersion() int {
+	return len(s.extensions)
 }
 
 // Ensure makes sure that the actual schema in the given database matches the
@@ -67,18 +89,27 @@ func (s *SchemaUpdate) Ensure(db *sql.DB) (int, error) {
 			return fmt.Errorf("failed to check if schema table is there: %w", err)
 		}
 
-		current := 0
+		currentInternal := 0
+		currentExtension := 0
 		if exists {
-			versions, err := query.SelectIntegers(ctx, tx, "SELECT version FROM schemas ORDER BY version")
+			err = ensureSchemaTypeColumn(ctx, tx, s.legacy)
+			if err != nil {
+				return fmt.Errorf("failed to separate schema version counters: %w", err)
+			}
+
+			currentInternal, err = selectSchemaVersion(ctx, tx, updateTypeInternal)
 			if err != nil {
 				return err
 			}
 
-			if len(versions) > 0 {
-				current = versions[len(versions)-1]
+			currentExtension, err = selectSchemaVersion(ctx, tx, updateTypeExtension)
+			if err != nil {
+				return err
 			}
 		}
 
+		current := currentInternal + currentExtension
+
 		if s.check != nil {
 			err := s.check(ctx, current, tx)
 			if err == schema.ErrGracefulAbort {
@@ -100,7 +131,12 @@ func (s *SchemaUpdate) Ensure(db *sql.DB) (int, error) {
 				return fmt.Errorf("cannot apply fresh schema: %w", err)
 			}
 		} else {
-			err = ensureUpdatesAreApplied(ctx, tx, current, s.updates, s.hook)
+			err = ensureUpdatesAreApplied(ctx, tx, currentInternal, updateTypeInternal, s.updates, s.hook)
+			if err != nil {
+				return err
+			}
+
+			err = ensureUpdatesAreApplied(ctx, tx, currentExtension, updateTypeExtension, s.extensions, s.hook)
 			if err != nil {
 				return err
 			}
@@ -126,7 +162,7 @@ func (s *SchemaUpdate) Ensure(db *sql.DB) (int, error) {
 func (s *SchemaUpdate) Dump(db *sql.DB) (string, error) {
 	var statements []string
 	err := query.Transaction(context.TODO(), db, func(ctx context.Context, tx *sql.Tx) error {
-		versions, err := query.SelectIntegers(ctx, tx, "SELECT version FROM schemas ORDER BY version")
+		versions, err := query.SelectIntegers(ctx, tx, "SELECT version FROM schemas WHERE type = ? ORDER BY version", updateTypeInternal)
 		if err != nil {
 			return err
 		}
@@ -159,8 +195,8 @@ func (s *SchemaUpdate) Dump(db *sql.DB) (string, error) {
 	statements = append(
 		statements,
 		fmt.Sprintf(`
-INSERT INTO schemas (version, updated_at) VALUES (%d, strftime("%%s"))
-`, len(s.updates)))
+INSERT INTO schemas (version, type, updated_at) VALUES (%d, %d, strftime("%%s"))
+`, len(s.updates), updateTypeInternal))
 	return strings.Join(statements, ";\n"), nil
 }
 
@@ -189,8 +225,60 @@ ORDER BY name
 	return query.SelectStrings(ctx, tx, statement)
 }
 
-// Apply any pending update that was not yet applied.
-func ensureUpdatesAreApplied(ctx context.Context, tx *sql.Tx, current int, updates []schema.Update, hook schema.Hook) error {
+// selectSchemaVersion returns the most recent version recorded in the schemas table for the given update type.
+func selectSchemaVersion(ctx context.Context, tx *sql.Tx, updateType int) (int, error) {
+	versions, err := query.SelectIntegers(ctx, tx, "SELECT version FROM schemas WHERE type = ? ORDER BY version", updateType)
+	if err != nil {
+		return -1, err
+	}
+
+	if len(versions) == 0 {
+		return 0, nil
+	}
+
+	return versions[len(versions)-1], nil
+}
+
+// ensureSchemaTypeColumn converts a schemas table from the legacy layout, where internal updates and the updates
+// appended by the application shared a single version counter, to one that tracks them separately. In the legacy
+// layout, the first legacyVersion versions belong to internal updates, and any later versions to the application.
+func ensureSchemaTypeColumn(ctx context.Context, tx *sql.Tx, legacyVersion int) error {
+	columns, err := query.SelectIntegers(ctx, tx, "SELECT COUNT(*) FROM pragma_table_info('schemas') WHERE name = 'type'")
+	if err != nil {
+		return err
+	}
+
+	if len(columns) == 1 && columns[0] > 0 {
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, "ALTER TABLE schemas RENAME TO schemas_legacy")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, CreateSchema)
+	if err != nil {
+		return err
+	}
+
+	stmt := `
+INSERT INTO schemas (version, type, updated_at)
+  SELECT version, ?, updated_at FROM schemas_legacy WHERE version <= ?
+  UNION ALL
+  SELECT version - ?, ?, updated_at FROM schemas_legacy WHERE version > ?
+`
+	_, err = tx.ExecContext(ctx, stmt, updateTypeInternal, legacyVersion, legacyVersion, updateTypeExtension, legacyVersion)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE schemas_legacy")
+	return err
+}
+
+// Apply any pending update of the given type that was not yet applied.
+func ensureUpdatesAreApplied(ctx context.Context, tx *sql.Tx, current int, updateType int, updates []schema.Update, hook schema.Hook) error {
 	if current > len(updates) {
 		return fmt.Errorf(
 			"schema version '%d' is more recent than expected '%d'",
@@ -217,8 +305,8 @@ func ensureUpdatesAreApplied(ctx context.Context, tx *sql.Tx, current int, updat
 		}
 		current++
 
-		statement := `INSERT INTO schemas (version, updated_at) VALUES (?, strftime("%s"))`
-		_, err = tx.ExecContext(ctx, statement, current)
+		statement := `INSERT INTO schemas (version, type, updated_at) VALUES (?, ?, strftime("%s"))`
+		_, err = tx.ExecContext(ctx, statement, current, updateType)
 		if err != nil {
 			return fmt.Errorf("failed to insert version %d: %w", current, err)
 		}
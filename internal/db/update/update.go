@@ -16,8 +16,9 @@ const CreateSchema = `
 CREATE TABLE schemas (
   id          INTEGER    PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
   version     INTEGER    NOT      NULL,
+  type        INTEGER    NOT      NULL,
   updated_at  DATETIME   NOT      NULL,
-  UNIQUE      (version)
+  UNIQUE      (version, type)
 );
 `
 
@@ -33,32 +34,65 @@ const dotGoTemplate = "package %s\n\n" +
 	"%s`\n"
 
 type SchemaUpdateManager struct {
-	updates map[int]schema.Update
+	updates    map[int]schema.Update
+	extensions map[int]schema.Update
+
+	// legacy is the number of internal updates that existed when internal updates and extensions shared a single
+	// version counter. It must never change.
+	legacy int
 }
 
 func NewSchema() *SchemaUpdateManager {
 	return &SchemaUpdateManager{
+		legacy:     1,
+		extensions: map[int]schema.Update{},
 		updates: map[int]schema.Update{
-			1: updateFromV0,
+			1:  updateFromV0,
+			2:  updateFromV1,
+			3:  updateFromV2,
+			4:  updateFromV3,
+			5:  updateFromV4,
+			6:  updateFromV5,
+			7:  updateFromV6,
+			8:  updateFromV7,
+			9:  updateFromV8,
+			10: updateFromV9,
+			11: updateFromV10,
+			12: updateFromV11,
+			13: updateFromV12,
+			14: updateFromV13,
+			15: updateFromV14,
+			16: updateFromV15,
+			17: updateFromV16,
+			18: updateFromV17,
+			19: updateFromV18,
 		},
 	}
 }
 
+// InternalVersion returns the schema version of the internal tables managed by MicroCluster, before any extensions are
+// appended.
+func InternalVersion() int {
+	return len(NewSchema().updates)
+}
+
 func (m *SchemaUpdateManager) Schema() *SchemaUpdate {
 	schema := NewFromMap(m.updates)
+	schema.extensions = NewFromMap(m.extensions).updates
+	schema.legacy = m.legacy
 	schema.Fresh("")
 	return schema
 }
 
+// AppendSchema appends the given updates to the extensions of the schema. Extensions are versioned separately from
+// the internal updates.
 func (m *SchemaUpdateManager) AppendSchema(extensions map[int]schema.Update) {
-	currentVersion := len(m.updates)
+	currentVersion := len(m.extensions)
 	schema := NewFromMap(extensions)
 	for _, extension := range schema.updates {
-		m.updates[currentVersion+1] = extension
-		currentVersion = len(m.updates)
+		m.extensions[currentVersion+1] = extension
+		currentVersion = len(m.extensions)
 	}
-
-	fmt.Println("Updates are", len(m.updates))
 }
 
 func (m *SchemaUpdateManager) SchemaDotGo() error {
@@ -126,3 +160,252 @@ CREATE TABLE internal_cluster_members (
 	_, err := tx.ExecContext(ctx, stmt)
 	return err
 }
+
+func updateFromV1(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE internal_feature_flags (
+  id           INTEGER   PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
+  name         TEXT      NOT      NULL,
+  enabled      BOOLEAN   NOT      NULL   DEFAULT  FALSE,
+  members      TEXT      NOT      NULL   DEFAULT  "[]",
+  percentage   INTEGER   NOT      NULL   DEFAULT  0,
+  UNIQUE(name)
+);
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV2(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE internal_projects (
+  id            INTEGER   PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
+  name          TEXT      NOT      NULL,
+  description   TEXT      NOT      NULL   DEFAULT  "",
+  UNIQUE(name)
+);
+
+CREATE TABLE internal_projects_config (
+  id           INTEGER   PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
+  project_id   INTEGER   NOT      NULL,
+  key          TEXT      NOT      NULL,
+  value        TEXT      NOT      NULL,
+  UNIQUE(project_id, key),
+  FOREIGN KEY (project_id) REFERENCES internal_projects (id) ON DELETE CASCADE
+);
+
+INSERT INTO internal_projects (name, description) VALUES ("default", "Default project");
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV3(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE internal_operations (
+  id           INTEGER   PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
+  type         TEXT      NOT      NULL,
+  initiator    TEXT      NOT      NULL,
+  member       TEXT      NOT      NULL,
+  started_at   DATETIME  NOT      NULL,
+  duration     INTEGER   NOT      NULL,
+  status       TEXT      NOT      NULL,
+  error        TEXT      NOT      NULL   DEFAULT  ""
+);
+
+CREATE INDEX internal_operations_started_at_idx ON internal_operations (started_at);
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV4(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+ALTER TABLE internal_token_records ADD COLUMN expiry_date DATETIME;
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV5(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+ALTER TABLE internal_token_records ADD COLUMN issuer TEXT NOT NULL DEFAULT "";
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV6(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+ALTER TABLE internal_cluster_members ADD COLUMN app_status TEXT NOT NULL DEFAULT "{}";
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV7(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+ALTER TABLE internal_cluster_members ADD COLUMN config TEXT NOT NULL DEFAULT "{}";
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV8(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+ALTER TABLE internal_cluster_members ADD COLUMN failure_domain INTEGER NOT NULL DEFAULT 0;
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV9(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE internal_snapshots (
+  id           INTEGER   PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
+  taken_at     DATETIME  NOT      NULL,
+  data         TEXT      NOT      NULL
+);
+
+CREATE INDEX internal_snapshots_taken_at_idx ON internal_snapshots (taken_at);
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV10(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE internal_cluster_config (
+  id           INTEGER   PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
+  key          TEXT      NOT      NULL,
+  value        TEXT      NOT      NULL,
+  UNIQUE(key)
+);
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV11(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+ALTER TABLE internal_cluster_members ADD COLUMN api_extensions TEXT NOT NULL DEFAULT "[]";
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV12(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+ALTER TABLE internal_cluster_members ADD COLUMN cordoned INTEGER NOT NULL DEFAULT 0;
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV13(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE internal_join_lock (
+  id           INTEGER   PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
+  name         TEXT      NOT      NULL,
+  expiry_date  DATETIME  NOT      NULL
+);
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV14(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE internal_events (
+  id          INTEGER   PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
+  type        TEXT      NOT      NULL,
+  member      TEXT      NOT      NULL,
+  message     TEXT      NOT      NULL,
+  created_at  DATETIME  NOT      NULL
+);
+
+CREATE INDEX internal_events_created_at_idx ON internal_events (created_at);
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV15(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE internal_certificate_acls (
+  id           INTEGER   PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
+  name         TEXT      NOT      NULL,
+  fingerprint  TEXT      NOT      NULL,
+  certificate  TEXT      NOT      NULL,
+  rules        TEXT      NOT      NULL   DEFAULT  "[]",
+  UNIQUE(name),
+  UNIQUE(fingerprint)
+);
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV16(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE internal_api_tokens (
+  id           INTEGER   PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
+  name         TEXT      NOT      NULL,
+  hash         TEXT      NOT      NULL,
+  rules        TEXT      NOT      NULL   DEFAULT  "[]",
+  expiry_date  DATETIME,
+  UNIQUE(name),
+  UNIQUE(hash)
+);
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV17(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE internal_trust_audit (
+  id           INTEGER   PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
+  action       TEXT      NOT      NULL,
+  type         TEXT      NOT      NULL,
+  name         TEXT      NOT      NULL,
+  fingerprint  TEXT      NOT      NULL,
+  requestor    TEXT      NOT      NULL,
+  created_at   DATETIME  NOT      NULL
+);
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
+
+func updateFromV18(ctx context.Context, tx *sql.Tx) error {
+	stmt := `
+CREATE TABLE internal_secrets (
+  id           INTEGER   PRIMARY  KEY    AUTOINCREMENT  NOT  NULL,
+  name         TEXT      NOT      NULL,
+  value        TEXT      NOT      NULL,
+  updated_at   DATETIME  NOT      NULL,
+  UNIQUE(name)
+);
+`
+
+	_, err := tx.ExecContext(ctx, stmt)
+	return err
+}
@@ -6,6 +6,7 @@ type Endpoint interface {
 	Serve()
 	Close() error
 	Type() EndpointType
+	Serving() bool
 }
 
 // EndpointType enumerates the supported endpoints.
@@ -17,6 +18,13 @@ const (
 
 	// EndpointNetwork represents the user endpoint accessible over https (on a different port to the user endpoint).
 	EndpointNetwork
+
+	// EndpointConsumer represents the endpoint for the consumer API accessible over https, separate from the cluster
+	// endpoint.
+	EndpointConsumer
+
+	// EndpointApplication represents the endpoint for the application API accessible via unix socket.
+	EndpointApplication
 )
 
 // String labels EndpointTypes for logging purposes.
@@ -26,6 +34,10 @@ func (et EndpointType) String() string {
 		return "control socket"
 	case EndpointNetwork:
 		return "https socket"
+	case EndpointConsumer:
+		return "consumer https socket"
+	case EndpointApplication:
+		return "application socket"
 	default:
 		return ""
 	}
@@ -2,9 +2,13 @@ package endpoints
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/internal/sys"
 )
 
 // Endpoints represents all listeners and servers for the microcluster daemon REST API.
@@ -111,3 +115,51 @@ func (e *Endpoints) Down(types ...EndpointType) error {
 
 	return nil
 }
+
+// NotServing returns the types of the configured listeners that are not serving, either because they were not brought up
+// or because they were closed.
+func (e *Endpoints) NotServing() []EndpointType {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var types []EndpointType
+	for endpointType, listener := range e.listeners {
+		if !listener.Serving() {
+			types = append(types, endpointType)
+		}
+	}
+
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	return types
+}
+
+// UpdateCert swaps the certificate served by the network listener of the given type without rebinding it.
+func (e *Endpoints) UpdateCert(endpointType EndpointType, cert *sys.CertInfo) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	network, ok := e.listeners[endpointType].(*Network)
+	if !ok {
+		return fmt.Errorf("No network listener of type %q", endpointType.String())
+	}
+
+	network.UpdateCert(cert)
+
+	return nil
+}
+
+// UpdateMemberCert swaps the member certificate served by the network listener of the given type.
+func (e *Endpoints) UpdateMemberCert(endpointType EndpointType, cert *sys.CertInfo) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	network, ok := e.listeners[endpointType].(*Network)
+	if !ok {
+		return fmt.Errorf("No network listener of type %q", endpointType.String())
+	}
+
+	network.UpdateMemberCert(cert)
+
+	return nil
+}
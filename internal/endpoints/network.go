@@ -2,39 +2,50 @@ package endpoints
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 
-	"github.com/canonical/lxd/lxd/endpoints/listeners"
 	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/internal/cryptopolicy"
+	"github.com/canonical/microcluster/internal/rest/client"
+	"github.com/canonical/microcluster/internal/sys"
 )
 
 // Network represents an HTTPS listener and its server.
 type Network struct {
-	address     api.URL
-	cert        *shared.CertInfo
+	addresses   []api.URL
+	cert        *sys.CertInfo
+	memberCert  *sys.CertInfo
+	certMu      sync.RWMutex
 	networkType EndpointType
+	policy      cryptopolicy.Policy
 
-	listener net.Listener
-	server   *http.Server
+	listeners []net.Listener
+	server    *http.Server
 
 	ctx    context.Context
 	cancel context.CancelFunc
 }
 
-// NewNetwork assigns an address, certificate, and server to the Network.
-func NewNetwork(ctx context.Context, endpointType EndpointType, server *http.Server, address api.URL, cert *shared.CertInfo) *Network {
+// NewNetwork assigns an address, certificates, and server to the Network. The member certificate is served instead of
+// the certificate to clients that ask for it by server name, so that they can verify which cluster member they reached.
+func NewNetwork(ctx context.Context, endpointType EndpointType, server *http.Server, address api.URL, cert *sys.CertInfo, memberCert *sys.CertInfo) *Network {
 	ctx, cancel := context.WithCancel(ctx)
 
 	return &Network{
-		address:     address,
+		addresses:   []api.URL{address},
 		cert:        cert,
+		memberCert:  memberCert,
 		networkType: endpointType,
+		policy:      cryptopolicy.Current,
 
 		server: server,
 		ctx:    ctx,
@@ -42,14 +53,72 @@ func NewNetwork(ctx context.Context, endpointType EndpointType, server *http.Ser
 	}
 }
 
+// SetCryptoPolicy sets the crypto policy the listener applies to its TLS configuration, instead of the policy of the
+// daemon. It must be called before Listen.
+func (n *Network) SetCryptoPolicy(policy cryptopolicy.Policy) {
+	n.policy = policy
+}
+
+// SetAddresses sets the addresses the listener binds to, instead of the address it was created with, such as both
+// an IPv4 and an IPv6 address, or "[::]" for all addresses of both families. It must be called before Listen.
+func (n *Network) SetAddresses(addresses ...api.URL) {
+	n.addresses = addresses
+}
+
 // Type returns the type of the Endpoint.
 func (n *Network) Type() EndpointType {
 	return n.networkType
 }
 
-// Listen on the given address.
+// Listen on the given addresses.
 func (n *Network) Listen() error {
-	listenAddress := util.CanonicalNetworkAddress(n.address.URL.Host, shared.HTTPSDefaultPort)
+	config := shared.InitTLSConfig()
+	config.ClientAuth = tls.RequestClientCert
+	// Offer HTTP/2, falling back to HTTP/1.1 for clients that do not support it or need to hijack the connection.
+	config.NextProtos = []string{"h2", "http/1.1"}
+	cryptopolicy.ApplyTLSPolicy(config, n.policy)
+
+	// Serve the current certificate on each connection, so that it can be swapped without rebinding.
+	config.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+		n.certMu.RLock()
+		defer n.certMu.RUnlock()
+
+		if hello.ServerName == client.MemberServerName && n.memberCert != nil {
+			keypair := n.memberCert.KeyPair()
+			return &keypair, nil
+		}
+
+		keypair := n.cert.KeyPair()
+		return &keypair, nil
+	}
+
+	listeners := make([]net.Listener, 0, len(n.addresses))
+	for _, address := range n.addresses {
+		listener, err := listen(address)
+		if err != nil {
+			for _, listener := range listeners {
+				_ = listener.Close()
+			}
+
+			return err
+		}
+
+		if len(ProxyProtocolSources) > 0 {
+			listener = &proxyListener{Listener: listener}
+		}
+
+		listeners = append(listeners, tls.NewListener(listener, config))
+	}
+
+	n.listeners = listeners
+
+	return nil
+}
+
+// listen binds a TCP socket to the given address. Unspecified IPv6 addresses, such as "[::]", accept both IPv4 and
+// IPv6 connections.
+func listen(address api.URL) (net.Listener, error) {
+	listenAddress := util.CanonicalNetworkAddress(address.URL.Host, shared.HTTPSDefaultPort)
 	protocol := "tcp"
 
 	if strings.HasPrefix(listenAddress, "0.0.0.0") {
@@ -58,54 +127,83 @@ func (n *Network) Listen() error {
 
 	_, err := net.Dial(protocol, listenAddress)
 	if err == nil {
-		return fmt.Errorf("%q listener with address %q is already running", protocol, listenAddress)
+		return nil, fmt.Errorf("%q listener with address %q is already running", protocol, listenAddress)
 	}
 
 	listener, err := net.Listen(protocol, listenAddress)
 	if err != nil {
-		return fmt.Errorf("Failed to listen on https socket: %w", err)
+		return nil, fmt.Errorf("Failed to listen on https socket %q: %w", listenAddress, err)
 	}
 
-	n.listener = listeners.NewFancyTLSListener(listener, n.cert)
+	return listener, nil
+}
 
-	return nil
+// UpdateCert swaps the certificate served by the listener without rebinding it. Connections established before the
+// swap keep using the old certificate.
+func (n *Network) UpdateCert(cert *sys.CertInfo) {
+	n.certMu.Lock()
+	defer n.certMu.Unlock()
+
+	n.cert = cert
+}
+
+// UpdateMemberCert swaps the member certificate served by the listener to clients that ask for it by server name,
+// without rebinding it.
+func (n *Network) UpdateMemberCert(cert *sys.CertInfo) {
+	n.certMu.Lock()
+	defer n.certMu.Unlock()
+
+	n.memberCert = cert
 }
 
 // Serve binds to the Network's server.
 func (n *Network) Serve() {
-	if n.listener == nil {
-		return
-	}
-
-	ctx := logger.Ctx{"network": n.listener.Addr()}
-	logger.Info(" - binding https socket", ctx)
-
-	go func() {
-		select {
-		case <-n.ctx.Done():
-			logger.Infof("Received shutdown signal - aborting https socket server startup")
-		default:
-			err := n.server.Serve(n.listener)
-			if err != nil {
-				select {
-				case <-n.ctx.Done():
-					logger.Infof("Received shutdown signal - aborting https socket server startup")
-				default:
-					logger.Error("Failed to start server", logger.Ctx{"err": err})
+	for _, listener := range n.listeners {
+		listener := listener
+
+		ctx := logger.Ctx{"network": listener.Addr()}
+		logger.Info(" - binding https socket", ctx)
+
+		go func() {
+			select {
+			case <-n.ctx.Done():
+				logger.Infof("Received shutdown signal - aborting https socket server startup")
+			default:
+				err := n.server.Serve(listener)
+				if err != nil {
+					select {
+					case <-n.ctx.Done():
+						logger.Infof("Received shutdown signal - aborting https socket server startup")
+					default:
+						logger.Error("Failed to start server", logger.Ctx{"err": err})
+					}
 				}
 			}
-		}
-	}()
+		}()
+	}
 }
 
-// Close the listener.
+// Serving returns whether the listener is bound and has not been closed.
+func (n *Network) Serving() bool {
+	return len(n.listeners) > 0 && n.ctx.Err() == nil
+}
+
+// Close the listeners.
 func (n *Network) Close() error {
-	if n.listener == nil {
+	if len(n.listeners) == 0 {
 		return nil
 	}
 
-	logger.Info("Stopping REST API handler - closing https socket", logger.Ctx{"address": n.listener.Addr()})
 	n.cancel()
 
-	return n.listener.Close()
+	var closeErr error
+	for _, listener := range n.listeners {
+		logger.Info("Stopping REST API handler - closing https socket", logger.Ctx{"address": listener.Addr()})
+		err := listener.Close()
+		if err != nil && closeErr == nil {
+			closeErr = err
+		}
+	}
+
+	return closeErr
 }
@@ -0,0 +1,223 @@
+package endpoints
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProxyProtocolSources are the networks of load balancers that send a PROXY protocol (v1 or v2) header at the start of
+// each connection to the network listeners. The client address from the header is used as the remote address of the
+// connection. Connections from other addresses are served without parsing a header.
+var ProxyProtocolSources []*net.IPNet
+
+// proxyHeaderTimeout is how long a connection from a load balancer has to send its PROXY protocol header.
+const proxyHeaderTimeout = 10 * time.Second
+
+// proxyV2Signature is the signature that starts a PROXY protocol v2 header.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// proxyListener wraps a listener to parse the PROXY protocol header of connections from ProxyProtocolSources.
+type proxyListener struct {
+	net.Listener
+}
+
+// Accept waits for and returns the next connection to the listener.
+func (l *proxyListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	if !proxyProtocolSource(conn.RemoteAddr()) {
+		return conn, nil
+	}
+
+	// The header is only read once the connection is used, so that a slow load balancer does not block the listener.
+	return &proxyConn{Conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+// proxyProtocolSource returns whether the given address belongs to ProxyProtocolSources.
+func proxyProtocolSource(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	for _, network := range ProxyProtocolSources {
+		if network.Contains(tcpAddr.IP) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// proxyConn is a connection from a load balancer, whose remote address is that of the client given in its PROXY
+// protocol header.
+type proxyConn struct {
+	net.Conn
+	reader *bufio.Reader
+
+	once       sync.Once
+	remoteAddr net.Addr
+	err        error
+}
+
+// readHeader reads the PROXY protocol header of the connection, once.
+func (c *proxyConn) readHeader() {
+	c.once.Do(func() {
+		err := c.Conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout))
+		if err != nil {
+			c.err = err
+			return
+		}
+
+		c.remoteAddr, c.err = readProxyHeader(c.reader)
+		if c.err != nil {
+			c.err = fmt.Errorf("Invalid PROXY protocol header from %q: %w", c.Conn.RemoteAddr().String(), c.err)
+			return
+		}
+
+		c.err = c.Conn.SetReadDeadline(time.Time{})
+	})
+}
+
+// Read reads data from the connection, after the PROXY protocol header.
+func (c *proxyConn) Read(b []byte) (int, error) {
+	c.readHeader()
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	return c.reader.Read(b)
+}
+
+// RemoteAddr returns the client address from the PROXY protocol header, or the address of the load balancer if the
+// header carries no address.
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.readHeader()
+	if c.remoteAddr == nil {
+		return c.Conn.RemoteAddr()
+	}
+
+	return c.remoteAddr
+}
+
+// readProxyHeader reads a PROXY protocol v1 or v2 header, and returns the source address it carries. The address is nil
+// for headers that carry none, such as health checks by the load balancer itself.
+func readProxyHeader(reader *bufio.Reader) (net.Addr, error) {
+	signature, err := reader.Peek(len(proxyV2Signature))
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Equal(signature, proxyV2Signature) {
+		return readProxyHeaderV2(reader)
+	}
+
+	if bytes.HasPrefix(signature, []byte("PROXY ")) {
+		return readProxyHeaderV1(reader)
+	}
+
+	return nil, fmt.Errorf("Missing header")
+}
+
+// readProxyHeaderV1 reads a human-readable PROXY protocol v1 header, such as "PROXY TCP4 <src> <dst> <sport> <dport>".
+func readProxyHeaderV1(reader *bufio.Reader) (net.Addr, error) {
+	// A v1 header is at most 107 bytes long, including the CRLF.
+	var line []byte
+	for len(line) < 107 {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+
+		line = append(line, b)
+		if bytes.HasSuffix(line, []byte("\r\n")) {
+			break
+		}
+	}
+
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, fmt.Errorf("Header is too long")
+	}
+
+	fields := strings.Fields(string(line))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("Malformed header %q", strings.TrimSpace(string(line)))
+	}
+
+	ip := net.ParseIP(fields[2])
+	if ip == nil {
+		return nil, fmt.Errorf("Invalid source address %q", fields[2])
+	}
+
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid source port %q", fields[4])
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+// readProxyHeaderV2 reads a binary PROXY protocol v2 header.
+func readProxyHeaderV2(reader *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, len(proxyV2Signature)+4)
+	_, err := io.ReadFull(reader, header)
+	if err != nil {
+		return nil, err
+	}
+
+	versionCommand := header[len(proxyV2Signature)]
+	family := header[len(proxyV2Signature)+1]
+	length := binary.BigEndian.Uint16(header[len(proxyV2Signature)+2:])
+
+	if versionCommand>>4 != 2 {
+		return nil, fmt.Errorf("Unsupported version %d", versionCommand>>4)
+	}
+
+	addresses := make([]byte, length)
+	_, err = io.ReadFull(reader, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	// The LOCAL command is used by the load balancer for its own connections, such as health checks.
+	if versionCommand&0x0f == 0 {
+		return nil, nil
+	}
+
+	if versionCommand&0x0f != 1 {
+		return nil, fmt.Errorf("Unsupported command %d", versionCommand&0x0f)
+	}
+
+	switch family {
+	case 0x11: // TCP over IPv4.
+		if len(addresses) < 12 {
+			return nil, fmt.Errorf("Truncated IPv4 addresses")
+		}
+
+		return &net.TCPAddr{IP: net.IP(addresses[0:4]), Port: int(binary.BigEndian.Uint16(addresses[8:10]))}, nil
+	case 0x21: // TCP over IPv6.
+		if len(addresses) < 36 {
+			return nil, fmt.Errorf("Truncated IPv6 addresses")
+		}
+
+		return &net.TCPAddr{IP: net.IP(addresses[0:16]), Port: int(binary.BigEndian.Uint16(addresses[32:34]))}, nil
+	}
+
+	// Other address families carry no address usable for a TCP connection.
+	return nil, nil
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/user"
 	"strconv"
+	"strings"
 
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
@@ -17,7 +18,11 @@ import (
 // Socket represents a unix socket with a given path.
 type Socket struct {
 	Path  string
+	Owner string
 	Group string
+	Mode  os.FileMode
+
+	socketType EndpointType
 
 	listener *net.UnixListener
 	server   *http.Server
@@ -32,6 +37,27 @@ func NewSocket(ctx context.Context, server *http.Server, path api.URL, group str
 	return &Socket{
 		Path:  path.Hostname(),
 		Group: group,
+		Mode:  0660,
+
+		socketType: EndpointControl,
+
+		server: server,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// NewApplicationSocket returns a Socket struct for the application API with no listener attached yet. The socket file
+// is owned by the given user and group, or those of the process if empty, and has the given file mode.
+func NewApplicationSocket(ctx context.Context, server *http.Server, path string, owner string, group string, mode os.FileMode) *Socket {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Socket{
+		Path:  path,
+		Owner: owner,
+		Group: group,
+		Mode:  mode,
+
+		socketType: EndpointApplication,
 
 		server: server,
 		ctx:    ctx,
@@ -41,19 +67,23 @@ func NewSocket(ctx context.Context, server *http.Server, path api.URL, group str
 
 // Type returns the type of the Endpoint.
 func (s *Socket) Type() EndpointType {
-	return EndpointControl
+	return s.socketType
 }
 
-// Listen on the unix socket path.
+// Listen on the unix socket path. A path starting with "@" is a socket in the Linux abstract namespace, which has no
+// file, and so no file permissions or ownership.
 func (s *Socket) Listen() error {
 	_, err := net.Dial("unix", s.Path)
 	if err == nil {
 		return fmt.Errorf("unix socket at %q is already running", s.Path)
 	}
 
-	err = s.removeStale()
-	if err != nil {
-		return err
+	abstract := strings.HasPrefix(s.Path, "@")
+	if !abstract {
+		err = s.removeStale()
+		if err != nil {
+			return err
+		}
 	}
 
 	addr, err := net.ResolveUnixAddr("unix", s.Path)
@@ -66,7 +96,11 @@ func (s *Socket) Listen() error {
 		return fmt.Errorf("cannot bind socket: %v", err)
 	}
 
-	err = localSetAccess(s.Path, s.Group)
+	if abstract {
+		return nil
+	}
+
+	err = localSetAccess(s.Path, s.Owner, s.Group, s.Mode)
 	if err != nil {
 		s.listener.Close()
 		return err
@@ -82,7 +116,7 @@ func (s *Socket) Serve() {
 	}
 
 	ctx := logger.Ctx{"socket": s.listener.Addr()}
-	logger.Info(" - binding control socket", ctx)
+	logger.Info(" - binding "+s.socketType.String(), ctx)
 
 	go func() {
 		select {
@@ -102,6 +136,11 @@ func (s *Socket) Serve() {
 	}()
 }
 
+// Serving returns whether the socket is bound and has not been closed.
+func (s *Socket) Serving() bool {
+	return s.listener != nil && s.ctx.Err() == nil
+}
+
 // Close the Socket's listener.
 func (s *Socket) Close() error {
 	if s.listener == nil {
@@ -130,16 +169,16 @@ func (s *Socket) removeStale() error {
 	return nil
 }
 
-// Change the file mode and ownership of the local endpoint control socket file,
-// so access is granted only to the process user and to the given group (or the
-// process group if group is empty).
-func localSetAccess(path string, group string) error {
-	err := socketControlSetPermissions(path, 0660)
+// Change the file mode and ownership of the local endpoint socket file,
+// so access is granted only to the given user (or the process user if owner is empty)
+// and to the given group (or the process group if group is empty).
+func localSetAccess(path string, owner string, group string, mode os.FileMode) error {
+	err := socketControlSetPermissions(path, mode)
 	if err != nil {
 		return err
 	}
 
-	err = socketControlSetOwnership(path, group)
+	err = socketControlSetOwnership(path, owner, group)
 	if err != nil {
 		return err
 	}
@@ -158,10 +197,25 @@ func socketControlSetPermissions(path string, mode os.FileMode) error {
 }
 
 // Change the ownership of the given control socket file.
-func socketControlSetOwnership(path string, groupName string) error {
+func socketControlSetOwnership(path string, userName string, groupName string) error {
+	var uid int
 	var gid int
 	var err error
 
+	if userName != "" {
+		u, err := user.Lookup(userName)
+		if err != nil {
+			return fmt.Errorf("cannot get user ID of '%s': %v", userName, err)
+		}
+
+		uid, err = strconv.Atoi(u.Uid)
+		if err != nil {
+			return err
+		}
+	} else {
+		uid = os.Getuid()
+	}
+
 	if groupName != "" {
 		g, err := user.LookupGroup(groupName)
 		if err != nil {
@@ -176,7 +230,7 @@ func socketControlSetOwnership(path string, groupName string) error {
 		gid = os.Getgid()
 	}
 
-	err = os.Chown(path, os.Getuid(), gid)
+	err = os.Chown(path, uid, gid)
 	if err != nil {
 		return fmt.Errorf("cannot change ownership on local socket: %v", err)
 	}
@@ -0,0 +1,109 @@
+package extensions
+
+// internalExtensions are the API extensions implemented by MicroCluster itself. New extensions must only be appended.
+var internalExtensions = []string{
+	"member_config",
+	"failure_domains",
+	"member_roles",
+	"snapshots",
+	"cluster_config",
+	"scheduled_backups",
+	"join_validation",
+	"api_extensions",
+	"rolling_upgrade",
+	"member_cordon",
+	"member_filters",
+	"leader",
+	"member_certificate",
+	"member_events",
+	"cluster_certificate_rotation",
+	"external_ca",
+	"certificate_acls",
+	"truststore_replication",
+	"truststore_check",
+	"certificate_inventory",
+	"api_tokens",
+	"encrypted_keys",
+	"trust_audit",
+	"join_tofu",
+	"warnings",
+	"key_signers",
+	"authorizer",
+	"secrets",
+	"join_token_signatures",
+	"dqlite_member_certificates",
+	"control_socket_credentials",
+	"trusted_proxies",
+	"consumer_listener",
+	"application_socket",
+	"proxy_protocol",
+	"rate_limits",
+	"request_limits",
+	"cors",
+	"websockets",
+	"event_streams",
+	"collection_queries",
+	"config_etags",
+	"api_versions",
+	"openapi",
+	"error_codes",
+	"member_targeting",
+	"leader_forwarding",
+	"health_checks",
+	"endpoint_metrics",
+}
+
+// AppExtensions are the API extensions implemented by the application.
+var AppExtensions []string
+
+// Supported returns the API extensions supported by this cluster member, including those of the application.
+func Supported() []string {
+	supported := make([]string, 0, len(internalExtensions)+len(AppExtensions))
+	seen := make(map[string]bool, cap(supported))
+	for _, list := range [][]string{internalExtensions, AppExtensions} {
+		for _, extension := range list {
+			if seen[extension] {
+				continue
+			}
+
+			seen[extension] = true
+			supported = append(supported, extension)
+		}
+	}
+
+	return supported
+}
+
+// Common returns the API extensions present in every one of the given lists, in the order of the first list.
+func Common(lists ...[]string) []string {
+	common := []string{}
+	if len(lists) == 0 {
+		return common
+	}
+
+	for _, extension := range lists[0] {
+		inAll := true
+		for _, list := range lists[1:] {
+			if !contains(list, extension) {
+				inAll = false
+				break
+			}
+		}
+
+		if inAll && !contains(common, extension) {
+			common = append(common, extension)
+		}
+	}
+
+	return common
+}
+
+func contains(list []string, value string) bool {
+	for _, entry := range list {
+		if entry == value {
+			return true
+		}
+	}
+
+	return false
+}
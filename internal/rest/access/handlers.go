@@ -1,16 +1,65 @@
 package access
 
 import (
+	"fmt"
 	"net/http"
 
+	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 
 	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
 )
 
 // TrustedRequest holds data pertaining to what level of trust we have for the request.
 type TrustedRequest struct {
 	Trusted bool
+
+	// Identity is the caller of the request, such as a cluster member, certificate or bearer token.
+	Identity rest.Identity
+
+	// Restricted is whether the caller is limited to the API endpoints allowed by the rules of its certificate ACL or
+	// bearer token.
+	Restricted bool
+}
+
+// Requestor returns a description of who made the given request, as determined when it was authenticated.
+func Requestor(r *http.Request) string {
+	trusted, ok := r.Context().Value(request.CtxAccess).(TrustedRequest)
+	if !ok {
+		return "Unknown"
+	}
+
+	if trusted.Identity.Address != "" {
+		return fmt.Sprintf("%s at %s", trusted.Identity, trusted.Identity.Address)
+	}
+
+	return trusted.Identity.String()
+}
+
+// Identity returns the caller of the given request, as determined when it was authenticated.
+func Identity(r *http.Request) rest.Identity {
+	trusted, ok := r.Context().Value(request.CtxAccess).(TrustedRequest)
+	if !ok {
+		return rest.Identity{Type: rest.IdentityAnonymous}
+	}
+
+	return trusted.Identity
+}
+
+// Restricted returns whether the caller of the given request is limited to the API endpoints allowed by a set of ACL
+// rules.
+func Restricted(r *http.Request) bool {
+	trusted, ok := r.Context().Value(request.CtxAccess).(TrustedRequest)
+
+	return !ok || trusted.Restricted
+}
+
+// Trusted returns whether the given request was made by a trusted caller, as determined when it was authenticated.
+func Trusted(r *http.Request) bool {
+	trusted, ok := r.Context().Value(request.CtxAccess).(TrustedRequest)
+
+	return ok && trusted.Trusted
 }
 
 // AllowAuthenticated is an AccessHandler which allows all requests.
@@ -20,3 +69,14 @@ type TrustedRequest struct {
 func AllowAuthenticated(state *state.State, r *http.Request) response.Response {
 	return response.EmptySyncResponse
 }
+
+// AllowClusterMembers is an AccessHandler which only allows requests from other cluster members and from the local
+// control socket, for endpoints that change the state of the cluster itself.
+func AllowClusterMembers(state *state.State, r *http.Request) response.Response {
+	identity := Identity(r)
+	if identity.Type != rest.IdentityClusterMember && identity.Type != rest.IdentityLocal {
+		return response.Forbidden(fmt.Errorf("Only cluster members may perform this action"))
+	}
+
+	return response.EmptySyncResponse
+}
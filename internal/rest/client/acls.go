@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetCertificateACLs returns all restricted certificates and their rules.
+func (c *Client) GetCertificateACLs(ctx context.Context) ([]types.CertificateACL, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	acls := []types.CertificateACL{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("acls"), nil, &acls)
+
+	return acls, err
+}
+
+// GetCertificateACL returns the restricted certificate with the given name and its rules.
+func (c *Client) GetCertificateACL(ctx context.Context, name string) (*types.CertificateACL, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	acl := &types.CertificateACL{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("acls", name), nil, acl)
+	if err != nil {
+		return nil, err
+	}
+
+	return acl, nil
+}
+
+// CreateCertificateACL trusts a new certificate, restricted to the API endpoints allowed by its rules.
+func (c *Client) CreateCertificateACL(ctx context.Context, acl types.CertificateACL) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "POST", PublicEndpoint, api.NewURL().Path("acls"), acl, nil)
+}
+
+// UpdateCertificateACL replaces the rules of the restricted certificate with the given name.
+func (c *Client) UpdateCertificateACL(ctx context.Context, name string, acl types.CertificateACLPut) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "PUT", PublicEndpoint, api.NewURL().Path("acls", name), acl, nil)
+}
+
+// DeleteCertificateACL removes the restricted certificate with the given name, so that it is no longer trusted.
+func (c *Client) DeleteCertificateACL(ctx context.Context, name string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "DELETE", PublicEndpoint, api.NewURL().Path("acls", name), nil, nil)
+}
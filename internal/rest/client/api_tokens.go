@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetAPITokens returns all issued bearer tokens, without their secrets.
+func (c *Client) GetAPITokens(ctx context.Context) ([]types.APIToken, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	tokens := []types.APIToken{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("api-tokens"), nil, &tokens)
+
+	return tokens, err
+}
+
+// CreateAPIToken issues a new bearer token restricted to the API endpoints allowed by its rules. The returned token
+// can not be retrieved again.
+func (c *Client) CreateAPIToken(ctx context.Context, token types.APITokenPost) (*types.APITokenSecret, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	secret := &types.APITokenSecret{}
+	err := c.QueryStruct(queryCtx, "POST", PublicEndpoint, api.NewURL().Path("api-tokens"), token, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// DeleteAPIToken revokes the bearer token with the given name.
+func (c *Client) DeleteAPIToken(ctx context.Context, name string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "DELETE", PublicEndpoint, api.NewURL().Path("api-tokens", name), nil, nil)
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/lxd/shared/api"
+)
+
+// GetDatabaseBackup writes an archive of the global database to w, compressed with the first of the given algorithms
+// supported by the cluster member, or with its default backup compression if none are. It returns the name of the
+// compression of the archive, which is "identity" if it is not compressed.
+func (c *Client) GetDatabaseBackup(ctx context.Context, w io.Writer, compressions ...string) (string, error) {
+	path := c.URL()
+	parts := strings.Split(string(InternalEndpoint), "/")
+	parts = append(parts, "database", "backup")
+	path = *path.Path(parts...)
+	req, err := http.NewRequestWithContext(ctx, "GET", path.String(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	// Always set the header, so that the archive is not transparently decompressed by the HTTP client.
+	acceptEncoding := "*"
+	if len(compressions) > 0 {
+		acceptEncoding = strings.Join(compressions, ", ")
+	}
+
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		_, err := parseResponse(resp)
+		if err != nil {
+			return "", err
+		}
+
+		return "", api.StatusErrorf(resp.StatusCode, "Failed to get database backup: %s", resp.Status)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("Failed to read database backup: %w", err)
+	}
+
+	compression := resp.Header.Get("Content-Encoding")
+	if compression == "" {
+		compression = "identity"
+	}
+
+	return compression, nil
+}
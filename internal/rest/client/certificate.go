@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetClusterCertificate returns the cluster certificate served by the cluster member.
+func (c *Client) GetClusterCertificate(ctx context.Context) (*types.ClusterCertificate, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	cert := types.ClusterCertificate{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("certificate"), nil, &cert)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cert, nil
+}
+
+// RotateClusterCertificate replaces the cluster certificate of every cluster member with a newly generated one, and
+// returns the new certificate.
+func (c *Client) RotateClusterCertificate(ctx context.Context) (*types.ClusterCertificate, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	cert := types.ClusterCertificate{}
+	err := c.QueryStruct(queryCtx, "POST", PublicEndpoint, api.NewURL().Path("certificate"), nil, &cert)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cert, nil
+}
+
+// UpdateClusterCertificate applies a phase of a cluster certificate rotation to the cluster member.
+func (c *Client) UpdateClusterCertificate(ctx context.Context, update types.ClusterCertificateUpdate) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "PUT", InternalEndpoint, api.NewURL().Path("certificate"), update, nil)
+}
@@ -22,8 +22,13 @@ import (
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/canonical/lxd/shared/tcp"
+
+	"github.com/canonical/microcluster/internal/sys"
 )
 
+// APIVersion is the version of the internally managed API.
+const APIVersion = "1.0"
+
 // EndpointType is a type specifying the endpoint on with the resource exists.
 type EndpointType string
 
@@ -48,14 +53,15 @@ type Client struct {
 }
 
 // New returns a new client configured with the given url and certificates.
-func New(url api.URL, clientCert *shared.CertInfo, remoteCert *x509.Certificate, forwarding bool) (*Client, error) {
+func New(url api.URL, clientCert *sys.CertInfo, remoteCert *x509.Certificate, forwarding bool) (*Client, error) {
 	var err error
 	var httpClient *http.Client
 
-	// If the url is an absolute path to the control.socket, return a client to the local unix socket.
-	if strings.HasSuffix(url.String(), "control.socket") && path.IsAbs(url.Hostname()) {
-		httpClient, err = unixHTTPClient(shared.HostPath(url.Hostname()))
-		url.Host(filepath.Base(url.Hostname()))
+	// If the url is an absolute path to the control.socket, or an abstract unix socket, return a client to the local
+	// unix socket.
+	if url.URL.Scheme == "http" && (path.IsAbs(url.URL.Host) || strings.HasPrefix(url.URL.Host, "@")) {
+		httpClient, err = unixHTTPClient(socketHostPath(url.URL.Host))
+		url.Host(socketHost(url.URL.Host))
 	} else {
 		proxy := shared.ProxyFromEnvironment
 		if forwarding {
@@ -75,6 +81,33 @@ func New(url api.URL, clientCert *shared.CertInfo, remoteCert *x509.Certificate,
 	}, nil
 }
 
+// NewUnix returns a new client for the daemon listening on the unix socket at the given path.
+func NewUnix(socketPath string) (*Client, error) {
+	httpClient, err := unixHTTPClient(socketHostPath(socketPath))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		Client: httpClient,
+		url:    *api.NewURL().Scheme("http").Host(socketHost(socketPath)),
+	}, nil
+}
+
+// socketHostPath returns the path of the unix socket on the host. Abstract unix sockets have no path to translate.
+func socketHostPath(socketPath string) string {
+	if strings.HasPrefix(socketPath, "@") {
+		return socketPath
+	}
+
+	return shared.HostPath(socketPath)
+}
+
+// socketHost returns the host name used in requests over the unix socket at the given path.
+func socketHost(socketPath string) string {
+	return strings.TrimPrefix(filepath.Base(socketPath), "@")
+}
+
 func unixHTTPClient(path string) (*http.Client, error) {
 	// Setup a Unix socket dialer
 	unixDial := func(ctx context.Context, network string, addr string) (net.Conn, error) {
@@ -107,7 +140,7 @@ func unixHTTPClient(path string) (*http.Client, error) {
 	return client, nil
 }
 
-func tlsHTTPClient(clientCert *shared.CertInfo, remoteCert *x509.Certificate, proxy func(req *http.Request) (*url.URL, error)) (*http.Client, error) {
+func tlsHTTPClient(clientCert *sys.CertInfo, remoteCert *x509.Certificate, proxy func(req *http.Request) (*url.URL, error)) (*http.Client, error) {
 	var tlsConfig *tls.Config
 	if remoteCert != nil {
 		var err error
@@ -115,7 +148,13 @@ func tlsHTTPClient(clientCert *shared.CertInfo, remoteCert *x509.Certificate, pr
 		if err != nil {
 			return nil, fmt.Errorf("Failed to parse TLS config: %w", err)
 		}
+	} else if Insecure {
+		tlsConfig = shared.InitTLSConfig()
+		if clientCert != nil {
+			tlsConfig.Certificates = []tls.Certificate{clientCert.KeyPair()}
+		}
 
+		tlsConfig = insecureConfig(tlsConfig)
 	}
 
 	tlsDialContext := func(ctx context.Context, network string, addr string) (net.Conn, error) {
@@ -33,6 +33,46 @@ func (c *Client) GetClusterMembers(ctx context.Context) ([]types.ClusterMember,
 	return clusterMembers, err
 }
 
+// FilterClusterMembers returns the cluster members matching the given filter.
+func (c *Client) FilterClusterMembers(ctx context.Context, filter types.ClusterMemberFilter) ([]types.ClusterMember, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	clusterMembers := []types.ClusterMember{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, clusterMembersURL(filter), nil, &clusterMembers)
+
+	return clusterMembers, err
+}
+
+// GetClusterMemberAddresses returns only the addresses of the cluster members matching the given filter.
+func (c *Client) GetClusterMemberAddresses(ctx context.Context, filter types.ClusterMemberFilter) ([]string, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	addresses := []string{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, clusterMembersURL(filter).WithQuery("recursion", "0"), nil, &addresses)
+
+	return addresses, err
+}
+
+// clusterMembersURL returns the URL of the cluster members listing with the query parameters for the given filter.
+func clusterMembersURL(filter types.ClusterMemberFilter) *api.URL {
+	endpoint := api.NewURL().Path("cluster")
+	if filter.Name != "" {
+		endpoint = endpoint.WithQuery("name", filter.Name)
+	}
+
+	if filter.Role != "" {
+		endpoint = endpoint.WithQuery("role", filter.Role)
+	}
+
+	if filter.Status != "" {
+		endpoint = endpoint.WithQuery("status", filter.Status)
+	}
+
+	return endpoint
+}
+
 // DeleteClusterMember deletes the cluster member with the given name.
 func (c *Client) DeleteClusterMember(ctx context.Context, name string, force bool) error {
 	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -46,6 +86,14 @@ func (c *Client) DeleteClusterMember(ctx context.Context, name string, force boo
 	return c.QueryStruct(queryCtx, "DELETE", PublicEndpoint, endpoint, nil, nil)
 }
 
+// RenameClusterMember changes the name of the cluster member with the given name.
+func (c *Client) RenameClusterMember(ctx context.Context, name string, newName string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "POST", PublicEndpoint, api.NewURL().Path("cluster", name), types.ClusterMemberRename{Name: newName}, nil)
+}
+
 // ResetClusterMember clears the state directory of the cluster member, and re-execs its daemon.
 func (c *Client) ResetClusterMember(ctx context.Context, name string, force bool) error {
 	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
@@ -58,3 +106,132 @@ func (c *Client) ResetClusterMember(ctx context.Context, name string, force bool
 
 	return c.QueryStruct(queryCtx, "PUT", PublicEndpoint, endpoint, nil, nil)
 }
+
+// GetClusterMemberConfig returns the user metadata of the cluster member with the given name.
+func (c *Client) GetClusterMemberConfig(ctx context.Context, name string) (map[string]string, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	config := types.ClusterMemberConfig{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("cluster", name, "config"), nil, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.Config, nil
+}
+
+// UpdateClusterMemberConfig replaces the user metadata of the cluster member with the given name.
+func (c *Client) UpdateClusterMemberConfig(ctx context.Context, name string, config map[string]string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "PUT", PublicEndpoint, api.NewURL().Path("cluster", name, "config"), types.ClusterMemberConfig{Config: config}, nil)
+}
+
+// PatchClusterMemberConfig merges the given keys into the user metadata of the cluster member with the given name.
+// Keys with empty values are removed.
+func (c *Client) PatchClusterMemberConfig(ctx context.Context, name string, config map[string]string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "PATCH", PublicEndpoint, api.NewURL().Path("cluster", name, "config"), types.ClusterMemberConfig{Config: config}, nil)
+}
+
+// GetTrustStore returns the entries in the local trust store of the cluster member.
+func (c *Client) GetTrustStore(ctx context.Context) ([]types.ClusterMemberLocal, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	entries := []types.ClusterMemberLocal{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("truststore"), nil, &entries)
+
+	return entries, err
+}
+
+// GetCertificates returns every certificate with access to the cluster, sorted by type, then by name.
+func (c *Client) GetCertificates(ctx context.Context) ([]types.TrustedCertificate, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	certs := []types.TrustedCertificate{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("certificates"), nil, &certs)
+
+	return certs, err
+}
+
+// GetMemberTrustStore returns the entries in the local trust store of the cluster member, even if its database has
+// not started yet.
+func (c *Client) GetMemberTrustStore(ctx context.Context) ([]types.ClusterMemberLocal, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	entries := []types.ClusterMemberLocal{}
+	err := c.QueryStruct(queryCtx, "GET", InternalEndpoint, api.NewURL().Path("truststore"), nil, &entries)
+
+	return entries, err
+}
+
+// CheckTrustStore compares the trust store of the cluster member against the cluster members in the database.
+// If all is true, the trust stores of all cluster members are checked.
+func (c *Client) CheckTrustStore(ctx context.Context, all bool) ([]types.TrustStoreCheck, error) {
+	return c.checkTrustStore(ctx, "GET", all)
+}
+
+// RepairTrustStore replaces the trust store of the cluster member with the cluster members in the database, if it
+// differs. If all is true, the trust stores of all cluster members are repaired.
+func (c *Client) RepairTrustStore(ctx context.Context, all bool) ([]types.TrustStoreCheck, error) {
+	return c.checkTrustStore(ctx, "POST", all)
+}
+
+func (c *Client) checkTrustStore(ctx context.Context, method string, all bool) ([]types.TrustStoreCheck, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	endpoint := api.NewURL().Path("truststore", "check")
+	if all {
+		endpoint = endpoint.WithQuery("all", "1")
+	}
+
+	checks := []types.TrustStoreCheck{}
+	err := c.QueryStruct(queryCtx, method, InternalEndpoint, endpoint, nil, &checks)
+	if err != nil {
+		return nil, err
+	}
+
+	return checks, nil
+}
+
+// UpdateTrustStore replaces the local trust store of the cluster member with the given full list of cluster members.
+func (c *Client) UpdateTrustStore(ctx context.Context, members []types.ClusterMemberLocal) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "PUT", InternalEndpoint, api.NewURL().Path("truststore"), members, nil)
+}
+
+// UpdateClusterMemberCordon cordons the cluster member with the given name for maintenance, or uncordons it.
+func (c *Client) UpdateClusterMemberCordon(ctx context.Context, name string, cordoned bool) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "PUT", PublicEndpoint, api.NewURL().Path("cluster", name, "cordon"), types.ClusterMemberCordon{Cordoned: cordoned}, nil)
+}
+
+// UpdateClusterMemberCertificate replaces the server certificate of the cluster member with the given name in the
+// database and in the trust store of every cluster member. The request must be signed by the key of the new certificate.
+func (c *Client) UpdateClusterMemberCertificate(ctx context.Context, name string, req types.ClusterMemberCertificate) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "PUT", PublicEndpoint, api.NewURL().Path("cluster", name, "certificate"), req, nil)
+}
+
+// UpdateClusterMemberRole requests the promotion or demotion of the cluster member with the given name to the given
+// dqlite role.
+func (c *Client) UpdateClusterMemberRole(ctx context.Context, name string, role string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "PUT", PublicEndpoint, api.NewURL().Path("cluster", name, "role"), types.ClusterMemberRole{Role: role}, nil)
+}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetCompatibility returns the compatibility information of the cluster member.
+// If all is true, the compatibility information of all cluster members is returned.
+func (c *Client) GetCompatibility(ctx context.Context, all bool) ([]types.Compatibility, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	endpoint := api.NewURL().Path("compatibility")
+	if all {
+		endpoint = endpoint.WithQuery("all", "1")
+	}
+
+	compat := []types.Compatibility{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, endpoint, nil, &compat)
+
+	return compat, err
+}
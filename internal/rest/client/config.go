@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetClusterConfig returns the cluster-wide key/value config.
+func (c *Client) GetClusterConfig(ctx context.Context) (map[string]string, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	config := types.ClusterConfig{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("config"), nil, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	return config.Config, nil
+}
+
+// UpdateClusterConfig replaces the cluster-wide key/value config.
+func (c *Client) UpdateClusterConfig(ctx context.Context, config map[string]string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "PUT", PublicEndpoint, api.NewURL().Path("config"), types.ClusterConfig{Config: config}, nil)
+}
+
+// PatchClusterConfig merges the given keys into the cluster-wide key/value config. Keys with empty values are removed.
+func (c *Client) PatchClusterConfig(ctx context.Context, config map[string]string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "PATCH", PublicEndpoint, api.NewURL().Path("config"), types.ClusterConfig{Config: config}, nil)
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+)
+
+// NotifyUpgraded notifies the cluster member that another cluster member has been upgraded, so that it can stop
+// waiting for the rest of the cluster if it is blocked on a schema upgrade. If the cluster member responded with an
+// error, the returned error is an api.StatusError.
+func (c *Client) NotifyUpgraded(ctx context.Context) error {
+	path := c.URL()
+	parts := strings.Split(string(InternalEndpoint), "/")
+	parts = append(parts, "database")
+	path = *path.Path(parts...)
+	upgradeRequest, err := http.NewRequestWithContext(ctx, "PATCH", path.String(), nil)
+	if err != nil {
+		return err
+	}
+
+	upgradeRequest.Header.Set("X-Dqlite-Version", fmt.Sprintf("%d", 1))
+
+	resp, err := c.Do(upgradeRequest)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	_, err = io.Copy(io.Discard, resp.Body)
+	if err != nil {
+		logger.Error("Failed to read upgrade notification response body", logger.Ctx{"error": err})
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return api.StatusErrorf(resp.StatusCode, "Database upgrade notification failed: %s", resp.Status)
+	}
+
+	return nil
+}
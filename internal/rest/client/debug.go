@@ -0,0 +1,18 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// SimulateFailure triggers the given failure scenario on the cluster member. The debug endpoints must be enabled.
+func (c *Client) SimulateFailure(ctx context.Context, failure types.SimulateFailure) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "POST", ControlEndpoint, api.NewURL().Path("debug", "failure"), failure, nil)
+}
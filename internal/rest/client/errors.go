@@ -0,0 +1,104 @@
+package client
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/go-dqlite/driver"
+	"github.com/canonical/lxd/shared/api"
+
+	apiTypes "github.com/canonical/microcluster/rest/types"
+)
+
+var (
+	// ErrNotFound is matched by errors for requests to resources that do not exist.
+	ErrNotFound = errors.New("Not found")
+
+	// ErrNotTrusted is matched by errors for requests whose certificate is not trusted by the cluster member.
+	ErrNotTrusted = errors.New("Not trusted")
+
+	// ErrQuorumLost is matched by errors for requests that failed because the cluster has no dqlite leader, usually
+	// because too many voters are offline.
+	ErrQuorumLost = errors.New("Quorum lost")
+
+	// ErrNotLeader is matched by errors for requests that must be made to the dqlite leader.
+	ErrNotLeader = errors.New("Not leader")
+
+	// ErrSchemaMismatch is matched by errors for requests that failed because cluster members have different schema
+	// versions, such as during an upgrade.
+	ErrSchemaMismatch = errors.New("Schema mismatch")
+
+	// ErrAlreadyMember is matched by errors for joins whose name, address or certificate is already in use by another
+	// cluster member.
+	ErrAlreadyMember = errors.New("Already a member")
+
+	// ErrNotInitialized is matched by errors for requests made before the daemon has joined a cluster.
+	ErrNotInitialized = errors.New("Not initialized")
+
+	// ErrShuttingDown is matched by errors for requests made while the daemon is shutting down.
+	ErrShuttingDown = errors.New("Shutting down")
+
+	// ErrRateLimited is matched by errors for requests rejected for exceeding the rate limit.
+	ErrRateLimited = errors.New("Rate limited")
+)
+
+// errorCodes maps the errors matched by Error to the error code the daemon sends for them.
+var errorCodes = map[error]apiTypes.ErrorCode{
+	ErrNotFound:       apiTypes.ErrorCodeNotFound,
+	ErrNotTrusted:     apiTypes.ErrorCodeNotTrusted,
+	ErrQuorumLost:     apiTypes.ErrorCodeQuorumLost,
+	ErrNotLeader:      apiTypes.ErrorCodeNotLeader,
+	ErrSchemaMismatch: apiTypes.ErrorCodeSchemaMismatch,
+	ErrAlreadyMember:  apiTypes.ErrorCodeAlreadyMember,
+	ErrNotInitialized: apiTypes.ErrorCodeNotInitialized,
+	ErrShuttingDown:   apiTypes.ErrorCodeShuttingDown,
+	ErrRateLimited:    apiTypes.ErrorCodeRateLimited,
+}
+
+// Error is an error response from the daemon. It matches the errors above with errors.Is according to its error code,
+// or for daemons that do not send one, its status code and message. It unwraps to an api.StatusError.
+type Error struct {
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+
+	// Code is the machine-readable error code sent by the daemon, if any.
+	Code apiTypes.ErrorCode
+
+	// Message is the error message sent by the daemon.
+	Message string
+}
+
+// newError returns an Error for the given status code and message.
+func newError(statusCode int, message string) *Error {
+	return &Error{StatusCode: statusCode, Message: message}
+}
+
+// Error returns the error message sent by the daemon.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the error as an api.StatusError.
+func (e *Error) Unwrap() error {
+	return api.StatusErrorf(e.StatusCode, "%s", e.Message)
+}
+
+// Is returns whether the error is of the kind given by one of the errors above.
+func (e *Error) Is(target error) bool {
+	code, ok := errorCodes[target]
+	if ok && e.Code == code {
+		return true
+	}
+
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrNotTrusted:
+		return e.StatusCode == http.StatusForbidden
+	case ErrQuorumLost:
+		return strings.Contains(e.Message, driver.ErrNoAvailableLeader.Error())
+	}
+
+	return false
+}
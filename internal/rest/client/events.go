@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetEvents returns the cluster membership event history, oldest first, optionally filtered by event type, cluster
+// member, and to the events recorded after the given time.
+func (c *Client) GetEvents(ctx context.Context, eventType string, member string, since time.Time) ([]types.Event, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	endpoint := api.NewURL().Path("events")
+	if eventType != "" {
+		endpoint = endpoint.WithQuery("type", eventType)
+	}
+
+	if member != "" {
+		endpoint = endpoint.WithQuery("member", member)
+	}
+
+	if !since.IsZero() {
+		endpoint = endpoint.WithQuery("since", since.UTC().Format(time.RFC3339Nano))
+	}
+
+	events := []types.Event{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, endpoint, nil, &events)
+
+	return events, err
+}
+
+// WatchEvents streams the events recorded by any cluster member from now on, optionally filtered by event types and
+// cluster member, and passes each of them to the handler. It returns once the context is cancelled, the handler returns
+// an error, or the connection fails.
+func (c *Client) WatchEvents(ctx context.Context, eventTypes []string, member string, handler func(event types.Event) error) error {
+	query := url.Values{}
+	for _, eventType := range eventTypes {
+		query.Add("type", eventType)
+	}
+
+	if member != "" {
+		query.Set("member", member)
+	}
+
+	endpoint := api.NewURL().Path("events")
+	endpoint.URL.RawQuery = query.Encode()
+
+	conn, err := c.WebSocket(ctx, PublicEndpoint, endpoint)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	// Close the connection to stop waiting for events once the context is cancelled.
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
+	for {
+		event := types.Event{}
+		err := conn.ReadJSON(&event)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("Failed to read event: %w", err)
+		}
+
+		err = handler(event)
+		if err != nil {
+			return err
+		}
+	}
+}
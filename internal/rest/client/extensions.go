@@ -0,0 +1,21 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetAPIExtensions returns the API extensions supported by each cluster member, and those supported by all of them.
+func (c *Client) GetAPIExtensions(ctx context.Context) (*types.APIExtensions, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	extensions := types.APIExtensions{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("extensions"), nil, &extensions)
+
+	return &extensions, err
+}
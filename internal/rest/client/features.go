@@ -0,0 +1,37 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetFeatureFlags returns all cluster-wide feature flags.
+func (c *Client) GetFeatureFlags(ctx context.Context) ([]types.FeatureFlag, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	flags := []types.FeatureFlag{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("features"), nil, &flags)
+
+	return flags, err
+}
+
+// UpdateFeatureFlag creates or updates the feature flag with the name given in the request.
+func (c *Client) UpdateFeatureFlag(ctx context.Context, flag types.FeatureFlag) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "PUT", PublicEndpoint, api.NewURL().Path("features", flag.Name), flag, nil)
+}
+
+// DeleteFeatureFlag deletes the feature flag with the given name.
+func (c *Client) DeleteFeatureFlag(ctx context.Context, name string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "DELETE", PublicEndpoint, api.NewURL().Path("features", name), nil, nil)
+}
@@ -0,0 +1,21 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetLeader returns the name and address of the cluster member currently holding dqlite leadership.
+func (c *Client) GetLeader(ctx context.Context) (*types.Leader, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	leader := types.Leader{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("leader"), nil, &leader)
+
+	return &leader, err
+}
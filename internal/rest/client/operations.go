@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetOperations returns the operation history, optionally filtered by status.
+func (c *Client) GetOperations(ctx context.Context, status string) ([]types.Operation, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	endpoint := api.NewURL().Path("operations")
+	if status != "" {
+		endpoint = endpoint.WithQuery("status", status)
+	}
+
+	operations := []types.Operation{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, endpoint, nil, &operations)
+
+	return operations, err
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetProjects returns all projects.
+func (c *Client) GetProjects(ctx context.Context) ([]types.Project, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	projects := []types.Project{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("projects"), nil, &projects)
+
+	return projects, err
+}
+
+// GetProject returns the project with the given name.
+func (c *Client) GetProject(ctx context.Context, name string) (*types.Project, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	project := &types.Project{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("projects", name), nil, project)
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// CreateProject creates a new project.
+func (c *Client) CreateProject(ctx context.Context, project types.Project) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "POST", PublicEndpoint, api.NewURL().Path("projects"), project, nil)
+}
+
+// UpdateProject replaces the description and config of the project with the given name.
+func (c *Client) UpdateProject(ctx context.Context, name string, project types.ProjectPut) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "PUT", PublicEndpoint, api.NewURL().Path("projects", name), project, nil)
+}
+
+// DeleteProject deletes the project with the given name.
+func (c *Client) DeleteProject(ctx context.Context, name string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "DELETE", PublicEndpoint, api.NewURL().Path("projects", name), nil, nil)
+}
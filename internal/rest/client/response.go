@@ -6,6 +6,8 @@ import (
 	"net/http"
 
 	"github.com/canonical/lxd/shared/api"
+
+	apiTypes "github.com/canonical/microcluster/rest/types"
 )
 
 func parseResponse(resp *http.Response) (*api.Response, error) {
@@ -17,7 +19,7 @@ func parseResponse(resp *http.Response) (*api.Response, error) {
 	if err != nil {
 		// Check the return value for a cleaner error
 		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("Failed to fetch %q: %q", resp.Request.URL.String(), resp.Status)
+			return nil, newError(resp.StatusCode, fmt.Sprintf("Failed to fetch %q: %q", resp.Request.URL.String(), resp.Status))
 		}
 
 		return nil, err
@@ -25,7 +27,15 @@ func parseResponse(resp *http.Response) (*api.Response, error) {
 
 	// Handle errors
 	if response.Type == api.ErrorResponse {
-		return nil, api.StatusErrorf(resp.StatusCode, response.Error)
+		err := newError(resp.StatusCode, response.Error)
+
+		// Error responses carry an error code in their metadata, if the daemon set one.
+		metadata := apiTypes.ErrorMetadata{}
+		if response.MetadataAsStruct(&metadata) == nil {
+			err.Code = metadata.Code
+		}
+
+		return nil, err
 	}
 
 	return &response, nil
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetSecrets returns all cluster-wide secrets, without their values.
+func (c *Client) GetSecrets(ctx context.Context) ([]types.Secret, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	secrets := []types.Secret{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("secrets"), nil, &secrets)
+
+	return secrets, err
+}
+
+// GetSecret returns the cluster-wide secret with the given name, including its value.
+func (c *Client) GetSecret(ctx context.Context, name string) (*types.Secret, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	secret := &types.Secret{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("secrets", name), nil, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// UpdateSecret sets the value of the cluster-wide secret with the given name, creating it if it does not exist.
+func (c *Client) UpdateSecret(ctx context.Context, name string, value string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "PUT", PublicEndpoint, api.NewURL().Path("secrets", name), types.SecretPut{Value: value}, nil)
+}
+
+// DeleteSecret removes the cluster-wide secret with the given name.
+func (c *Client) DeleteSecret(ctx context.Context, name string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "DELETE", PublicEndpoint, api.NewURL().Path("secrets", name), nil, nil)
+}
@@ -0,0 +1,19 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetServer returns the status information of the cluster member.
+func (c *Client) GetServer(ctx context.Context) (*types.Server, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	server := types.Server{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, nil, nil, &server)
+
+	return &server, err
+}
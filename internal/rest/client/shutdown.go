@@ -14,3 +14,20 @@ func (c *Client) ShutdownDaemon(ctx context.Context) error {
 
 	return c.QueryStruct(queryCtx, "POST", ControlEndpoint, api.NewURL().Path("shutdown"), nil, nil)
 }
+
+// ShutdownCluster gracefully stops every cluster member, with the dqlite leader stopped last.
+func (c *Client) ShutdownCluster(ctx context.Context) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "POST", PublicEndpoint, api.NewURL().Path("shutdown"), nil, nil)
+}
+
+// ShutdownClusterMember stops the cluster member as part of a cluster-wide shutdown. The client must be created as a
+// notifier so that the request is not fanned out again.
+func (c *Client) ShutdownClusterMember(ctx context.Context) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "POST", PublicEndpoint, api.NewURL().Path("shutdown"), nil, nil)
+}
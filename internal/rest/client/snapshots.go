@@ -0,0 +1,30 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetSnapshot returns the most recent snapshot of the cluster membership and configuration taken at or before the
+// given time. If the time is zero, the current state is returned.
+func (c *Client) GetSnapshot(ctx context.Context, at time.Time) (*types.Snapshot, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	endpoint := api.NewURL().Path("snapshot")
+	if !at.IsZero() {
+		endpoint = endpoint.WithQuery("at", at.Format(time.RFC3339))
+	}
+
+	snapshot := types.Snapshot{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, endpoint, nil, &snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
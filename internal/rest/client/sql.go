@@ -42,3 +42,23 @@ func (c *Client) PostSQL(ctx context.Context, query types.SQLQuery) (*types.SQLB
 
 	return batch, nil
 }
+
+// CheckDatabaseIntegrity runs an integrity check against the databases of the cluster member.
+// If all is true, the check is run on all cluster members.
+func (c *Client) CheckDatabaseIntegrity(ctx context.Context, all bool) ([]types.IntegrityCheck, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	endpoint := api.NewURL().Path("database", "integrity")
+	if all {
+		endpoint = endpoint.WithQuery("all", "1")
+	}
+
+	checks := []types.IntegrityCheck{}
+	err := c.QueryStruct(reqCtx, "GET", InternalEndpoint, endpoint, nil, &checks)
+	if err != nil {
+		return nil, err
+	}
+
+	return checks, nil
+}
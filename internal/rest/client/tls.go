@@ -1,17 +1,33 @@
 package client
 
 import (
+	"bytes"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"sync"
 
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/internal/ca"
+	"github.com/canonical/microcluster/internal/cryptopolicy"
+	"github.com/canonical/microcluster/internal/sys"
 )
 
+// MemberServerName is the TLS server name with which a cluster member is asked to present its own server certificate
+// rather than the cluster certificate, so that it can be told apart from other holders of the cluster key.
+const MemberServerName = "microcluster-member"
+
+// Insecure disables verification of the certificates presented by remotes to the clients of the daemon, and of
+// certificates presented to its network endpoints against the external certificate authority. It is only set when
+// the daemon starts, and is only meant for local development.
+var Insecure bool
+
 // TLSClientConfig returns a TLS configuration suitable for establishing horizontal and vertical connections.
 // clientCert contains the private key pair for the client. remoteCert is the public
 // key of the server we are connecting to.
-func TLSClientConfig(clientCert *shared.CertInfo, remoteCert *x509.Certificate) (*tls.Config, error) {
+func TLSClientConfig(clientCert *sys.CertInfo, remoteCert *x509.Certificate) (*tls.Config, error) {
 	if clientCert == nil {
 		return nil, fmt.Errorf("Invalid client certificate")
 	}
@@ -23,6 +39,7 @@ func TLSClientConfig(clientCert *shared.CertInfo, remoteCert *x509.Certificate)
 	keypair := clientCert.KeyPair()
 	config := shared.InitTLSConfig()
 	config.Certificates = []tls.Certificate{keypair}
+	cryptopolicy.ApplyTLS(config)
 
 	// Add the public key to the CA pool to make it trusted.
 	config.RootCAs = x509.NewCertPool()
@@ -35,5 +52,165 @@ func TLSClientConfig(clientCert *shared.CertInfo, remoteCert *x509.Certificate)
 		config.ServerName = remoteCert.DNSNames[0]
 	}
 
-	return config, nil
+	// Certificates issued by an external certificate authority are validated against it instead of the remote
+	// certificate, as each remote may present a different certificate.
+	authority := ca.Current()
+	if authority != nil {
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return fmt.Errorf("Remote did not present a certificate")
+			}
+
+			chain := make([]*x509.Certificate, 0, len(rawCerts))
+			for _, raw := range rawCerts {
+				cert, err := x509.ParseCertificate(raw)
+				if err != nil {
+					return fmt.Errorf("Failed to parse remote certificate: %w", err)
+				}
+
+				chain = append(chain, cert)
+			}
+
+			return authority.Verify(chain)
+		}
+
+		return insecureConfig(config), nil
+	}
+
+	// If another certificate is accepted in place of the remote certificate, the certificates may have different DNS
+	// names, so the presented certificate is compared with both directly instead.
+	alternate := AlternateRemoteCert(remoteCert)
+	if alternate != nil {
+		accepted := []*x509.Certificate{remoteCert, alternate}
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return fmt.Errorf("Remote did not present a certificate")
+			}
+
+			for _, cert := range accepted {
+				if bytes.Equal(rawCerts[0], cert.Raw) {
+					return nil
+				}
+			}
+
+			return fmt.Errorf("Remote certificate %q is not trusted", shared.CertFingerprint(remoteCert))
+		}
+	}
+
+	return insecureConfig(config), nil
+}
+
+// insecureConfig disables verification of remote certificates in the given TLS configuration if Insecure is set. A
+// warning is logged for every remote certificate that would otherwise have been rejected.
+func insecureConfig(config *tls.Config) *tls.Config {
+	if !Insecure {
+		return config
+	}
+
+	verify := peerVerifier(config)
+	config.InsecureSkipVerify = true
+	config.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		var err error
+		if verify != nil {
+			err = verify(rawCerts, verifiedChains)
+		}
+
+		if err != nil {
+			logger.Warn("INSECURE: Accepting untrusted remote certificate", logger.Ctx{"error": err})
+		}
+
+		return nil
+	}
+
+	return config
+}
+
+// PinMemberCert requires the remote to present the given server certificate of the cluster member instead of the
+// cluster certificate, by requesting it with the MemberServerName. If pinned returns false, as the cluster member has
+// not yet been seen to serve its own certificate, the cluster certificate is still accepted in its place. Once the
+// member certificate is accepted, pin is called.
+func PinMemberCert(config *tls.Config, memberCert *x509.Certificate, pinned func() bool, pin func()) *tls.Config {
+	verify := peerVerifier(config)
+	config.ServerName = MemberServerName
+	config.InsecureSkipVerify = true
+	config.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("Remote did not present a certificate")
+		}
+
+		if bytes.Equal(rawCerts[0], memberCert.Raw) {
+			pin()
+			return nil
+		}
+
+		if pinned() {
+			return fmt.Errorf("Remote certificate does not match the certificate %q of the cluster member", shared.CertFingerprint(memberCert))
+		}
+
+		// Cluster members that do not serve their own certificate yet present the cluster certificate.
+		if verify == nil {
+			return nil
+		}
+
+		return verify(rawCerts, verifiedChains)
+	}
+
+	return config
+}
+
+// peerVerifier returns a function that verifies remote certificates as the given TLS configuration does, for use by
+// a VerifyPeerCertificate function that replaces the default verification. It returns nil if the configuration does
+// not verify remote certificates.
+func peerVerifier(config *tls.Config) func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+	if config.InsecureSkipVerify {
+		return config.VerifyPeerCertificate
+	}
+
+	roots := config.RootCAs
+	serverName := config.ServerName
+
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("Remote did not present a certificate")
+		}
+
+		cert, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return fmt.Errorf("Failed to parse remote certificate: %w", err)
+		}
+
+		_, err = cert.Verify(x509.VerifyOptions{Roots: roots, DNSName: serverName})
+
+		return err
+	}
+}
+
+// alternateRemoteCerts maps the fingerprint of a remote certificate to another certificate that is also accepted in
+// its place, such as while the cluster certificate is being rotated.
+var alternateRemoteCerts = map[string]*x509.Certificate{}
+var alternateRemoteCertsMu sync.RWMutex
+
+// SetAlternateRemoteCert sets another certificate to accept from remotes in place of the given remote certificate. If
+// the alternate certificate is nil, only the remote certificate is accepted.
+func SetAlternateRemoteCert(remoteCert *x509.Certificate, alternate *x509.Certificate) {
+	alternateRemoteCertsMu.Lock()
+	defer alternateRemoteCertsMu.Unlock()
+
+	fingerprint := shared.CertFingerprint(remoteCert)
+	if alternate == nil {
+		delete(alternateRemoteCerts, fingerprint)
+	} else {
+		alternateRemoteCerts[fingerprint] = alternate
+	}
+}
+
+// AlternateRemoteCert returns the certificate accepted from remotes in place of the given remote certificate, or nil
+// if there is none.
+func AlternateRemoteCert(remoteCert *x509.Certificate) *x509.Certificate {
+	alternateRemoteCertsMu.RLock()
+	defer alternateRemoteCertsMu.RUnlock()
+
+	return alternateRemoteCerts[shared.CertFingerprint(remoteCert)]
 }
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetTrustAudit returns the trail of changes to the set of trusted certificates, oldest first, optionally filtered by
+// the name of the cluster member or certificate, and to the changes recorded after the given time.
+func (c *Client) GetTrustAudit(ctx context.Context, name string, since time.Time) ([]types.TrustAudit, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	endpoint := api.NewURL().Path("trust-audit")
+	if name != "" {
+		endpoint = endpoint.WithQuery("name", name)
+	}
+
+	if !since.IsZero() {
+		endpoint = endpoint.WithQuery("since", since.UTC().Format(time.RFC3339Nano))
+	}
+
+	audits := []types.TrustAudit{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, endpoint, nil, &audits)
+
+	return audits, err
+}
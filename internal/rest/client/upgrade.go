@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// StartUpgrade starts a rolling upgrade of the cluster, coordinated by this cluster member.
+func (c *Client) StartUpgrade(ctx context.Context) error {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "POST", PublicEndpoint, api.NewURL().Path("upgrade"), nil, nil)
+}
+
+// GetUpgrade returns the progress of the last rolling upgrade coordinated by this cluster member.
+func (c *Client) GetUpgrade(ctx context.Context) (*types.Upgrade, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	upgrade := types.Upgrade{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("upgrade"), nil, &upgrade)
+
+	return &upgrade, err
+}
+
+// UpgradeMember runs the upgrade hook on the cluster member. The hook may restart the daemon, in which case the
+// request fails without a response.
+func (c *Client) UpgradeMember(ctx context.Context, timeout time.Duration) error {
+	queryCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return c.QueryStruct(queryCtx, "POST", InternalEndpoint, api.NewURL().Path("upgrade"), nil, nil)
+}
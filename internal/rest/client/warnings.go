@@ -0,0 +1,22 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/internal/rest/types"
+)
+
+// GetWarnings returns the conditions of the cluster that need the attention of an operator, such as certificates that
+// are about to expire.
+func (c *Client) GetWarnings(ctx context.Context) ([]types.Warning, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	warnings := []types.Warning{}
+	err := c.QueryStruct(queryCtx, "GET", PublicEndpoint, api.NewURL().Path("warnings"), nil, &warnings)
+
+	return warnings, err
+}
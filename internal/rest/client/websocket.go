@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/shared/api"
+	"github.com/gorilla/websocket"
+)
+
+// webSocketHandshakeTimeout is how long the WebSocket handshake may take.
+const webSocketHandshakeTimeout = 30 * time.Second
+
+// WebSocket connects to the WebSocket served by the provided endpoint on the API matching the endpointType, using the
+// same connection settings as other requests of the client.
+func (c *Client) WebSocket(ctx context.Context, endpointType EndpointType, endpoint *api.URL) (*websocket.Conn, error) {
+	transport, ok := c.Transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("Client does not support WebSocket connections")
+	}
+
+	localURL := api.NewURL()
+	if endpoint != nil {
+		newURL := *endpoint
+		localURL = &newURL
+	}
+
+	localURL.URL.Host = c.url.URL.Host
+	localURL.URL.Scheme = "ws"
+	if c.url.URL.Scheme == "https" {
+		localURL.URL.Scheme = "wss"
+	}
+
+	localURL.URL.Path = "/" + string(endpointType) + localURL.URL.Path
+
+	localQuery := localURL.URL.Query()
+	clientQuery := c.url.URL.Query()
+	for k, values := range localQuery {
+		clientQuery[k] = values
+	}
+
+	localURL.URL.RawQuery = clientQuery.Encode()
+
+	dialer := websocket.Dialer{
+		NetDialContext:    transport.DialContext,
+		NetDialTLSContext: transport.DialTLSContext,
+		HandshakeTimeout:  webSocketHandshakeTimeout,
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, localURL.String(), nil)
+	if err != nil {
+		// If the daemon refused the upgrade, return its error instead.
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			defer resp.Body.Close()
+			_, parseErr := parseResponse(resp)
+			if parseErr != nil {
+				return nil, parseErr
+			}
+		}
+
+		return nil, fmt.Errorf("Failed to connect to WebSocket %q: %w", localURL.String(), err)
+	}
+
+	return conn, nil
+}
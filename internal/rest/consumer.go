@@ -0,0 +1,21 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+)
+
+// consumerCtxKey marks the context of requests received on the consumer API listener.
+type consumerCtxKey struct{}
+
+// ConsumerContext returns a context for connections accepted by the consumer API listener.
+func ConsumerContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, consumerCtxKey{}, true)
+}
+
+// consumerRequest returns whether the request was received on the consumer API listener.
+func consumerRequest(r *http.Request) bool {
+	consumer, _ := r.Context().Value(consumerCtxKey{}).(bool)
+
+	return consumer
+}
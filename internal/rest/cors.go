@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// CORSAllowedOrigins are the origins of browser-based clients, such as web UIs, allowed to call the consumer API
+// cross-origin. Only listed origins may send credentials, such as client certificates. A "*" allows any other origin to
+// make requests without credentials. If empty, no CORS headers are sent.
+var CORSAllowedOrigins []string
+
+// CORSAllowedMethods are the HTTP methods allowed in cross-origin requests.
+var CORSAllowedMethods = []string{"GET", "PUT", "POST", "DELETE", "PATCH"}
+
+// CORSAllowedHeaders are the request headers allowed in cross-origin requests.
+var CORSAllowedHeaders = []string{"Authorization", "Content-Type"}
+
+// corsMaxAge is how long in seconds browsers may cache the result of a preflight request.
+const corsMaxAge = 3600
+
+// CORS is the middleware of the consumer API endpoints. It sends CORS headers to browser-based clients from
+// CORSAllowedOrigins, and answers their preflight requests. Requests from other origins are passed on without CORS
+// headers, so browsers will not expose the responses to them.
+func CORS(handler http.Handler) http.Handler {
+	if len(CORSAllowedOrigins) == 0 {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !corsOriginAllowed(origin) {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		if shared.ValueInSlice(origin, CORSAllowedOrigins) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		} else {
+			// Browsers do not send credentials to, or expose credentialed responses from, a wildcard origin.
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		}
+
+		// Answer preflight requests without passing them on, as endpoints do not implement OPTIONS.
+		if r.Method == "OPTIONS" && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(CORSAllowedMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(CORSAllowedHeaders, ", "))
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// corsOriginAllowed returns whether the given origin is in CORSAllowedOrigins.
+func corsOriginAllowed(origin string) bool {
+	return shared.ValueInSlice("*", CORSAllowedOrigins) || shared.ValueInSlice(origin, CORSAllowedOrigins)
+}
@@ -0,0 +1,46 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/rest"
+)
+
+// Deprecation returns middleware that marks the responses of a deprecated endpoint with the Deprecation header, and
+// with the Sunset header if the time after which the endpoint may be removed is known.
+func Deprecation(sunset time.Time) rest.Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			if !sunset.IsZero() {
+				w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DeprecatedAlias returns middleware that marks the responses of a deprecated alias of an endpoint with the Deprecation
+// header, and a Warning header naming the endpoint path to use instead. Each use of the alias is logged.
+func DeprecatedAlias(version string, aliasPath string, endpointPath string) rest.Middleware {
+	alias := path.Join("/", version, aliasPath)
+	endpoint := path.Join("/", version, endpointPath)
+	warning := fmt.Sprintf("299 - %q", fmt.Sprintf("%s is deprecated, use %s instead", alias, endpoint))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logger.Warn("Deprecated endpoint alias used", logger.Ctx{"alias": alias, "endpoint": endpoint, "remote": r.RemoteAddr, "method": r.Method})
+
+			w.Header().Set("Deprecation", "true")
+			w.Header().Add("Warning", warning)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package rest
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/response"
+
+	internalState "github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+	"github.com/canonical/microcluster/rest/types"
+)
+
+// MaxRequestBodySize is the maximum size in bytes of the body of a request. Larger requests are rejected with 413
+// Request Entity Too Large. Zero means no limit.
+var MaxRequestBodySize int64 = 32 * 1024 * 1024
+
+// HandlerTimeout is how long an endpoint handler may take to return its response before the request is answered with
+// 503 Service Unavailable. Zero means no limit.
+var HandlerTimeout time.Duration
+
+// errBodyTooLarge is returned when reading past MaxRequestBodySize.
+var errBodyTooLarge = fmt.Errorf("Request body too large")
+
+// limitedBody is a request body that can not be read past a size limit, and records whether the limit was exceeded.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
+// Read reads from the request body, returning errBodyTooLarge if the body is larger than the limit.
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, errBodyTooLarge
+	}
+
+	// Read one byte past the limit, to tell a body of exactly the limit apart from a larger one.
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) <= b.remaining {
+		b.remaining -= int64(n)
+		return n, err
+	}
+
+	n = int(b.remaining)
+	b.remaining = 0
+	b.exceeded = true
+
+	return n, errBodyTooLarge
+}
+
+// limitBody limits the size of the body of the request to MaxRequestBodySize. It returns a 413 response if the
+// request declares a larger body up front, and otherwise the wrapped body so the handler's response can be replaced if
+// it reads past the limit.
+func limitBody(r *http.Request) (*limitedBody, response.Response) {
+	if MaxRequestBodySize <= 0 || r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	if r.ContentLength > MaxRequestBodySize {
+		return nil, response.ErrorResponse(http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body is larger than %d bytes", MaxRequestBodySize))
+	}
+
+	body := &limitedBody{ReadCloser: r.Body, remaining: MaxRequestBodySize}
+	r.Body = body
+
+	return body, nil
+}
+
+// runHandler runs the handler of an endpoint with the request limits applied. The handler is abandoned once the
+// request context is done, which is after HandlerTimeout, or when the daemon shuts down unless the endpoint is allowed
+// during shutdown. The database endpoint hijacks its connection, so it is run without limits. Abandoned handlers are
+// given a detached response writer, so that they never touch the response once it has been rendered.
+func runHandler(state *internalState.State, e rest.Endpoint, w http.ResponseWriter, r *http.Request, handle func(w http.ResponseWriter, r *http.Request) response.Response) response.Response {
+	if e.Path == "database" {
+		return recoverRequest(state, w, r, func() response.Response { return handle(w, r) })
+	}
+
+	body, resp := limitBody(r)
+	if resp != nil {
+		return resp
+	}
+
+	shutdown := state.Context.Done()
+	if e.AllowedDuringShutdown {
+		shutdown = nil
+	}
+
+	var timeout <-chan struct{}
+	if HandlerTimeout > 0 {
+		timeout = r.Context().Done()
+	}
+
+	if timeout == nil && shutdown == nil {
+		return checkBody(body, recoverRequest(state, w, r, func() response.Response { return handle(w, r) }))
+	}
+
+	type result struct {
+		resp  response.Response
+		abort any
+	}
+
+	detached := &detachedWriter{header: http.Header{}}
+	done := make(chan result, 1)
+	go func() {
+		var res result
+		defer func() {
+			// Pass on aborts to the HTTP server, which expects them on the goroutine serving the request.
+			res.abort = recover()
+			done <- res
+		}()
+
+		res.resp = recoverRequest(state, detached, r, func() response.Response { return handle(detached, r) })
+	}()
+
+	select {
+	case res := <-done:
+		if res.abort != nil {
+			panic(res.abort)
+		}
+
+		for key, values := range detached.header {
+			w.Header()[key] = values
+		}
+
+		return checkBody(body, res.resp)
+	case <-timeout:
+		return response.Unavailable(fmt.Errorf("Request timed out after %s", HandlerTimeout))
+	case <-shutdown:
+		setRetryAfter(w)
+		return rest.ErrorResponse(http.StatusServiceUnavailable, types.ErrorCodeShuttingDown, "Daemon is shutting down")
+	}
+}
+
+// detachedWriter is the response writer of handlers run on their own goroutine. It only collects headers, which are
+// copied to the response if the handler returns in time, as the response is only written once it is rendered.
+type detachedWriter struct {
+	header http.Header
+}
+
+// Header returns the headers collected from the handler.
+func (w *detachedWriter) Header() http.Header {
+	return w.header
+}
+
+// Write fails, as the response body is only written once the response is rendered.
+func (w *detachedWriter) Write(b []byte) (int, error) {
+	return 0, fmt.Errorf("Response body can only be written when the response is rendered")
+}
+
+// WriteHeader does nothing, as the status code is only written once the response is rendered.
+func (w *detachedWriter) WriteHeader(statusCode int) {
+}
+
+// checkBody replaces the response of a handler that read past MaxRequestBodySize with a 413 response.
+func checkBody(body *limitedBody, resp response.Response) response.Response {
+	if body != nil && body.exceeded {
+		return response.ErrorResponse(http.StatusRequestEntityTooLarge, fmt.Sprintf("Request body is larger than %d bytes", MaxRequestBodySize))
+	}
+
+	return resp
+}
@@ -0,0 +1,184 @@
+package rest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// metricsLatencySamples is the number of most recent request latencies kept for each endpoint to compute percentiles.
+const metricsLatencySamples = 1024
+
+// MetricsQuantiles are the latency percentiles reported for each endpoint.
+var MetricsQuantiles = []float64{0.5, 0.9, 0.99}
+
+// EndpointMetrics are the request metrics of an endpoint for a single method, since the daemon started.
+type EndpointMetrics struct {
+	// Path is the path template the endpoint is registered with, such as "/1.0/cluster/{name}".
+	Path   string
+	Method string
+
+	Requests uint64
+	Errors   uint64
+
+	// Latency is the total time spent handling requests, and Quantiles the latency of the most recent requests at each
+	// of MetricsQuantiles.
+	Latency   time.Duration
+	Quantiles []time.Duration
+}
+
+// metricsKey identifies the metrics of an endpoint.
+type metricsKey struct {
+	path   string
+	method string
+}
+
+// endpointMetrics records the requests to an endpoint.
+type endpointMetrics struct {
+	requests  uint64
+	errors    uint64
+	latency   time.Duration
+	latencies []time.Duration
+	next      int
+}
+
+var metricsMu sync.Mutex
+var metrics = map[metricsKey]*endpointMetrics{}
+
+// Metrics returns the request metrics of all endpoints that have received requests, ordered by path and method.
+func Metrics() []EndpointMetrics {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	result := make([]EndpointMetrics, 0, len(metrics))
+	for key, m := range metrics {
+		sorted := make([]time.Duration, len(m.latencies))
+		copy(sorted, m.latencies)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		quantiles := make([]time.Duration, 0, len(MetricsQuantiles))
+		for _, q := range MetricsQuantiles {
+			quantiles = append(quantiles, sorted[int(q*float64(len(sorted)-1))])
+		}
+
+		result = append(result, EndpointMetrics{
+			Path:      key.path,
+			Method:    key.method,
+			Requests:  m.requests,
+			Errors:    m.errors,
+			Latency:   m.latency,
+			Quantiles: quantiles,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Path != result[j].Path {
+			return result[i].Path < result[j].Path
+		}
+
+		return result[i].Method < result[j].Method
+	})
+
+	return result
+}
+
+// recordRequest records a request to the endpoint with the given path template, which failed if its status code is
+// 400 or above.
+func recordRequest(path string, method string, statusCode int, latency time.Duration) {
+	// Limit the methods to those used by endpoints, so that clients can not grow the metrics without bounds.
+	switch method {
+	case "GET", "PUT", "POST", "DELETE", "PATCH":
+	default:
+		method = "OTHER"
+	}
+
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	key := metricsKey{path: path, method: method}
+	m, ok := metrics[key]
+	if !ok {
+		m = &endpointMetrics{}
+		metrics[key] = m
+	}
+
+	m.requests++
+	if statusCode >= http.StatusBadRequest {
+		m.errors++
+	}
+
+	m.latency += latency
+	if len(m.latencies) < metricsLatencySamples {
+		m.latencies = append(m.latencies, latency)
+	} else {
+		m.latencies[m.next] = latency
+		m.next = (m.next + 1) % metricsLatencySamples
+	}
+}
+
+// instrument wraps the handler of the endpoint with the given path template to record the metrics of its requests.
+func instrument(path string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		mw := &metricsResponseWriter{ResponseWriter: w}
+
+		handler.ServeHTTP(mw, r)
+
+		statusCode := mw.statusCode
+		if statusCode == 0 && !mw.hijacked {
+			statusCode = http.StatusOK
+		}
+
+		recordRequest(path, r.Method, statusCode, time.Since(start))
+	})
+}
+
+// metricsResponseWriter records the status code of a response. It supports flushing and hijacking, for endpoints that
+// stream their responses or upgrade the connection.
+type metricsResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	hijacked   bool
+}
+
+// WriteHeader records the status code and writes it.
+func (w *metricsResponseWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records the implicit 200 status code if none was written yet, and writes the response body.
+func (w *metricsResponseWriter) Write(b []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush sends any buffered data to the client.
+func (w *metricsResponseWriter) Flush() {
+	flusher, ok := w.ResponseWriter.(http.Flusher)
+	if ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack lets the handler take over the connection.
+func (w *metricsResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("Response writer does not support hijacking")
+	}
+
+	w.hijacked = true
+
+	return hijacker.Hijack()
+}
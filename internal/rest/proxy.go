@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// TrustedProxies are the networks of reverse proxies and load balancers in front of the daemon. The client address of
+// requests from them is taken from the X-Forwarded-For header.
+var TrustedProxies []*net.IPNet
+
+// trustedProxy returns whether the given IP address belongs to a trusted proxy.
+func trustedProxy(ip net.IP) bool {
+	for _, network := range TrustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// clientAddress returns the address of the client that made the request. For requests from a trusted proxy, it is the
+// last address in the X-Forwarded-For header that is not itself a trusted proxy.
+func clientAddress(r *http.Request) string {
+	if r.RemoteAddr == "@" || len(TrustedProxies) == 0 {
+		return r.RemoteAddr
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !trustedProxy(ip) {
+		return r.RemoteAddr
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			hop = strings.TrimSpace(hop)
+			if hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+
+	if len(hops) == 0 {
+		return r.RemoteAddr
+	}
+
+	// Proxies append the address they received the request from, so walk back from the proxy closest to the daemon.
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(hops[i])
+		if ip == nil {
+			// Anything before an invalid entry can not be trusted.
+			return r.RemoteAddr
+		}
+
+		if !trustedProxy(ip) || i == 0 {
+			return hops[i]
+		}
+	}
+
+	return r.RemoteAddr
+}
@@ -0,0 +1,120 @@
+package rest
+
+import (
+	"container/list"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+
+	"github.com/canonical/microcluster/internal/ca"
+	internalState "github.com/canonical/microcluster/internal/state"
+)
+
+// RateLimit is the sustained number of requests per second allowed from each client over the network. Clients with a
+// certificate issued by the external certificate authority are told apart by their certificate fingerprint, and other
+// clients by their address, so that clients can not escape the limit by presenting new self-signed certificates.
+// Requests from cluster members and over the control socket are not limited. Zero disables rate limiting.
+var RateLimit float64
+
+// RateLimitBurst is the number of requests a client can make in a burst above RateLimit.
+var RateLimitBurst = 20
+
+// rateLimitIdle is how long a client must be idle before its request budget is forgotten.
+const rateLimitIdle = 10 * time.Minute
+
+// rateLimitMaxClients is the number of clients whose request budget is tracked. Once reached, the budget of the least
+// recently seen client is forgotten to make room for a new one.
+const rateLimitMaxClients = 10000
+
+// bucket is the request budget of a client.
+type bucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter tracks the request budget of each client with a token bucket. Buckets are kept in order of when their
+// client was last seen, most recent first, so that idle and least recently seen clients are found at the back.
+type rateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	order   *list.List
+}
+
+var limiter = &rateLimiter{buckets: map[string]*list.Element{}, order: list.New()}
+
+// allow spends one request from the budget of the given client, and returns whether it had any left.
+func (l *rateLimiter) allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for e := l.order.Back(); e != nil && now.Sub(e.Value.(*bucket).last) > rateLimitIdle; e = l.order.Back() {
+		l.remove(e)
+	}
+
+	e, ok := l.buckets[key]
+	if ok {
+		l.order.MoveToFront(e)
+	} else {
+		if len(l.buckets) >= rateLimitMaxClients {
+			l.remove(l.order.Back())
+		}
+
+		e = l.order.PushFront(&bucket{key: key, tokens: float64(RateLimitBurst), last: now})
+		l.buckets[key] = e
+	}
+
+	b := e.Value.(*bucket)
+	b.tokens += now.Sub(b.last).Seconds() * RateLimit
+	if b.tokens > float64(RateLimitBurst) {
+		b.tokens = float64(RateLimitBurst)
+	}
+
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+
+	return true
+}
+
+// remove forgets the budget of the client of the given bucket.
+func (l *rateLimiter) remove(e *list.Element) {
+	l.order.Remove(e)
+	delete(l.buckets, e.Value.(*bucket).key)
+}
+
+// rateLimited returns whether the request exceeds the rate limit of the client that made it.
+func rateLimited(state *internalState.State, r *http.Request) bool {
+	if RateLimit <= 0 || r.RemoteAddr == "@" {
+		return false
+	}
+
+	key := clientAddress(r)
+	host, _, err := net.SplitHostPort(key)
+	if err == nil {
+		key = host
+	}
+
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		fingerprint := shared.CertFingerprint(r.TLS.PeerCertificates[0])
+
+		// Cluster members are not limited, so that heartbeats and forwarded requests are never rejected.
+		if state.Remotes().RemoteByCertificateFingerprint(fingerprint) != nil {
+			return false
+		}
+
+		authority := ca.Current()
+		if authority != nil && verifyAuthority(authority, r) == nil {
+			key = fingerprint
+		}
+	}
+
+	return !limiter.allow(key)
+}
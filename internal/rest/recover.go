@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync/atomic"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/google/uuid"
+
+	internalState "github.com/canonical/microcluster/internal/state"
+)
+
+// panicCount is the number of API handler panics recovered since the daemon started.
+var panicCount uint64
+
+// PanicCount returns the number of API handler panics recovered since the daemon started.
+func PanicCount() uint64 {
+	return atomic.LoadUint64(&panicCount)
+}
+
+// recoverRequest runs the given request handler, and converts any panic into an internal error response carrying a
+// request ID. The request ID is also set in the `X-Request-ID` response header so that it can be matched against the
+// logged stack trace.
+func recoverRequest(state *internalState.State, w http.ResponseWriter, r *http.Request, handle func() response.Response) (resp response.Response) {
+	defer func() {
+		value := recover()
+		if value == nil {
+			return
+		}
+
+		// The connection is already gone if the handler was aborted, so let the HTTP server deal with it.
+		if value == http.ErrAbortHandler {
+			panic(value)
+		}
+
+		atomic.AddUint64(&panicCount, 1)
+
+		requestID := uuid.New().String()
+		logger.Error("Recovered from panic in API handler", logger.Ctx{"requestID": requestID, "method": r.Method, "url": r.URL.String(), "panic": fmt.Sprintf("%v", value), "stack": string(debug.Stack())})
+
+		if internalState.OnHandlerPanicHook != nil {
+			err := internalState.OnHandlerPanicHook(state, r, requestID, value)
+			if err != nil {
+				logger.Error("Failed to run handler panic hook", logger.Ctx{"requestID": requestID, "error": err})
+			}
+		}
+
+		w.Header().Set("X-Request-ID", requestID)
+		resp = response.InternalError(fmt.Errorf("Internal server error (request ID %s)", requestID))
+	}()
+
+	return handle()
+}
@@ -0,0 +1,189 @@
+package resources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var aclsCmd = rest.Endpoint{
+	Path: "acls",
+
+	Get:  rest.EndpointAction{Handler: aclsGet, AccessHandler: access.AllowAuthenticated},
+	Post: rest.EndpointAction{Handler: aclsPost, AccessHandler: access.AllowAuthenticated},
+}
+
+var aclCmd = rest.Endpoint{
+	Path:       "acls/{name}",
+	PathParams: map[string]rest.PathParam{"name": rest.PathParamName},
+
+	Get:    rest.EndpointAction{Handler: aclGet, AccessHandler: access.AllowAuthenticated},
+	Put:    rest.EndpointAction{Handler: aclPut, AccessHandler: access.AllowAuthenticated},
+	Delete: rest.EndpointAction{Handler: aclDelete, AccessHandler: access.AllowAuthenticated},
+}
+
+func aclsGet(s *state.State, r *http.Request) response.Response {
+	var acls []internalTypes.CertificateACL
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		dbACLs, err := cluster.GetInternalRestrictedCertificates(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		acls = make([]internalTypes.CertificateACL, 0, len(dbACLs))
+		for _, dbACL := range dbACLs {
+			acl, err := dbACL.ToAPI()
+			if err != nil {
+				return err
+			}
+
+			acls = append(acls, *acl)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return rest.CollectionResponse(r, acls)
+}
+
+// aclsPost trusts a new certificate, restricted to the API endpoints allowed by its rules. The certificates of cluster
+// members can not be restricted. Callers that are themselves restricted by ACL rules can not manage ACLs, as they could
+// otherwise allow more than their own rules.
+func aclsPost(s *state.State, r *http.Request) response.Response {
+	if access.Restricted(r) {
+		return response.Forbidden(fmt.Errorf("ACLs can not be created by %s, as it is restricted by ACL rules", access.Identity(r)))
+	}
+
+	req, resp := rest.DecodeRequest(r, func(req internalTypes.CertificateACL) error {
+		return cluster.ValidateACLRules(req.Rules)
+	})
+
+	if resp != nil {
+		return resp
+	}
+
+	fingerprint := shared.CertFingerprint(req.Certificate.Certificate)
+	if s.Remotes().RemoteByCertificateFingerprint(fingerprint) != nil {
+		return response.BadRequest(fmt.Errorf("Certificate %q belongs to a cluster member", fingerprint))
+	}
+
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := cluster.GetInternalRestrictedCertificateByFingerprint(ctx, tx, fingerprint)
+		if err == nil {
+			return api.StatusErrorf(http.StatusConflict, "Certificate %q already has an ACL", fingerprint)
+		} else if !api.StatusErrorCheck(err, http.StatusNotFound) {
+			return err
+		}
+
+		_, err = cluster.CreateInternalRestrictedCertificate(ctx, tx, cluster.InternalRestrictedCertificate{
+			Name:        req.Name,
+			Fingerprint: fingerprint,
+			Certificate: req.Certificate.String(),
+			Rules:       req.Rules,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = cluster.CreateInternalTrustAudit(ctx, tx, cluster.NewInternalTrustAudit(cluster.TrustAuditAdd, cluster.TrustAuditCertificateACL, req.Name, fingerprint, access.Requestor(r)))
+
+		return err
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+func aclGet(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	var acl *internalTypes.CertificateACL
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		dbACL, err := cluster.GetInternalRestrictedCertificate(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		acl, err = dbACL.ToAPI()
+
+		return err
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.SyncResponse(true, acl)
+}
+
+func aclPut(s *state.State, r *http.Request) response.Response {
+	if access.Restricted(r) {
+		return response.Forbidden(fmt.Errorf("ACLs can not be modified by %s, as it is restricted by ACL rules", access.Identity(r)))
+	}
+
+	name := rest.PathValue[string](r, "name")
+
+	req := internalTypes.CertificateACLPut{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = cluster.ValidateACLRules(req.Rules)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		return cluster.UpdateInternalRestrictedCertificateRules(ctx, tx, name, req.Rules)
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+func aclDelete(s *state.State, r *http.Request) response.Response {
+	if access.Restricted(r) {
+		return response.Forbidden(fmt.Errorf("ACLs can not be deleted by %s, as it is restricted by ACL rules", access.Identity(r)))
+	}
+
+	name := rest.PathValue[string](r, "name")
+
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		acl, err := cluster.GetInternalRestrictedCertificate(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		err = cluster.DeleteInternalRestrictedCertificate(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		_, err = cluster.CreateInternalTrustAudit(ctx, tx, cluster.NewInternalTrustAudit(cluster.TrustAuditRemove, cluster.TrustAuditCertificateACL, name, acl.Fingerprint, access.Requestor(r)))
+
+		return err
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
@@ -1,10 +1,17 @@
 package resources
 
 import (
+	"context"
+	"database/sql"
 	"net/http"
+	"time"
 
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/logger"
 
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/extensions"
+	internalREST "github.com/canonical/microcluster/internal/rest"
 	"github.com/canonical/microcluster/internal/rest/access"
 	internalTypes "github.com/canonical/microcluster/internal/rest/types"
 	"github.com/canonical/microcluster/internal/state"
@@ -12,21 +19,111 @@ import (
 	"github.com/canonical/microcluster/rest/types"
 )
 
+// BackupOperation is the operation type recorded in the operation history for scheduled backups.
+const BackupOperation = "backup"
+
+// UpgradeOperation is the operation type recorded in the operation history for rolling upgrades.
+const UpgradeOperation = "upgrade"
+
+// ClusterCertificateOperation is the operation type recorded in the operation history for cluster certificate
+// rotations.
+const ClusterCertificateOperation = "cluster-certificate"
+
+// TrustStoreRepairOperation is the operation type recorded in the operation history for trust store repairs.
+const TrustStoreRepairOperation = "truststore-repair"
+
 var api10Cmd = rest.Endpoint{
 	AllowedBeforeInit: true,
 
-	Get: rest.EndpointAction{Handler: api10Get, AccessHandler: access.AllowAuthenticated},
+	Get: rest.EndpointAction{Handler: api10Get, AccessHandler: access.AllowAuthenticated, ProxyTarget: true},
 }
 
 func api10Get(s *state.State, r *http.Request) response.Response {
 	addrPort, err := types.ParseAddrPort(s.Address().URL.Host)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
-	return response.SyncResponse(true, internalTypes.Server{
+	server := internalTypes.Server{
 		Name:    s.Name(),
 		Address: addrPort,
 		Ready:   s.Database.IsOpen(),
+
+		APIExtensions: extensions.Supported(),
+
+		HandlerPanics: internalREST.PanicCount(),
+	}
+
+	wait := s.Database.UpgradeWait()
+	if wait != nil {
+		server.BlockingMember = wait.Member
+		server.Status = wait.String()
+	}
+
+	if server.Ready {
+		server.Backup, err = backupStatus(s)
+		if err != nil {
+			return rest.SmartError(err)
+		}
+
+		server.Leader, err = isLeader(s)
+		if err != nil {
+			logger.Warn("Failed to determine dqlite leader", logger.Ctx{"error": err})
+		}
+	}
+
+	return response.SyncResponse(true, server)
+}
+
+// backupStatus returns the result of scheduled backups from the operation history, or nil if none have run.
+func backupStatus(s *state.State) (*internalTypes.BackupStatus, error) {
+	opType := BackupOperation
+	var operations []cluster.InternalOperation
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		operations, err = cluster.GetInternalOperations(ctx, tx, cluster.InternalOperationFilter{Type: &opType})
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(operations) == 0 {
+		return nil, nil
+	}
+
+	last := operations[len(operations)-1]
+	status := &internalTypes.BackupStatus{LastAttempt: last.StartedAt}
+	if last.Status == cluster.OperationFailed {
+		status.LastError = last.Error
+	}
+
+	for i := len(operations) - 1; i >= 0; i-- {
+		if operations[i].Status == cluster.OperationSuccess {
+			status.LastSuccess = operations[i].StartedAt
+			break
+		}
+	}
+
+	return status, nil
+}
+
+// isLeader returns whether this cluster member is the dqlite leader.
+func isLeader(s *state.State) (bool, error) {
+	ctx, cancel := context.WithTimeout(s.Context, 5*time.Second)
+	defer cancel()
+
+	leaderClient, err := s.Database.Leader(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	defer leaderClient.Close()
+
+	leaderInfo, err := leaderClient.Leader(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return leaderInfo.Address == s.Address().URL.Host, nil
 }
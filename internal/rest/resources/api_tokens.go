@@ -0,0 +1,114 @@
+package resources
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var apiTokensCmd = rest.Endpoint{
+	Path: "api-tokens",
+
+	Get:  rest.EndpointAction{Handler: apiTokensGet, AccessHandler: access.AllowAuthenticated},
+	Post: rest.EndpointAction{Handler: apiTokensPost, AccessHandler: access.AllowAuthenticated},
+}
+
+var apiTokenCmd = rest.Endpoint{
+	Path:       "api-tokens/{name}",
+	PathParams: map[string]rest.PathParam{"name": rest.PathParamName},
+
+	Delete: rest.EndpointAction{Handler: apiTokenDelete, AccessHandler: access.AllowAuthenticated},
+}
+
+func apiTokensGet(s *state.State, r *http.Request) response.Response {
+	var tokens []internalTypes.APIToken
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		dbTokens, err := cluster.GetInternalBearerTokens(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		tokens = make([]internalTypes.APIToken, 0, len(dbTokens))
+		for _, token := range dbTokens {
+			tokens = append(tokens, token.ToAPI())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return rest.CollectionResponse(r, tokens)
+}
+
+// apiTokensPost issues a new bearer token restricted to the API endpoints allowed by its rules. The token is only
+// returned in the response, as only its hash is stored. Callers that are themselves restricted by ACL rules can not
+// issue tokens, as the rules of the new token could allow more than their own.
+func apiTokensPost(s *state.State, r *http.Request) response.Response {
+	if access.Restricted(r) {
+		return response.Forbidden(fmt.Errorf("API tokens can not be issued by %s, as it is restricted by ACL rules", access.Identity(r)))
+	}
+
+	req, resp := rest.DecodeRequest(r, func(req internalTypes.APITokenPost) error {
+		return cluster.ValidateACLRules(req.Rules)
+	})
+
+	if resp != nil {
+		return resp
+	}
+
+	secret, err := shared.RandomCryptoString()
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	token := cluster.InternalBearerToken{Name: req.Name, Hash: cluster.HashAPIToken(secret), Rules: req.Rules}
+	if req.ExpireAfter > 0 {
+		token.ExpiryDate = sql.NullTime{Time: time.Now().Add(req.ExpireAfter).UTC(), Valid: true}
+	}
+
+	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := cluster.CreateInternalBearerToken(ctx, tx, token)
+		if err != nil {
+			return err
+		}
+
+		_, err = cluster.CreateInternalTrustAudit(ctx, tx, cluster.NewInternalTrustAudit(cluster.TrustAuditAdd, cluster.TrustAuditAPIToken, req.Name, "", access.Requestor(r)))
+		return err
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.SyncResponse(true, internalTypes.APITokenSecret{Name: req.Name, Token: secret, ExpiresAt: token.ToAPI().ExpiresAt})
+}
+
+func apiTokenDelete(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		err := cluster.DeleteInternalBearerToken(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		_, err = cluster.CreateInternalTrustAudit(ctx, tx, cluster.NewInternalTrustAudit(cluster.TrustAuditRemove, cluster.TrustAuditAPIToken, name, "", access.Requestor(r)))
+		return err
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
@@ -0,0 +1,48 @@
+package resources
+
+import (
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/response"
+
+	"github.com/canonical/microcluster/internal/backup"
+	"github.com/canonical/microcluster/internal/rest/access"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var databaseBackupCmd = rest.Endpoint{
+	Path: "database/backup",
+
+	Get: rest.EndpointAction{Handler: databaseBackupGet, AccessHandler: access.AllowClusterMembers},
+}
+
+// databaseBackupGet streams an archive of the global database. It is compressed with the first algorithm listed in the
+// Accept-Encoding header of the request that is supported, or with the default backup compression otherwise.
+func databaseBackupGet(s *state.State, r *http.Request) response.Response {
+	compression, err := backup.Negotiate(r.Header.Get("Accept-Encoding"))
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	dump, err := s.Database.Dump(r.Context())
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	files := make([]backup.File, 0, len(dump))
+	for _, file := range dump {
+		files = append(files, backup.File{Name: file.Name, Data: file.Data})
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		w.Header().Set("Content-Type", "application/x-tar")
+		if compression.Name != backup.Identity {
+			w.Header().Set("Content-Encoding", compression.Name)
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		return backup.WriteArchive(w, compression, files)
+	})
+}
@@ -0,0 +1,298 @@
+package resources
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/client"
+	"github.com/canonical/microcluster/internal/cryptopolicy"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalClient "github.com/canonical/microcluster/internal/rest/client"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/internal/sys"
+	"github.com/canonical/microcluster/rest"
+	"github.com/canonical/microcluster/rest/types"
+)
+
+var clusterCertificateCmd = rest.Endpoint{
+	Path: "certificate",
+
+	Get:  rest.EndpointAction{Handler: clusterCertificateGet, AccessHandler: access.AllowAuthenticated},
+	Post: rest.EndpointAction{Handler: clusterCertificatePost, AccessHandler: access.AllowAuthenticated, LeaderOnly: true},
+}
+
+var clusterCertificateMemberCmd = rest.Endpoint{
+	Path: "certificate",
+
+	Put: rest.EndpointAction{Handler: clusterCertificateMemberPut, AccessHandler: access.AllowClusterMembers},
+}
+
+// clusterCertificateRotation is held by this cluster member while it coordinates a cluster certificate rotation.
+var clusterCertificateRotation sync.Mutex
+
+func clusterCertificateGet(s *state.State, r *http.Request) response.Response {
+	cert, err := s.ClusterCert().PublicKeyX509()
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.SyncResponse(true, internalTypes.ClusterCertificate{
+		Certificate: types.X509Certificate{Certificate: cert},
+		Fingerprint: shared.CertFingerprint(cert),
+	})
+}
+
+// clusterCertificatePost replaces the cluster certificate of every cluster member with a newly generated one. The new
+// certificate is first distributed to every cluster member, which accepts it from other cluster members alongside the
+// old one. Every cluster member then switches to serving the new certificate, and finally stops accepting the old one.
+// As both certificates are accepted until every cluster member has switched, the cluster stays available throughout.
+// Join tokens embed the fingerprint of the cluster certificate, so tokens handed out before the rotation must be
+// fetched again.
+func clusterCertificatePost(s *state.State, r *http.Request) response.Response {
+	if !clusterCertificateRotation.TryLock() {
+		return rest.SmartError(api.StatusErrorf(http.StatusConflict, "A cluster certificate rotation is already in progress"))
+	}
+
+	defer clusterCertificateRotation.Unlock()
+
+	if len(s.ClusterCert().PrivateKey()) == 0 {
+		return response.BadRequest(fmt.Errorf("The cluster certificate can not be rotated while its private key is held by an external signer"))
+	}
+
+	var newCert *x509.Certificate
+	err := s.RunOperation(ClusterCertificateOperation, access.Requestor(r), func(ctx context.Context) error {
+		var err error
+		newCert, err = rotateClusterCert(ctx, s)
+		return err
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.SyncResponse(true, internalTypes.ClusterCertificate{
+		Certificate: types.X509Certificate{Certificate: newCert},
+		Fingerprint: shared.CertFingerprint(newCert),
+	})
+}
+
+// rotateClusterCert generates a new cluster certificate, and applies each phase of the rotation to every cluster
+// member in turn. It returns the new cluster certificate.
+func rotateClusterCert(ctx context.Context, s *state.State) (*x509.Certificate, error) {
+	certPEM, keyPEM, err := shared.GenerateMemCert(false, true)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate cluster certificate: %w", err)
+	}
+
+	newCert, err := sys.NewCertInfo(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse generated cluster certificate: %w", err)
+	}
+
+	publicKey, err := newCert.PublicKeyX509()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse generated cluster certificate: %w", err)
+	}
+
+	update := internalTypes.ClusterCertificateUpdate{
+		Phase:       internalTypes.ClusterCertificatePrepare,
+		Certificate: types.X509Certificate{Certificate: publicKey},
+		Key:         string(keyPEM),
+	}
+
+	// Clients to the other cluster members are created for each phase, as the certificates they accept are only
+	// fixed when they are created.
+	applyPhase := func(phase internalTypes.ClusterCertificatePhase) error {
+		update.Phase = phase
+		peers, err := s.Cluster(nil)
+		if err != nil {
+			return err
+		}
+
+		err = peers.Query(ctx, true, func(ctx context.Context, c *client.Client) error {
+			return c.UpdateClusterCertificate(ctx, update)
+		})
+		if err != nil {
+			return err
+		}
+
+		return applyClusterCertPhase(s, update)
+	}
+
+	err = applyPhase(internalTypes.ClusterCertificatePrepare)
+	if err != nil {
+		abortErr := applyPhase(internalTypes.ClusterCertificateAbort)
+		if abortErr != nil {
+			logger.Warn("Failed to discard new cluster certificate", logger.Ctx{"error": abortErr})
+		}
+
+		return nil, fmt.Errorf("Failed to distribute new cluster certificate: %w", err)
+	}
+
+	// The private key is only needed to prepare the rotation.
+	update.Key = ""
+
+	err = applyPhase(internalTypes.ClusterCertificateSwitch)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to switch to the new cluster certificate, both certificates remain accepted: %w", err)
+	}
+
+	err = applyPhase(internalTypes.ClusterCertificateFinish)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to stop accepting the old cluster certificate: %w", err)
+	}
+
+	return publicKey, nil
+}
+
+// clusterCertificateMemberPut applies a phase of a cluster certificate rotation to this cluster member.
+func clusterCertificateMemberPut(s *state.State, r *http.Request) response.Response {
+	req := internalTypes.ClusterCertificateUpdate{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = applyClusterCertPhase(s, req)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// applyClusterCertPhase applies a phase of a cluster certificate rotation to this cluster member. Each phase can be
+// applied more than once.
+func applyClusterCertPhase(s *state.State, update internalTypes.ClusterCertificateUpdate) error {
+	if update.Certificate.Certificate == nil {
+		return api.StatusErrorf(http.StatusBadRequest, "No cluster certificate provided")
+	}
+
+	current, err := s.ClusterCert().PublicKeyX509()
+	if err != nil {
+		return fmt.Errorf("Failed to parse cluster certificate: %w", err)
+	}
+
+	newCert := update.Certificate.Certificate
+	switched := bytes.Equal(current.Raw, newCert.Raw)
+
+	switch update.Phase {
+	case internalTypes.ClusterCertificatePrepare:
+		if switched {
+			return nil
+		}
+
+		err = cryptopolicy.CheckCertificate(newCert)
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Cluster certificate violates the crypto policy: %v", err)
+		}
+
+		_, err = sys.NewCertInfo([]byte(update.Certificate.String()), []byte(update.Key))
+		if err != nil {
+			return api.StatusErrorf(http.StatusBadRequest, "Invalid cluster certificate keypair: %v", err)
+		}
+
+		err = s.OS.WriteKeyPair("cluster-pending", []byte(update.Certificate.String()), []byte(update.Key))
+		if err != nil {
+			return fmt.Errorf("Failed to write pending cluster certificate: %w", err)
+		}
+
+		internalClient.SetAlternateRemoteCert(current, newCert)
+	case internalTypes.ClusterCertificateSwitch:
+		if switched {
+			return nil
+		}
+
+		pending, err := s.OS.PendingClusterCert()
+		if err != nil {
+			return err
+		}
+
+		if pending == nil {
+			return api.StatusErrorf(http.StatusBadRequest, "No pending cluster certificate to switch to")
+		}
+
+		pendingCert, err := pending.PublicKeyX509()
+		if err != nil {
+			return fmt.Errorf("Failed to parse pending cluster certificate: %w", err)
+		}
+
+		if !bytes.Equal(pendingCert.Raw, newCert.Raw) {
+			return api.StatusErrorf(http.StatusConflict, "Pending cluster certificate %q does not match %q", shared.CertFingerprint(pendingCert), shared.CertFingerprint(newCert))
+		}
+
+		// Keep the current keypair so that its certificate is still accepted after a restart, until the rotation
+		// finishes.
+		err = s.OS.WriteKeyPair("cluster-previous", s.ClusterCert().PublicKey(), s.ClusterCert().PrivateKey())
+		if err != nil {
+			return fmt.Errorf("Failed to write previous cluster certificate: %w", err)
+		}
+
+		for _, ext := range []string{".crt", ".key"} {
+			err = os.Rename(filepath.Join(s.OS.StateDir, "cluster-pending"+ext), filepath.Join(s.OS.StateDir, "cluster"+ext))
+			if err != nil {
+				return fmt.Errorf("Failed to replace cluster certificate: %w", err)
+			}
+		}
+
+		err = s.SetClusterCert(pending)
+		if err != nil {
+			return fmt.Errorf("Failed to load new cluster certificate: %w", err)
+		}
+
+		internalClient.SetAlternateRemoteCert(current, nil)
+		internalClient.SetAlternateRemoteCert(newCert, current)
+
+		logger.Info("Switched to new cluster certificate", logger.Ctx{"fingerprint": shared.CertFingerprint(newCert)})
+	case internalTypes.ClusterCertificateFinish:
+		if !switched {
+			return api.StatusErrorf(http.StatusConflict, "Cluster certificate %q has not been switched to", shared.CertFingerprint(newCert))
+		}
+
+		err = removeCertFiles(s.OS.StateDir, "cluster-previous")
+		if err != nil {
+			return err
+		}
+
+		internalClient.SetAlternateRemoteCert(current, nil)
+	case internalTypes.ClusterCertificateAbort:
+		if switched {
+			return nil
+		}
+
+		err = removeCertFiles(s.OS.StateDir, "cluster-pending")
+		if err != nil {
+			return err
+		}
+
+		internalClient.SetAlternateRemoteCert(current, nil)
+	default:
+		return api.StatusErrorf(http.StatusBadRequest, "Invalid cluster certificate rotation phase %q", update.Phase)
+	}
+
+	return nil
+}
+
+// removeCertFiles removes the keypair with the given prefix from the given directory, if it exists.
+func removeCertFiles(dir string, prefix string) error {
+	for _, ext := range []string{".crt", ".key"} {
+		err := os.Remove(filepath.Join(dir, prefix+ext))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Failed to remove %q certificate: %w", prefix, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package resources
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sort"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var certificatesCmd = rest.Endpoint{
+	Path: "certificates",
+
+	Get: rest.EndpointAction{Handler: certificatesGet, AccessHandler: access.AllowAuthenticated},
+}
+
+// certificatesGet lists every certificate with access to the cluster: the certificates of cluster members in the trust
+// store, and the client certificates restricted by an ACL. They are sorted by type, then by name.
+func certificatesGet(s *state.State, r *http.Request) response.Response {
+	certs, err := trustedCertificates(s)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return rest.CollectionResponse(r, certs)
+}
+
+// trustedCertificates returns every certificate with access to the cluster, sorted by type, then by name.
+func trustedCertificates(s *state.State) ([]internalTypes.TrustedCertificate, error) {
+	certs := []internalTypes.TrustedCertificate{}
+	for _, remote := range s.Remotes().RemotesByName() {
+		certs = append(certs, internalTypes.TrustedCertificate{
+			Name:        remote.Name,
+			Type:        internalTypes.TrustedCertificateMember,
+			Fingerprint: shared.CertFingerprint(remote.Certificate.Certificate),
+			Subject:     remote.Certificate.Subject.String(),
+			ExpiresAt:   remote.Certificate.NotAfter,
+		})
+	}
+
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		acls, err := cluster.GetInternalRestrictedCertificates(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		for _, dbACL := range acls {
+			acl, err := dbACL.ToAPI()
+			if err != nil {
+				return err
+			}
+
+			certs = append(certs, internalTypes.TrustedCertificate{
+				Name:        acl.Name,
+				Type:        internalTypes.TrustedCertificateClient,
+				Fingerprint: acl.Fingerprint,
+				Subject:     acl.Certificate.Subject.String(),
+				ExpiresAt:   acl.Certificate.NotAfter,
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(certs, func(i, j int) bool {
+		if certs[i].Type != certs[j].Type {
+			return certs[i].Type < certs[j].Type
+		}
+
+		return certs[i].Name < certs[j].Name
+	})
+
+	return certs, nil
+}
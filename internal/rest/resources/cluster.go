@@ -3,11 +3,11 @@ package resources
 import (
 	"context"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net/http"
-	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -15,13 +15,15 @@ import (
 
 	dqliteClient "github.com/canonical/go-dqlite/client"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
-	"github.com/gorilla/mux"
 	"golang.org/x/sys/unix"
 
 	"github.com/canonical/microcluster/client"
 	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/cryptopolicy"
 	"github.com/canonical/microcluster/internal/rest/access"
 	internalClient "github.com/canonical/microcluster/internal/rest/client"
 	internalTypes "github.com/canonical/microcluster/internal/rest/types"
@@ -34,6 +36,7 @@ import (
 
 var clusterCmd = rest.Endpoint{
 	Path:              "cluster",
+	Aliases:           []rest.EndpointAlias{{Name: "members", Path: "members"}},
 	AllowedBeforeInit: true,
 
 	Post: rest.EndpointAction{Handler: clusterPost, AllowUntrusted: true},
@@ -41,10 +44,47 @@ var clusterCmd = rest.Endpoint{
 }
 
 var clusterMemberCmd = rest.Endpoint{
-	Path: "cluster/{name}",
+	Path:       "cluster/{name}",
+	Aliases:    []rest.EndpointAlias{{Name: "members", Path: "members/{name}"}},
+	PathParams: map[string]rest.PathParam{"name": rest.PathParamName},
 
 	Put:    rest.EndpointAction{Handler: clusterMemberPut, AccessHandler: access.AllowAuthenticated},
-	Delete: rest.EndpointAction{Handler: clusterMemberDelete, AccessHandler: access.AllowAuthenticated},
+	Post:   rest.EndpointAction{Handler: clusterMemberPost, AccessHandler: access.AllowAuthenticated},
+	Delete: rest.EndpointAction{Handler: clusterMemberDelete, AccessHandler: access.AllowClusterMembers},
+}
+
+var clusterMemberConfigCmd = rest.Endpoint{
+	Path:       "cluster/{name}/config",
+	Aliases:    []rest.EndpointAlias{{Name: "members", Path: "members/{name}/config"}},
+	PathParams: map[string]rest.PathParam{"name": rest.PathParamName},
+
+	Get:   rest.EndpointAction{Handler: clusterMemberConfigGet, AccessHandler: access.AllowAuthenticated},
+	Put:   rest.EndpointAction{Handler: clusterMemberConfigPut, AccessHandler: access.AllowAuthenticated},
+	Patch: rest.EndpointAction{Handler: clusterMemberConfigPatch, AccessHandler: access.AllowAuthenticated},
+}
+
+var clusterMemberRoleCmd = rest.Endpoint{
+	Path:       "cluster/{name}/role",
+	Aliases:    []rest.EndpointAlias{{Name: "members", Path: "members/{name}/role"}},
+	PathParams: map[string]rest.PathParam{"name": rest.PathParamName},
+
+	Put: rest.EndpointAction{Handler: clusterMemberRolePut, AccessHandler: access.AllowAuthenticated},
+}
+
+var clusterMemberCordonCmd = rest.Endpoint{
+	Path:       "cluster/{name}/cordon",
+	Aliases:    []rest.EndpointAlias{{Name: "members", Path: "members/{name}/cordon"}},
+	PathParams: map[string]rest.PathParam{"name": rest.PathParamName},
+
+	Put: rest.EndpointAction{Handler: clusterMemberCordonPut, AccessHandler: access.AllowAuthenticated},
+}
+
+var clusterMemberCertificateCmd = rest.Endpoint{
+	Path:       "cluster/{name}/certificate",
+	Aliases:    []rest.EndpointAlias{{Name: "members", Path: "members/{name}/certificate"}},
+	PathParams: map[string]rest.PathParam{"name": rest.PathParamName},
+
+	Put: rest.EndpointAction{Handler: clusterMemberCertificatePut, AccessHandler: access.AllowClusterMembers},
 }
 
 func clusterPost(s *state.State, r *http.Request) response.Response {
@@ -58,14 +98,14 @@ func clusterPost(s *state.State, r *http.Request) response.Response {
 		for !s.Database.IsOpen() {
 			select {
 			case <-ctx.Done():
-				return response.SmartError(fmt.Errorf("Error waiting for peer to initialize: %w", ctx.Err()))
+				return rest.SmartError(fmt.Errorf("Error waiting for peer to initialize: %w", ctx.Err()))
 			default:
 			}
 		}
 
 		err := state.OnNewMemberHook(s)
 		if err != nil {
-			return response.SmartError(fmt.Errorf("Failed to run post cluster member add actions: %w", err))
+			return rest.SmartError(fmt.Errorf("Failed to run post cluster member add actions: %w", err))
 		}
 
 		return response.EmptySyncResponse
@@ -89,18 +129,24 @@ func clusterPost(s *state.State, r *http.Request) response.Response {
 
 	leaderClient, err := s.Database.Leader(ctx)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	leaderInfo, err := leaderClient.Leader(ctx)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
-	// Check if any of the remote's addresses are currently in use.
-	existingRemote := s.Remotes().RemoteByAddress(req.Address)
-	if existingRemote != nil {
-		return response.SmartError(fmt.Errorf("Remote with address %q exists", req.Address.String()))
+	// The joining member must present the certificate it joins with, unless the request was relayed by another
+	// cluster member which has checked it already.
+	if !presentsCertificate(s, r, req.Certificate) {
+		return response.Forbidden(fmt.Errorf("Join request was not made with the certificate of the joining cluster member"))
+	}
+
+	// Check if any of the remote's addresses are currently in use, or if this is a retry of an interrupted join.
+	rejoin, err := checkExistingMember(s, req)
+	if err != nil {
+		return rest.SmartError(err)
 	}
 
 	newRemote := trust.Remote{
@@ -112,23 +158,61 @@ func clusterPost(s *state.State, r *http.Request) response.Response {
 	if leaderInfo.Address != s.Address().URL.Host {
 		client, err := s.Leader()
 		if err != nil {
-			return response.SmartError(err)
+			return rest.SmartError(err)
 		}
 
 		tokenResponse, err := client.AddClusterMember(s.Context, req)
 		if err != nil {
-			return response.SmartError(err)
+			return rest.SmartError(err)
+		}
+
+		// The leader has admitted the cluster member, so entries left over from an interrupted join can be removed.
+		err = removeStaleRemote(s, req)
+		if err != nil {
+			return rest.SmartError(err)
 		}
 
 		// If we are not the leader, just add the cluster member to our local store for authentication.
-		err = s.Remotes().Add(s.OS.TrustDir, newRemote)
+		err = addRemote(s, newRemote)
 		if err != nil {
-			return response.SmartError(err)
+			return rest.SmartError(err)
 		}
 
 		return response.SyncResponse(true, tokenResponse)
 	}
 
+	if rejoin {
+		err = prepareRejoin(ctx, s, leaderClient, req)
+		if err != nil {
+			return rest.SmartError(err)
+		}
+
+		return joinResponse(s, newRemote)
+	}
+
+	// Check the join token before validating the joining member, so that the validation hook is only run for members
+	// with a valid token.
+	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		record, err := cluster.GetInternalTokenRecord(ctx, tx, req.Secret)
+		if err != nil {
+			return err
+		}
+
+		if record.Expired() {
+			return api.StatusErrorf(http.StatusForbidden, "Join token %q has expired", record.Name)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	err = validateJoin(s, req)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
 	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
 		dbClusterMember := cluster.InternalClusterMember{
 			Name:        req.Name,
@@ -144,17 +228,62 @@ func clusterPost(s *state.State, r *http.Request) response.Response {
 			return err
 		}
 
+		if record.Expired() {
+			return api.StatusErrorf(http.StatusForbidden, "Join token %q has expired", record.Name)
+		}
+
+		// Check uniqueness again in the same transaction as the insert, so that a cluster member admitted since the
+		// earlier check is reported descriptively rather than failing on a database constraint.
+		dbClusterMembers, err := cluster.GetInternalClusterMembers(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		rejoin, err := checkMemberUnique(dbClusterMembers, req)
+		if err != nil {
+			return err
+		}
+
+		if rejoin {
+			return rest.Errorf(http.StatusConflict, types.ErrorCodeAlreadyMember, "Cluster member %q has already been admitted to the cluster", req.Name)
+		}
+
+		// Only let one cluster member join at a time, as concurrent joins race on distributing the trust store and
+		// reconfiguring dqlite. The joining cluster member releases the lock once it has joined dqlite.
+		err = cluster.AcquireJoinLock(ctx, tx, req.Name, JoinLockExpiry)
+		if err != nil {
+			return err
+		}
+
 		_, err = cluster.CreateInternalClusterMember(ctx, tx, dbClusterMember)
 		if err != nil {
 			return err
 		}
 
+		_, err = cluster.CreateInternalTrustAudit(ctx, tx, cluster.NewInternalTrustAudit(cluster.TrustAuditAdd, cluster.TrustAuditClusterMember, req.Name, shared.CertFingerprint(req.Certificate.Certificate), fmt.Sprintf("Join token %q", record.Name)))
+		if err != nil {
+			return err
+		}
+
 		return cluster.DeleteInternalTokenRecord(ctx, tx, record.Name)
 	})
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
+	// Only remove entries left over from an interrupted join once the join token has been checked and the join lock
+	// taken.
+	err = removeStaleRemote(s, req)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return joinResponse(s, newRemote)
+}
+
+// joinResponse adds the joining cluster member to the local trust store, and returns the credentials it needs to join
+// the cluster.
+func joinResponse(s *state.State, newRemote trust.Remote) response.Response {
 	remotes := s.Remotes()
 	clusterMembers := make([]internalTypes.ClusterMemberLocal, 0, remotes.Count())
 	for _, clusterMember := range remotes.RemotesByName() {
@@ -169,7 +298,7 @@ func clusterPost(s *state.State, r *http.Request) response.Response {
 
 	clusterCert, err := s.ClusterCert().PublicKeyX509()
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	tokenResponse := internalTypes.TokenResponse{
@@ -179,20 +308,36 @@ func clusterPost(s *state.State, r *http.Request) response.Response {
 		ClusterMembers: clusterMembers,
 	}
 
+	secretsKey, err := s.SecretsKey()
+	if err == nil {
+		tokenResponse.SecretsKey = hex.EncodeToString(secretsKey)
+	} else {
+		logger.Warn("Joining cluster member will not be able to read cluster-wide secrets", logger.Ctx{"name": newRemote.Name, "error": err})
+	}
+
 	// Add the cluster member to our local store for authentication.
-	err = s.Remotes().Add(s.OS.TrustDir, newRemote)
+	err = addRemote(s, newRemote)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	return response.SyncResponse(true, tokenResponse)
 }
 
+// clusterGet returns the cluster members, optionally filtered by the "name", "role" and "status" query parameters.
+// With "recursion=0", only the addresses of the matching cluster members are returned.
+// The generic "filter", "limit", "offset" and "cursor" query parameters are handled by rest.CollectionKeysResponse.
 func clusterGet(s *state.State, r *http.Request) response.Response {
 	if !s.Database.IsOpen() {
 		return response.Unavailable(fmt.Errorf("Daemon not yet initialized"))
 	}
 
+	filter := internalTypes.ClusterMemberFilter{
+		Name:   r.URL.Query().Get("name"),
+		Role:   r.URL.Query().Get("role"),
+		Status: r.URL.Query().Get("status"),
+	}
+
 	var apiClusterMembers []internalTypes.ClusterMember
 	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
 		clusterMembers, err := cluster.GetInternalClusterMembers(ctx, tx)
@@ -200,6 +345,31 @@ func clusterGet(s *state.State, r *http.Request) response.Response {
 			return err
 		}
 
+		appStatuses, err := cluster.GetClusterMemberAppStatuses(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		configs, err := cluster.GetClusterMemberConfigs(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		domains, err := cluster.GetClusterMemberFailureDomains(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		apiExtensions, err := cluster.GetClusterMemberAPIExtensions(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		cordons, err := cluster.GetClusterMemberCordons(ctx, tx)
+		if err != nil {
+			return err
+		}
+
 		apiClusterMembers = make([]internalTypes.ClusterMember, 0, len(clusterMembers))
 		for _, clusterMember := range clusterMembers {
 			apiClusterMember, err := clusterMember.ToAPI()
@@ -207,37 +377,474 @@ func clusterGet(s *state.State, r *http.Request) response.Response {
 				return err
 			}
 
+			if len(appStatuses[clusterMember.Name]) > 0 {
+				apiClusterMember.AppStatus = appStatuses[clusterMember.Name]
+			}
+
+			apiClusterMember.Config = configs[clusterMember.Name]
+			apiClusterMember.FailureDomain = domains[clusterMember.Name]
+			apiClusterMember.APIExtensions = apiExtensions[clusterMember.Name]
+			apiClusterMember.Cordoned = cordons[clusterMember.Name]
+
 			apiClusterMembers = append(apiClusterMembers, *apiClusterMember)
 		}
 
 		return nil
 	})
 	if err != nil {
-		return response.SmartError(fmt.Errorf("Failed to get cluster members: %w", err))
+		return rest.SmartError(fmt.Errorf("Failed to get cluster members: %w", err))
 	}
 
-	clusterCert, err := s.ClusterCert().PublicKeyX509()
+	// Report the current dqlite roles, as the recorded roles are only updated on heartbeats.
+	roles, err := dqliteRoles(s)
 	if err != nil {
-		return response.SmartError(err)
+		logger.Warn("Failed to get dqlite roles of cluster members", logger.Ctx{"error": err})
 	}
 
-	// Send a small request to each node to ensure they are reachable.
-	for i, clusterMember := range apiClusterMembers {
-		addr := api.NewURL().Scheme("https").Host(clusterMember.Address.String())
-		d, err := internalClient.New(*addr, s.ServerCert(), clusterCert, false)
+	filtered := make([]internalTypes.ClusterMember, 0, len(apiClusterMembers))
+	for _, clusterMember := range apiClusterMembers {
+		role, ok := roles[clusterMember.Address.String()]
+		if ok {
+			clusterMember.Role = role.String()
+		}
+
+		if filter.Match(clusterMember) {
+			filtered = append(filtered, clusterMember)
+		}
+	}
+
+	return rest.CollectionKeysResponse(r, filtered, func(clusterMember internalTypes.ClusterMember) string {
+		return clusterMember.Address.String()
+	})
+}
+
+// clusterMemberPost renames a cluster member, and notifies all other cluster members of the new name.
+func clusterMemberPost(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	req := internalTypes.ClusterMemberRename{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Name == "" {
+		return response.BadRequest(fmt.Errorf("No new cluster member name provided"))
+	}
+
+	// If we received a forwarded request, assume the cluster member was already renamed in the database,
+	// and update our local records.
+	if client.IsForwardedRequest(r) {
+		err := renameLocalClusterMember(s, name, req.Name)
 		if err != nil {
-			return response.SmartError(fmt.Errorf("Failed to create HTTPS client for cluster member with address %q: %w", addr.String(), err))
+			return rest.SmartError(err)
 		}
 
-		err = d.CheckReady(s.Context)
-		if err == nil {
-			apiClusterMembers[i].Status = internalTypes.MemberOnline
-		} else {
-			logger.Warnf("Failed to get status of cluster member with address %q: %v", addr.String(), err)
+		return response.EmptySyncResponse
+	}
+
+	_, ok := s.Remotes().RemotesByName()[req.Name]
+	if ok {
+		return response.BadRequest(fmt.Errorf("A cluster member with name %q already exists", req.Name))
+	}
+
+	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		clusterMember, err := cluster.GetInternalClusterMember(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		clusterMember.Name = req.Name
+
+		return cluster.UpdateInternalClusterMember(ctx, tx, name, *clusterMember)
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	err = renameLocalClusterMember(s, name, req.Name)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	cluster, err := s.Cluster(nil)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	err = cluster.Query(s.Context, true, func(ctx context.Context, c *client.Client) error {
+		return c.RenameClusterMember(ctx, name, req.Name)
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// renameLocalClusterMember updates the local trust store entry of the cluster member with the given name, and the
+// daemon configuration if the renamed cluster member is this one.
+func renameLocalClusterMember(s *state.State, name string, newName string) error {
+	allRemotes := s.Remotes().RemotesByName()
+	_, ok := allRemotes[name]
+	if ok {
+		newRemotes := make([]internalTypes.ClusterMember, 0, len(allRemotes))
+		for _, remote := range allRemotes {
+			if remote.Name == name {
+				remote.Name = newName
+			}
+
+			clusterMember := internalTypes.ClusterMemberLocal{Name: remote.Name, Address: remote.Address, Certificate: remote.Certificate}
+			newRemotes = append(newRemotes, internalTypes.ClusterMember{ClusterMemberLocal: clusterMember})
+		}
+
+		err := s.Remotes().Replace(s.OS.TrustDir, newRemotes...)
+		if err != nil {
+			return fmt.Errorf("Failed to rename cluster member %q in the trust store: %w", name, err)
 		}
 	}
 
-	return response.SyncResponse(true, apiClusterMembers)
+	if s.Name() == name {
+		err := s.SetName(newName)
+		if err != nil {
+			return fmt.Errorf("Failed to update daemon configuration: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// clusterMemberCertificatePut replaces the server certificate of a cluster member in the database, and notifies all
+// other cluster members to update their trust store. The certificate can only be replaced by the cluster member itself
+// or over the local control socket, and the request must be signed by the key of the new certificate.
+func clusterMemberCertificatePut(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	identity := access.Identity(r)
+	if identity.Type == rest.IdentityClusterMember && identity.Name != name && !client.IsForwardedRequest(r) {
+		return response.Forbidden(fmt.Errorf("Only cluster member %q may replace its certificate", name))
+	}
+
+	req := internalTypes.ClusterMemberCertificate{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Certificate.Certificate == nil {
+		return response.BadRequest(fmt.Errorf("No certificate provided"))
+	}
+
+	err = cryptopolicy.CheckCertificate(req.Certificate.Certificate)
+	if err != nil {
+		return response.BadRequest(fmt.Errorf("Certificate violates the crypto policy: %w", err))
+	}
+
+	err = req.Verify(name)
+	if err != nil {
+		return response.Forbidden(err)
+	}
+
+	// If we received a forwarded request, assume the certificate was already replaced in the database, and update our
+	// local records.
+	if client.IsForwardedRequest(r) {
+		err := updateLocalClusterMemberCertificate(s, name, req.Certificate)
+		if err != nil {
+			return rest.SmartError(err)
+		}
+
+		return response.EmptySyncResponse
+	}
+
+	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		clusterMember, err := cluster.GetInternalClusterMember(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		clusterMember.Certificate = req.Certificate.String()
+
+		err = cluster.UpdateInternalClusterMember(ctx, tx, name, *clusterMember)
+		if err != nil {
+			return err
+		}
+
+		_, err = cluster.CreateInternalTrustAudit(ctx, tx, cluster.NewInternalTrustAudit(cluster.TrustAuditUpdate, cluster.TrustAuditClusterMember, name, shared.CertFingerprint(req.Certificate.Certificate), access.Requestor(r)))
+		return err
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	err = updateLocalClusterMemberCertificate(s, name, req.Certificate)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	cluster, err := s.Cluster(nil)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	err = cluster.Query(s.Context, true, func(ctx context.Context, c *client.Client) error {
+		return c.UpdateClusterMemberCertificate(ctx, name, req)
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// updateLocalClusterMemberCertificate replaces the certificate of the cluster member with the given name in the local
+// trust store.
+func updateLocalClusterMemberCertificate(s *state.State, name string, cert types.X509Certificate) error {
+	allRemotes := s.Remotes().RemotesByName()
+	_, ok := allRemotes[name]
+	if !ok {
+		return api.StatusErrorf(http.StatusNotFound, "Cluster member %q not found in the trust store", name)
+	}
+
+	newRemotes := make([]internalTypes.ClusterMember, 0, len(allRemotes))
+	for _, remote := range allRemotes {
+		if remote.Name == name {
+			remote.Certificate = cert
+		}
+
+		clusterMember := internalTypes.ClusterMemberLocal{Name: remote.Name, Address: remote.Address, Certificate: remote.Certificate}
+		newRemotes = append(newRemotes, internalTypes.ClusterMember{ClusterMemberLocal: clusterMember})
+	}
+
+	err := s.Remotes().Replace(s.OS.TrustDir, newRemotes...)
+	if err != nil {
+		return fmt.Errorf("Failed to replace certificate of cluster member %q in the trust store: %w", name, err)
+	}
+
+	return nil
+}
+
+// dqliteRoles returns the dqlite roles of all dqlite cluster members, keyed by address.
+func dqliteRoles(s *state.State) (map[string]dqliteClient.NodeRole, error) {
+	ctx, cancel := context.WithTimeout(s.Context, 30*time.Second)
+	defer cancel()
+
+	leader, err := s.Database.Leader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer leader.Close()
+
+	info, err := s.Database.Cluster(ctx, leader)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string]dqliteClient.NodeRole, len(info))
+	for _, node := range info {
+		roles[node.Address] = node.Role
+	}
+
+	return roles, nil
+}
+
+// clusterMemberRolePut promotes or demotes a cluster member to the requested dqlite role.
+// Note that dqlite may re-assign roles later on to maintain the desired number of voters and stand-bys.
+func clusterMemberRolePut(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	req := internalTypes.ClusterMemberRole{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	var role dqliteClient.NodeRole
+	switch req.Role {
+	case dqliteClient.Voter.String():
+		role = dqliteClient.Voter
+	case dqliteClient.StandBy.String():
+		role = dqliteClient.StandBy
+	case dqliteClient.Spare.String():
+		role = dqliteClient.Spare
+	default:
+		return response.BadRequest(fmt.Errorf("Invalid role %q, must be one of %q, %q or %q", req.Role, dqliteClient.Voter, dqliteClient.StandBy, dqliteClient.Spare))
+	}
+
+	remote, ok := s.Remotes().RemotesByName()[name]
+	if !ok {
+		return response.NotFound(fmt.Errorf("No cluster member found with name %q", name))
+	}
+
+	ctx, cancel := context.WithTimeout(s.Context, 30*time.Second)
+	defer cancel()
+
+	leader, err := s.Database.Leader(ctx)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	defer leader.Close()
+
+	info, err := s.Database.Cluster(ctx, leader)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	var node *dqliteClient.NodeInfo
+	voters := 0
+	for i := range info {
+		if info[i].Address == remote.Address.String() {
+			node = &info[i]
+		}
+
+		if info[i].Role == dqliteClient.Voter {
+			voters++
+		}
+	}
+
+	if node == nil {
+		return response.BadRequest(fmt.Errorf("Cluster member %q has not yet joined dqlite", name))
+	}
+
+	if node.Role == role {
+		return response.EmptySyncResponse
+	}
+
+	if node.Role == dqliteClient.Voter && voters == 1 {
+		return response.BadRequest(fmt.Errorf("Cannot demote the only voter %q", name))
+	}
+
+	err = leader.Assign(ctx, node.ID, role)
+	if err != nil {
+		return rest.SmartError(fmt.Errorf("Failed to assign role %q to cluster member %q: %w", role, name, err))
+	}
+
+	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		clusterMember, err := cluster.GetInternalClusterMember(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		clusterMember.Role = cluster.Role(role.String())
+
+		return cluster.UpdateInternalClusterMember(ctx, tx, name, *clusterMember)
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// clusterMemberCordonPut cordons a cluster member for maintenance, or uncordons it. Dqlite leadership is transferred
+// away from a cordoned cluster member.
+func clusterMemberCordonPut(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	req := internalTypes.ClusterMemberCordon{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	var cordoned map[string]bool
+	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		err := cluster.UpdateClusterMemberCordoned(ctx, tx, name, req.Cordoned)
+		if err != nil {
+			return err
+		}
+
+		cordoned, err = cluster.GetCordonedClusterMemberAddresses(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	if req.Cordoned {
+		ctx, cancel := context.WithTimeout(s.Context, 30*time.Second)
+		defer cancel()
+
+		err = s.Database.TransferLeadership(ctx, cordoned)
+		if err != nil {
+			return rest.SmartError(fmt.Errorf("Failed to transfer leadership away from cordoned cluster member %q: %w", name, err))
+		}
+	}
+
+	return response.EmptySyncResponse
+}
+
+// clusterMemberConfigGet returns the user metadata of a cluster member, with an ETag that can be passed in the If-Match
+// header of a subsequent PUT or PATCH request to prevent overwriting concurrent changes.
+func clusterMemberConfigGet(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	var config map[string]string
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		config, err = cluster.GetClusterMemberConfig(ctx, tx, name)
+		return err
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, internalTypes.ClusterMemberConfig{Config: config}, config)
+}
+
+// clusterMemberConfigPut replaces the user metadata of a cluster member. Fails with 412 Precondition Failed if the
+// metadata no longer matches the ETag given in the If-Match header.
+func clusterMemberConfigPut(s *state.State, r *http.Request) response.Response {
+	return updateClusterMemberConfig(s, r, false)
+}
+
+// clusterMemberConfigPatch merges the given keys into the user metadata of a cluster member.
+// Keys with empty values are removed. Fails with 412 Precondition Failed if the metadata no longer matches the ETag
+// given in the If-Match header.
+func clusterMemberConfigPatch(s *state.State, r *http.Request) response.Response {
+	return updateClusterMemberConfig(s, r, true)
+}
+
+func updateClusterMemberConfig(s *state.State, r *http.Request, merge bool) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	req := internalTypes.ClusterMemberConfig{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		current, err := cluster.GetClusterMemberConfig(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		err = util.EtagCheck(r, current)
+		if err != nil {
+			return err
+		}
+
+		config := req.Config
+		if merge {
+			config = current
+			for key, value := range req.Config {
+				if value == "" {
+					delete(config, key)
+				} else {
+					config[key] = value
+				}
+			}
+		}
+
+		return cluster.UpdateClusterMemberConfig(ctx, tx, name, config)
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
 }
 
 // clusterDisableMu is used to prevent the daemon process from being replaced/stopped during removal from the
@@ -253,7 +860,7 @@ func clusterMemberPut(s *state.State, r *http.Request) response.Response {
 	if client.IsForwardedRequest(r) {
 		err := state.PreRemoveHook(s, force)
 		if err != nil {
-			return response.SmartError(fmt.Errorf("Failed to run pre-removal hook: %w", err))
+			return rest.SmartError(fmt.Errorf("Failed to run pre-removal hook: %w", err))
 		}
 
 		return response.EmptySyncResponse
@@ -261,17 +868,17 @@ func clusterMemberPut(s *state.State, r *http.Request) response.Response {
 
 	err := s.Database.Stop()
 	if err != nil {
-		return response.SmartError(fmt.Errorf("Failed shutting down database: %w", err))
+		return rest.SmartError(fmt.Errorf("Failed shutting down database: %w", err))
 	}
 
 	err = state.StopListeners()
 	if err != nil {
-		return response.SmartError(fmt.Errorf("Failed shutting down listeners: %w", err))
+		return rest.SmartError(fmt.Errorf("Failed shutting down listeners: %w", err))
 	}
 
 	err = os.RemoveAll(s.OS.StateDir)
 	if err != nil {
-		return response.SmartError(fmt.Errorf("Failed to remove the s directory: %w", err))
+		return rest.SmartError(fmt.Errorf("Failed to remove the s directory: %w", err))
 	}
 
 	go func() {
@@ -317,17 +924,30 @@ func clusterMemberPut(s *state.State, r *http.Request) response.Response {
 // clusterMemberDelete Removes a cluster member from dqlite and re-execs its daemon.
 func clusterMemberDelete(s *state.State, r *http.Request) response.Response {
 	force := r.URL.Query().Get("force") == "1"
-	name, err := url.PathUnescape(mux.Vars(r)["name"])
-	if err != nil {
-		return response.SmartError(err)
-	}
+	name := rest.PathValue[string](r, "name")
 
 	// If we received a forwarded request, assume the new member was successfully removed on the leader,
-	// and execute the post-remove hook.
+	// remove it from our trust store, and execute the post-remove hook.
 	if client.IsForwardedRequest(r) {
+		_, ok := s.Remotes().RemotesByName()[name]
+		if ok {
+			newRemotes := []internalTypes.ClusterMember{}
+			for _, remote := range s.Remotes().RemotesByName() {
+				if remote.Name != name {
+					clusterMember := internalTypes.ClusterMemberLocal{Name: remote.Name, Address: remote.Address, Certificate: remote.Certificate}
+					newRemotes = append(newRemotes, internalTypes.ClusterMember{ClusterMemberLocal: clusterMember})
+				}
+			}
+
+			err := s.Remotes().Replace(s.OS.TrustDir, newRemotes...)
+			if err != nil {
+				return rest.SmartError(fmt.Errorf("Failed to remove cluster member %q from the trust store: %w", name, err))
+			}
+		}
+
 		err := state.PostRemoveHook(s, force)
 		if err != nil {
-			return response.SmartError(fmt.Errorf("Failed to run post cluster member remove actions: %w", err))
+			return rest.SmartError(fmt.Errorf("Failed to run post cluster member remove actions: %w", err))
 		}
 
 		return response.EmptySyncResponse
@@ -336,7 +956,7 @@ func clusterMemberDelete(s *state.State, r *http.Request) response.Response {
 	allRemotes := s.Remotes().RemotesByName()
 	remote, ok := allRemotes[name]
 	if !ok {
-		return response.SmartError(fmt.Errorf("No remote exists with the given name %q", name))
+		return rest.SmartError(fmt.Errorf("No remote exists with the given name %q", name))
 	}
 
 	ctx, cancel := context.WithTimeout(s.Context, time.Second*30)
@@ -344,12 +964,12 @@ func clusterMemberDelete(s *state.State, r *http.Request) response.Response {
 
 	leader, err := s.Database.Leader(ctx)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	leaderInfo, err := leader.Leader(ctx)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	// If we are not the leader, just update our trust store and forward the request.
@@ -372,12 +992,12 @@ func clusterMemberDelete(s *state.State, r *http.Request) response.Response {
 
 		client, err := s.Leader()
 		if err != nil {
-			return response.SmartError(err)
+			return rest.SmartError(err)
 		}
 
 		err = client.DeleteClusterMember(s.Context, name, force)
 		if err != nil {
-			return response.SmartError(err)
+			return rest.SmartError(err)
 		}
 
 		newRemotes := []internalTypes.ClusterMember{}
@@ -390,7 +1010,7 @@ func clusterMemberDelete(s *state.State, r *http.Request) response.Response {
 
 		err = s.Remotes().Replace(s.OS.TrustDir, newRemotes...)
 		if err != nil {
-			return response.SmartError(err)
+			return rest.SmartError(err)
 		}
 
 		return response.ManualResponse(func(w http.ResponseWriter) error {
@@ -413,7 +1033,7 @@ func clusterMemberDelete(s *state.State, r *http.Request) response.Response {
 
 	info, err := leader.Cluster(s.Context)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	index := -1
@@ -425,17 +1045,17 @@ func clusterMemberDelete(s *state.State, r *http.Request) response.Response {
 	}
 
 	if index < 0 {
-		return response.SmartError(fmt.Errorf("No dqlite cluster member exists with the given name %q", name))
+		return rest.SmartError(fmt.Errorf("No dqlite cluster member exists with the given name %q", name))
 	}
 
 	localClient, err := internalClient.New(s.OS.ControlSocket(), nil, nil, false)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	clusterMembers, err := localClient.GetClusterMembers(s.Context)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	numPending := 0
@@ -446,11 +1066,11 @@ func clusterMemberDelete(s *state.State, r *http.Request) response.Response {
 	}
 
 	if len(clusterMembers)-numPending < 2 {
-		return response.SmartError(fmt.Errorf("Cannot remove cluster members, there are no remaining non-pending members"))
+		return rest.SmartError(fmt.Errorf("Cannot remove cluster members, there are no remaining non-pending members"))
 	}
 
 	if len(info) < 2 {
-		return response.SmartError(fmt.Errorf("Cannot leave a cluster with %d members", len(info)))
+		return rest.SmartError(fmt.Errorf("Cannot leave a cluster with %d members", len(info)))
 	}
 
 	// If we are removing the leader of a 2-node cluster, ensure the remaining node is a voter.
@@ -459,7 +1079,7 @@ func clusterMemberDelete(s *state.State, r *http.Request) response.Response {
 			if node.Address != leaderInfo.Address && node.Role != dqliteClient.Voter {
 				err = leader.Assign(ctx, node.ID, dqliteClient.Voter)
 				if err != nil {
-					return response.SmartError(err)
+					return rest.SmartError(err)
 				}
 			}
 		}
@@ -475,18 +1095,18 @@ func clusterMemberDelete(s *state.State, r *http.Request) response.Response {
 		}
 
 		if len(otherNodes) == 0 {
-			return response.SmartError(fmt.Errorf("Found no voters to transfer leadership to"))
+			return rest.SmartError(fmt.Errorf("Found no voters to transfer leadership to"))
 		}
 
 		randomID := otherNodes[rand.Intn(len(otherNodes))]
 		err = leader.Transfer(ctx, randomID)
 		if err != nil {
-			return response.SmartError(err)
+			return rest.SmartError(err)
 		}
 
 		client, err := s.Leader()
 		if err != nil {
-			return response.SmartError(err)
+			return rest.SmartError(err)
 		}
 
 		clusterDisableMu.Lock()
@@ -501,7 +1121,7 @@ func clusterMemberDelete(s *state.State, r *http.Request) response.Response {
 
 		err = client.DeleteClusterMember(s.Context, name, force)
 		if err != nil {
-			return response.SmartError(err)
+			return rest.SmartError(err)
 		}
 
 		return response.ManualResponse(func(w http.ResponseWriter) error {
@@ -524,33 +1144,57 @@ func clusterMemberDelete(s *state.State, r *http.Request) response.Response {
 
 	// Remove the cluster member from the database.
 	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
-		return cluster.DeleteInternalClusterMember(ctx, tx, info[index].Address)
+		err := cluster.DeleteInternalClusterMember(ctx, tx, info[index].Address)
+		if err != nil {
+			return err
+		}
+
+		message := "Removed from the cluster"
+		if force {
+			message = "Forcibly removed from the cluster"
+		}
+
+		_, err = cluster.CreateInternalEvent(ctx, tx, cluster.NewInternalEvent(cluster.EventMemberRemoved, name, message))
+		if err != nil {
+			return err
+		}
+
+		_, err = cluster.CreateInternalTrustAudit(ctx, tx, cluster.NewInternalTrustAudit(cluster.TrustAuditRemove, cluster.TrustAuditClusterMember, name, shared.CertFingerprint(remote.Certificate.Certificate), access.Requestor(r)))
+		return err
 	})
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	publicKey, err := s.ClusterCert().PublicKeyX509()
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	// Set the forwarded flag so that the the system to be removed knows the removal is in progress.
 	c, err := internalClient.New(remote.URL(), s.ServerCert(), publicKey, true)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	// Tell the cluster member to run its PreRemove hook and return.
 	err = c.ResetClusterMember(s.Context, name, force)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
+	}
+
+	// Demote the node before removing it, so that it no longer participates in dqlite consensus.
+	if info[index].Role != dqliteClient.Spare {
+		err = leader.Assign(ctx, info[index].ID, dqliteClient.Spare)
+		if err != nil && !force {
+			return rest.SmartError(fmt.Errorf("Failed to demote cluster member %q: %w", name, err))
+		}
 	}
 
 	// Remove the node from dqlite.
 	err = leader.Remove(s.Context, info[index].ID)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	newRemotes := []internalTypes.ClusterMember{}
@@ -564,34 +1208,34 @@ func clusterMemberDelete(s *state.State, r *http.Request) response.Response {
 	// Remove the cluster member from the leader's trust store.
 	err = s.Remotes().Replace(s.OS.TrustDir, newRemotes...)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	c, err = internalClient.New(remote.URL(), s.ServerCert(), publicKey, false)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	err = c.ResetClusterMember(s.Context, name, force)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	cluster, err := s.Cluster(nil)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	err = state.PostRemoveHook(s, force)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	err = cluster.Query(s.Context, true, func(ctx context.Context, c *client.Client) error {
 		return c.DeleteClusterMember(ctx, name, force)
 	})
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	return response.EmptySyncResponse
@@ -0,0 +1,101 @@
+package resources
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/client"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalClient "github.com/canonical/microcluster/internal/rest/client"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+	"github.com/canonical/microcluster/rest/types"
+)
+
+var compatibilityCmd = rest.Endpoint{
+	Path:              "compatibility",
+	AllowedBeforeInit: true,
+
+	Get: rest.EndpointAction{Handler: compatibilityGet, AccessHandler: access.AllowAuthenticated},
+}
+
+// compatibilityGet returns the compatibility information of this cluster member. If the "all" query parameter is set,
+// the compatibility information of all other cluster members is included.
+func compatibilityGet(s *state.State, r *http.Request) response.Response {
+	results := []internalTypes.Compatibility{localCompatibility(s)}
+
+	if r.URL.Query().Get("all") != "1" {
+		return response.SyncResponse(true, results)
+	}
+
+	if !s.Database.IsOpen() {
+		return response.Unavailable(nil)
+	}
+
+	cluster, err := s.Cluster(nil)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	mu := sync.Mutex{}
+	err = cluster.Query(s.Context, true, func(ctx context.Context, c *client.Client) error {
+		compat, err := c.GetCompatibility(ctx, false)
+		if err != nil {
+			compat = []internalTypes.Compatibility{{Name: c.URL().URL.Host, Error: err.Error()}}
+		}
+
+		mu.Lock()
+		results = append(results, compat...)
+		mu.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.SyncResponse(true, results)
+}
+
+// localCompatibility returns the compatibility information of this cluster member.
+func localCompatibility(s *state.State) internalTypes.Compatibility {
+	internal, extended := s.Database.SchemaVersions()
+
+	return internalTypes.Compatibility{
+		Name:                  s.Name(),
+		InternalSchemaVersion: internal,
+		AppSchemaVersion:      extended,
+		APIVersion:            internalClient.APIVersion,
+		MinPeerSchemaVersion:  internal + extended,
+		MaxPeerSchemaVersion:  internal + extended,
+	}
+}
+
+// validateJoin checks that the compatibility information supplied by a joining cluster member matches this cluster
+// member, and runs the ValidateJoin hook. Incompatible members are rejected with a conflict error, and members
+// rejected by the hook with a forbidden error.
+func validateJoin(s *state.State, req internalTypes.ClusterMember) error {
+	if req.Compatibility != nil {
+		local := localCompatibility(s)
+		joiner := *req.Compatibility
+		if joiner.InternalSchemaVersion != local.InternalSchemaVersion || joiner.AppSchemaVersion != local.AppSchemaVersion {
+			return rest.Errorf(http.StatusConflict, types.ErrorCodeSchemaMismatch, "Cluster member %q has schema version %d (internal) / %d (app), but the cluster has %d (internal) / %d (app)", req.Name, joiner.InternalSchemaVersion, joiner.AppSchemaVersion, local.InternalSchemaVersion, local.AppSchemaVersion)
+		}
+
+		if joiner.APIVersion != local.APIVersion {
+			return api.StatusErrorf(http.StatusConflict, "Cluster member %q has API version %q, but the cluster has %q", req.Name, joiner.APIVersion, local.APIVersion)
+		}
+	}
+
+	err := state.ValidateJoinHook(s, req.Name, req.JoinConfig)
+	if err != nil {
+		return api.StatusErrorf(http.StatusForbidden, "Cluster member %q was rejected: %v", req.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package resources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/lxd/util"
+
+	"github.com/canonical/microcluster/client"
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var configCmd = rest.Endpoint{
+	Path: "config",
+
+	Get:   rest.EndpointAction{Handler: configGet, AccessHandler: access.AllowAuthenticated},
+	Put:   rest.EndpointAction{Handler: configPut, AccessHandler: access.AllowAuthenticated},
+	Patch: rest.EndpointAction{Handler: configPatch, AccessHandler: access.AllowAuthenticated},
+}
+
+// configGet returns the cluster-wide config, with an ETag that can be passed in the If-Match header of a subsequent
+// PUT or PATCH request to prevent overwriting concurrent changes.
+func configGet(s *state.State, r *http.Request) response.Response {
+	config, err := s.ClusterConfig()
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.SyncResponseETag(true, internalTypes.ClusterConfig{Config: config}, config)
+}
+
+// configPut replaces the cluster-wide config. Fails with 412 Precondition Failed if the config no longer matches the
+// ETag given in the If-Match header.
+func configPut(s *state.State, r *http.Request) response.Response {
+	return updateConfig(s, r, false)
+}
+
+// configPatch merges the given keys into the cluster-wide config. Keys with empty values are removed.
+// Fails with 412 Precondition Failed if the config no longer matches the ETag given in the If-Match header.
+func configPatch(s *state.State, r *http.Request) response.Response {
+	return updateConfig(s, r, true)
+}
+
+func updateConfig(s *state.State, r *http.Request, merge bool) response.Response {
+	req := internalTypes.ClusterConfig{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	// If we received a forwarded request, assume the config was already updated with the given changes,
+	// and execute the config change hook.
+	if client.IsForwardedRequest(r) {
+		err := state.OnConfigChangeHook(s, configKeys(req.Config))
+		if err != nil {
+			return rest.SmartError(fmt.Errorf("Failed to run config change hook: %w", err))
+		}
+
+		return response.EmptySyncResponse
+	}
+
+	changes := map[string]string{}
+	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		current, err := cluster.GetClusterConfig(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		err = util.EtagCheck(r, current)
+		if err != nil {
+			return err
+		}
+
+		for key, value := range req.Config {
+			if current[key] != value {
+				changes[key] = value
+			}
+		}
+
+		if !merge {
+			for key := range current {
+				_, ok := req.Config[key]
+				if !ok {
+					changes[key] = ""
+				}
+			}
+		}
+
+		return cluster.UpdateClusterConfig(ctx, tx, changes)
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	if len(changes) == 0 {
+		return response.EmptySyncResponse
+	}
+
+	err = state.OnConfigChangeHook(s, configKeys(changes))
+	if err != nil {
+		return rest.SmartError(fmt.Errorf("Failed to run config change hook: %w", err))
+	}
+
+	cluster, err := s.Cluster(nil)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	err = cluster.Query(s.Context, true, func(ctx context.Context, c *client.Client) error {
+		return c.PatchClusterConfig(ctx, changes)
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// configKeys returns the sorted keys of the given config.
+func configKeys(config map[string]string) []string {
+	keys := make([]string, 0, len(config))
+	for key := range config {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -2,20 +2,25 @@ package resources
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"time"
 
 	"github.com/canonical/lxd/lxd/response"
-	"github.com/canonical/lxd/lxd/util"
 	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
+	"github.com/google/renameio"
+	"gopkg.in/yaml.v2"
 
 	"github.com/canonical/microcluster/internal/rest/access"
 	"github.com/canonical/microcluster/internal/rest/client"
 	internalTypes "github.com/canonical/microcluster/internal/rest/types"
 	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/internal/sys"
 	"github.com/canonical/microcluster/internal/trust"
 	"github.com/canonical/microcluster/rest"
 	"github.com/canonical/microcluster/rest/types"
@@ -36,31 +41,55 @@ func controlPost(state *state.State, r *http.Request) response.Response {
 	}
 
 	if req.Bootstrap && req.JoinToken != "" {
-		return response.SmartError(fmt.Errorf("Invalid options - received join token and bootstrap flag"))
+		return rest.SmartError(fmt.Errorf("Invalid options - received join token and bootstrap flag"))
 	}
 
 	if req.JoinToken != "" {
 		return joinWithToken(state, req)
 	}
 
+	if !req.Bootstrap && len(req.Tokens) > 0 {
+		return response.BadRequest(fmt.Errorf("Join tokens can only be recorded when bootstrapping"))
+	}
+
 	daemonConfig := &trust.Location{Address: req.Address, Name: req.Name}
 	err = state.StartAPI(req.Bootstrap, req.InitConfig, daemonConfig)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
+	}
+
+	if len(req.Tokens) > 0 {
+		err = recordTokens(state, req.Tokens)
+		if err != nil {
+			return rest.SmartError(err)
+		}
 	}
 
 	return response.EmptySyncResponse
 }
 
 func joinWithToken(state *state.State, req *internalTypes.Control) response.Response {
+	if state.Database.IsOpen() {
+		return response.BadRequest(fmt.Errorf("This cluster member has already joined a cluster"))
+	}
+
 	token, err := internalTypes.DecodeToken(req.JoinToken)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
+	}
+
+	// Without a fingerprint, the cluster certificate can not be verified before the join secret is sent.
+	if token.Fingerprint == "" {
+		return response.BadRequest(fmt.Errorf("Join token %q has no cluster certificate fingerprint", token.Name))
+	}
+
+	if !token.ExpiresAt.IsZero() && time.Now().After(token.ExpiresAt) {
+		return response.BadRequest(fmt.Errorf("Join token %q expired at %s", token.Name, token.ExpiresAt))
 	}
 
 	serverCert, err := state.ServerCert().PublicKeyX509()
 	if err != nil {
-		return response.SmartError(fmt.Errorf("Failed to parse server certificate when bootstrapping API: %w", err))
+		return rest.SmartError(fmt.Errorf("Failed to parse server certificate when bootstrapping API: %w", err))
 	}
 
 	// Add the local node to the list of clusterMembers.
@@ -79,68 +108,184 @@ func joinWithToken(state *state.State, req *internalTypes.Control) response.Resp
 		},
 		SchemaVersion: state.Database.Schema().Version(),
 		Secret:        token.Secret,
+		JoinConfig:    req.InitConfig,
+	}
+
+	compat := localCompatibility(state)
+	newClusterMember.Compatibility = &compat
+
+	// Record the join before contacting the cluster, so that if the daemon crashes before the join completes, it can
+	// reset its partial state and retry the join on the next start.
+	err = writeJoinState(state, req)
+	if err != nil {
+		return rest.SmartError(err)
 	}
 
 	// Get a client to the target address.
 	var joinInfo *internalTypes.TokenResponse
-	for _, addr := range token.JoinAddresses {
-		url := api.NewURL().Scheme("https").Host(addr.String())
+	var joinErr error
+	// Join hostnames are kept as the host of the URL, so that they are resolved again on each connection attempt.
+	for _, addr := range token.Addresses() {
+		url := api.NewURL().Scheme("https").Host(addr)
 
 		cert, err := shared.GetRemoteCertificate(url.String(), "")
 		if err != nil {
-			return response.SmartError(fmt.Errorf("Failed to get certificate of cluster member %q: %w", url.URL.Host, err))
+			return rest.SmartError(fmt.Errorf("Failed to get certificate of cluster member %q: %w", url.URL.Host, err))
 		}
 
 		fingerprint := shared.CertFingerprint(cert)
 		if fingerprint != token.Fingerprint {
-			return response.SmartError(fmt.Errorf("Cluster certificate token does not match that of cluster member %q", url.URL.Host))
+			return rest.SmartError(fmt.Errorf("Cluster certificate token does not match that of cluster member %q", url.URL.Host))
 		}
 
 		d, err := client.New(*url, state.ServerCert(), cert, false)
 		if err != nil {
-			return response.SmartError(err)
+			return rest.SmartError(err)
+		}
+
+		if token.Signature != "" {
+			err = token.Verify(cert)
+			if err != nil {
+				removeJoinState(state)
+				return rest.SmartError(fmt.Errorf("Failed to verify join token %q: %w", token.Name, err))
+			}
+		} else {
+			// Unsigned tokens are only accepted from clusters with members too old to sign them.
+			signed, err := clusterSignsTokens(d)
+			if err != nil {
+				logger.Warn("Failed to check whether the cluster signs join tokens", logger.Ctx{"address": url.URL.Host, "error": err})
+			}
+
+			if signed {
+				removeJoinState(state)
+				return response.Forbidden(fmt.Errorf("Join token %q is not signed, but all cluster members sign join tokens", token.Name))
+			}
+
+			logger.Warn("Join token is not signed by the cluster, it may have been issued by an older cluster member", logger.Ctx{"name": token.Name})
 		}
 
 		joinInfo, err = d.AddClusterMember(context.Background(), newClusterMember)
 		if err != nil {
-			logger.Error("Unable to complete cluster join request", logger.Ctx{"address": addr.String(), "error": err})
+			// The cluster rejected this member, so there is no point in trying other addresses.
+			if api.StatusErrorCheck(err, http.StatusConflict) || api.StatusErrorCheck(err, http.StatusForbidden) {
+				removeJoinState(state)
+				return rest.SmartError(fmt.Errorf("Cluster refused to accept join request: %w", err))
+			}
+
+			logger.Error("Unable to complete cluster join request", logger.Ctx{"address": addr, "error": err})
+			joinErr = err
 		} else {
 			break
 		}
 	}
 
 	if joinInfo == nil {
-		return response.SmartError(fmt.Errorf("Failed to join cluster with the given join token"))
+		removeJoinState(state)
+
+		// Keep the status of a busy cluster so that the caller knows to retry later, and the reason a name, address or
+		// certificate is already in use.
+		if api.StatusErrorCheck(joinErr, http.StatusServiceUnavailable, http.StatusConflict) {
+			return rest.SmartError(fmt.Errorf("Failed to join cluster with the given join token: %w", joinErr))
+		}
+
+		return rest.SmartError(fmt.Errorf("Failed to join cluster with the given join token"))
+	}
+
+	// The cluster does not share its cluster key if it is held by an external signer, in which case this cluster member
+	// must hold the same key.
+	if joinInfo.ClusterKey == "" {
+		external, err := sys.ExternalKey("cluster")
+		if err != nil {
+			return rest.SmartError(err)
+		}
+
+		if !external {
+			removeJoinState(state)
+			return rest.SmartError(fmt.Errorf("Cluster key is held by an external signer, but none is configured for this cluster member"))
+		}
 	}
 
-	err = util.WriteCert(state.OS.StateDir, "cluster", []byte(joinInfo.ClusterCert.String()), []byte(joinInfo.ClusterKey), nil)
+	err = state.OS.WriteKeyPair("cluster", []byte(joinInfo.ClusterCert.String()), []byte(joinInfo.ClusterKey))
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
+	}
+
+	if joinInfo.SecretsKey != "" {
+		secretsKey, err := hex.DecodeString(joinInfo.SecretsKey)
+		if err != nil {
+			return rest.SmartError(fmt.Errorf("Invalid secrets key: %w", err))
+		}
+
+		err = state.OS.WriteSecretsKey(secretsKey)
+		if err != nil {
+			return rest.SmartError(err)
+		}
 	}
 
+	// Replace the trust store rather than adding to it, as it may hold entries left over from an interrupted join.
 	joinAddrs := types.AddrPorts{}
-	clusterMembers := make([]trust.Remote, 0, len(joinInfo.ClusterMembers))
+	clusterMembers := make([]internalTypes.ClusterMember, 0, len(joinInfo.ClusterMembers)+1)
 	for _, clusterMember := range joinInfo.ClusterMembers {
-		remote := trust.Remote{
-			Location:    trust.Location{Name: clusterMember.Name, Address: clusterMember.Address},
-			Certificate: clusterMember.Certificate,
+		// When rejoining, the cluster already knows about this cluster member.
+		if clusterMember.Name == localClusterMember.Name {
+			continue
 		}
 
 		joinAddrs = append(joinAddrs, clusterMember.Address)
-		clusterMembers = append(clusterMembers, remote)
+		clusterMembers = append(clusterMembers, internalTypes.ClusterMember{ClusterMemberLocal: clusterMember})
 	}
 
-	clusterMembers = append(clusterMembers, localClusterMember)
-	err = state.Remotes().Add(state.OS.TrustDir, clusterMembers...)
+	clusterMembers = append(clusterMembers, internalTypes.ClusterMember{
+		ClusterMemberLocal: internalTypes.ClusterMemberLocal{
+			Name:        localClusterMember.Name,
+			Address:     localClusterMember.Address,
+			Certificate: localClusterMember.Certificate,
+		},
+	})
+
+	err = state.Remotes().Replace(state.OS.TrustDir, clusterMembers...)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	// Start the HTTPS listeners and join Dqlite.
 	err = state.StartAPI(false, req.InitConfig, daemonConfig, joinAddrs.Strings()...)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	return response.EmptySyncResponse
 }
+
+// clusterSignsTokens returns whether every member of the cluster reached by the given client signs join tokens.
+func clusterSignsTokens(d *client.Client) (bool, error) {
+	apiExtensions, err := d.GetAPIExtensions(context.Background())
+	if err != nil {
+		return false, err
+	}
+
+	return shared.ValueInSlice("join_token_signatures", apiExtensions.Common), nil
+}
+
+// writeJoinState records the given join request in the state directory until the daemon has joined dqlite.
+func writeJoinState(state *state.State, req *internalTypes.Control) error {
+	data, err := yaml.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("Failed to encode join request: %w", err)
+	}
+
+	err = renameio.WriteFile(state.OS.JoinStatePath(), data, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to record join request: %w", err)
+	}
+
+	return nil
+}
+
+// removeJoinState removes the record of an ongoing join from the state directory.
+func removeJoinState(state *state.State) {
+	err := os.Remove(state.OS.JoinStatePath())
+	if err != nil && !os.IsNotExist(err) {
+		logger.Warn("Failed to remove join request record", logger.Ctx{"error": err})
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/canonical/lxd/lxd/response"
 
+	"github.com/canonical/microcluster/internal/rest/access"
 	"github.com/canonical/microcluster/internal/state"
 	"github.com/canonical/microcluster/rest"
 )
@@ -15,8 +16,8 @@ var databaseCmd = rest.Endpoint{
 	AllowedBeforeInit: true,
 	Path:              "database",
 
-	Post:  rest.EndpointAction{Handler: databasePost},
-	Patch: rest.EndpointAction{Handler: databasePatch},
+	Post:  rest.EndpointAction{Handler: databasePost, AccessHandler: access.AllowClusterMembers},
+	Patch: rest.EndpointAction{Handler: databasePatch, AccessHandler: access.AllowClusterMembers},
 }
 
 func databasePost(state *state.State, r *http.Request) response.Response {
@@ -0,0 +1,101 @@
+package resources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"time"
+
+	dqliteClient "github.com/canonical/go-dqlite/client"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/logger"
+
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+	"github.com/canonical/microcluster/rest/types"
+)
+
+var debugFailureCmd = rest.Endpoint{
+	Path: "debug/failure",
+
+	Post: rest.EndpointAction{Handler: debugFailurePost},
+}
+
+// EnableDebugEndpoints adds the endpoints used to simulate failure scenarios to the control socket.
+// These endpoints are meant for runbook rehearsal and resilience testing, and should not be enabled in production.
+func EnableDebugEndpoints() {
+	UnixEndpoints.Endpoints = append(UnixEndpoints.Endpoints, debugFailureCmd)
+}
+
+func debugFailurePost(s *state.State, r *http.Request) response.Response {
+	req := internalTypes.SimulateFailure{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	logger.Warn("Simulating failure", logger.Ctx{"action": req.Action, "duration": req.Duration})
+
+	switch req.Action {
+	case internalTypes.FailureDropLeadership:
+		err = dropLeadership(s)
+	case internalTypes.FailurePauseHeartbeats:
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid duration %q: %w", req.Duration, err))
+		}
+
+		s.Database.PauseHeartbeats(duration)
+	case internalTypes.FailureCloseDatabase:
+		err = s.Database.Stop()
+	default:
+		return response.BadRequest(fmt.Errorf("Unknown failure action %q", req.Action))
+	}
+
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// dropLeadership transfers dqlite leadership to a random voter if this cluster member is the leader.
+func dropLeadership(s *state.State) error {
+	ctx, cancel := context.WithTimeout(s.Context, 30*time.Second)
+	defer cancel()
+
+	leader, err := s.Database.Leader(ctx)
+	if err != nil {
+		return err
+	}
+
+	leaderInfo, err := leader.Leader(ctx)
+	if err != nil {
+		return err
+	}
+
+	if leaderInfo.Address != s.Address().URL.Host {
+		return rest.Errorf(http.StatusInternalServerError, types.ErrorCodeNotLeader, "Cluster member %q is not the leader", s.Name())
+	}
+
+	info, err := s.Database.Cluster(ctx, leader)
+	if err != nil {
+		return err
+	}
+
+	otherNodes := []uint64{}
+	for _, node := range info {
+		if node.Address != leaderInfo.Address && node.Role == dqliteClient.Voter {
+			otherNodes = append(otherNodes, node.ID)
+		}
+	}
+
+	if len(otherNodes) == 0 {
+		return fmt.Errorf("Found no voters to transfer leadership to")
+	}
+
+	return leader.Transfer(ctx, otherNodes[rand.Intn(len(otherNodes))])
+}
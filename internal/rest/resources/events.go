@@ -0,0 +1,239 @@
+package resources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/gorilla/websocket"
+
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var eventsCmd = rest.Endpoint{
+	Path: "events",
+
+	Get: rest.EndpointAction{Handler: eventsGet, AccessHandler: access.AllowAuthenticated},
+}
+
+// eventsPollInterval is how often event streams check for newly recorded events.
+const eventsPollInterval = time.Second
+
+// eventsGet returns the cluster event history, oldest first. It can be filtered by event type and cluster member with
+// the "type" and "member" query parameters, and to the events recorded after an RFC3339 time with the "since" query
+// parameter. The "type" query parameter can be given more than once.
+//
+// Requests upgrading to a WebSocket, or accepting "text/event-stream" for Server-Sent Events, instead stream the
+// matching events as they are recorded by any cluster member. Streams start with the events after "since" if given,
+// and otherwise with the next recorded event.
+func eventsGet(s *state.State, r *http.Request) response.Response {
+	filter := cluster.InternalEventFilter{}
+
+	eventTypes := map[cluster.EventType]bool{}
+	for _, value := range r.URL.Query()["type"] {
+		eventType := cluster.EventType(value)
+		valid := false
+		for _, validType := range cluster.EventTypes {
+			if eventType == validType {
+				valid = true
+				break
+			}
+		}
+
+		if !valid {
+			return response.BadRequest(fmt.Errorf("Invalid event type %q", eventType))
+		}
+
+		eventTypes[eventType] = true
+		if len(r.URL.Query()["type"]) == 1 {
+			filter.Type = &eventType
+		}
+	}
+
+	member := r.URL.Query().Get("member")
+	if member != "" {
+		filter.Member = &member
+	}
+
+	var since time.Time
+	value := r.URL.Query().Get("since")
+	if value != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid %q query parameter %q: %w", "since", value, err))
+		}
+
+		since = since.UTC()
+	}
+
+	if websocket.IsWebSocketUpgrade(r) || strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		return eventsStream(s, r, filter, since, eventTypes)
+	}
+
+	var events []internalTypes.Event
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		dbEvents, err := cluster.GetInternalEventsAfter(ctx, tx, filter, 0, since)
+		if err != nil {
+			return err
+		}
+
+		events = make([]internalTypes.Event, 0, len(dbEvents))
+		for _, event := range dbEvents {
+			if len(eventTypes) > 0 && !eventTypes[event.Type] {
+				continue
+			}
+
+			events = append(events, event.ToAPI())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return rest.CollectionResponse(r, events)
+}
+
+// eventsStream returns a response streaming the events matching the filter and event types over a WebSocket, or as
+// Server-Sent Events. Server-Sent Events clients can resume a stream with the "Last-Event-ID" header.
+func eventsStream(s *state.State, r *http.Request, filter cluster.InternalEventFilter, since time.Time, eventTypes map[cluster.EventType]bool) response.Response {
+	var lastID int64
+	value := r.Header.Get("Last-Event-ID")
+	if value != "" {
+		var err error
+		lastID, err = strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid %q header %q: %w", "Last-Event-ID", value, err))
+		}
+	} else if since.IsZero() {
+		err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+			var err error
+			lastID, err = cluster.GetInternalEventsLastID(ctx, tx)
+			return err
+		})
+		if err != nil {
+			return rest.SmartError(err)
+		}
+	}
+
+	if websocket.IsWebSocketUpgrade(r) {
+		return rest.WebSocketResponse(r, func(conn *websocket.Conn) error {
+			ctx, cancel := context.WithCancel(s.Context)
+			defer cancel()
+
+			// Keep reading from the connection to handle control messages, and to notice when the client closes it.
+			go func() {
+				for {
+					_, _, err := conn.NextReader()
+					if err != nil {
+						cancel()
+						return
+					}
+				}
+			}()
+
+			return watchEvents(ctx, s, filter, since, eventTypes, lastID, func(event internalTypes.Event) error {
+				return conn.WriteJSON(event)
+			})
+		})
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			return fmt.Errorf("Webserver does not support streaming")
+		}
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		go func() {
+			select {
+			case <-s.Context.Done():
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		err := watchEvents(ctx, s, filter, since, eventTypes, lastID, func(event internalTypes.Event) error {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+			if err != nil {
+				return err
+			}
+
+			flusher.Flush()
+
+			return nil
+		})
+		if err != nil {
+			// The response is already sent, so the stream can only be ended.
+			logger.Debug("Event stream ended", logger.Ctx{"error": err})
+		}
+
+		return nil
+	})
+}
+
+// watchEvents sends the events matching the filter and event types that are recorded after the given time and after
+// the event with the given ID, until the context is cancelled.
+func watchEvents(ctx context.Context, s *state.State, filter cluster.InternalEventFilter, since time.Time, eventTypes map[cluster.EventType]bool, lastID int64, send func(event internalTypes.Event) error) error {
+	ticker := time.NewTicker(eventsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		var events []cluster.InternalEvent
+		err := s.Database.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			var err error
+			events, err = cluster.GetInternalEventsAfter(ctx, tx, filter, lastID, since)
+			return err
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		for _, event := range events {
+			lastID = event.ID
+			if len(eventTypes) > 0 && !eventTypes[event.Type] {
+				continue
+			}
+
+			err = send(event.ToAPI())
+			if err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
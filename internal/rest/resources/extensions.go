@@ -0,0 +1,34 @@
+package resources
+
+import (
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/response"
+
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var extensionsCmd = rest.Endpoint{
+	Path: "extensions",
+
+	// Joining cluster members check the common API extensions before they are trusted.
+	Get: rest.EndpointAction{Handler: extensionsGet, AllowUntrusted: true},
+}
+
+// extensionsGet returns the API extensions supported by each cluster member, and those supported by all of them.
+// Untrusted callers only get the API extensions supported by all cluster members.
+func extensionsGet(s *state.State, r *http.Request) response.Response {
+	members, common, err := s.APIExtensions()
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	if !access.Trusted(r) {
+		return response.SyncResponse(true, internalTypes.APIExtensions{Common: common})
+	}
+
+	return response.SyncResponse(true, internalTypes.APIExtensions{Common: common, Members: members})
+}
@@ -0,0 +1,142 @@
+package resources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/microcluster/client"
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var featuresCmd = rest.Endpoint{
+	Path: "features",
+
+	Get: rest.EndpointAction{Handler: featuresGet, AccessHandler: access.AllowAuthenticated},
+}
+
+var featureCmd = rest.Endpoint{
+	Path:       "features/{name}",
+	PathParams: map[string]rest.PathParam{"name": rest.PathParamName},
+
+	Put:    rest.EndpointAction{Handler: featurePut, AccessHandler: access.AllowAuthenticated},
+	Delete: rest.EndpointAction{Handler: featureDelete, AccessHandler: access.AllowAuthenticated},
+}
+
+func featuresGet(s *state.State, r *http.Request) response.Response {
+	var flags []internalTypes.FeatureFlag
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		dbFlags, err := cluster.GetInternalFeatureFlags(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		flags = make([]internalTypes.FeatureFlag, 0, len(dbFlags))
+		for _, flag := range dbFlags {
+			flags = append(flags, flag.ToAPI())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return rest.CollectionResponse(r, flags)
+}
+
+func featurePut(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	// If we received a forwarded request, assume the flag was already updated, and execute the feature change hook.
+	if client.IsForwardedRequest(r) {
+		err := state.OnFeatureChangeHook(s, name)
+		if err != nil {
+			return rest.SmartError(fmt.Errorf("Failed to run feature change hook: %w", err))
+		}
+
+		return response.EmptySyncResponse
+	}
+
+	req := internalTypes.FeatureFlag{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	req.Name = name
+	if req.Percentage < 0 || req.Percentage > 100 {
+		return response.BadRequest(fmt.Errorf("Rollout percentage must be between 0 and 100"))
+	}
+
+	flag := cluster.InternalFeatureFlag{
+		Name:       name,
+		Enabled:    req.Enabled,
+		Members:    req.Members,
+		Percentage: req.Percentage,
+	}
+
+	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		return cluster.UpsertInternalFeatureFlag(ctx, tx, flag)
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return notifyFeatureChange(s, name, func(ctx context.Context, c *client.Client) error {
+		return c.UpdateFeatureFlag(ctx, req)
+	})
+}
+
+func featureDelete(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	// If we received a forwarded request, assume the flag was already deleted, and execute the feature change hook.
+	if client.IsForwardedRequest(r) {
+		err := state.OnFeatureChangeHook(s, name)
+		if err != nil {
+			return rest.SmartError(fmt.Errorf("Failed to run feature change hook: %w", err))
+		}
+
+		return response.EmptySyncResponse
+	}
+
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		return cluster.DeleteInternalFeatureFlag(ctx, tx, name)
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return notifyFeatureChange(s, name, func(ctx context.Context, c *client.Client) error {
+		return c.DeleteFeatureFlag(ctx, name)
+	})
+}
+
+// notifyFeatureChange runs the feature change hook locally, and then forwards the change notification to all other
+// cluster members so that they can run the hook as well.
+func notifyFeatureChange(s *state.State, name string, notify func(context.Context, *client.Client) error) response.Response {
+	err := state.OnFeatureChangeHook(s, name)
+	if err != nil {
+		return rest.SmartError(fmt.Errorf("Failed to run feature change hook: %w", err))
+	}
+
+	cluster, err := s.Cluster(nil)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	err = cluster.Query(s.Context, true, notify)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
@@ -14,23 +14,29 @@ import (
 
 	"github.com/canonical/microcluster/client"
 	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/access"
 	internalClient "github.com/canonical/microcluster/internal/rest/client"
 	"github.com/canonical/microcluster/internal/rest/types"
 	"github.com/canonical/microcluster/internal/state"
 	"github.com/canonical/microcluster/rest"
+	apiTypes "github.com/canonical/microcluster/rest/types"
 )
 
 var heartbeatCmd = rest.Endpoint{
 	Path: "heartbeat",
 
-	Post: rest.EndpointAction{Handler: heartbeatPost, AllowUntrusted: true},
+	Post: rest.EndpointAction{Handler: heartbeatPost, AccessHandler: access.AllowClusterMembers},
 }
 
 func heartbeatPost(s *state.State, r *http.Request) response.Response {
 	var hbInfo types.HeartbeatInfo
 	err := json.NewDecoder(r.Body).Decode(&hbInfo)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
+	}
+
+	if s.Database.HeartbeatsPaused() {
+		return response.Unavailable(fmt.Errorf("Heartbeats are paused on this cluster member"))
 	}
 
 	if hbInfo.BeginRound {
@@ -41,19 +47,24 @@ func heartbeatPost(s *state.State, r *http.Request) response.Response {
 	// so we should update our local store of cluster members with the data from the heartbeat.
 
 	if !s.Database.IsOpen() {
-		return response.SmartError(fmt.Errorf("Failed to respond to heartbeat, database is not yet open"))
+		return rest.SmartError(fmt.Errorf("Failed to respond to heartbeat, database is not yet open"))
 	}
 
-	clusterMemberList := []types.ClusterMember{}
-	for _, clusterMember := range hbInfo.ClusterMembers {
-		clusterMemberList = append(clusterMemberList, clusterMember)
+	// Leaders that predate the full member list only send the members that took part in the heartbeat.
+	members := hbInfo.Members
+	if len(members) == 0 {
+		for _, clusterMember := range hbInfo.ClusterMembers {
+			members = append(members, clusterMember.ClusterMemberLocal)
+		}
 	}
 
-	err = s.Remotes().Replace(s.OS.TrustDir, clusterMemberList...)
+	err = reconcileTrustStore(s, members)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
+	updateAppStatus(s)
+
 	var schemaVersion int
 	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
 		localClusterMember, err := cluster.GetInternalClusterMember(ctx, tx, s.Name())
@@ -66,13 +77,13 @@ func heartbeatPost(s *state.State, r *http.Request) response.Response {
 		return nil
 	})
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	if schemaVersion != hbInfo.MaxSchema {
 		err := s.Database.Update()
 		if err != nil {
-			return response.SmartError(err)
+			return rest.SmartError(err)
 		}
 	}
 
@@ -91,16 +102,16 @@ func beginHeartbeat(s *state.State, r *http.Request) response.Response {
 	// Only a leader can begin a heartbeat round.
 	leader, err := s.Database.Leader(ctx)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	leaderInfo, err := leader.Leader(ctx)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	if s.Address().URL.Host != leaderInfo.Address {
-		return response.SmartError(fmt.Errorf("Attempt to initiate heartbeat from non-leader"))
+		return rest.SmartError(rest.Errorf(http.StatusInternalServerError, apiTypes.ErrorCodeNotLeader, "Attempt to initiate heartbeat from non-leader"))
 	}
 
 	// Get the database record of cluster members.
@@ -124,13 +135,13 @@ func beginHeartbeat(s *state.State, r *http.Request) response.Response {
 		return err
 	})
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	// Get dqlite record of cluster members.
 	dqliteCluster, err := s.Database.Cluster(ctx, leader)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	if len(clusterMembers) == 0 || len(dqliteCluster) == 0 {
@@ -188,16 +199,17 @@ func beginHeartbeat(s *state.State, r *http.Request) response.Response {
 	// Update local record of cluster members from the database, including any pending nodes for authentication.
 	err = s.Remotes().Replace(s.OS.TrustDir, clusterMembers...)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	// Set the time of the last heartbeat to now.
 	leaderEntry.LastHeartbeat = time.Now()
 	clusterMap[s.Address().URL.Host] = leaderEntry
 
-	// Record the maximum schema version discovered.
-	hbInfo := types.HeartbeatInfo{ClusterMembers: clusterMap}
+	// Record every cluster member and the maximum schema version discovered.
+	hbInfo := types.HeartbeatInfo{ClusterMembers: clusterMap, Members: make([]types.ClusterMemberLocal, 0, len(clusterMembers))}
 	for _, node := range clusterMembers {
+		hbInfo.Members = append(hbInfo.Members, node.ClusterMemberLocal)
 		if node.SchemaVersion > hbInfo.MaxSchema {
 			hbInfo.MaxSchema = node.SchemaVersion
 		}
@@ -205,7 +217,7 @@ func beginHeartbeat(s *state.State, r *http.Request) response.Response {
 
 	clusterClients, err := s.Cluster(nil)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	// Use a lock to handle concurrent access to hbInfo.
@@ -232,6 +244,14 @@ func beginHeartbeat(s *state.State, r *http.Request) response.Response {
 		err := c.Heartbeat(ctx, hbInfo)
 		if err != nil {
 			logger.Error("Received error sending heartbeat to cluster member", logger.Ctx{"target": addr, "error": err})
+
+			// The cluster member may be unable to start its database because its trust store is outdated, so
+			// push the trust store directly, as that does not need the database.
+			err = c.UpdateTrustStore(ctx, hbInfo.Members)
+			if err != nil {
+				logger.Debug("Failed to push trust store to cluster member", logger.Ctx{"target": addr, "error": err})
+			}
+
 			return nil
 		}
 
@@ -244,40 +264,113 @@ func beginHeartbeat(s *state.State, r *http.Request) response.Response {
 		return nil
 	})
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	// Having sent a heartbeat to each valid cluster member, update the database record of members.
+	memberStatusMu.Lock()
+	defer memberStatusMu.Unlock()
+
+	var statuses map[string]types.MemberStatus
 	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
 		dbClusterMembers, err := cluster.GetInternalClusterMembers(ctx, tx)
 		if err != nil {
 			return err
 		}
 
+		statuses = make(map[string]types.MemberStatus, len(dbClusterMembers))
 		for _, clusterMember := range dbClusterMembers {
 			heartbeatInfo, ok := hbInfo.ClusterMembers[clusterMember.Address]
 			if !ok {
 				continue
 			}
 
+			oldRole := clusterMember.Role
+			oldStatus, ok := memberStatuses[clusterMember.Name]
+			if !ok {
+				oldStatus = clusterMember.Status()
+			}
+
 			clusterMember.Heartbeat = heartbeatInfo.LastHeartbeat
 			clusterMember.Role = cluster.Role(heartbeatInfo.Role)
 			err = cluster.UpdateInternalClusterMember(ctx, tx, clusterMember.Name, clusterMember)
 			if err != nil {
 				return err
 			}
+
+			statuses[clusterMember.Name] = clusterMember.Status()
+			err = recordHeartbeatEvents(ctx, tx, clusterMember, oldRole, oldStatus)
+			if err != nil {
+				return err
+			}
 		}
 
 		return nil
 	})
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
+	memberStatuses = statuses
+
+	updateAppStatus(s)
+
 	err = state.OnHeartbeatHook(s)
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	return response.EmptySyncResponse
 }
+
+// memberStatuses holds the status of each cluster member as of the last heartbeat round sent by this member, so that
+// members going offline or coming back online can be detected between rounds.
+var memberStatuses = map[string]types.MemberStatus{}
+var memberStatusMu sync.Mutex
+
+// recordHeartbeatEvents records an event for any change in role or online status of the given cluster member since
+// the last heartbeat round. Role changes from pending are skipped, as joining the cluster is recorded separately.
+func recordHeartbeatEvents(ctx context.Context, tx *sql.Tx, clusterMember cluster.InternalClusterMember, oldRole cluster.Role, oldStatus types.MemberStatus) error {
+	if oldRole != clusterMember.Role && oldRole != cluster.Pending && clusterMember.Role != "" {
+		_, err := cluster.CreateInternalEvent(ctx, tx, cluster.NewInternalEvent(cluster.EventMemberRoleChanged, clusterMember.Name, fmt.Sprintf("Role changed from %q to %q", oldRole, clusterMember.Role)))
+		if err != nil {
+			return err
+		}
+	}
+
+	newStatus := clusterMember.Status()
+	if newStatus == types.MemberOffline && oldStatus != types.MemberOffline && oldStatus != types.MemberUnreachable {
+		_, err := cluster.CreateInternalEvent(ctx, tx, cluster.NewInternalEvent(cluster.EventMemberOffline, clusterMember.Name, fmt.Sprintf("No heartbeat since %s", clusterMember.Heartbeat.UTC().Format(time.RFC3339))))
+		if err != nil {
+			return err
+		}
+	} else if newStatus == types.MemberOnline && oldStatus == types.MemberOffline {
+		_, err := cluster.CreateInternalEvent(ctx, tx, cluster.NewInternalEvent(cluster.EventMemberOnline, clusterMember.Name, "Responded to heartbeat"))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// updateAppStatus records the application status fields returned by the HeartbeatStatus hook against the local
+// cluster member. Failures are logged rather than failing the heartbeat.
+func updateAppStatus(s *state.State) {
+	status, err := state.HeartbeatStatusHook(s)
+	if err != nil {
+		logger.Warn("Failed to get application status for heartbeat", logger.Ctx{"error": err})
+		return
+	}
+
+	if status == nil {
+		return
+	}
+
+	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		return cluster.UpdateClusterMemberAppStatus(ctx, tx, s.Name(), status)
+	})
+	if err != nil {
+		logger.Warn("Failed to record application status", logger.Ctx{"error": err})
+	}
+}
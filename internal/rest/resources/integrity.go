@@ -0,0 +1,75 @@
+package resources
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/response"
+
+	"github.com/canonical/microcluster/client"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var integrityCmd = rest.Endpoint{
+	Path: "database/integrity",
+
+	Get: rest.EndpointAction{Handler: integrityGet, AccessHandler: access.AllowClusterMembers},
+}
+
+// integrityGet runs an integrity check against the databases of this cluster member. If the "all" query parameter is
+// set, the check is also run on all other cluster members.
+func integrityGet(s *state.State, r *http.Request) response.Response {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	results := []internalTypes.IntegrityCheck{checkIntegrity(ctx, s)}
+	if r.URL.Query().Get("all") != "1" {
+		return response.SyncResponse(true, results)
+	}
+
+	cluster, err := s.Cluster(nil)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	mu := sync.Mutex{}
+	err = cluster.Query(ctx, true, func(ctx context.Context, c *client.Client) error {
+		checks, err := c.CheckDatabaseIntegrity(ctx, false)
+		if err != nil {
+			checks = []internalTypes.IntegrityCheck{{Name: c.URL().URL.Host, Error: err.Error()}}
+		}
+
+		mu.Lock()
+		results = append(results, checks...)
+		mu.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.SyncResponse(true, results)
+}
+
+// checkIntegrity runs an integrity check against the databases of this cluster member.
+func checkIntegrity(ctx context.Context, s *state.State) internalTypes.IntegrityCheck {
+	result := internalTypes.IntegrityCheck{Name: s.Name()}
+
+	global, local, err := s.Database.IntegrityCheck(ctx)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Global = global
+	result.Local = local
+	result.OK = len(global) == 1 && global[0] == "ok" && len(local) == 1 && local[0] == "ok"
+
+	return result
+}
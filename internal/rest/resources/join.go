@@ -0,0 +1,173 @@
+package resources
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	dqliteClient "github.com/canonical/go-dqlite/client"
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/cluster"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/internal/trust"
+	"github.com/canonical/microcluster/rest"
+	"github.com/canonical/microcluster/rest/types"
+)
+
+// presentsCertificate returns whether the request was made with the given certificate, or relayed by a trusted
+// cluster member.
+func presentsCertificate(s *state.State, r *http.Request, cert types.X509Certificate) bool {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 || cert.Certificate == nil {
+		return false
+	}
+
+	peer := r.TLS.PeerCertificates[0]
+	if peer.Equal(cert.Certificate) {
+		return true
+	}
+
+	return s.Remotes().RemoteByCertificateFingerprint(shared.CertFingerprint(peer)) != nil
+}
+
+// checkMemberUnique checks that the name, address and certificate of a joining cluster member are not used by any of
+// the given cluster members. It returns true if the cluster member was already admitted with the same address and
+// certificate but never finished joining.
+func checkMemberUnique(clusterMembers []cluster.InternalClusterMember, req internalTypes.ClusterMember) (bool, error) {
+	var rejoin bool
+	for _, clusterMember := range clusterMembers {
+		if clusterMember.Name == req.Name {
+			if clusterMember.Role != cluster.Pending || clusterMember.Address != req.Address.String() || clusterMember.Certificate != req.Certificate.String() {
+				return false, rest.Errorf(http.StatusConflict, types.ErrorCodeAlreadyMember, "Cluster member name %q is already in use by the cluster member at %q", req.Name, clusterMember.Address)
+			}
+
+			rejoin = true
+		} else if clusterMember.Address == req.Address.String() {
+			return false, rest.Errorf(http.StatusConflict, types.ErrorCodeAlreadyMember, "Address %q is already in use by cluster member %q", req.Address.String(), clusterMember.Name)
+		} else if clusterMember.Certificate == req.Certificate.String() {
+			return false, rest.Errorf(http.StatusConflict, types.ErrorCodeAlreadyMember, "Certificate of cluster member %q is already in use by cluster member %q", req.Name, clusterMember.Name)
+		}
+	}
+
+	return rejoin, nil
+}
+
+// checkExistingMember checks that the name and address of a joining cluster member are not in use. It returns true if
+// the cluster member was already admitted with the same address and certificate but never finished joining, in which
+// case the join can be retried without a new join token.
+func checkExistingMember(s *state.State, req internalTypes.ClusterMember) (bool, error) {
+	var rejoin bool
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		dbClusterMembers, err := cluster.GetInternalClusterMembers(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		rejoin, err = checkMemberUnique(dbClusterMembers, req)
+
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return rejoin, nil
+}
+
+// removeStaleRemote removes trust store entries with the name or address of the joining cluster member but no
+// database record, left over from an interrupted join. It must only be called once the join has been admitted, so that
+// the trust store can not be rewritten by unauthenticated join requests.
+func removeStaleRemote(s *state.State, req internalTypes.ClusterMember) error {
+	existingRemote := s.Remotes().RemoteByAddress(req.Address)
+	if existingRemote == nil {
+		remote, ok := s.Remotes().RemotesByName()[req.Name]
+		if ok {
+			existingRemote = &remote
+		}
+	}
+
+	if existingRemote == nil || existingRemote.Name == req.Name && existingRemote.Address.String() == req.Address.String() && existingRemote.Certificate.String() == req.Certificate.String() {
+		return nil
+	}
+
+	var clusterMembers []internalTypes.ClusterMember
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		dbClusterMembers, err := cluster.GetInternalClusterMembers(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		clusterMembers = make([]internalTypes.ClusterMember, 0, len(dbClusterMembers))
+		for _, clusterMember := range dbClusterMembers {
+			apiClusterMember, err := clusterMember.ToAPI()
+			if err != nil {
+				return err
+			}
+
+			clusterMembers = append(clusterMembers, *apiClusterMember)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.Warn("Removing trust store entry left over from an interrupted join", logger.Ctx{"name": existingRemote.Name, "address": existingRemote.Address.String()})
+	err = s.Remotes().Replace(s.OS.TrustDir, clusterMembers...)
+	if err != nil {
+		return fmt.Errorf("Failed to remove stale trust store entry %q: %w", existingRemote.Name, err)
+	}
+
+	return nil
+}
+
+// prepareRejoin lets a cluster member retry an interrupted join. Any dqlite node left at its address is removed, as
+// the cluster member rejoins with a fresh database.
+func prepareRejoin(ctx context.Context, s *state.State, leaderClient *dqliteClient.Client, req internalTypes.ClusterMember) error {
+	err := s.Database.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		return cluster.AcquireJoinLock(ctx, tx, req.Name, JoinLockExpiry)
+	})
+	if err != nil {
+		return err
+	}
+
+	nodes, err := s.Database.Cluster(ctx, leaderClient)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		if node.Address != req.Address.String() {
+			continue
+		}
+
+		err = leaderClient.Remove(ctx, node.ID)
+		if err != nil {
+			return fmt.Errorf("Failed to remove dqlite node of interrupted join: %w", err)
+		}
+	}
+
+	logger.Info("Retrying interrupted join of cluster member", logger.Ctx{"name": req.Name, "address": req.Address.String()})
+
+	return nil
+}
+
+// addRemote adds the given remote to the local trust store, unless an identical entry already exists. A different entry
+// with the same name is never overwritten.
+func addRemote(s *state.State, remote trust.Remote) error {
+	existing, ok := s.Remotes().RemotesByName()[remote.Name]
+	if ok {
+		if existing.Address.String() == remote.Address.String() && existing.Certificate.String() == remote.Certificate.String() {
+			return nil
+		}
+
+		return api.StatusErrorf(http.StatusConflict, "Trust store already has a different entry for cluster member %q at %q", remote.Name, existing.Address.String())
+	}
+
+	return s.Remotes().Add(s.OS.TrustDir, remote)
+}
@@ -0,0 +1,61 @@
+package resources
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/response"
+
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+	"github.com/canonical/microcluster/rest/types"
+)
+
+var leaderCmd = rest.Endpoint{
+	Path: "leader",
+
+	Get: rest.EndpointAction{Handler: leaderGet, AccessHandler: access.AllowAuthenticated, ProxyTarget: true},
+}
+
+// leaderGet returns the name and address of the current dqlite leader.
+func leaderGet(s *state.State, r *http.Request) response.Response {
+	if !s.Database.IsOpen() {
+		return response.Unavailable(fmt.Errorf("Daemon not yet initialized"))
+	}
+
+	ctx, cancel := context.WithTimeout(s.Context, 5*time.Second)
+	defer cancel()
+
+	leaderClient, err := s.Database.Leader(ctx)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	defer leaderClient.Close()
+
+	leaderInfo, err := leaderClient.Leader(ctx)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	if leaderInfo == nil {
+		return rest.ErrorResponse(http.StatusServiceUnavailable, types.ErrorCodeQuorumLost, "No dqlite leader is currently elected")
+	}
+
+	addr, err := types.ParseAddrPort(leaderInfo.Address)
+	if err != nil {
+		return rest.SmartError(fmt.Errorf("Failed to parse address %q of dqlite leader: %w", leaderInfo.Address, err))
+	}
+
+	leader := internalTypes.Leader{Address: addr}
+	remote := s.Remotes().RemoteByAddress(addr)
+	if remote != nil {
+		leader.Name = remote.Name
+	}
+
+	return response.SyncResponse(true, leader)
+}
@@ -0,0 +1,78 @@
+package resources
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/response"
+
+	internalREST "github.com/canonical/microcluster/internal/rest"
+	"github.com/canonical/microcluster/internal/rest/access"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var metricsCmd = rest.Endpoint{
+	AllowedBeforeInit: true,
+	Path:              "metrics",
+
+	Get: rest.EndpointAction{Handler: metricsGet, AccessHandler: access.AllowAuthenticated, ProxyTarget: true},
+}
+
+// metricsGet returns the request metrics of the endpoints of this cluster member in the Prometheus text format.
+func metricsGet(s *state.State, r *http.Request) response.Response {
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.WriteHeader(http.StatusOK)
+
+		return writeMetrics(w, internalREST.Metrics())
+	})
+}
+
+// writeMetrics writes the given endpoint metrics in the Prometheus text format.
+func writeMetrics(w io.Writer, metrics []internalREST.EndpointMetrics) error {
+	var b strings.Builder
+
+	b.WriteString("# HELP microcluster_http_requests_total Number of requests to each endpoint.\n")
+	b.WriteString("# TYPE microcluster_http_requests_total counter\n")
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "microcluster_http_requests_total{%s} %d\n", metricsLabels(m), m.Requests)
+	}
+
+	b.WriteString("# HELP microcluster_http_request_errors_total Number of requests to each endpoint answered with an error.\n")
+	b.WriteString("# TYPE microcluster_http_request_errors_total counter\n")
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "microcluster_http_request_errors_total{%s} %d\n", metricsLabels(m), m.Errors)
+	}
+
+	b.WriteString("# HELP microcluster_http_request_duration_seconds Latency of the requests to each endpoint.\n")
+	b.WriteString("# TYPE microcluster_http_request_duration_seconds summary\n")
+	for _, m := range metrics {
+		labels := metricsLabels(m)
+		for i, latency := range m.Quantiles {
+			quantile := strconv.FormatFloat(internalREST.MetricsQuantiles[i], 'g', -1, 64)
+			fmt.Fprintf(&b, "microcluster_http_request_duration_seconds{%s,quantile=%q} %g\n", labels, quantile, latency.Seconds())
+		}
+
+		fmt.Fprintf(&b, "microcluster_http_request_duration_seconds_sum{%s} %g\n", labels, m.Latency.Seconds())
+		fmt.Fprintf(&b, "microcluster_http_request_duration_seconds_count{%s} %d\n", labels, m.Requests)
+	}
+
+	b.WriteString("# HELP microcluster_http_handler_panics_total Number of recovered endpoint handler panics.\n")
+	b.WriteString("# TYPE microcluster_http_handler_panics_total counter\n")
+	fmt.Fprintf(&b, "microcluster_http_handler_panics_total %d\n", internalREST.PanicCount())
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// metricsLabels returns the labels identifying the endpoint of the given metrics.
+func metricsLabels(m internalREST.EndpointMetrics) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+	return fmt.Sprintf(`path="%s",method="%s"`, replacer.Replace(m.Path), m.Method)
+}
@@ -0,0 +1,225 @@
+package resources
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/response"
+
+	"github.com/canonical/microcluster/internal/extensions"
+	"github.com/canonical/microcluster/internal/rest/access"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var openAPICmd = rest.Endpoint{
+	Path: "openapi.json",
+
+	Get: rest.EndpointAction{Handler: openAPIGet, AccessHandler: access.AllowAuthenticated},
+}
+
+// EnableOpenAPIEndpoint adds the endpoint serving the OpenAPI document of the API to the application endpoints. It is
+// added after the endpoints of the application, so that applications can serve their own document instead.
+func EnableOpenAPIEndpoint() {
+	ExtendedEndpoints.Endpoints = append(ExtendedEndpoints.Endpoints, openAPICmd)
+}
+
+// openAPIDocument is an OpenAPI 3.1 document, limited to the fields that can be derived from endpoint definitions.
+type openAPIDocument struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       openAPIInfo                            `json:"info"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components openAPIComponents                      `json:"components"`
+	Security   []map[string][]string                  `json:"security"`
+}
+
+type openAPIInfo struct {
+	Title         string   `json:"title"`
+	Version       string   `json:"version"`
+	APIExtensions []string `json:"x-api-extensions,omitempty"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Tags        []string                   `json:"tags"`
+	Deprecated  bool                       `json:"deprecated,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+	Security    *[]map[string][]string     `json:"security,omitempty"`
+}
+
+type openAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required"`
+	Schema   map[string]any `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Content map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema map[string]any `json:"schema"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]map[string]any `json:"schemas"`
+	SecuritySchemes map[string]map[string]any `json:"securitySchemes"`
+}
+
+// openAPIPathVariable matches the variables of a route path, with an optional pattern after the name.
+var openAPIPathVariable = regexp.MustCompile(`{([^}:]+)(:[^}]*)?}`)
+
+// openAPIGet returns an OpenAPI document describing the endpoints served on the network, including those of the
+// application. Payloads are described by the response envelope, as endpoint definitions carry no payload types.
+func openAPIGet(s *state.State, r *http.Request) response.Response {
+	doc := openAPIDocument{
+		OpenAPI: "3.1.0",
+		Info: openAPIInfo{
+			Title:         "MicroCluster",
+			Version:       "1.0",
+			APIExtensions: extensions.Supported(),
+		},
+		Paths:      map[string]map[string]openAPIOperation{},
+		Components: openAPIComponents{Schemas: openAPISchemas(), SecuritySchemes: openAPISecuritySchemes()},
+		Security:   []map[string][]string{{"tls": {}}, {"token": {}}},
+	}
+
+	for _, endpoints := range append([]*Resources{PublicEndpoints, ExtendedEndpoints}, ExtendedVersions...) {
+		for _, e := range endpoints.Endpoints {
+			// Endpoints only available over the control socket are not part of the network API.
+			if e.Access == rest.AccessControlSocket {
+				continue
+			}
+
+			addOpenAPIPath(doc.Paths, endpoints, e.Path, e)
+			for _, alias := range e.Aliases {
+				ae := e
+				ae.Deprecated = e.Deprecated || alias.Deprecated
+				addOpenAPIPath(doc.Paths, endpoints, alias.Path, ae)
+			}
+		}
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+
+		return json.NewEncoder(w).Encode(doc)
+	})
+}
+
+// addOpenAPIPath adds the operations of the given endpoint, served at the given path of the resources, to the paths of
+// an OpenAPI document.
+func addOpenAPIPath(paths map[string]map[string]openAPIOperation, endpoints *Resources, path string, e rest.Endpoint) {
+	url := "/" + string(endpoints.Path)
+	if path != "" {
+		url += "/" + path
+	}
+
+	var parameters []openAPIParameter
+	for _, match := range openAPIPathVariable.FindAllStringSubmatch(url, -1) {
+		parameters = append(parameters, openAPIParameter{Name: match[1], In: "path", Required: true, Schema: map[string]any{"type": "string"}})
+	}
+
+	url = openAPIPathVariable.ReplaceAllString(url, "{$1}")
+	if paths[url] != nil {
+		return
+	}
+
+	actions := map[string]rest.EndpointAction{"get": e.Get, "put": e.Put, "post": e.Post, "delete": e.Delete, "patch": e.Patch}
+	methods := make([]string, 0, len(actions))
+	for method := range actions {
+		methods = append(methods, method)
+	}
+
+	sort.Strings(methods)
+
+	operations := map[string]openAPIOperation{}
+	for _, method := range methods {
+		action := actions[method]
+		if action.Handler == nil {
+			continue
+		}
+
+		op := openAPIOperation{
+			OperationID: openAPIOperationID(method, url),
+			Tags:        []string{string(endpoints.Path)},
+			Deprecated:  endpoints.Deprecated || e.Deprecated,
+			Parameters:  parameters,
+			Responses: map[string]openAPIResponse{
+				"200":     {Description: "Success", Content: map[string]openAPIMediaType{"application/json": {Schema: map[string]any{"$ref": "#/components/schemas/SyncResponse"}}}},
+				"default": {Description: "Error", Content: map[string]openAPIMediaType{"application/json": {Schema: map[string]any{"$ref": "#/components/schemas/ErrorResponse"}}}},
+			},
+		}
+
+		if action.ProxyTarget {
+			op.Parameters = append(op.Parameters, openAPIParameter{Name: "target", In: "query", Schema: map[string]any{"type": "string"}})
+		}
+
+		if method == "put" || method == "post" || method == "patch" {
+			op.RequestBody = &openAPIRequestBody{Content: map[string]openAPIMediaType{"application/json": {Schema: map[string]any{"type": "object"}}}}
+		}
+
+		if action.AllowUntrusted || e.Access == rest.AccessAny {
+			op.Security = &[]map[string][]string{}
+		}
+
+		operations[method] = op
+	}
+
+	if len(operations) > 0 {
+		paths[url] = operations
+	}
+}
+
+// openAPIOperationID returns a unique identifier for the operation of the given method on the given path, such as
+// "get_cluster_1.0_cluster_name" for "GET /cluster/1.0/cluster/{name}".
+func openAPIOperationID(method string, url string) string {
+	replacer := strings.NewReplacer("/", "_", "{", "", "}", "")
+
+	return method + replacer.Replace(url)
+}
+
+// openAPISchemas returns the schemas of the response envelopes of the API.
+func openAPISchemas() map[string]map[string]any {
+	return map[string]map[string]any{
+		"SyncResponse": {
+			"type": "object",
+			"properties": map[string]any{
+				"type":        map[string]any{"type": "string", "enum": []string{"sync"}},
+				"status":      map[string]any{"type": "string"},
+				"status_code": map[string]any{"type": "integer"},
+				"operation":   map[string]any{"type": "string"},
+				"metadata":    map[string]any{},
+			},
+		},
+		"ErrorResponse": {
+			"type": "object",
+			"properties": map[string]any{
+				"type":       map[string]any{"type": "string", "enum": []string{"error"}},
+				"error":      map[string]any{"type": "string"},
+				"error_code": map[string]any{"type": "integer"},
+				"metadata":   map[string]any{"type": "object", "properties": map[string]any{"code": map[string]any{"type": "string"}}},
+			},
+		},
+	}
+}
+
+// openAPISecuritySchemes returns the ways clients can authenticate with the API.
+func openAPISecuritySchemes() map[string]map[string]any {
+	return map[string]map[string]any{
+		"tls":   {"type": "mutualTLS", "description": "Trusted client certificate"},
+		"token": {"type": "http", "scheme": "bearer", "description": "API token"},
+	}
+}
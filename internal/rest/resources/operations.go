@@ -0,0 +1,65 @@
+package resources
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/response"
+
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var operationsCmd = rest.Endpoint{
+	Path: "operations",
+
+	Get: rest.EndpointAction{Handler: operationsGet, AccessHandler: access.AllowAuthenticated},
+}
+
+func operationsGet(s *state.State, r *http.Request) response.Response {
+	filter := cluster.InternalOperationFilter{}
+	filters := []cluster.InternalOperationFilter{}
+
+	opType := r.URL.Query().Get("type")
+	if opType != "" {
+		filter.Type = &opType
+	}
+
+	status := cluster.OperationStatus(r.URL.Query().Get("status"))
+	if status != "" {
+		if status != cluster.OperationSuccess && status != cluster.OperationFailed {
+			return response.BadRequest(fmt.Errorf("Invalid operation status %q", status))
+		}
+
+		filter.Status = &status
+	}
+
+	if filter.Type != nil || filter.Status != nil {
+		filters = append(filters, filter)
+	}
+
+	var operations []internalTypes.Operation
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		dbOperations, err := cluster.GetInternalOperations(ctx, tx, filters...)
+		if err != nil {
+			return err
+		}
+
+		operations = make([]internalTypes.Operation, 0, len(dbOperations))
+		for _, operation := range dbOperations {
+			operations = append(operations, operation.ToAPI())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return rest.CollectionResponse(r, operations)
+}
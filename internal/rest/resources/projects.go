@@ -0,0 +1,146 @@
+package resources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var projectsCmd = rest.Endpoint{
+	Path: "projects",
+
+	Get:  rest.EndpointAction{Handler: projectsGet, AccessHandler: access.AllowAuthenticated},
+	Post: rest.EndpointAction{Handler: projectsPost, AccessHandler: access.AllowAuthenticated},
+}
+
+var projectCmd = rest.Endpoint{
+	Path:       "projects/{name}",
+	PathParams: map[string]rest.PathParam{"name": rest.PathParamName},
+
+	Get:    rest.EndpointAction{Handler: projectGet, AccessHandler: access.AllowAuthenticated},
+	Put:    rest.EndpointAction{Handler: projectPut, AccessHandler: access.AllowAuthenticated},
+	Delete: rest.EndpointAction{Handler: projectDelete, AccessHandler: access.AllowAuthenticated},
+}
+
+func projectsGet(s *state.State, r *http.Request) response.Response {
+	var projects []internalTypes.Project
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		dbProjects, err := cluster.GetInternalProjects(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		projects = make([]internalTypes.Project, 0, len(dbProjects))
+		for _, project := range dbProjects {
+			config, err := cluster.GetProjectConfig(ctx, tx, project.Name)
+			if err != nil {
+				return err
+			}
+
+			projects = append(projects, project.ToAPI(config))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return rest.CollectionResponse(r, projects)
+}
+
+func projectsPost(s *state.State, r *http.Request) response.Response {
+	req, resp := rest.DecodeRequest[internalTypes.Project](r)
+	if resp != nil {
+		return resp
+	}
+
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := cluster.CreateInternalProject(ctx, tx, cluster.InternalProject{Name: req.Name, Description: req.Description})
+		if err != nil {
+			return err
+		}
+
+		return cluster.UpdateProjectConfig(ctx, tx, req.Name, req.Config)
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+func projectGet(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	var project internalTypes.Project
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		dbProject, err := cluster.GetInternalProject(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		config, err := cluster.GetProjectConfig(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+
+		project = dbProject.ToAPI(config)
+
+		return nil
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.SyncResponse(true, project)
+}
+
+func projectPut(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	req := internalTypes.ProjectPut{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		err := cluster.UpdateInternalProject(ctx, tx, name, cluster.InternalProject{Name: name, Description: req.Description})
+		if err != nil {
+			return err
+		}
+
+		return cluster.UpdateProjectConfig(ctx, tx, name, req.Config)
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+func projectDelete(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+	if name == cluster.DefaultProject {
+		return response.BadRequest(fmt.Errorf("The %q project cannot be deleted", cluster.DefaultProject))
+	}
+
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		return cluster.DeleteInternalProject(ctx, tx, name)
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
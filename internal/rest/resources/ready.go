@@ -1,33 +1,229 @@
 package resources
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/api"
 
 	"github.com/canonical/microcluster/internal/rest/access"
 	"github.com/canonical/microcluster/internal/state"
 	"github.com/canonical/microcluster/rest"
+	"github.com/canonical/microcluster/rest/types"
 )
 
 var readyCmd = rest.Endpoint{
 	AllowedBeforeInit: true,
 	Path:              "ready",
 
-	Get: rest.EndpointAction{Handler: getWaitReady, AccessHandler: access.AllowAuthenticated},
+	Get: rest.EndpointAction{Handler: getWaitReady, AccessHandler: access.AllowAuthenticated, ProxyTarget: true},
 }
 
+var appReadyCmd = rest.Endpoint{
+	AllowedBeforeInit: true,
+	Path:              "ready",
+	Access:            rest.AccessAny,
+
+	Get: rest.EndpointAction{Handler: appReadyGet},
+}
+
+var healthCmd = rest.Endpoint{
+	AllowedBeforeInit: true,
+	Path:              "health",
+	Access:            rest.AccessAny,
+
+	Get: rest.EndpointAction{Handler: healthGet},
+}
+
+// healthLeaderTimeout is how long the health check waits to reach the dqlite leader.
+const healthLeaderTimeout = 5 * time.Second
+
+// EnableHealthEndpoints adds the readiness and health endpoints to the application endpoints, for load balancers and
+// orchestrators that can only reach the application API. They are added after the endpoints of the application, so
+// that applications can serve their own checks instead.
+func EnableHealthEndpoints() {
+	ExtendedEndpoints.Endpoints = append(ExtendedEndpoints.Endpoints, appReadyCmd, healthCmd)
+}
+
+// getWaitReady returns the health of the daemon, with 503 Service Unavailable until it has finished starting up.
 func getWaitReady(state *state.State, r *http.Request) response.Response {
 	if state.Context.Err() != nil {
 		return response.Unavailable(fmt.Errorf("Daemon is shutting down"))
 	}
 
+	health := checkHealth(state, r)
+	if !health.Ready {
+		return healthResponse(http.StatusServiceUnavailable, health, "Daemon is not ready yet")
+	}
+
+	return healthResponse(http.StatusOK, health, "")
+}
+
+// appReadyGet returns the health of the daemon, with 503 Service Unavailable unless it has finished starting up and all
+// of its components are healthy, so that load balancers only send requests to cluster members that can serve them.
+func appReadyGet(state *state.State, r *http.Request) response.Response {
+	if state.Context.Err() != nil {
+		return response.Unavailable(fmt.Errorf("Daemon is shutting down"))
+	}
+
+	health := checkHealth(state, r)
+	if !health.Ready || !health.Healthy {
+		return healthResponse(http.StatusServiceUnavailable, health, "Daemon is not ready yet")
+	}
+
+	return healthResponse(http.StatusOK, health, "")
+}
+
+// healthGet returns the health of the daemon, with 503 Service Unavailable if any of its components is unhealthy.
+func healthGet(state *state.State, r *http.Request) response.Response {
+	if state.Context.Err() != nil {
+		return response.Unavailable(fmt.Errorf("Daemon is shutting down"))
+	}
+
+	health := checkHealth(state, r)
+	if !health.Healthy {
+		return healthResponse(http.StatusServiceUnavailable, health, "Daemon is unhealthy")
+	}
+
+	return healthResponse(http.StatusOK, health, "")
+}
+
+// checkHealth returns the state of the components of the daemon. Messages describing unhealthy components are only
+// included for trusted requests, as they may reveal details of the cluster.
+func checkHealth(s *state.State, r *http.Request) types.Health {
+	checks := []types.HealthCheck{
+		healthCheck(types.HealthComponentDatabase, checkDatabase(s)),
+		healthCheck(types.HealthComponentLeader, checkLeader(s)),
+		healthCheck(types.HealthComponentTrustStore, checkTrustStoreLoaded(s)),
+		healthCheck(types.HealthComponentEndpoints, checkEndpoints(s)),
+		healthCheck(types.HealthComponentSchema, checkSchema(s)),
+	}
+
+	health := types.Health{Healthy: true, Components: checks}
+	for i := range checks {
+		if !checks[i].Healthy {
+			health.Healthy = false
+		}
+
+		if !access.Trusted(r) {
+			checks[i].Message = ""
+		}
+	}
+
 	select {
-	case <-state.ReadyCh:
+	case <-s.ReadyCh:
+		health.Ready = true
 	default:
-		return response.Unavailable(fmt.Errorf("Daemon is not ready yet"))
 	}
 
-	return response.EmptySyncResponse
+	return health
+}
+
+// healthCheck returns the state of the given component, which is unhealthy if the check returned an error.
+func healthCheck(component types.HealthComponent, err error) types.HealthCheck {
+	if err != nil {
+		return types.HealthCheck{Name: component, Message: err.Error()}
+	}
+
+	return types.HealthCheck{Name: component, Healthy: true}
+}
+
+// checkDatabase returns an error if the database is not open.
+func checkDatabase(s *state.State) error {
+	if !s.Database.IsOpen() {
+		return fmt.Errorf("Database is not open")
+	}
+
+	return nil
+}
+
+// checkLeader returns an error if the dqlite leader can not be reached.
+func checkLeader(s *state.State) error {
+	if !s.Database.IsOpen() {
+		return fmt.Errorf("Database is not open")
+	}
+
+	ctx, cancel := context.WithTimeout(s.Context, healthLeaderTimeout)
+	defer cancel()
+
+	leaderClient, err := s.Database.Leader(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to connect to dqlite leader: %w", err)
+	}
+
+	defer leaderClient.Close()
+
+	_, err = leaderClient.Leader(ctx)
+	if err != nil {
+		return fmt.Errorf("Failed to get dqlite leader: %w", err)
+	}
+
+	return nil
+}
+
+// checkTrustStoreLoaded returns an error if the trust store has not been loaded with any cluster members.
+func checkTrustStoreLoaded(s *state.State) error {
+	remotes := s.Remotes()
+	if remotes == nil || remotes.Count() == 0 {
+		return fmt.Errorf("Trust store is empty")
+	}
+
+	return nil
+}
+
+// checkEndpoints returns an error if any of the API listeners is not serving.
+func checkEndpoints(s *state.State) error {
+	notServing := s.Endpoints.NotServing()
+	if len(notServing) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(notServing))
+	for _, endpointType := range notServing {
+		names = append(names, endpointType.String())
+	}
+
+	return fmt.Errorf("Listeners not serving: %s", strings.Join(names, ", "))
+}
+
+// checkSchema returns an error if the database is waiting for cluster members to upgrade their schema.
+func checkSchema(s *state.State) error {
+	wait := s.Database.UpgradeWait()
+	if wait != nil {
+		return fmt.Errorf("%s", wait.String())
+	}
+
+	if !s.Database.IsOpen() {
+		return fmt.Errorf("Schema has not been loaded")
+	}
+
+	return nil
+}
+
+// healthResponse returns a response with the given status code and the health of the daemon as its metadata. Error
+// responses carry the given message, in the same format as other error responses.
+func healthResponse(statusCode int, health types.Health, message string) response.Response {
+	if statusCode == http.StatusOK {
+		return response.SyncResponse(true, health)
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		resp := api.ResponseRaw{
+			Type:     api.ErrorResponse,
+			Error:    message,
+			Code:     statusCode,
+			Metadata: health,
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(statusCode)
+
+		return json.NewEncoder(w).Encode(resp)
+	})
 }
@@ -1,6 +1,10 @@
 package resources
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/canonical/microcluster/internal/rest/client"
 	"github.com/canonical/microcluster/rest"
 )
@@ -9,6 +13,10 @@ import (
 type Resources struct {
 	Path      client.EndpointType
 	Endpoints []rest.Endpoint
+
+	// Deprecated and Sunset are sent to clients of deprecated application API versions.
+	Deprecated bool
+	Sunset     time.Time
 }
 
 // UnixEndpoints are the endpoints available over the unix socket.
@@ -27,8 +35,35 @@ var PublicEndpoints = &Resources{
 		api10Cmd,
 		clusterCmd,
 		clusterMemberCmd,
+		clusterMemberConfigCmd,
+		clusterMemberRoleCmd,
+		clusterMemberCordonCmd,
+		clusterMemberCertificateCmd,
+		truststoreCmd,
 		tokensCmd,
 		readyCmd,
+		configCmd,
+		featuresCmd,
+		featureCmd,
+		projectsCmd,
+		projectCmd,
+		operationsCmd,
+		eventsCmd,
+		snapshotCmd,
+		compatibilityCmd,
+		extensionsCmd,
+		upgradeCmd,
+		clusterShutdownCmd,
+		leaderCmd,
+		clusterCertificateCmd,
+		aclsCmd,
+		aclCmd,
+		certificatesCmd,
+		apiTokensCmd,
+		apiTokenCmd,
+		trustAuditCmd,
+		warningsCmd,
+		metricsCmd,
 	},
 }
 
@@ -37,9 +72,15 @@ var InternalEndpoints = &Resources{
 	Path: client.InternalEndpoint,
 	Endpoints: []rest.Endpoint{
 		databaseCmd,
+		databaseBackupCmd,
+		integrityCmd,
 		sqlCmd,
 		tokenCmd,
 		heartbeatCmd,
+		upgradeMemberCmd,
+		clusterCertificateMemberCmd,
+		truststoreMemberCmd,
+		truststoreCheckCmd,
 	},
 }
 
@@ -48,3 +89,32 @@ var ExtendedEndpoints = &Resources{
 	Path:      client.ExtendedEndpoint,
 	Endpoints: []rest.Endpoint{},
 }
+
+// ExtendedVersions holds the endpoints added by external usage of MicroCluster under API versions other than 1.0.
+var ExtendedVersions = []*Resources{}
+
+// AddAPIVersion registers the endpoints of a version of the application API. Endpoints of version 1.0 are added to
+// ExtendedEndpoints.
+func AddAPIVersion(version rest.APIVersion) error {
+	if version.Version == "" || strings.Contains(version.Version, "/") || strings.HasPrefix(version.Version, "cluster") {
+		return fmt.Errorf("Invalid API version %q", version.Version)
+	}
+
+	resources := ExtendedEndpoints
+	if version.Version != string(client.ExtendedEndpoint) {
+		for _, existing := range ExtendedVersions {
+			if string(existing.Path) == version.Version {
+				return fmt.Errorf("API version %q is already registered", version.Version)
+			}
+		}
+
+		resources = &Resources{Path: client.EndpointType(version.Version)}
+		ExtendedVersions = append(ExtendedVersions, resources)
+	}
+
+	resources.Endpoints = append(resources.Endpoints, version.Endpoints...)
+	resources.Deprecated = version.Deprecated
+	resources.Sunset = version.Sunset
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package resources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/internal/sys"
+	"github.com/canonical/microcluster/rest"
+)
+
+var secretsCmd = rest.Endpoint{
+	Path: "secrets",
+
+	Get: rest.EndpointAction{Handler: secretsGet, AccessHandler: access.AllowAuthenticated},
+}
+
+var secretCmd = rest.Endpoint{
+	Path:       "secrets/{name}",
+	PathParams: map[string]rest.PathParam{"name": rest.PathParamName},
+
+	Get:    rest.EndpointAction{Handler: secretGet, AccessHandler: access.AllowAuthenticated},
+	Put:    rest.EndpointAction{Handler: secretPut, AccessHandler: access.AllowAuthenticated},
+	Delete: rest.EndpointAction{Handler: secretDelete, AccessHandler: access.AllowAuthenticated},
+}
+
+// secretsGet returns the names of all cluster-wide secrets, without their values.
+func secretsGet(s *state.State, r *http.Request) response.Response {
+	var secrets []internalTypes.Secret
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		dbSecrets, err := cluster.GetInternalSecrets(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		secrets = make([]internalTypes.Secret, 0, len(dbSecrets))
+		for _, secret := range dbSecrets {
+			secrets = append(secrets, internalTypes.Secret{Name: secret.Name, UpdatedAt: secret.UpdatedAt})
+		}
+
+		return nil
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return rest.CollectionResponse(r, secrets)
+}
+
+func secretGet(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	key, err := s.SecretsKey()
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	var secret *cluster.InternalSecret
+	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		secret, err = cluster.GetInternalSecret(ctx, tx, name)
+		return err
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	value, err := sys.DecryptSecret(key, secret.Name, secret.Value)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.SyncResponse(true, internalTypes.Secret{Name: secret.Name, Value: string(value), UpdatedAt: secret.UpdatedAt})
+}
+
+func secretPut(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	req := internalTypes.SecretPut{}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if req.Value == "" {
+		return response.BadRequest(fmt.Errorf("No value provided for secret %q", name))
+	}
+
+	err = s.SetSecret(name, req.Value)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+func secretDelete(s *state.State, r *http.Request) response.Response {
+	name := rest.PathValue[string](r, "name")
+
+	err := s.DeleteSecret(name)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
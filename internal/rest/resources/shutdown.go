@@ -1,11 +1,16 @@
 package resources
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"time"
 
+	dqliteClient "github.com/canonical/go-dqlite/client"
 	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/logger"
 
+	"github.com/canonical/microcluster/client"
 	"github.com/canonical/microcluster/internal/rest/access"
 	"github.com/canonical/microcluster/internal/state"
 	"github.com/canonical/microcluster/rest"
@@ -18,17 +23,127 @@ var shutdownCmd = rest.Endpoint{
 	Post: rest.EndpointAction{Handler: shutdownPost, AccessHandler: access.AllowAuthenticated},
 }
 
+var clusterShutdownCmd = rest.Endpoint{
+	Path: "shutdown",
+
+	Post: rest.EndpointAction{Handler: clusterShutdownPost, AccessHandler: access.AllowAuthenticated},
+}
+
 func shutdownPost(state *state.State, r *http.Request) response.Response {
 	if state.Context.Err() != nil {
-		return response.SmartError(fmt.Errorf("Shutdown already in progress"))
+		return rest.SmartError(fmt.Errorf("Shutdown already in progress"))
+	}
+
+	return shutdownResponse(state, r)
+}
+
+// clusterShutdownPost gracefully stops every cluster member. The cluster member receiving the request first takes over
+// dqlite leadership if it is a voter, then stops all other cluster members, and stops itself last so that leadership
+// is not moved around while the cluster is shutting down. If it cannot take over leadership, the leader is stopped
+// just before it.
+func clusterShutdownPost(s *state.State, r *http.Request) response.Response {
+	if s.Context.Err() != nil {
+		return rest.SmartError(fmt.Errorf("Shutdown already in progress"))
+	}
+
+	// If the request was forwarded by another cluster member, just stop this one.
+	if client.IsForwardedRequest(r) {
+		return shutdownResponse(s, r)
+	}
+
+	ctx, cancel := context.WithTimeout(s.Context, 30*time.Second)
+	leaderAddress, err := claimLeadership(ctx, s)
+	cancel()
+	if err != nil {
+		logger.Warn("Failed to take over dqlite leadership before cluster shutdown", logger.Ctx{"error": err})
+	}
+
+	peers, err := s.Cluster(r)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	var leader *client.Client
+	followers := make(client.Cluster, 0, len(peers))
+	for i, peer := range peers {
+		if peer.URL().URL.Host == leaderAddress {
+			leader = &peers[i]
+			continue
+		}
+
+		followers = append(followers, peer)
+	}
+
+	err = followers.Query(s.Context, true, func(ctx context.Context, c *client.Client) error {
+		err := c.ShutdownClusterMember(ctx)
+		if err != nil {
+			return fmt.Errorf("Failed to shut down cluster member %q: %w", c.URL().URL.Host, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return rest.SmartError(err)
 	}
 
+	if leader != nil {
+		err = leader.ShutdownClusterMember(s.Context)
+		if err != nil {
+			return rest.SmartError(fmt.Errorf("Failed to shut down cluster member %q: %w", leader.URL().URL.Host, err))
+		}
+	}
+
+	return shutdownResponse(s, r)
+}
+
+// claimLeadership transfers dqlite leadership to this cluster member if it is a voter, and returns the address of the
+// leader.
+func claimLeadership(ctx context.Context, s *state.State) (string, error) {
+	leader, err := s.Database.Leader(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	defer leader.Close()
+
+	leaderInfo, err := leader.Leader(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if leaderInfo.Address == s.Address().URL.Host {
+		return leaderInfo.Address, nil
+	}
+
+	nodes, err := s.Database.Cluster(ctx, leader)
+	if err != nil {
+		return leaderInfo.Address, err
+	}
+
+	for _, node := range nodes {
+		if node.Address != s.Address().URL.Host || node.Role != dqliteClient.Voter {
+			continue
+		}
+
+		err = leader.Transfer(ctx, node.ID)
+		if err != nil {
+			return leaderInfo.Address, fmt.Errorf("Failed to transfer dqlite leadership: %w", err)
+		}
+
+		return node.Address, nil
+	}
+
+	return leaderInfo.Address, nil
+}
+
+// shutdownResponse stops the daemon, and sends the result before the daemon process ends.
+func shutdownResponse(state *state.State, r *http.Request) response.Response {
 	return response.ManualResponse(func(w http.ResponseWriter) error {
 		<-state.ReadyCh // Wait for daemon to start.
 
 		// Run shutdown sequence synchronously.
 		stopErr := state.Stop()
-		err := response.SmartError(stopErr).Render(w)
+		err := rest.SmartError(stopErr).Render(w)
 		if err != nil {
 			return err
 		}
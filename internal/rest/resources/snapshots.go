@@ -0,0 +1,55 @@
+package resources
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/response"
+
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var snapshotCmd = rest.Endpoint{
+	Path: "snapshot",
+
+	Get: rest.EndpointAction{Handler: snapshotGet, AccessHandler: access.AllowAuthenticated},
+}
+
+// snapshotGet returns the most recent snapshot of the cluster membership and configuration taken at or before the
+// RFC3339 time given with the "at" query parameter. If no time is given, the current state is returned.
+func snapshotGet(s *state.State, r *http.Request) response.Response {
+	value := r.URL.Query().Get("at")
+
+	var at time.Time
+	if value != "" {
+		var err error
+		at, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid %q query parameter %q: %w", "at", value, err))
+		}
+	}
+
+	var snapshot *internalTypes.Snapshot
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		if at.IsZero() {
+			snapshot, err = cluster.TakeSnapshot(ctx, tx)
+		} else {
+			snapshot, err = cluster.GetInternalSnapshotAt(ctx, tx, at)
+		}
+
+		return err
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.SyncResponse(true, snapshot)
+}
@@ -22,8 +22,8 @@ import (
 var sqlCmd = rest.Endpoint{
 	Path: "sql",
 
-	Get:  rest.EndpointAction{Handler: sqlGet, AccessHandler: access.AllowAuthenticated},
-	Post: rest.EndpointAction{Handler: sqlPost, AccessHandler: access.AllowAuthenticated},
+	Get:  rest.EndpointAction{Handler: sqlGet, AccessHandler: access.AllowClusterMembers},
+	Post: rest.EndpointAction{Handler: sqlPost, AccessHandler: access.AllowClusterMembers},
 }
 
 // Perform a database dump.
@@ -46,7 +46,7 @@ func sqlGet(state *state.State, r *http.Request) response.Response {
 		return nil
 	})
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	return response.SyncResponse(true, types.SQLDump{Text: dump})
@@ -87,7 +87,7 @@ func sqlPost(state *state.State, r *http.Request) response.Response {
 			return err
 		})
 		if err != nil {
-			return response.SmartError(err)
+			return rest.SmartError(err)
 		}
 
 		batch.Results = append(batch.Results, result)
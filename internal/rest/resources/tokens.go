@@ -2,16 +2,17 @@ package resources
 
 import (
 	"context"
+	"crypto"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
-	"net/url"
+	"time"
 
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
-	"github.com/gorilla/mux"
-
 	"github.com/canonical/microcluster/cluster"
 	"github.com/canonical/microcluster/internal/rest/access"
 	internalTypes "github.com/canonical/microcluster/internal/rest/types"
@@ -20,6 +21,17 @@ import (
 	"github.com/canonical/microcluster/rest/types"
 )
 
+// TokenExpiry is the default lifetime of join tokens.
+var TokenExpiry = 3 * time.Hour
+
+// JoinHostnames are host:port pairs that are added to every join token, so that joining members can reach the cluster
+// by DNS even if the addresses of its members change before the token is used.
+var JoinHostnames []string
+
+// JoinLockExpiry is how long a joining cluster member may hold the join lock before other cluster members are allowed to
+// join regardless.
+var JoinLockExpiry = 5 * time.Minute
+
 var tokensCmd = rest.Endpoint{
 	Path: "tokens",
 
@@ -28,9 +40,10 @@ var tokensCmd = rest.Endpoint{
 }
 
 var tokenCmd = rest.Endpoint{
-	Path: "tokens/{name}",
+	Path:       "tokens/{name}",
+	PathParams: map[string]rest.PathParam{"name": rest.PathParamName},
 
-	Delete: rest.EndpointAction{Handler: tokenDelete, AccessHandler: access.AllowAuthenticated},
+	Delete: rest.EndpointAction{Handler: tokenDelete, AccessHandler: access.AllowClusterMembers},
 }
 
 func tokensPost(state *state.State, r *http.Request) response.Response {
@@ -63,15 +76,32 @@ func tokensPost(state *state.State, r *http.Request) response.Response {
 		logger.Warnf("Failed to check trust store for eligible join addresses. Issuing token with join address %q", state.Address().URL.Host)
 		joinAddresses, err = types.ParseAddrPorts([]string{state.Address().URL.Host})
 		if err != nil {
-			return response.SmartError(err)
+			return rest.SmartError(err)
 		}
 	}
 
+	expireAfter := req.ExpireAfter
+	if expireAfter <= 0 {
+		expireAfter = TokenExpiry
+	}
+
 	token := internalTypes.Token{
 		Name:          req.Name,
 		Secret:        tokenKey,
 		Fingerprint:   shared.CertFingerprint(clusterCert),
 		JoinAddresses: joinAddresses,
+		JoinHostnames: JoinHostnames,
+		ExpiresAt:     time.Now().Add(expireAfter).UTC(),
+	}
+
+	clusterKey, err := clusterSigner(state)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
+	err = token.Sign(clusterKey)
+	if err != nil {
+		return response.InternalError(err)
 	}
 
 	tokenString, err := token.String()
@@ -80,22 +110,61 @@ func tokensPost(state *state.State, r *http.Request) response.Response {
 	}
 
 	err = state.Database.Transaction(state.Context, func(ctx context.Context, tx *sql.Tx) error {
-		_, err = cluster.CreateInternalTokenRecord(ctx, tx, cluster.InternalTokenRecord{Name: req.Name, Secret: tokenKey})
+		_, err = cluster.CreateInternalTokenRecord(ctx, tx, cluster.InternalTokenRecord{
+			Name:       req.Name,
+			Secret:     tokenKey,
+			ExpiryDate: sql.NullTime{Time: token.ExpiresAt, Valid: true},
+			Issuer:     state.Name(),
+		})
 		return err
 	})
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	return response.SyncResponse(true, tokenString)
 }
 
+// recordTokens records join tokens with the given names and secrets, expiring after the default token expiry unless
+// given their own.
+func recordTokens(state *state.State, records []internalTypes.TokenRecord) error {
+	return state.Database.Transaction(state.Context, func(ctx context.Context, tx *sql.Tx) error {
+		for _, record := range records {
+			if record.Name == "" || record.Token == "" {
+				return api.StatusErrorf(http.StatusBadRequest, "Join tokens require a name and a secret")
+			}
+
+			expireAfter := record.ExpireAfter
+			if expireAfter <= 0 {
+				expireAfter = TokenExpiry
+			}
+
+			_, err := cluster.CreateInternalTokenRecord(ctx, tx, cluster.InternalTokenRecord{
+				Name:       record.Name,
+				Secret:     record.Token,
+				ExpiryDate: sql.NullTime{Time: time.Now().Add(expireAfter).UTC(), Valid: true},
+				Issuer:     state.Name(),
+			})
+			if err != nil {
+				return fmt.Errorf("Failed to record join token %q: %w", record.Name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
 func tokensGet(state *state.State, r *http.Request) response.Response {
 	clusterCert, err := state.ClusterCert().PublicKeyX509()
 	if err != nil {
 		return response.InternalError(err)
 	}
 
+	clusterKey, err := clusterSigner(state)
+	if err != nil {
+		return response.InternalError(err)
+	}
+
 	joinAddresses := []types.AddrPort{}
 	for _, addr := range state.Remotes().Addresses() {
 		joinAddresses = append(joinAddresses, addr)
@@ -111,7 +180,7 @@ func tokensGet(state *state.State, r *http.Request) response.Response {
 
 		records = make([]internalTypes.TokenRecord, 0, len(tokens))
 		for _, token := range tokens {
-			apiToken, err := token.ToAPI(clusterCert, joinAddresses)
+			apiToken, err := token.ToAPI(clusterCert, clusterKey, joinAddresses, JoinHostnames)
 			if err != nil {
 				return err
 			}
@@ -122,24 +191,33 @@ func tokensGet(state *state.State, r *http.Request) response.Response {
 		return nil
 	})
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
-	return response.SyncResponse(true, records)
+	return rest.CollectionKeysResponse(r, records, func(record internalTypes.TokenRecord) string {
+		return record.Name
+	})
 }
 
 func tokenDelete(state *state.State, r *http.Request) response.Response {
-	name, err := url.PathUnescape(mux.Vars(r)["name"])
-	if err != nil {
-		return response.SmartError(err)
-	}
+	name := rest.PathValue[string](r, "name")
 
-	err = state.Database.Transaction(state.Context, func(ctx context.Context, tx *sql.Tx) error {
+	err := state.Database.Transaction(state.Context, func(ctx context.Context, tx *sql.Tx) error {
 		return cluster.DeleteInternalTokenRecord(ctx, tx, name)
 	})
 	if err != nil {
-		return response.SmartError(err)
+		return rest.SmartError(err)
 	}
 
 	return response.EmptySyncResponse
 }
+
+// clusterSigner returns the private key of the cluster certificate, which join tokens are signed with.
+func clusterSigner(state *state.State) (crypto.Signer, error) {
+	signer, ok := state.ClusterCert().KeyPair().PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("Cluster key can not be used for signing")
+	}
+
+	return signer, nil
+}
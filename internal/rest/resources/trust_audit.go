@@ -0,0 +1,67 @@
+package resources
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/response"
+
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+var trustAuditCmd = rest.Endpoint{
+	Path: "trust-audit",
+
+	Get: rest.EndpointAction{Handler: trustAuditGet, AccessHandler: access.AllowAuthenticated},
+}
+
+// trustAuditGet returns the trail of changes to the set of trusted certificates, oldest first. It can be filtered by
+// the name of the cluster member or certificate with the "name" query parameter, and to the changes recorded after an
+// RFC3339 time with the "since" query parameter.
+func trustAuditGet(s *state.State, r *http.Request) response.Response {
+	filter := cluster.InternalTrustAuditFilter{}
+
+	name := r.URL.Query().Get("name")
+	if name != "" {
+		filter.Name = &name
+	}
+
+	var since time.Time
+	value := r.URL.Query().Get("since")
+	if value != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return response.BadRequest(fmt.Errorf("Invalid %q query parameter %q: %w", "since", value, err))
+		}
+
+		since = since.UTC()
+	}
+
+	var audits []internalTypes.TrustAudit
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		dbAudits, err := cluster.GetInternalTrustAuditsSince(ctx, tx, filter, since)
+		if err != nil {
+			return err
+		}
+
+		audits = make([]internalTypes.TrustAudit, 0, len(dbAudits))
+		for _, audit := range dbAudits {
+			audits = append(audits, audit.ToAPI())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return rest.CollectionResponse(r, audits)
+}
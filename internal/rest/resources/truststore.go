@@ -0,0 +1,254 @@
+package resources
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/client"
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/internal/trust"
+	"github.com/canonical/microcluster/rest"
+)
+
+var truststoreCmd = rest.Endpoint{
+	Path: "truststore",
+
+	Get: rest.EndpointAction{Handler: truststoreGet, AccessHandler: access.AllowAuthenticated, ProxyTarget: true},
+}
+
+var truststoreMemberCmd = rest.Endpoint{
+	Path: "truststore",
+
+	// The trust store is kept outside the database, so it can be exchanged while the database is still starting.
+	AllowedBeforeInit: true,
+
+	Get: rest.EndpointAction{Handler: truststoreGet, AccessHandler: access.AllowClusterMembers},
+	Put: rest.EndpointAction{Handler: truststorePut, AccessHandler: access.AllowClusterMembers},
+}
+
+var truststoreCheckCmd = rest.Endpoint{
+	Path: "truststore/check",
+
+	Get:  rest.EndpointAction{Handler: truststoreCheckGet, AccessHandler: access.AllowClusterMembers},
+	Post: rest.EndpointAction{Handler: truststoreCheckPost, AccessHandler: access.AllowClusterMembers},
+}
+
+// truststoreGet lists the entries in the local trust store of this cluster member, sorted by name.
+func truststoreGet(s *state.State, r *http.Request) response.Response {
+	remotes := s.Remotes().RemotesByName()
+	entries := make([]internalTypes.ClusterMemberLocal, 0, len(remotes))
+	for _, remote := range remotes {
+		entries = append(entries, internalTypes.ClusterMemberLocal{Name: remote.Name, Address: remote.Address, Certificate: remote.Certificate})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+	return rest.CollectionResponse(r, entries)
+}
+
+// truststorePut replaces the local trust store of this cluster member with the given full list of cluster members, if
+// it differs.
+func truststorePut(s *state.State, r *http.Request) response.Response {
+	var members []internalTypes.ClusterMemberLocal
+	err := json.NewDecoder(r.Body).Decode(&members)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if len(members) == 0 {
+		return response.BadRequest(fmt.Errorf("Received empty trust store"))
+	}
+
+	err = reconcileTrustStore(s, members)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.EmptySyncResponse
+}
+
+// reconcileTrustStore replaces the local trust store with the given full list of cluster members, unless it already
+// matches.
+func reconcileTrustStore(s *state.State, members []internalTypes.ClusterMemberLocal) error {
+	clusterMembers := make([]internalTypes.ClusterMember, 0, len(members))
+	for _, member := range members {
+		clusterMembers = append(clusterMembers, internalTypes.ClusterMember{ClusterMemberLocal: member})
+	}
+
+	if s.Remotes().Matches(clusterMembers...) {
+		return nil
+	}
+
+	logger.Info("Updating trust store from cluster member list", logger.Ctx{"members": len(clusterMembers)})
+
+	err := s.Remotes().Replace(s.OS.TrustDir, clusterMembers...)
+	if err != nil {
+		return fmt.Errorf("Failed to update trust store: %w", err)
+	}
+
+	return nil
+}
+
+// truststoreCheckGet compares the trust store of this cluster member against the cluster members in the database. If
+// the "all" query parameter is set, the trust stores of all other cluster members are also checked.
+func truststoreCheckGet(s *state.State, r *http.Request) response.Response {
+	return truststoreCheck(s, r, false)
+}
+
+// truststoreCheckPost repairs the trust store of this cluster member by replacing it with the cluster members in the
+// database, if it differs. If the "all" query parameter is set, the trust stores of all other cluster members are also
+// repaired.
+func truststoreCheckPost(s *state.State, r *http.Request) response.Response {
+	return truststoreCheck(s, r, true)
+}
+
+func truststoreCheck(s *state.State, r *http.Request, repair bool) response.Response {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	var result internalTypes.TrustStoreCheck
+	if repair {
+		err := s.RunOperation(TrustStoreRepairOperation, access.Requestor(r), func(ctx context.Context) error {
+			result = checkTrustStore(ctx, s, true)
+			if result.Error != "" {
+				return fmt.Errorf("%s", result.Error)
+			}
+
+			return nil
+		})
+		if err != nil && result.Error == "" {
+			return rest.SmartError(err)
+		}
+	} else {
+		result = checkTrustStore(ctx, s, false)
+	}
+
+	results := []internalTypes.TrustStoreCheck{result}
+	if r.URL.Query().Get("all") != "1" {
+		return response.SyncResponse(true, results)
+	}
+
+	cluster, err := s.Cluster(nil)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	mu := sync.Mutex{}
+	err = cluster.Query(ctx, true, func(ctx context.Context, c *client.Client) error {
+		var checks []internalTypes.TrustStoreCheck
+		var err error
+		if repair {
+			checks, err = c.RepairTrustStore(ctx, false)
+		} else {
+			checks, err = c.CheckTrustStore(ctx, false)
+		}
+
+		if err != nil {
+			checks = []internalTypes.TrustStoreCheck{{Name: c.URL().URL.Host, Error: err.Error()}}
+		}
+
+		mu.Lock()
+		results = append(results, checks...)
+		mu.Unlock()
+
+		return nil
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return response.SyncResponse(true, results)
+}
+
+// checkTrustStore compares the trust store files of this cluster member against the cluster members in the database.
+// If repair is true and they differ, the trust store is replaced with the cluster members in the database, and checked
+// again.
+func checkTrustStore(ctx context.Context, s *state.State, repair bool) internalTypes.TrustStoreCheck {
+	result := internalTypes.TrustStoreCheck{Name: s.Name(), Missing: []string{}, Stale: []string{}, Orphaned: []string{}}
+
+	var members []internalTypes.ClusterMember
+	err := s.Database.Transaction(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		dbMembers, err := cluster.GetInternalClusterMembers(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		members = make([]internalTypes.ClusterMember, 0, len(dbMembers))
+		for _, dbMember := range dbMembers {
+			member, err := dbMember.ToAPI()
+			if err != nil {
+				return err
+			}
+
+			members = append(members, *member)
+		}
+
+		return nil
+	})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	remotes, invalid, err := trust.ReadDir(s.OS.TrustDir)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	memberNames := make(map[string]bool, len(members))
+	for _, member := range members {
+		memberNames[member.Name] = true
+
+		remote, ok := remotes[member.Name]
+		if !ok {
+			result.Missing = append(result.Missing, member.Name)
+			continue
+		}
+
+		if remote.Name != member.Name || remote.Address.String() != member.Address.String() || remote.Certificate.String() != member.Certificate.String() {
+			result.Stale = append(result.Stale, member.Name)
+		}
+	}
+
+	for name := range remotes {
+		if !memberNames[name] {
+			result.Orphaned = append(result.Orphaned, name+".yaml")
+		}
+	}
+
+	result.Orphaned = append(result.Orphaned, invalid...)
+	sort.Strings(result.Missing)
+	sort.Strings(result.Stale)
+	sort.Strings(result.Orphaned)
+
+	result.OK = len(result.Missing) == 0 && len(result.Stale) == 0 && len(result.Orphaned) == 0
+	if result.OK || !repair || len(members) == 0 {
+		return result
+	}
+
+	logger.Warn("Repairing trust store from the database", logger.Ctx{"missing": result.Missing, "stale": result.Stale, "orphaned": result.Orphaned})
+
+	err = s.Remotes().Replace(s.OS.TrustDir, members...)
+	if err != nil {
+		result.Error = fmt.Sprintf("Failed to repair trust store: %v", err)
+		return result
+	}
+
+	result = checkTrustStore(ctx, s, false)
+	result.Repaired = true
+
+	return result
+}
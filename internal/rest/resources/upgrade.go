@@ -0,0 +1,248 @@
+package resources
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
+
+	"github.com/canonical/microcluster/client"
+	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+// UpgradeStepTimeout is how long a rolling upgrade waits for each cluster member to be upgraded and come back online.
+var UpgradeStepTimeout = 10 * time.Minute
+
+var upgradeCmd = rest.Endpoint{
+	Path: "upgrade",
+
+	Get:  rest.EndpointAction{Handler: upgradeGet, AccessHandler: access.AllowAuthenticated, LeaderOnly: true},
+	Post: rest.EndpointAction{Handler: upgradePost, AccessHandler: access.AllowClusterMembers, LeaderOnly: true},
+}
+
+var upgradeMemberCmd = rest.Endpoint{
+	Path: "upgrade",
+
+	Post: rest.EndpointAction{Handler: upgradeMemberPost, AccessHandler: access.AllowClusterMembers},
+}
+
+// upgradeProgress is the progress of the last rolling upgrade coordinated by this cluster member.
+var upgradeProgress struct {
+	mu      sync.Mutex
+	running bool
+	status  *internalTypes.Upgrade
+}
+
+func upgradeGet(s *state.State, r *http.Request) response.Response {
+	upgradeProgress.mu.Lock()
+	defer upgradeProgress.mu.Unlock()
+
+	if upgradeProgress.status == nil {
+		return response.NotFound(fmt.Errorf("No rolling upgrade has been started on this cluster member"))
+	}
+
+	// Copy the progress, as the coordinator keeps updating it.
+	status := *upgradeProgress.status
+	status.Members = append([]internalTypes.UpgradeMember{}, upgradeProgress.status.Members...)
+
+	return response.SyncResponse(true, status)
+}
+
+// upgradePost starts a rolling upgrade of the cluster coordinated by this cluster member, which is the dqlite leader as
+// requests are forwarded to it. The other cluster members are upgraded one at a time, in order of name, and this
+// cluster member is upgraded last.
+func upgradePost(s *state.State, r *http.Request) response.Response {
+	var clusterMembers []cluster.InternalClusterMember
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		clusterMembers, err = cluster.GetInternalClusterMembers(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	sort.Slice(clusterMembers, func(i, j int) bool { return clusterMembers[i].Name < clusterMembers[j].Name })
+
+	status := &internalTypes.Upgrade{
+		Coordinator: s.Name(),
+		StartedAt:   time.Now(),
+		Members:     make([]internalTypes.UpgradeMember, 0, len(clusterMembers)),
+	}
+
+	peers := make([]cluster.InternalClusterMember, 0, len(clusterMembers))
+	for _, clusterMember := range clusterMembers {
+		if clusterMember.Name == s.Name() {
+			continue
+		}
+
+		peers = append(peers, clusterMember)
+		status.Members = append(status.Members, internalTypes.UpgradeMember{Name: clusterMember.Name, Stage: internalTypes.UpgradePending})
+	}
+
+	status.Members = append(status.Members, internalTypes.UpgradeMember{Name: s.Name(), Stage: internalTypes.UpgradePending})
+
+	upgradeProgress.mu.Lock()
+	if upgradeProgress.running {
+		upgradeProgress.mu.Unlock()
+		return rest.SmartError(api.StatusErrorf(http.StatusConflict, "A rolling upgrade is already in progress"))
+	}
+
+	upgradeProgress.running = true
+	upgradeProgress.status = status
+	upgradeProgress.mu.Unlock()
+
+	go runUpgrade(s, access.Requestor(r), peers)
+
+	return response.EmptySyncResponse
+}
+
+// upgradeMemberPost runs the upgrade hook on this cluster member.
+func upgradeMemberPost(s *state.State, r *http.Request) response.Response {
+	err := state.UpgradeHook(s)
+	if err != nil {
+		return rest.SmartError(fmt.Errorf("Failed to run upgrade hook: %w", err))
+	}
+
+	return response.EmptySyncResponse
+}
+
+// setUpgradeStage records the stage of the cluster member with the given name in the rolling upgrade progress.
+func setUpgradeStage(name string, stage internalTypes.UpgradeStage, err error) {
+	upgradeProgress.mu.Lock()
+	defer upgradeProgress.mu.Unlock()
+
+	for i, member := range upgradeProgress.status.Members {
+		if member.Name != name {
+			continue
+		}
+
+		upgradeProgress.status.Members[i].Stage = stage
+		if err != nil {
+			upgradeProgress.status.Members[i].Error = err.Error()
+		}
+	}
+}
+
+// finishUpgrade marks the rolling upgrade as finished.
+func finishUpgrade(err error) {
+	upgradeProgress.mu.Lock()
+	defer upgradeProgress.mu.Unlock()
+
+	upgradeProgress.running = false
+	upgradeProgress.status.FinishedAt = time.Now()
+	if err != nil {
+		upgradeProgress.status.Error = err.Error()
+	}
+}
+
+// runUpgrade upgrades the given cluster members one at a time, and then this cluster member. The upgrade is recorded
+// in the operation history as started by the given initiator.
+func runUpgrade(s *state.State, initiator string, peers []cluster.InternalClusterMember) {
+	err := s.RunOperation(UpgradeOperation, initiator, func(ctx context.Context) error {
+		peerClients, err := s.Cluster(nil)
+		if err != nil {
+			return err
+		}
+
+		clients := make(map[string]client.Client, len(peerClients))
+		for _, c := range peerClients {
+			clients[c.URL().URL.Host] = c
+		}
+
+		for _, peer := range peers {
+			c, ok := clients[peer.Address]
+			if !ok {
+				err := fmt.Errorf("No client found for cluster member %q", peer.Name)
+				setUpgradeStage(peer.Name, internalTypes.UpgradeFailed, err)
+				return err
+			}
+
+			err := upgradePeer(ctx, peer.Name, &c)
+			if err != nil {
+				return err
+			}
+
+			// Let any cluster members waiting on a schema upgrade check the cluster again.
+			err = peerClients.Query(ctx, true, func(ctx context.Context, c *client.Client) error {
+				err := c.NotifyUpgraded(ctx)
+				if err != nil {
+					logger.Warn("Failed to send database upgrade notification", logger.Ctx{"address": c.URL().URL.Host, "error": err})
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.Error("Rolling upgrade failed", logger.Ctx{"error": err})
+		finishUpgrade(err)
+		return
+	}
+
+	// Upgrade this cluster member last, as the upgrade hook is likely to restart the daemon. Once restarted, the
+	// daemon notifies the rest of the cluster that the upgrade has completed.
+	setUpgradeStage(s.Name(), internalTypes.UpgradeRunning, nil)
+	err = state.UpgradeHook(s)
+	if err != nil {
+		setUpgradeStage(s.Name(), internalTypes.UpgradeFailed, err)
+		finishUpgrade(fmt.Errorf("Failed to upgrade cluster member %q: %w", s.Name(), err))
+		return
+	}
+
+	setUpgradeStage(s.Name(), internalTypes.UpgradeDone, nil)
+	finishUpgrade(nil)
+}
+
+// upgradePeer runs the upgrade hook on the given cluster member, and waits for it to come back online, either ready or
+// waiting on the rest of the cluster to be upgraded.
+func upgradePeer(ctx context.Context, name string, c *client.Client) error {
+	setUpgradeStage(name, internalTypes.UpgradeRunning, nil)
+	err := c.UpgradeMember(ctx, UpgradeStepTimeout)
+	if err != nil {
+		// Only an error response means the upgrade hook failed, as the connection is dropped if the hook restarts the
+		// daemon.
+		_, found := api.StatusErrorMatch(err)
+		if found {
+			err = fmt.Errorf("Failed to upgrade cluster member %q: %w", name, err)
+			setUpgradeStage(name, internalTypes.UpgradeFailed, err)
+			return err
+		}
+	}
+
+	setUpgradeStage(name, internalTypes.UpgradeWaiting, nil)
+	waitCtx, cancel := context.WithTimeout(ctx, UpgradeStepTimeout)
+	defer cancel()
+
+	for {
+		server, err := c.GetServer(waitCtx)
+		if err == nil && (server.Ready || server.BlockingMember != "") {
+			setUpgradeStage(name, internalTypes.UpgradeDone, nil)
+			return nil
+		}
+
+		select {
+		case <-waitCtx.Done():
+			err = fmt.Errorf("Timed out waiting for cluster member %q to come back online: %w", name, waitCtx.Err())
+			setUpgradeStage(name, internalTypes.UpgradeFailed, err)
+			return err
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package resources
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/response"
+
+	"github.com/canonical/microcluster/internal/rest/access"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+)
+
+// CertificateExpiryWarning is how long before a certificate expires that a warning is reported for it.
+var CertificateExpiryWarning = 30 * 24 * time.Hour
+
+var warningsCmd = rest.Endpoint{
+	Path: "warnings",
+
+	Get: rest.EndpointAction{Handler: warningsGet, AccessHandler: access.AllowAuthenticated, ProxyTarget: true},
+}
+
+// warningsGet returns the conditions of the cluster that need the attention of an operator, such as certificates that
+// expire within CertificateExpiryWarning.
+func warningsGet(s *state.State, r *http.Request) response.Response {
+	warnings, err := CertificateWarnings(s)
+	if err != nil {
+		return rest.SmartError(err)
+	}
+
+	return rest.CollectionResponse(r, warnings)
+}
+
+// CertificateWarnings returns a warning for the cluster certificate, and for every certificate with access to the
+// cluster, that has expired or expires within CertificateExpiryWarning.
+func CertificateWarnings(s *state.State) ([]internalTypes.Warning, error) {
+	certs, err := trustedCertificates(s)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterCert, err := s.ClusterCert().PublicKeyX509()
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse cluster certificate: %w", err)
+	}
+
+	now := time.Now()
+	warnings := []internalTypes.Warning{}
+	warning := certificateWarning("Cluster certificate", clusterCert.NotAfter, now)
+	if warning != nil {
+		warnings = append(warnings, *warning)
+	}
+
+	for _, cert := range certs {
+		entity := fmt.Sprintf("Certificate %q", cert.Name)
+		if cert.Type == internalTypes.TrustedCertificateMember {
+			entity = fmt.Sprintf("Cluster member %q", cert.Name)
+		}
+
+		warning := certificateWarning(entity, cert.ExpiresAt, now)
+		if warning != nil {
+			warnings = append(warnings, *warning)
+		}
+	}
+
+	return warnings, nil
+}
+
+// certificateWarning returns a warning for the certificate of the given entity if it has expired or expires within
+// CertificateExpiryWarning, or nil otherwise.
+func certificateWarning(entity string, expiry time.Time, now time.Time) *internalTypes.Warning {
+	if now.After(expiry) {
+		return &internalTypes.Warning{
+			Type:      internalTypes.WarningCertificateExpired,
+			Entity:    entity,
+			Message:   fmt.Sprintf("Certificate expired at %s", expiry.UTC().Format(time.RFC3339)),
+			ExpiresAt: expiry,
+		}
+	}
+
+	if expiry.Sub(now) < CertificateExpiryWarning {
+		return &internalTypes.Warning{
+			Type:      internalTypes.WarningCertificateExpiring,
+			Entity:    entity,
+			Message:   fmt.Sprintf("Certificate expires at %s", expiry.UTC().Format(time.RFC3339)),
+			ExpiresAt: expiry,
+		}
+	}
+
+	return nil
+}
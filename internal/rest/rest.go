@@ -5,37 +5,55 @@ import (
 	"crypto/x509"
 	"database/sql"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/canonical/lxd/lxd/request"
 	"github.com/canonical/lxd/lxd/response"
 	"github.com/canonical/lxd/lxd/util"
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
 	"github.com/gorilla/mux"
 
 	"github.com/canonical/microcluster/cluster"
+	"github.com/canonical/microcluster/internal/ca"
 	"github.com/canonical/microcluster/internal/rest/access"
 	"github.com/canonical/microcluster/internal/rest/client"
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
 	internalState "github.com/canonical/microcluster/internal/state"
 	"github.com/canonical/microcluster/rest"
+	"github.com/canonical/microcluster/rest/types"
 )
 
+// forbidden returns a Forbidden response with the NotTrusted error code, and the given reason if any.
+func forbidden(err error) response.Response {
+	message := "not authorized"
+	if err != nil {
+		message = err.Error()
+	}
+
+	return rest.ErrorResponse(http.StatusForbidden, types.ErrorCodeNotTrusted, message)
+}
+
 func handleAPIRequest(action rest.EndpointAction, state *internalState.State, w http.ResponseWriter, r *http.Request) response.Response {
 	trusted := r.Context().Value(request.CtxAccess)
 	if trusted == nil {
-		return response.Forbidden(nil)
+		return forbidden(nil)
 	}
 
 	trustedReq, ok := trusted.(access.TrustedRequest)
 	if !ok {
-		return response.Forbidden(nil)
+		return forbidden(nil)
 	}
 
 	if !trustedReq.Trusted && !action.AllowUntrusted {
-		return response.Forbidden(nil)
+		return forbidden(nil)
 	}
 
 	if action.Handler == nil {
@@ -57,6 +75,10 @@ func handleAPIRequest(action rest.EndpointAction, state *internalState.State, w
 		}
 	}
 
+	if action.LeaderOnly {
+		return proxyLeader(action, state, r)
+	}
+
 	if action.ProxyTarget {
 		return proxyTarget(action, state, r)
 	}
@@ -64,6 +86,40 @@ func handleAPIRequest(action rest.EndpointAction, state *internalState.State, w
 	return action.Handler(state, r)
 }
 
+// leaderForwardedHeader marks requests forwarded to the dqlite leader, so they are handled by the receiving cluster
+// member even if leadership has moved in the meantime, rather than forwarded again.
+const leaderForwardedHeader = "X-Microcluster-Leader-Forwarded"
+
+// proxyLeader forwards the request to the dqlite leader, and returns its response as-is. Requests are handled locally
+// on the leader itself. The forwarding header is only honoured from cluster members, so that other clients can not use
+// it to run leader-only actions elsewhere.
+func proxyLeader(action rest.EndpointAction, s *internalState.State, r *http.Request) response.Response {
+	if r.Header.Get(leaderForwardedHeader) != "" {
+		if access.Identity(r).Type == rest.IdentityClusterMember {
+			return action.Handler(s, r)
+		}
+
+		r.Header.Del(leaderForwardedHeader)
+	}
+
+	leader, err := s.Leader()
+	if err != nil {
+		return rest.SmartError(fmt.Errorf("Failed to get a client for the dqlite leader: %w", err))
+	}
+
+	leaderURL := leader.URL()
+	if leaderURL.URL.Host == s.Address().URL.Host {
+		return action.Handler(s, r)
+	}
+
+	logger.Info("Forwarding request to dqlite leader", logger.Ctx{"source": s.Name(), "leader": leaderURL.URL.Host, "url": r.URL.Path})
+	r.Header.Set(leaderForwardedHeader, s.Name())
+
+	return forwardRequest(&leader.Client, r, leaderURL)
+}
+
+// proxyTarget forwards the request to the cluster member named by the "target" query parameter, and returns its
+// response as-is. Requests without a target, or targeting this cluster member, are handled locally.
 func proxyTarget(action rest.EndpointAction, s *internalState.State, r *http.Request) response.Response {
 	if r.URL == nil {
 		return action.Handler(s, r)
@@ -95,7 +151,7 @@ func proxyTarget(action rest.EndpointAction, s *internalState.State, r *http.Req
 		return nil
 	})
 	if err != nil {
-		return response.BadRequest(err)
+		return rest.SmartError(err)
 	}
 
 	clusterCert, err := s.ClusterCert().PublicKeyX509()
@@ -108,40 +164,66 @@ func proxyTarget(action rest.EndpointAction, s *internalState.State, r *http.Req
 		return response.InternalError(fmt.Errorf("Failed to get a client for the target %q at address %q: %w", target, targetURL.String(), err))
 	}
 
+	logger.Info("Forwarding request to specified target", logger.Ctx{"source": s.Name(), "target": target})
+
+	return forwardRequest(client, r, *targetURL)
+}
+
+// forwardRequest sends the request to the cluster member at the given URL with the given client, and returns its
+// response as-is, including the status code, headers and body.
+func forwardRequest(c *client.Client, r *http.Request, targetURL api.URL) response.Response {
 	// Update request URL.
 	r.RequestURI = ""
 	r.URL.Scheme = targetURL.URL.Scheme
 	r.URL.Host = targetURL.URL.Host
 	r.Host = targetURL.URL.Host
 
-	logger.Info("Forwarding request to specified target", logger.Ctx{"source": s.Name(), "target": target})
-	resp, err := client.MakeRequest(r)
+	resp, err := c.Do(r)
 	if err != nil {
-		return response.SmartError(fmt.Errorf("Failed to send request to target %q: %w", target, err))
+		return response.Unavailable(fmt.Errorf("Failed to send request to %q: %w", targetURL.URL.Host, err))
 	}
 
-	return response.SyncResponse(true, resp.Metadata)
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		defer resp.Body.Close()
+
+		for key, values := range resp.Header {
+			w.Header()[key] = values
+		}
+
+		w.WriteHeader(resp.StatusCode)
+		_, err := io.Copy(w, resp.Body)
+
+		return err
+	})
 }
 
 func handleDatabaseRequest(action rest.EndpointAction, state *internalState.State, w http.ResponseWriter, r *http.Request) response.Response {
 	trusted := r.Context().Value(request.CtxAccess)
 	if trusted == nil {
-		return response.Forbidden(nil)
+		return forbidden(nil)
 	}
 
 	trustedReq, ok := trusted.(access.TrustedRequest)
 	if !ok {
-		return response.Forbidden(nil)
+		return forbidden(nil)
 	}
 
 	if !trustedReq.Trusted {
-		return response.Forbidden(nil)
+		return forbidden(nil)
 	}
 
 	if action.Handler == nil {
 		return response.NotImplemented(nil)
 	}
 
+	// Check access before the connection is hijacked, as no response can be sent afterwards.
+	if action.AccessHandler != nil {
+		accessResp := action.AccessHandler(state, r)
+		if accessResp != response.EmptySyncResponse {
+			return accessResp
+		}
+	}
+
 	// If the request is a POST, then it is likely from the dqlite dial function, so hijack the connection.
 	if r.Method == "POST" {
 		hijacker, ok := w.(http.Hijacker)
@@ -154,29 +236,56 @@ func handleDatabaseRequest(action rest.EndpointAction, state *internalState.Stat
 			return response.InternalError(fmt.Errorf("Failed to hijack connection: %w", err))
 		}
 
+		// Clear the deadlines set by the HTTP server timeouts, which do not apply to dqlite connections.
+		err = conn.SetDeadline(time.Time{})
+		if err != nil {
+			_ = conn.Close()
+			return response.InternalError(fmt.Errorf("Failed to clear connection deadline: %w", err))
+		}
+
 		state.Database.Accept(conn)
 	}
 
 	return action.Handler(state, r)
 }
 
+// MethodNotAllowedHandler handles requests for methods not implemented by the endpoint at the requested path, in place
+// of the default error response.
+var MethodNotAllowedHandler http.Handler
+
+// methodAllowed returns whether the endpoint implements the given HTTP method.
+func methodAllowed(e rest.Endpoint, method string) bool {
+	actions := map[string]rest.EndpointAction{"GET": e.Get, "PUT": e.Put, "POST": e.Post, "DELETE": e.Delete, "PATCH": e.Patch}
+
+	return actions[method].Handler != nil
+}
+
 // HandleEndpoint adds the endpoint to the mux router. A function variable is used to implement common logic
-// before calling the endpoint action handler associated with the request method, if it exists.
-func HandleEndpoint(state *internalState.State, mux *mux.Router, version string, e rest.Endpoint) {
+// before calling the endpoint action handler associated with the request method, if it exists. The given middleware
+// wraps all of it, with the first middleware outermost, and the metrics of its requests are recorded for Metrics.
+func HandleEndpoint(state *internalState.State, mux *mux.Router, version string, e rest.Endpoint, middleware ...rest.Middleware) {
 	url := "/" + version
 	if e.Path != "" {
 		url = filepath.Join(url, e.Path)
 	}
 
-	route := mux.HandleFunc(url, func(w http.ResponseWriter, r *http.Request) {
+	if e.Access == rest.AccessAny {
+		for _, action := range []*rest.EndpointAction{&e.Get, &e.Put, &e.Post, &e.Delete, &e.Patch} {
+			action.AllowUntrusted = true
+		}
+	}
+
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
 		// Actually process the request.
 		var resp response.Response
 
-		// Return Unavailable Error (503) if daemon is shutting down, except for endpoints with AllowedDuringShutdown.
-		if state.Context.Err() == context.Canceled && !e.AllowedDuringShutdown {
-			err := response.Unavailable(fmt.Errorf("Daemon is shutting down")).Render(w)
+		// Return Unavailable Error (503) if daemon is starting or shutting down, unless the endpoint allows it.
+		resp = unavailable(state, e, r)
+		if resp != nil {
+			setRetryAfter(w)
+			err := resp.Render(w)
 			if err != nil {
 				logger.Error("Failed to write HTTP response", logger.Ctx{"url": r.URL, "err": err})
 			}
@@ -184,15 +293,9 @@ func HandleEndpoint(state *internalState.State, mux *mux.Router, version string,
 			return
 		}
 
-		if !e.AllowedBeforeInit {
-			if !state.Database.IsOpen() {
-				err := response.Unavailable(fmt.Errorf("Daemon not yet initialized")).Render(w)
-				if err != nil {
-					logger.Error("Failed to write HTTP response", logger.Ctx{"url": r.URL, "err": err})
-				}
-
-				return
-			}
+		if MethodNotAllowedHandler != nil && !methodAllowed(e, r.Method) {
+			MethodNotAllowedHandler.ServeHTTP(w, r)
+			return
 		}
 
 		// If the request is a database request, the connection should be hijacked.
@@ -201,23 +304,62 @@ func HandleEndpoint(state *internalState.State, mux *mux.Router, version string,
 			handleRequest = handleDatabaseRequest
 		}
 
-		trusted, err := authenticate(state, r)
-		if err != nil {
-			resp = response.Forbidden(fmt.Errorf("Failed to authenticate request: %w", err))
+		// Check the rate limit first, so that rejected requests do not reach the database to authenticate.
+		var trusted bool
+		var restricted *restriction
+		var err error
+		var identity rest.Identity
+		limited := rateLimited(state, r)
+		if !limited {
+			trusted, restricted, err = authenticate(state, r)
+			identity = identify(state, r, restricted)
+		}
+
+		if limited {
+			w.Header().Set("Retry-After", "1")
+			resp = rest.ErrorResponse(http.StatusTooManyRequests, types.ErrorCodeRateLimited, "Too many requests")
+		} else if e.Access == rest.AccessControlSocket && r.RemoteAddr != "@" {
+			resp = forbidden(fmt.Errorf("%s %q is only available over the control socket", r.Method, url))
+		} else if err != nil {
+			resp = forbidden(fmt.Errorf("Failed to authenticate request: %w", err))
+		} else if restricted != nil && !cluster.ACLRulesAllow(restricted.rules, r.Method, url) {
+			resp = forbidden(fmt.Errorf("%s is not allowed to access %s %q", identity, r.Method, url))
+		} else if resp = authorize(r, version, trusted, identity, url); resp != nil {
+			logger.Debug("Request denied by authorizer", logger.Ctx{"identity": identity, "method": r.Method, "url": url})
+		} else if r, err = rest.ParsePathParams(r, e.PathParams); err != nil {
+			resp = response.BadRequest(err)
 		} else {
-			r = r.WithContext(context.WithValue(r.Context(), any(request.CtxAccess), access.TrustedRequest{Trusted: trusted}))
+			ctx := context.WithValue(r.Context(), any(request.CtxAccess), access.TrustedRequest{Trusted: trusted, Identity: identity, Restricted: restricted != nil})
+			if HandlerTimeout > 0 && e.Path != "database" {
+				// The context is only cancelled once the response is rendered, as rendering may still need it.
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, HandlerTimeout)
+				defer cancel()
+			}
+
+			r = r.WithContext(ctx)
 
 			switch r.Method {
 			case "GET":
-				resp = handleRequest(e.Get, state, w, r)
+				resp = runHandler(state, e, w, r, func(w http.ResponseWriter, r *http.Request) response.Response {
+					return handleRequest(e.Get, state, w, r)
+				})
 			case "PUT":
-				resp = handleRequest(e.Put, state, w, r)
+				resp = runHandler(state, e, w, r, func(w http.ResponseWriter, r *http.Request) response.Response {
+					return handleRequest(e.Put, state, w, r)
+				})
 			case "POST":
-				resp = handleRequest(e.Post, state, w, r)
+				resp = runHandler(state, e, w, r, func(w http.ResponseWriter, r *http.Request) response.Response {
+					return handleRequest(e.Post, state, w, r)
+				})
 			case "DELETE":
-				resp = handleRequest(e.Delete, state, w, r)
+				resp = runHandler(state, e, w, r, func(w http.ResponseWriter, r *http.Request) response.Response {
+					return handleRequest(e.Delete, state, w, r)
+				})
 			case "PATCH":
-				resp = handleRequest(e.Patch, state, w, r)
+				resp = runHandler(state, e, w, r, func(w http.ResponseWriter, r *http.Request) response.Response {
+					return handleRequest(e.Patch, state, w, r)
+				})
 			default:
 				resp = response.NotFound(fmt.Errorf("Method '%s' not found", r.Method))
 			}
@@ -235,6 +377,17 @@ func HandleEndpoint(state *internalState.State, mux *mux.Router, version string,
 		}
 	})
 
+	for i := len(middleware) - 1; i >= 0; i-- {
+		handler = middleware[i](handler)
+	}
+
+	// The database endpoint holds its hijacked connections open, so its latency would not be meaningful.
+	if e.Path != "database" {
+		handler = instrument(url, handler)
+	}
+
+	route := mux.Handle(url, handler)
+
 	// If the endpoint has a canonical name then record it so it can be used to build URLS
 	// and accessed in the context of the request by the handler function.
 	if e.Name != "" {
@@ -242,42 +395,244 @@ func HandleEndpoint(state *internalState.State, mux *mux.Router, version string,
 	}
 }
 
+// ListenAddresses are the addresses the cluster API binds to, if they differ from the advertised address. Requests may
+// be addressed to any of them.
+var ListenAddresses []string
+
+// listenAddress returns whether the given request address is the advertised address or one of ListenAddresses.
+// Listeners on all addresses accept requests to any address with their port.
+func listenAddress(state *internalState.State, address string) bool {
+	if address == state.Address().URL.Host {
+		return true
+	}
+
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+
+	for _, listen := range ListenAddresses {
+		if address == listen {
+			return true
+		}
+
+		listenHost, listenPort, err := net.SplitHostPort(listen)
+		if err != nil || listenPort != port {
+			continue
+		}
+
+		ip := net.ParseIP(listenHost)
+		if listenHost == "" || ip != nil && ip.IsUnspecified() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Authorizer is the application's authorizer for requests to its endpoints, if any.
+var Authorizer rest.Authorizer
+
+// authorize asks the Authorizer whether the given identity may make the request to an application endpoint, and
+// returns a Forbidden response if not. Requests over the local control socket, and untrusted requests to endpoints
+// that allow them, are not subject to the Authorizer.
+func authorize(r *http.Request, version string, trusted bool, identity rest.Identity, url string) response.Response {
+	if Authorizer == nil || version != string(client.ExtendedEndpoint) || !trusted || identity.Type == rest.IdentityLocal {
+		return nil
+	}
+
+	allowed, err := Authorizer.Authorize(r.Context(), identity, url, r.Method)
+	if err != nil {
+		return response.InternalError(fmt.Errorf("Failed to authorize request: %w", err))
+	}
+
+	if !allowed {
+		return forbidden(fmt.Errorf("%s is not allowed to access %s %q", identity, r.Method, url))
+	}
+
+	return nil
+}
+
+// restriction limits a trusted request to the API endpoints allowed by a set of ACL rules.
+type restriction struct {
+	// identity is the certificate or bearer token the restriction applies to.
+	identity rest.Identity
+	rules    []internalTypes.ACLRule
+}
+
 // authenticate ensures the request certificates are trusted before proceeding.
 // - Requests over the unix socket are always allowed.
 // - HTTP requests require our cluster cert, or remote certs.
-func authenticate(state *internalState.State, r *http.Request) (bool, error) {
+// - Certificates with an ACL, and requests with a bearer token, are trusted, but restricted to the endpoints allowed by
+// the returned restriction.
+// - If an external certificate authority is set, any certificate it issued is trusted, and all certificates must be
+// issued by it.
+func authenticate(state *internalState.State, r *http.Request) (bool, *restriction, error) {
 	if r.RemoteAddr == "@" {
-		return true, nil
+		err := checkControlCredentials(r)
+		if err != nil {
+			return false, nil, err
+		}
+
+		return true, nil, nil
 	}
 
 	if state.Address().URL.Host == "" {
 		logger.Info("Allowing unauthenticated request to un-initialized system")
-		return true, nil
-	}
-
-	var trustedCerts map[string]x509.Certificate
-	switch r.Host {
-	case state.Address().URL.Host:
-		trustedCerts = state.Remotes().CertificatesNative()
-	default:
-		return false, fmt.Errorf("Invalid request address %q", r.Host)
-	}
-
-	if r.TLS != nil {
-		for _, cert := range r.TLS.PeerCertificates {
-			trusted, fingerprint := util.CheckTrustState(*cert, trustedCerts, nil, false)
-			if trusted {
-				remote := state.Remotes().RemoteByCertificateFingerprint(fingerprint)
-				if remote == nil {
-					// The cert fingerprint can no longer be matched back against what is in the truststore (e.g. file
-					// was deleted), so we are no longer trusted.
-					return false, nil
-				}
+		return true, nil, nil
+	}
+
+	// Requests to the consumer API listener may use any address that routes to it, such as that of a load balancer.
+	if !consumerRequest(r) && !listenAddress(state, r.Host) {
+		return false, nil, fmt.Errorf("Invalid request address %q", r.Host)
+	}
 
-				return true, nil
+	trustedCerts := state.Remotes().CertificatesNative()
+
+	authorization := r.Header.Get("Authorization")
+	if strings.HasPrefix(authorization, "Bearer ") {
+		return authenticateBearer(state, strings.TrimPrefix(authorization, "Bearer "))
+	}
+
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return false, nil, nil
+	}
+
+	// Certificates issued by an external certificate authority are trusted if they have not been revoked. Unless they
+	// belong to a cluster member, they are only trusted as clients, and can not use the endpoints reserved for cluster
+	// members.
+	authority := ca.Current()
+	if authority != nil {
+		err := verifyAuthority(authority, r)
+		if err != nil {
+			if !client.Insecure {
+				logger.Debug("Rejecting request with untrusted certificate", logger.Ctx{"address": clientAddress(r), "error": err})
+				return false, nil, nil
 			}
+
+			// Insecure daemons still check the certificate against the trust store, but do not trust it as issued by
+			// the certificate authority.
+			logger.Warn("INSECURE: Accepting certificate not issued by the certificate authority", logger.Ctx{"address": clientAddress(r), "error": err})
+			authority = nil
+		}
+	}
+
+	for _, cert := range r.TLS.PeerCertificates {
+		trusted, fingerprint := util.CheckTrustState(*cert, trustedCerts, nil, false)
+		if trusted {
+			remote := state.Remotes().RemoteByCertificateFingerprint(fingerprint)
+			if remote == nil {
+				// The cert fingerprint can no longer be matched back against what is in the truststore (e.g. file
+				// was deleted), so we are no longer trusted.
+				return false, nil, nil
+			}
+
+			return true, nil, nil
+		}
+	}
+
+	acl, err := certificateACL(state, r.TLS.PeerCertificates[0])
+	if err != nil {
+		return false, nil, err
+	}
+
+	if acl != nil {
+		identity := rest.Identity{Type: rest.IdentityCertificate, Name: acl.Name, Fingerprint: acl.Fingerprint}
+		return true, &restriction{identity: identity, rules: acl.Rules}, nil
+	}
+
+	return authority != nil, nil, nil
+}
+
+// identify returns the identity of the caller of the request, for authorization and for attributing the changes it
+// makes.
+func identify(state *internalState.State, r *http.Request, restricted *restriction) rest.Identity {
+	identity := identifyCaller(state, r, restricted)
+	if r.RemoteAddr != "@" {
+		identity.Address = clientAddress(r)
+	}
+
+	return identity
+}
+
+// identifyCaller returns the identity of the caller of the request, without its address.
+func identifyCaller(state *internalState.State, r *http.Request, restricted *restriction) rest.Identity {
+	if r.RemoteAddr == "@" {
+		identity := rest.Identity{Type: rest.IdentityLocal}
+		cred, err := controlCredentials(r)
+		if err == nil {
+			identity.Credentials = &rest.Credentials{UID: cred.Uid, GID: cred.Gid, PID: cred.Pid}
 		}
+
+		return identity
+	}
+
+	if restricted != nil {
+		return restricted.identity
+	}
+
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return rest.Identity{Type: rest.IdentityAnonymous}
+	}
+
+	fingerprint := shared.CertFingerprint(r.TLS.PeerCertificates[0])
+	remote := state.Remotes().RemoteByCertificateFingerprint(fingerprint)
+	if remote != nil {
+		return rest.Identity{Type: rest.IdentityClusterMember, Name: remote.Name, Fingerprint: fingerprint}
+	}
+
+	return rest.Identity{Type: rest.IdentityCertificate, Name: fingerprint, Fingerprint: fingerprint}
+}
+
+// certificateACL returns the ACL restricting the given certificate, or nil if it has none.
+func certificateACL(state *internalState.State, cert *x509.Certificate) (*cluster.InternalRestrictedCertificate, error) {
+	if !state.Database.IsOpen() {
+		return nil, nil
+	}
+
+	var acl *cluster.InternalRestrictedCertificate
+	err := state.Database.Transaction(state.Context, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		acl, err = cluster.GetInternalRestrictedCertificateByFingerprint(ctx, tx, shared.CertFingerprint(cert))
+
+		return err
+	})
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("Failed to get certificate ACL: %w", err)
+	}
+
+	return acl, nil
+}
+
+// authenticateBearer checks that the given bearer token has been issued and has not expired, and returns the
+// restriction of its rules.
+func authenticateBearer(state *internalState.State, bearer string) (bool, *restriction, error) {
+	if !state.Database.IsOpen() {
+		return false, nil, fmt.Errorf("Bearer tokens can not be checked before the database is open")
+	}
+
+	var token *cluster.InternalBearerToken
+	err := state.Database.Transaction(state.Context, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		token, err = cluster.GetInternalBearerTokenByHash(ctx, tx, cluster.HashAPIToken(bearer))
+
+		return err
+	})
+	if err != nil {
+		if api.StatusErrorCheck(err, http.StatusNotFound) {
+			return false, nil, fmt.Errorf("Invalid bearer token")
+		}
+
+		return false, nil, fmt.Errorf("Failed to get bearer token: %w", err)
+	}
+
+	if token.Expired() {
+		return false, nil, fmt.Errorf("Bearer token %q has expired", token.Name)
 	}
 
-	return false, nil
+	return true, &restriction{identity: rest.Identity{Type: rest.IdentityBearerToken, Name: token.Name}, rules: token.Rules}, nil
 }
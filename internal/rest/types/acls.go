@@ -0,0 +1,28 @@
+package types
+
+import (
+	"github.com/canonical/microcluster/rest/types"
+)
+
+// ACLRule allows a restricted certificate to access the API endpoints matching a path pattern.
+type ACLRule struct {
+	// Path is a pattern in path.Match syntax matched against the endpoint path, including the API version, such as
+	// "/1.0/cluster" or "/1.0/cluster/*".
+	Path string `json:"path" yaml:"path"`
+
+	// Methods are the HTTP methods allowed on the matching endpoints. All methods are allowed if empty.
+	Methods []string `json:"methods" yaml:"methods"`
+}
+
+// CertificateACL represents a certificate trusted by the cluster that is restricted to a set of API endpoints.
+type CertificateACL struct {
+	Name        string                `json:"name" yaml:"name" validate:"required"`
+	Fingerprint string                `json:"fingerprint" yaml:"fingerprint"`
+	Certificate types.X509Certificate `json:"certificate" yaml:"certificate" validate:"required"`
+	Rules       []ACLRule             `json:"rules" yaml:"rules"`
+}
+
+// CertificateACLPut represents the modifiable fields of a certificate ACL.
+type CertificateACLPut struct {
+	Rules []ACLRule `json:"rules" yaml:"rules"`
+}
@@ -0,0 +1,29 @@
+package types
+
+import (
+	"time"
+)
+
+// APIToken represents a bearer token that grants access to the API endpoints allowed by its rules, as an alternative
+// to a client certificate.
+type APIToken struct {
+	Name      string    `json:"name" yaml:"name"`
+	Rules     []ACLRule `json:"rules" yaml:"rules"`
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
+
+// APITokenPost represents a request to issue a new API token.
+type APITokenPost struct {
+	Name  string    `json:"name" yaml:"name" validate:"required"`
+	Rules []ACLRule `json:"rules" yaml:"rules" validate:"required"`
+
+	// ExpireAfter is the lifetime of the requested token. If unset, the token never expires.
+	ExpireAfter time.Duration `json:"expire_after,omitempty" yaml:"expire_after,omitempty"`
+}
+
+// APITokenSecret holds a newly issued API token. The token is only returned once, as only its hash is stored.
+type APITokenSecret struct {
+	Name      string    `json:"name" yaml:"name"`
+	Token     string    `json:"token" yaml:"token"`
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
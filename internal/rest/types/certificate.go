@@ -0,0 +1,60 @@
+package types
+
+import (
+	"time"
+
+	"github.com/canonical/microcluster/rest/types"
+)
+
+// ClusterCertificatePhase is a step of a cluster certificate rotation, applied to every cluster member in turn.
+type ClusterCertificatePhase string
+
+const (
+	// ClusterCertificatePrepare stores the new cluster certificate, and accepts it from other cluster members in
+	// addition to the current one.
+	ClusterCertificatePrepare ClusterCertificatePhase = "prepare"
+
+	// ClusterCertificateSwitch replaces the current cluster certificate with the new one, and keeps accepting the old
+	// one from other cluster members.
+	ClusterCertificateSwitch ClusterCertificatePhase = "switch"
+
+	// ClusterCertificateFinish stops accepting the old cluster certificate.
+	ClusterCertificateFinish ClusterCertificatePhase = "finish"
+
+	// ClusterCertificateAbort discards the new cluster certificate if it has not been switched to yet.
+	ClusterCertificateAbort ClusterCertificatePhase = "abort"
+)
+
+// ClusterCertificateUpdate represents a phase of a cluster certificate rotation sent to a cluster member. Every phase
+// carries the new cluster certificate, but its key is only sent with the prepare phase.
+type ClusterCertificateUpdate struct {
+	Phase       ClusterCertificatePhase `json:"phase"       yaml:"phase"`
+	Certificate types.X509Certificate   `json:"certificate" yaml:"certificate"`
+	Key         string                  `json:"key"         yaml:"key"`
+}
+
+// ClusterCertificate represents the cluster certificate served by every cluster member.
+type ClusterCertificate struct {
+	Certificate types.X509Certificate `json:"certificate" yaml:"certificate"`
+	Fingerprint string                `json:"fingerprint" yaml:"fingerprint"`
+}
+
+// TrustedCertificateType is the kind of access granted by a trusted certificate.
+type TrustedCertificateType string
+
+const (
+	// TrustedCertificateMember is the certificate of a cluster member in the trust store, with full access.
+	TrustedCertificateMember TrustedCertificateType = "member"
+
+	// TrustedCertificateClient is a client certificate restricted to the API endpoints allowed by its ACL.
+	TrustedCertificateClient TrustedCertificateType = "client"
+)
+
+// TrustedCertificate represents a certificate that has access to the cluster.
+type TrustedCertificate struct {
+	Name        string                 `json:"name"        yaml:"name"`
+	Type        TrustedCertificateType `json:"type"        yaml:"type"`
+	Fingerprint string                 `json:"fingerprint" yaml:"fingerprint"`
+	Subject     string                 `json:"subject"     yaml:"subject"`
+	ExpiresAt   time.Time              `json:"expires_at"  yaml:"expires_at"`
+}
@@ -1,6 +1,9 @@
 package types
 
 import (
+	"crypto"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/canonical/microcluster/rest/types"
@@ -14,6 +17,111 @@ type ClusterMember struct {
 	LastHeartbeat time.Time    `json:"last_heartbeat" yaml:"last_heartbeat"`
 	Status        MemberStatus `json:"status" yaml:"status"`
 	Secret        string       `json:"secret" yaml:"secret"`
+	FailureDomain uint64       `json:"failure_domain" yaml:"failure_domain"`
+
+	// AppStatus holds small status fields reported by the application on the cluster member during heartbeats.
+	AppStatus map[string]any `json:"app_status,omitempty" yaml:"app_status,omitempty"`
+
+	// Config holds user metadata attached to the cluster member by the application.
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// Cordoned is whether the cluster member is in maintenance mode. A cordoned cluster member does not hold dqlite
+	// leadership.
+	Cordoned bool `json:"cordoned" yaml:"cordoned"`
+
+	// APIExtensions are the API extensions supported by the cluster member.
+	APIExtensions []string `json:"api_extensions" yaml:"api_extensions"`
+
+	// Compatibility and JoinConfig are supplied by a joining cluster member so that the cluster can validate it before
+	// accepting the join.
+	Compatibility *Compatibility    `json:"compatibility,omitempty" yaml:"compatibility,omitempty"`
+	JoinConfig    map[string]string `json:"join_config,omitempty"   yaml:"join_config,omitempty"`
+}
+
+// ClusterMemberFilter selects cluster members by name, dqlite role and status. The status is matched regardless of
+// case. Empty fields match any cluster member.
+type ClusterMemberFilter struct {
+	Name   string
+	Role   string
+	Status string
+}
+
+// Match returns whether the given cluster member matches the filter.
+func (f ClusterMemberFilter) Match(member ClusterMember) bool {
+	if f.Name != "" && member.Name != f.Name {
+		return false
+	}
+
+	if f.Role != "" && member.Role != f.Role {
+		return false
+	}
+
+	if f.Status != "" && !strings.EqualFold(string(member.Status), f.Status) {
+		return false
+	}
+
+	return true
+}
+
+// ClusterMemberCordon represents a request to cordon a cluster member for maintenance, or to uncordon it.
+type ClusterMemberCordon struct {
+	Cordoned bool `json:"cordoned" yaml:"cordoned"`
+}
+
+// ClusterMemberCertificate represents a request to replace the server certificate of a cluster member.
+type ClusterMemberCertificate struct {
+	Certificate types.X509Certificate `json:"certificate" yaml:"certificate"`
+
+	// Signature is the signature of the cluster member name and the new certificate by the key of the new certificate,
+	// proving that the cluster member holds that key.
+	Signature string `json:"signature" yaml:"signature"`
+}
+
+// signedData returns the data that is signed for the cluster member with the given name.
+func (c ClusterMemberCertificate) signedData(name string) []byte {
+	return []byte(name + "\n" + c.Certificate.String())
+}
+
+// Sign signs the request for the cluster member with the given name with the key of the new certificate.
+func (c *ClusterMemberCertificate) Sign(name string, signer crypto.Signer) error {
+	signature, err := sign(signer, c.signedData(name))
+	if err != nil {
+		return fmt.Errorf("Failed to sign cluster member certificate: %w", err)
+	}
+
+	c.Signature = signature
+
+	return nil
+}
+
+// Verify checks that the request for the cluster member with the given name was signed by the key of the new
+// certificate.
+func (c ClusterMemberCertificate) Verify(name string) error {
+	if c.Signature == "" {
+		return fmt.Errorf("Cluster member certificate is not signed")
+	}
+
+	err := verify(c.Certificate.Certificate, c.signedData(name), c.Signature)
+	if err != nil {
+		return fmt.Errorf("Cluster member certificate was not signed by its key: %w", err)
+	}
+
+	return nil
+}
+
+// ClusterMemberRole represents a request to assign a dqlite role ("voter", "stand-by" or "spare") to a cluster member.
+type ClusterMemberRole struct {
+	Role string `json:"role" yaml:"role"`
+}
+
+// ClusterMemberConfig represents the user metadata attached to a cluster member.
+type ClusterMemberConfig struct {
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// ClusterMemberRename represents a request to rename a cluster member.
+type ClusterMemberRename struct {
+	Name string `json:"name" yaml:"name"`
 }
 
 // ClusterMemberLocal represents local information about a new cluster member.
@@ -30,6 +138,14 @@ const (
 	// MemberOnline should be the MemberStatus when the node is online and reachable.
 	MemberOnline MemberStatus = "ONLINE"
 
+	// MemberSuspect should be the MemberStatus when the node has missed heartbeats, but not for long enough to be
+	// considered offline.
+	MemberSuspect MemberStatus = "SUSPECT"
+
+	// MemberOffline should be the MemberStatus when the node has not responded to heartbeats for longer than the
+	// offline threshold.
+	MemberOffline MemberStatus = "OFFLINE"
+
 	// MemberUnreachable should be the MemberStatus when we were not able to connect to the node.
 	MemberUnreachable MemberStatus = "UNREACHABLE"
 
@@ -0,0 +1,25 @@
+package types
+
+// Compatibility describes the versions supported by a cluster member, so that upgrade tooling can determine a safe
+// upgrade order.
+type Compatibility struct {
+	Name string `json:"name" yaml:"name"`
+
+	// InternalSchemaVersion is the version of the tables managed by MicroCluster.
+	InternalSchemaVersion int `json:"internal_schema_version" yaml:"internal_schema_version"`
+
+	// AppSchemaVersion is the version of the tables added by the application's schema extensions.
+	AppSchemaVersion int `json:"app_schema_version" yaml:"app_schema_version"`
+
+	// APIVersion is the version of the internally managed API.
+	APIVersion string `json:"api_version" yaml:"api_version"`
+
+	// MinPeerSchemaVersion and MaxPeerSchemaVersion are the range of total schema versions a peer can have for this
+	// member to start. Peers below the range block this member until they are upgraded, and peers above the range
+	// cause this member to refuse to start until it is upgraded itself.
+	MinPeerSchemaVersion int `json:"min_peer_schema_version" yaml:"min_peer_schema_version"`
+	MaxPeerSchemaVersion int `json:"max_peer_schema_version" yaml:"max_peer_schema_version"`
+
+	// Error is set if the member could not be queried when aggregating the cluster view.
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+}
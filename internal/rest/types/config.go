@@ -0,0 +1,6 @@
+package types
+
+// ClusterConfig represents the cluster-wide key/value config.
+type ClusterConfig struct {
+	Config map[string]string `json:"config" yaml:"config"`
+}
@@ -11,4 +11,8 @@ type Control struct {
 	JoinToken  string            `json:"join_token" yaml:"join_token"`
 	Address    types.AddrPort    `json:"address" yaml:"address"`
 	Name       string            `json:"name" yaml:"name"`
+
+	// Tokens are join tokens to record once the cluster is bootstrapped, so that members given the same secrets in
+	// advance can join without a token being issued to them.
+	Tokens []TokenRecord `json:"tokens,omitempty" yaml:"tokens,omitempty"`
 }
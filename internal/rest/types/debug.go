@@ -0,0 +1,23 @@
+package types
+
+// FailureAction is a failure scenario that can be simulated on a cluster member.
+type FailureAction string
+
+const (
+	// FailureDropLeadership transfers dqlite leadership away from this cluster member, if it is the leader.
+	FailureDropLeadership FailureAction = "drop-leadership"
+
+	// FailurePauseHeartbeats stops this cluster member from sending or answering heartbeats for a duration.
+	FailurePauseHeartbeats FailureAction = "pause-heartbeats"
+
+	// FailureCloseDatabase stops the database on this cluster member. The daemon must be restarted to recover.
+	FailureCloseDatabase FailureAction = "close-database"
+)
+
+// SimulateFailure represents a request to simulate a failure scenario on a cluster member.
+type SimulateFailure struct {
+	Action FailureAction `json:"action" yaml:"action"`
+
+	// Duration is how long the failure should last, for actions that recover on their own.
+	Duration string `json:"duration" yaml:"duration"`
+}
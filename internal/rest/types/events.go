@@ -0,0 +1,14 @@
+package types
+
+import (
+	"time"
+)
+
+// Event represents a change in cluster membership, or an application event, recorded in the event history.
+type Event struct {
+	ID        int64     `json:"id"         yaml:"id"`
+	Type      string    `json:"type"       yaml:"type"`
+	Member    string    `json:"member"     yaml:"member"`
+	Message   string    `json:"message"    yaml:"message"`
+	CreatedAt time.Time `json:"created_at" yaml:"created_at"`
+}
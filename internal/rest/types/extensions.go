@@ -0,0 +1,10 @@
+package types
+
+// APIExtensions describes the API extensions supported across the cluster.
+type APIExtensions struct {
+	// Common are the API extensions supported by every cluster member.
+	Common []string `json:"common" yaml:"common"`
+
+	// Members are the API extensions supported by each cluster member, keyed by name.
+	Members map[string][]string `json:"members" yaml:"members"`
+}
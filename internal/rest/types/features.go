@@ -0,0 +1,9 @@
+package types
+
+// FeatureFlag represents a cluster-wide feature flag and its rollout.
+type FeatureFlag struct {
+	Name       string   `json:"name" yaml:"name"`
+	Enabled    bool     `json:"enabled" yaml:"enabled"`
+	Members    []string `json:"members" yaml:"members"`
+	Percentage int      `json:"percentage" yaml:"percentage"`
+}
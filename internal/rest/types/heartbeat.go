@@ -6,4 +6,8 @@ type HeartbeatInfo struct {
 	BeginRound     bool                     `json:"begin_round" yaml:"begin_round"`
 	MaxSchema      int                      `json:"max_schema" yaml:"max_schema"`
 	ClusterMembers map[string]ClusterMember `json:"cluster_members" yaml:"cluster_members"`
+
+	// Members is every cluster member recorded in the database, including pending members, so that members that missed
+	// a trust store update converge on the leader's view.
+	Members []ClusterMemberLocal `json:"members" yaml:"members"`
 }
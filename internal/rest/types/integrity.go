@@ -0,0 +1,10 @@
+package types
+
+// IntegrityCheck represents the result of a database integrity check on a cluster member.
+type IntegrityCheck struct {
+	Name   string   `json:"name" yaml:"name"`
+	OK     bool     `json:"ok" yaml:"ok"`
+	Global []string `json:"global" yaml:"global"`
+	Local  []string `json:"local" yaml:"local"`
+	Error  string   `json:"error" yaml:"error"`
+}
@@ -0,0 +1,11 @@
+package types
+
+import (
+	"github.com/canonical/microcluster/rest/types"
+)
+
+// Leader identifies the cluster member currently holding dqlite leadership.
+type Leader struct {
+	Name    string         `json:"name" yaml:"name"`
+	Address types.AddrPort `json:"address" yaml:"address"`
+}
@@ -0,0 +1,16 @@
+package types
+
+import (
+	"time"
+)
+
+// Operation represents a completed operation recorded in the operation history.
+type Operation struct {
+	Type      string        `json:"type"       yaml:"type"`
+	Initiator string        `json:"initiator"  yaml:"initiator"`
+	Member    string        `json:"member"     yaml:"member"`
+	StartedAt time.Time     `json:"started_at" yaml:"started_at"`
+	Duration  time.Duration `json:"duration"   yaml:"duration"`
+	Status    string        `json:"status"     yaml:"status"`
+	Error     string        `json:"error"      yaml:"error"`
+}
@@ -0,0 +1,14 @@
+package types
+
+// Project represents a project used to scope resources in a multi-tenant cluster.
+type Project struct {
+	Name        string            `json:"name" yaml:"name" validate:"required"`
+	Description string            `json:"description" yaml:"description"`
+	Config      map[string]string `json:"config" yaml:"config"`
+}
+
+// ProjectPut represents the modifiable fields of a project.
+type ProjectPut struct {
+	Description string            `json:"description" yaml:"description"`
+	Config      map[string]string `json:"config" yaml:"config"`
+}
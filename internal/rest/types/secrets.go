@@ -0,0 +1,18 @@
+package types
+
+import (
+	"time"
+)
+
+// Secret represents a cluster-wide secret, such as a credential shared by all cluster members. The value is only
+// returned when fetching a single secret.
+type Secret struct {
+	Name      string    `json:"name" yaml:"name"`
+	Value     string    `json:"value,omitempty" yaml:"value,omitempty"`
+	UpdatedAt time.Time `json:"updated_at" yaml:"updated_at"`
+}
+
+// SecretPut represents a request to set the value of a cluster-wide secret.
+type SecretPut struct {
+	Value string `json:"value" yaml:"value"`
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"time"
+
 	"github.com/canonical/microcluster/rest/types"
 )
 
@@ -9,4 +11,28 @@ type Server struct {
 	Name    string         `json:"name"    yaml:"name"`
 	Address types.AddrPort `json:"address" yaml:"address"`
 	Ready   bool           `json:"ready"   yaml:"ready"`
+
+	// Leader is whether this cluster member is the dqlite leader.
+	Leader bool `json:"leader" yaml:"leader"`
+
+	// APIExtensions are the API extensions supported by this cluster member.
+	APIExtensions []string `json:"api_extensions" yaml:"api_extensions"`
+
+	// BlockingMember is the name of the cluster member whose pending schema upgrade is preventing this member from
+	// becoming ready, and Status describes the wait.
+	BlockingMember string `json:"blocking_member,omitempty" yaml:"blocking_member,omitempty"`
+	Status         string `json:"status,omitempty"          yaml:"status,omitempty"`
+
+	// Backup reports the result of scheduled database backups, if any have run.
+	Backup *BackupStatus `json:"backup,omitempty" yaml:"backup,omitempty"`
+
+	// HandlerPanics is the number of API handler panics recovered since the daemon started.
+	HandlerPanics uint64 `json:"handler_panics" yaml:"handler_panics"`
+}
+
+// BackupStatus represents the result of scheduled database backups.
+type BackupStatus struct {
+	LastAttempt time.Time `json:"last_attempt"         yaml:"last_attempt"`
+	LastSuccess time.Time `json:"last_success"         yaml:"last_success"`
+	LastError   string    `json:"last_error,omitempty" yaml:"last_error,omitempty"`
 }
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+)
+
+// sign returns the base64 encoded signature of the given data by the given key. Ed25519 keys sign the data itself, and
+// other keys its SHA-256 digest.
+func sign(signer crypto.Signer, data []byte) (string, error) {
+	var signature []byte
+	var err error
+	_, ok := signer.Public().(ed25519.PublicKey)
+	if ok {
+		signature, err = signer.Sign(rand.Reader, data, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(data)
+		signature, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(signature), nil
+}
+
+// verify checks that the given base64 encoded signature of the given data was made by the key of the given certificate.
+func verify(cert *x509.Certificate, data []byte, encodedSignature string) error {
+	signature, err := base64.StdEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return fmt.Errorf("Invalid signature: %w", err)
+	}
+
+	digest := sha256.Sum256(data)
+	var valid bool
+	switch key := cert.PublicKey.(type) {
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(key, digest[:], signature)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) == nil
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(key, data, signature)
+	default:
+		return fmt.Errorf("Unsupported certificate key type %T", cert.PublicKey)
+	}
+
+	if !valid {
+		return fmt.Errorf("Signature does not match the certificate")
+	}
+
+	return nil
+}
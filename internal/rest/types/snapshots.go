@@ -0,0 +1,26 @@
+package types
+
+import (
+	"time"
+
+	"github.com/canonical/microcluster/rest/types"
+)
+
+// Snapshot represents the cluster membership and configuration at a point in time.
+type Snapshot struct {
+	TakenAt      time.Time         `json:"taken_at"      yaml:"taken_at"`
+	Config       map[string]string `json:"config"        yaml:"config"`
+	Members      []SnapshotMember  `json:"members"       yaml:"members"`
+	FeatureFlags []FeatureFlag     `json:"feature_flags" yaml:"feature_flags"`
+	Projects     []Project         `json:"projects"      yaml:"projects"`
+}
+
+// SnapshotMember represents a cluster member as recorded in a snapshot.
+type SnapshotMember struct {
+	Name          string            `json:"name"           yaml:"name"`
+	Address       types.AddrPort    `json:"address"        yaml:"address"`
+	Role          string            `json:"role"           yaml:"role"`
+	SchemaVersion int               `json:"schema_version" yaml:"schema_version"`
+	FailureDomain uint64            `json:"failure_domain" yaml:"failure_domain"`
+	Config        map[string]string `json:"config"         yaml:"config"`
+}
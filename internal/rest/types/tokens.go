@@ -1,16 +1,25 @@
 package types
 
 import (
+	"crypto"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"time"
 
 	"github.com/canonical/microcluster/rest/types"
 )
 
 // TokenRecord holds information for requesting a join token.
 type TokenRecord struct {
-	Name  string `json:"name" yaml:"name"`
-	Token string `json:"token" yaml:"token"`
+	Name      string    `json:"name" yaml:"name"`
+	Token     string    `json:"token" yaml:"token"`
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+	Issuer    string    `json:"issuer" yaml:"issuer"`
+
+	// ExpireAfter is the lifetime of the requested token. If unset, the default token expiry is used.
+	ExpireAfter time.Duration `json:"expire_after,omitempty" yaml:"expire_after,omitempty"`
 }
 
 // TokenResponse holds the information for connecting to a cluster by a node with a valid join token.
@@ -18,6 +27,9 @@ type TokenResponse struct {
 	ClusterCert    types.X509Certificate `json:"cluster_cert" yaml:"cluster_cert"`
 	ClusterKey     string                `json:"cluster_key" yaml:"cluster_key"`
 	ClusterMembers []ClusterMemberLocal  `json:"cluster_members" yaml:"cluster_members"`
+
+	// SecretsKey is the hex encoded key that cluster-wide secrets are encrypted with.
+	SecretsKey string `json:"secrets_key,omitempty" yaml:"secrets_key,omitempty"`
 }
 
 // Token holds the information that is presented to the joining node when requesting a token.
@@ -26,6 +38,69 @@ type Token struct {
 	Secret        string           `json:"secret" yaml:"secret"`
 	Fingerprint   string           `json:"fingerprint" yaml:"fingerprint"`
 	JoinAddresses []types.AddrPort `json:"join_addresses" yaml:"join_addresses"`
+	ExpiresAt     time.Time        `json:"expires_at" yaml:"expires_at"`
+
+	// JoinHostnames are host:port pairs naming the cluster by DNS, for clusters whose member addresses may change.
+	JoinHostnames []string `json:"join_hostnames,omitempty" yaml:"join_hostnames,omitempty"`
+
+	// Signature is the signature of the token by the cluster key, which the joining cluster member verifies against
+	// the cluster certificate. Tokens built from preseeded secrets are signed with the preseeded cluster key.
+	Signature string `json:"signature,omitempty" yaml:"signature,omitempty"`
+}
+
+// Addresses returns the addresses to contact when joining with the token. Join hostnames come first, as they are
+// resolved again on every connection and so remain valid after the addresses of cluster members change.
+func (t Token) Addresses() []string {
+	addresses := make([]string, 0, len(t.JoinHostnames)+len(t.JoinAddresses))
+	addresses = append(addresses, t.JoinHostnames...)
+	for _, addr := range t.JoinAddresses {
+		addresses = append(addresses, addr.String())
+	}
+
+	return addresses
+}
+
+// signedData returns the encoding of the token that is signed, which excludes the signature.
+func (t Token) signedData() ([]byte, error) {
+	t.Signature = ""
+
+	return json.Marshal(t)
+}
+
+// Sign signs the token with the given cluster key.
+func (t *Token) Sign(signer crypto.Signer) error {
+	data, err := t.signedData()
+	if err != nil {
+		return err
+	}
+
+	signature, err := sign(signer, data)
+	if err != nil {
+		return fmt.Errorf("Failed to sign join token: %w", err)
+	}
+
+	t.Signature = signature
+
+	return nil
+}
+
+// Verify checks that the token was signed by the key of the given cluster certificate.
+func (t Token) Verify(clusterCert *x509.Certificate) error {
+	if t.Signature == "" {
+		return fmt.Errorf("Join token is not signed")
+	}
+
+	data, err := t.signedData()
+	if err != nil {
+		return err
+	}
+
+	err = verify(clusterCert, data, t.Signature)
+	if err != nil {
+		return fmt.Errorf("Join token was not signed by the cluster certificate: %w", err)
+	}
+
+	return nil
 }
 
 func (t Token) String() (string, error) {
@@ -0,0 +1,16 @@
+package types
+
+import (
+	"time"
+)
+
+// TrustAudit represents a change to the set of trusted certificates recorded in the trust audit trail.
+type TrustAudit struct {
+	ID          int64     `json:"id"          yaml:"id"`
+	Action      string    `json:"action"      yaml:"action"`
+	Type        string    `json:"type"        yaml:"type"`
+	Name        string    `json:"name"        yaml:"name"`
+	Fingerprint string    `json:"fingerprint" yaml:"fingerprint"`
+	Requestor   string    `json:"requestor"   yaml:"requestor"`
+	CreatedAt   time.Time `json:"created_at"  yaml:"created_at"`
+}
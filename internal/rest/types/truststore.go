@@ -0,0 +1,21 @@
+package types
+
+// TrustStoreCheck represents how the trust store of a cluster member differs from the cluster members recorded in the
+// database.
+type TrustStoreCheck struct {
+	Name string `json:"name" yaml:"name"`
+	OK   bool   `json:"ok" yaml:"ok"`
+
+	// Missing are the cluster members that have no entry in the trust store.
+	Missing []string `json:"missing" yaml:"missing"`
+
+	// Stale are the cluster members whose trust store entry has a different address or certificate.
+	Stale []string `json:"stale" yaml:"stale"`
+
+	// Orphaned are the files in the trust store that do not belong to any cluster member, or can not be parsed.
+	Orphaned []string `json:"orphaned" yaml:"orphaned"`
+
+	// Repaired is whether the trust store was replaced with the cluster members in the database.
+	Repaired bool   `json:"repaired" yaml:"repaired"`
+	Error    string `json:"error" yaml:"error"`
+}
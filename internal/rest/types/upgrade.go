@@ -0,0 +1,42 @@
+package types
+
+import (
+	"time"
+)
+
+// UpgradeStage is the progress of a cluster member through a rolling upgrade.
+type UpgradeStage string
+
+const (
+	// UpgradePending is the stage of a cluster member that has not been upgraded yet.
+	UpgradePending UpgradeStage = "pending"
+
+	// UpgradeRunning is the stage of a cluster member whose upgrade hook is running.
+	UpgradeRunning UpgradeStage = "upgrading"
+
+	// UpgradeWaiting is the stage of a cluster member that has been upgraded, and is being waited on to come back.
+	UpgradeWaiting UpgradeStage = "waiting"
+
+	// UpgradeDone is the stage of a cluster member that has been upgraded and is back online.
+	UpgradeDone UpgradeStage = "done"
+
+	// UpgradeFailed is the stage of a cluster member whose upgrade failed.
+	UpgradeFailed UpgradeStage = "failed"
+)
+
+// Upgrade represents the progress of a rolling upgrade of the cluster.
+type Upgrade struct {
+	// Coordinator is the name of the cluster member sequencing the upgrade. It is upgraded last.
+	Coordinator string          `json:"coordinator" yaml:"coordinator"`
+	StartedAt   time.Time       `json:"started_at"  yaml:"started_at"`
+	FinishedAt  time.Time       `json:"finished_at" yaml:"finished_at"`
+	Members     []UpgradeMember `json:"members"     yaml:"members"`
+	Error       string          `json:"error"       yaml:"error"`
+}
+
+// UpgradeMember represents the progress of a single cluster member through a rolling upgrade.
+type UpgradeMember struct {
+	Name  string       `json:"name"  yaml:"name"`
+	Stage UpgradeStage `json:"stage" yaml:"stage"`
+	Error string       `json:"error" yaml:"error"`
+}
@@ -0,0 +1,26 @@
+package types
+
+import (
+	"time"
+)
+
+// WarningType is the kind of condition reported by a warning.
+type WarningType string
+
+const (
+	// WarningCertificateExpiring is reported for a certificate that expires soon.
+	WarningCertificateExpiring WarningType = "certificate-expiring"
+
+	// WarningCertificateExpired is reported for a certificate that has expired.
+	WarningCertificateExpired WarningType = "certificate-expired"
+)
+
+// Warning represents a condition of the cluster that needs the attention of an operator.
+type Warning struct {
+	Type    WarningType `json:"type"    yaml:"type"`
+	Entity  string      `json:"entity"  yaml:"entity"`
+	Message string      `json:"message" yaml:"message"`
+
+	// ExpiresAt is when the certificate the warning is about expires.
+	ExpiresAt time.Time `json:"expires_at" yaml:"expires_at"`
+}
@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/request"
+	"github.com/canonical/lxd/lxd/ucred"
+	"golang.org/x/sys/unix"
+)
+
+// ControlSocketUIDs are the user IDs allowed to use the control socket, in addition to root and the user running the
+// daemon. If both ControlSocketUIDs and ControlSocketGIDs are empty, anyone who can open the socket is allowed, except
+// on abstract unix sockets, which have no file permissions and are only open to root and the user running the daemon.
+var ControlSocketUIDs []uint32
+
+// ControlSocketGIDs are the primary group IDs allowed to use the control socket, in addition to root and the user
+// running the daemon.
+var ControlSocketGIDs []uint32
+
+// controlCredentials returns the credentials of the process that made the request over the control socket.
+func controlCredentials(r *http.Request) (*unix.Ucred, error) {
+	conn, ok := r.Context().Value(request.CtxConn).(*net.UnixConn)
+	if !ok {
+		return nil, ucred.ErrNotUnixSocket
+	}
+
+	return ucred.GetCred(conn)
+}
+
+// checkControlCredentials returns an error if the process that made the request over the control socket is not
+// allowed to use it.
+func checkControlCredentials(r *http.Request) error {
+	conn, ok := r.Context().Value(request.CtxConn).(*net.UnixConn)
+	abstract := ok && strings.HasPrefix(conn.LocalAddr().String(), "@")
+	if !abstract && len(ControlSocketUIDs) == 0 && len(ControlSocketGIDs) == 0 {
+		return nil
+	}
+
+	cred, err := controlCredentials(r)
+	if err != nil {
+		return fmt.Errorf("Failed to get credentials of control socket peer: %w", err)
+	}
+
+	if cred.Uid == 0 || cred.Uid == uint32(os.Geteuid()) {
+		return nil
+	}
+
+	for _, uid := range ControlSocketUIDs {
+		if cred.Uid == uid {
+			return nil
+		}
+	}
+
+	for _, gid := range ControlSocketGIDs {
+		if cred.Gid == gid {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("User %d (group %d) is not allowed to use the control socket", cred.Uid, cred.Gid)
+}
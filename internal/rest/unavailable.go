@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared"
+
+	internalState "github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/rest"
+	"github.com/canonical/microcluster/rest/types"
+)
+
+// UnavailableRetryAfter is how long clients are asked to wait with the Retry-After header before retrying requests
+// answered with 503 Service Unavailable because the daemon is starting or shutting down.
+var UnavailableRetryAfter = 5 * time.Second
+
+// unavailable returns a 503 response if the daemon can not handle requests to the endpoint yet, or anymore.
+// Requests over the network are held back until the daemon is ready, as handlers may depend on the hooks run during
+// startup. Requests over the control socket and from other cluster members only wait for the database, as those hooks
+// may call the daemon themselves, or wait on the rest of the cluster.
+func unavailable(state *internalState.State, e rest.Endpoint, r *http.Request) response.Response {
+	if state.Context.Err() == context.Canceled && !e.AllowedDuringShutdown {
+		return rest.ErrorResponse(http.StatusServiceUnavailable, types.ErrorCodeShuttingDown, "Daemon is shutting down")
+	}
+
+	if e.AllowedBeforeInit {
+		return nil
+	}
+
+	if !state.Database.IsOpen() {
+		wait := state.Database.UpgradeWait()
+		if wait != nil {
+			return rest.ErrorResponse(http.StatusServiceUnavailable, types.ErrorCodeSchemaMismatch, wait.String())
+		}
+
+		return rest.ErrorResponse(http.StatusServiceUnavailable, types.ErrorCodeNotInitialized, "Daemon not yet initialized")
+	}
+
+	if r.RemoteAddr != "@" && !clusterMemberRequest(state, r) && state.ReadyCh != nil {
+		select {
+		case <-state.ReadyCh:
+		default:
+			return rest.ErrorResponse(http.StatusServiceUnavailable, types.ErrorCodeNotInitialized, "Daemon is still starting")
+		}
+	}
+
+	return nil
+}
+
+// clusterMemberRequest returns whether the request was made with the certificate of a cluster member in the local trust
+// store. The request is still authenticated as usual before it is handled.
+func clusterMemberRequest(state *internalState.State, r *http.Request) bool {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return false
+	}
+
+	return state.Remotes().RemoteByCertificateFingerprint(shared.CertFingerprint(r.TLS.PeerCertificates[0])) != nil
+}
+
+// setRetryAfter asks the client to retry a request answered with 503 Service Unavailable after UnavailableRetryAfter.
+func setRetryAfter(w http.ResponseWriter) {
+	if UnavailableRetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(UnavailableRetryAfter.Seconds())))
+	}
+}
@@ -0,0 +1,45 @@
+package rest
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/canonical/microcluster/internal/ca"
+)
+
+// verificationCtxKey holds the verification of the client certificate of a connection.
+type verificationCtxKey struct{}
+
+// verification is the result of verifying the client certificate of a connection against a certificate authority.
+type verification struct {
+	mu        sync.Mutex
+	authority *ca.Authority
+	err       error
+}
+
+// VerificationContext returns a context for connections accepted by the daemon, in which the client certificate is only
+// verified against the certificate authority once.
+func VerificationContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, verificationCtxKey{}, &verification{})
+}
+
+// verifyAuthority verifies the client certificate of the request against the given certificate authority. The result
+// is kept for later requests over the same connection, as its certificate can not change, unless the certificate
+// authority is replaced.
+func verifyAuthority(authority *ca.Authority, r *http.Request) error {
+	v, ok := r.Context().Value(verificationCtxKey{}).(*verification)
+	if !ok {
+		return authority.Verify(r.TLS.PeerCertificates)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.authority != authority {
+		v.authority = authority
+		v.err = authority.Verify(r.TLS.PeerCertificates)
+	}
+
+	return v.err
+}
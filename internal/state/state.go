@@ -2,16 +2,20 @@ package state
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/canonical/lxd/lxd/cluster/request"
-	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
+	"github.com/canonical/lxd/shared/logger"
 
 	"github.com/canonical/microcluster/client"
+	"github.com/canonical/microcluster/cluster"
 	"github.com/canonical/microcluster/internal/db"
 	"github.com/canonical/microcluster/internal/endpoints"
+	"github.com/canonical/microcluster/internal/extensions"
 	internalClient "github.com/canonical/microcluster/internal/rest/client"
 	"github.com/canonical/microcluster/internal/sys"
 	"github.com/canonical/microcluster/internal/trust"
@@ -37,14 +41,23 @@ type State struct {
 	// Name of the cluster member.
 	Name func() string
 
+	// SetName changes the name of the cluster member in its local daemon configuration.
+	SetName func(name string) error
+
 	// Server.
 	Endpoints *endpoints.Endpoints
 
 	// Server certificate is used for server-to-server connection.
-	ServerCert func() *shared.CertInfo
+	ServerCert func() *sys.CertInfo
 
 	// Cluster certificate is used for downstream connections within a cluster.
-	ClusterCert func() *shared.CertInfo
+	ClusterCert func() *sys.CertInfo
+
+	// SetClusterCert replaces the cluster certificate without restarting the daemon.
+	SetClusterCert func(cert *sys.CertInfo) error
+
+	// SecretsKey returns the key that cluster-wide secrets are encrypted with.
+	SecretsKey func() ([]byte, error)
 
 	// Database.
 	Database *db.DB
@@ -71,9 +84,191 @@ var PreRemoveHook func(state *State, force bool) error
 // OnHeartbeatHook is a post-action hook that is run on the leader after a successful heartbeat round.
 var OnHeartbeatHook func(state *State) error
 
+// HeartbeatStatusHook is run on each cluster member during a heartbeat round to collect application status fields.
+var HeartbeatStatusHook func(state *State) (map[string]any, error)
+
+// ValidateJoinHook is a pre-action hook that is run on the leader before a new cluster member is accepted.
+var ValidateJoinHook func(state *State, name string, joinConfig map[string]string) error
+
 // OnNewMemberHook is a post-action hook that is run on all cluster members when a new cluster member joins the cluster.
 var OnNewMemberHook func(state *State) error
 
+// OnFeatureChangeHook is a post-action hook that is run on all cluster members when a feature flag is changed.
+var OnFeatureChangeHook func(state *State, name string) error
+
+// OnConfigChangeHook is a post-action hook that is run on all cluster members when the cluster-wide config changes.
+var OnConfigChangeHook func(state *State, keys []string) error
+
+// OnMemberDemotedHook is a post-action hook that is run when a cluster member is automatically demoted from voter to
+// spare.
+var OnMemberDemotedHook func(state *State, name string, reason string) error
+
+// OnHandlerPanicHook is a post-action hook that is run on a cluster member after recovering from a panic in an API
+// handler.
+var OnHandlerPanicHook func(state *State, r *http.Request, requestID string, value any) error
+
+// OnMemberPromotedHook is a post-action hook that is run on a cluster member after it automatically promotes itself
+// back to voter.
+var OnMemberPromotedHook func(state *State, name string, reason string) error
+
+// UpgradeHook is run on a cluster member when a rolling upgrade reaches it.
+var UpgradeHook func(state *State) error
+
+// ProjectAccessHook is a pre-action hook that is run before any request to a project-scoped endpoint.
+var ProjectAccessHook func(state *State, r *http.Request, project string) error
+
+// Feature returns whether the feature flag with the given name is enabled for this cluster member.
+// Feature flags that do not exist are considered disabled.
+func (s *State) Feature(name string) (bool, error) {
+	var enabled bool
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		flag, err := cluster.GetInternalFeatureFlag(ctx, tx, name)
+		if err != nil {
+			if api.StatusErrorCheck(err, http.StatusNotFound) {
+				return nil
+			}
+
+			return err
+		}
+
+		enabled = flag.EnabledFor(s.Name())
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return enabled, nil
+}
+
+// ClusterConfig returns the cluster-wide key/value config.
+func (s *State) ClusterConfig() (map[string]string, error) {
+	var config map[string]string
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		config, err = cluster.GetClusterConfig(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// RecordEvent records an application event for this cluster member in the cluster event history, and streams it to
+// clients watching the events of the cluster.
+func (s *State) RecordEvent(message string) error {
+	return s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := cluster.CreateInternalEvent(ctx, tx, cluster.NewInternalEvent(cluster.EventApplication, s.Name(), message))
+		return err
+	})
+}
+
+// Secret returns the decrypted value of the cluster-wide secret with the given name.
+func (s *State) Secret(name string) (string, error) {
+	key, err := s.SecretsKey()
+	if err != nil {
+		return "", err
+	}
+
+	var secret *cluster.InternalSecret
+	err = s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		secret, err = cluster.GetInternalSecret(ctx, tx, name)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	value, err := sys.DecryptSecret(key, name, secret.Value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(value), nil
+}
+
+// SetSecret encrypts the given value and stores it as the cluster-wide secret with the given name, replacing any
+// existing value.
+func (s *State) SetSecret(name string, value string) error {
+	key, err := s.SecretsKey()
+	if err != nil {
+		return err
+	}
+
+	encrypted, err := sys.EncryptSecret(key, name, []byte(value))
+	if err != nil {
+		return fmt.Errorf("Failed to encrypt secret %q: %w", name, err)
+	}
+
+	return s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		return cluster.SetInternalSecret(ctx, tx, name, encrypted)
+	})
+}
+
+// DeleteSecret removes the cluster-wide secret with the given name.
+func (s *State) DeleteSecret(name string) error {
+	return s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		return cluster.DeleteInternalSecret(ctx, tx, name)
+	})
+}
+
+// Cordoned returns whether this cluster member is cordoned for maintenance.
+func (s *State) Cordoned() (bool, error) {
+	var cordons map[string]bool
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		cordons, err = cluster.GetClusterMemberCordons(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return cordons[s.Name()], nil
+}
+
+// APIExtensions returns the API extensions supported by each cluster member, keyed by name, and the API extensions
+// supported by all of them.
+func (s *State) APIExtensions() (map[string][]string, []string, error) {
+	var members map[string][]string
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		members, err = cluster.GetClusterMemberAPIExtensions(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lists := make([][]string, 0, len(members)+1)
+	lists = append(lists, extensions.Supported())
+	for _, memberExtensions := range members {
+		lists = append(lists, memberExtensions)
+	}
+
+	return members, extensions.Common(lists...), nil
+}
+
+// HasAPIExtension returns whether the API extension with the given name is supported by every cluster member.
+func (s *State) HasAPIExtension(name string) (bool, error) {
+	_, common, err := s.APIExtensions()
+	if err != nil {
+		return false, err
+	}
+
+	for _, extension := range common {
+		if extension == name {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
 // Cluster returns a client for every member of a cluster, except
 // this one, with the UserAgentNotifier header set if a request is given.
 func (s *State) Cluster(r *http.Request) (client.Cluster, error) {
@@ -142,3 +337,35 @@ func (s *State) Leader() (*client.Client, error) {
 
 	return &client.Client{Client: *c}, nil
 }
+
+// RunOperation runs the given function, and records its type, initiator, duration and result in the cluster-wide
+// operation history. The initiator is who started the operation, such as the requestor of an API request. The error
+// returned by the function is returned unchanged.
+func (s *State) RunOperation(opType string, initiator string, f func(ctx context.Context) error) error {
+	startedAt := time.Now()
+	opErr := f(s.Context)
+
+	operation := cluster.InternalOperation{
+		Type:      opType,
+		Initiator: initiator,
+		Member:    s.Name(),
+		StartedAt: startedAt,
+		Duration:  time.Since(startedAt),
+		Status:    cluster.OperationSuccess,
+	}
+
+	if opErr != nil {
+		operation.Status = cluster.OperationFailed
+		operation.Error = opErr.Error()
+	}
+
+	err := s.Database.Transaction(s.Context, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := cluster.CreateInternalOperation(ctx, tx, operation)
+		return err
+	})
+	if err != nil {
+		logger.Warn("Failed to record operation in history", logger.Ctx{"type": opType, "error": err})
+	}
+
+	return opErr
+}
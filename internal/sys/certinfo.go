@@ -0,0 +1,135 @@
+package sys
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"time"
+)
+
+// KeySigner optionally returns the signer for the private key of the keypair with the given prefix, such as a key
+// held in a PKCS#11 token or TPM. If it returns nil, the private key is read from the state directory.
+var KeySigner func(prefix string) (crypto.Signer, error)
+
+// CertInfo holds a certificate and its private key. The private key may be held by an external signer, in which case
+// it can not be exported.
+type CertInfo struct {
+	keypair tls.Certificate
+	keyPEM  []byte
+}
+
+// NewCertInfo returns a CertInfo for the given PEM encoded certificate and private key.
+func NewCertInfo(certPEM []byte, keyPEM []byte) (*CertInfo, error) {
+	keypair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CertInfo{keypair: keypair, keyPEM: keyPEM}, nil
+}
+
+// NewSignerCertInfo returns a CertInfo for the given PEM encoded certificate, whose private key is held by the given
+// signer.
+func NewSignerCertInfo(certPEM []byte, signer crypto.Signer) (*CertInfo, error) {
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("Invalid certificate")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	certKey, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	signerKey, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.Equal(certKey, signerKey) {
+		return nil, fmt.Errorf("Certificate does not match the public key of the signer")
+	}
+
+	return &CertInfo{keypair: tls.Certificate{Certificate: [][]byte{cert.Raw}, PrivateKey: signer, Leaf: cert}}, nil
+}
+
+// KeyPair returns the certificate and private key for use in TLS configurations.
+func (c *CertInfo) KeyPair() tls.Certificate {
+	return c.keypair
+}
+
+// PublicKey returns the PEM encoded certificate.
+func (c *CertInfo) PublicKey() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.keypair.Certificate[0]})
+}
+
+// PublicKeyX509 returns the parsed certificate.
+func (c *CertInfo) PublicKeyX509() (*x509.Certificate, error) {
+	return x509.ParseCertificate(c.keypair.Certificate[0])
+}
+
+// PrivateKey returns the PEM encoded private key, or nil if it is held by an external signer.
+func (c *CertInfo) PrivateKey() []byte {
+	return c.keyPEM
+}
+
+// generateSignerCert returns a new PEM encoded self-signed server certificate for the private key held by the given
+// signer, covering the host name and addresses of this machine.
+func generateSignerCert(signer crypto.Signer) ([]byte, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to generate serial number: %w", err)
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "UNKNOWN"
+	}
+
+	validFrom := time.Now()
+	template := x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject: pkix.Name{
+			Organization: []string{"LXD"},
+			CommonName:   fmt.Sprintf("root@%s", hostname),
+		},
+		NotBefore:             validFrom,
+		NotAfter:              validFrom.Add(10 * 365 * 24 * time.Hour),
+		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              []string{hostname},
+	}
+
+	addrs, err := net.InterfaceAddrs()
+	if err == nil {
+		for _, addr := range addrs {
+			ip, _, err := net.ParseCIDR(addr.String())
+			if err != nil || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+				continue
+			}
+
+			template.IPAddresses = append(template.IPAddresses, ip)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, signer.Public(), signer)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create certificate: %w", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), nil
+}
@@ -1,9 +1,14 @@
 package sys
 
 const (
-	// DqliteSocket is the configurable location of the dqlite socket.
+	// DqliteSocket is the configurable location of the dqlite socket. A location starting with "@" is a socket in the
+	// Linux abstract namespace.
 	DqliteSocket = "DQLITE_SOCKET"
 
+	// ControlSocket is the configurable location of the control socket. A location starting with "@" is a socket in the
+	// Linux abstract namespace.
+	ControlSocket = "CONTROL_SOCKET"
+
 	// StateDir is the location of the daemon state directory.
 	StateDir = "STATE_DIR"
 
@@ -0,0 +1,295 @@
+package sys
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/google/renameio"
+	"golang.org/x/crypto/scrypt"
+)
+
+// KeyPassphrase is the passphrase that private keys in the state directory are encrypted with. If empty, private keys
+// are stored unencrypted.
+var KeyPassphrase []byte
+
+// encryptedKeyType is the PEM block type of an encrypted private key. The block holds the original PEM encoded private
+// key, encrypted with AES-256-GCM under a key derived from the passphrase with scrypt.
+const encryptedKeyType = "MICROCLUSTER ENCRYPTED PRIVATE KEY"
+
+// machineIDPaths are the files checked, in order, for the machine ID used to derive a machine-bound passphrase.
+var machineIDPaths = []string{"/etc/machine-id", "/var/lib/dbus/machine-id"}
+
+// LoadKeyPair loads the keypair with the given prefix from the state directory, generating a new one if it does not
+// exist. If a passphrase is set, an unencrypted private key is encrypted in place. If KeySigner holds the private key,
+// only the certificate is loaded from the state directory.
+func (s *OS) LoadKeyPair(prefix string) (*CertInfo, error) {
+	certPath := filepath.Join(s.StateDir, prefix+".crt")
+	keyPath := filepath.Join(s.StateDir, prefix+".key")
+	if KeySigner != nil {
+		signer, err := KeySigner(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get signer for %q private key: %w", prefix, err)
+		}
+
+		if signer != nil {
+			return s.loadSignerKeyPair(prefix, signer)
+		}
+	}
+
+	if !shared.PathExists(certPath) || !shared.PathExists(keyPath) {
+		certPEM, keyPEM, err := shared.GenerateMemCert(false, true)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to generate %q certificate: %w", prefix, err)
+		}
+
+		err = s.WriteKeyPair(prefix, certPEM, keyPEM)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %q certificate: %w", prefix, err)
+	}
+
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %q private key: %w", prefix, err)
+	}
+
+	if isEncryptedKey(keyPEM) {
+		if len(KeyPassphrase) == 0 {
+			return nil, fmt.Errorf("Private key %q is encrypted, but no passphrase was given", prefix)
+		}
+
+		keyPEM, err = decryptKey(keyPEM, KeyPassphrase)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decrypt %q private key: %w", prefix, err)
+		}
+	} else if len(KeyPassphrase) > 0 {
+		logger.Info("Encrypting private key", logger.Ctx{"prefix": prefix})
+
+		err = s.writeKey(keyPath, keyPEM)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cert, err := NewCertInfo(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load %q certificate: %w", prefix, err)
+	}
+
+	return cert, nil
+}
+
+// ExternalKey returns whether the private key of the keypair with the given prefix is held by KeySigner.
+func ExternalKey(prefix string) (bool, error) {
+	if KeySigner == nil {
+		return false, nil
+	}
+
+	signer, err := KeySigner(prefix)
+	if err != nil {
+		return false, fmt.Errorf("Failed to get signer for %q private key: %w", prefix, err)
+	}
+
+	return signer != nil, nil
+}
+
+// loadSignerKeyPair loads the certificate with the given prefix from the state directory, for the private key held by
+// the given signer. If the certificate does not exist, a new one is generated.
+func (s *OS) loadSignerKeyPair(prefix string, signer crypto.Signer) (*CertInfo, error) {
+	certPath := filepath.Join(s.StateDir, prefix+".crt")
+	if !shared.PathExists(certPath) {
+		certPEM, err := generateSignerCert(signer)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to generate %q certificate: %w", prefix, err)
+		}
+
+		err = renameio.WriteFile(certPath, certPEM, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to write %q certificate: %w", prefix, err)
+		}
+	}
+
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read %q certificate: %w", prefix, err)
+	}
+
+	cert, err := NewSignerCertInfo(certPEM, signer)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load %q certificate: %w", prefix, err)
+	}
+
+	return cert, nil
+}
+
+// WriteKeyPair writes the given PEM encoded certificate and private key to the state directory with the given prefix.
+// If a passphrase is set, the private key is encrypted. If the private key is empty, as it is held by KeySigner, only
+// the certificate is written.
+func (s *OS) WriteKeyPair(prefix string, certPEM []byte, keyPEM []byte) error {
+	err := renameio.WriteFile(filepath.Join(s.StateDir, prefix+".crt"), certPEM, 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to write %q certificate: %w", prefix, err)
+	}
+
+	if len(keyPEM) == 0 {
+		return nil
+	}
+
+	return s.writeKey(filepath.Join(s.StateDir, prefix+".key"), keyPEM)
+}
+
+// writeKey writes the given PEM encoded private key to the given path, encrypting it if a passphrase is set.
+func (s *OS) writeKey(path string, keyPEM []byte) error {
+	var err error
+	if len(KeyPassphrase) > 0 {
+		keyPEM, err = encryptKey(keyPEM, KeyPassphrase)
+		if err != nil {
+			return fmt.Errorf("Failed to encrypt private key %q: %w", path, err)
+		}
+	}
+
+	err = renameio.WriteFile(path, keyPEM, 0600)
+	if err != nil {
+		return fmt.Errorf("Failed to write private key %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// MachineKey returns a passphrase bound to this machine, derived from its machine ID. Keys encrypted with it can only
+// be decrypted on the same machine, and only as long as the machine ID does not change.
+func MachineKey() ([]byte, error) {
+	for _, path := range machineIDPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		id := strings.TrimSpace(string(data))
+		if id == "" {
+			continue
+		}
+
+		hash := sha256.Sum256([]byte("microcluster:" + id))
+
+		return []byte(hex.EncodeToString(hash[:])), nil
+	}
+
+	return nil, fmt.Errorf("Failed to find a machine ID in %v", machineIDPaths)
+}
+
+// isEncryptedKey returns whether the given PEM data holds an encrypted private key.
+func isEncryptedKey(data []byte) bool {
+	block, _ := pem.Decode(data)
+
+	return block != nil && block.Type == encryptedKeyType
+}
+
+// deriveKey derives an AES-256 key from the passphrase and salt.
+func deriveKey(passphrase []byte, salt []byte) ([]byte, error) {
+	return scrypt.Key(passphrase, salt, 1<<15, 8, 1, 32)
+}
+
+// encryptKey encrypts the given PEM encoded private key with the passphrase.
+func encryptKey(keyPEM []byte, passphrase []byte) ([]byte, error) {
+	salt := make([]byte, 16)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type: encryptedKeyType,
+		Headers: map[string]string{
+			"KDF":   "scrypt",
+			"Salt":  hex.EncodeToString(salt),
+			"Nonce": hex.EncodeToString(nonce),
+		},
+		Bytes: gcm.Seal(nil, nonce, keyPEM, nil),
+	}), nil
+}
+
+// decryptKey decrypts the given encrypted private key with the passphrase, and returns the PEM encoded private key.
+func decryptKey(data []byte, passphrase []byte) ([]byte, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != encryptedKeyType {
+		return nil, fmt.Errorf("Private key is not encrypted")
+	}
+
+	if block.Headers["KDF"] != "scrypt" {
+		return nil, fmt.Errorf("Unsupported key derivation function %q", block.Headers["KDF"])
+	}
+
+	salt, err := hex.DecodeString(block.Headers["Salt"])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid salt: %w", err)
+	}
+
+	nonce, err := hex.DecodeString(block.Headers["Nonce"])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid nonce: %w", err)
+	}
+
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	aesBlock, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(aesBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("Invalid nonce size %d", len(nonce))
+	}
+
+	keyPEM, err := gcm.Open(nil, nonce, block.Bytes, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Wrong passphrase or corrupted key")
+	}
+
+	return keyPEM, nil
+}
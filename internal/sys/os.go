@@ -83,9 +83,23 @@ func (s *OS) init(createDir bool) error {
 	return nil
 }
 
+// ControlSocketPath overrides the path of the control socket, which is control.socket in the state directory by
+// default. A path starting with "@", such as "@microcluster", is a socket in the Linux abstract namespace, for when
+// the state directory is not shareable or writable, such as in containers.
+var ControlSocketPath string
+
 // ControlSocket returns the full path to the control.socket file that this daemon is listening on.
 func (s *OS) ControlSocket() api.URL {
-	return *api.NewURL().Scheme("http").Host(filepath.Join(s.StateDir, "control.socket"))
+	path := ControlSocketPath
+	if path == "" {
+		path = os.Getenv(ControlSocket)
+	}
+
+	if path == "" {
+		path = filepath.Join(s.StateDir, "control.socket")
+	}
+
+	return *api.NewURL().Scheme("http").Host(path)
 }
 
 // DatabasePath returns the path of the database file managed by dqlite.
@@ -93,13 +107,41 @@ func (s *OS) DatabasePath() string {
 	return filepath.Join(s.DatabaseDir, "db.bin")
 }
 
+// LocalDatabasePath returns the path of the node-local database file, which is not replicated by dqlite.
+func (s *OS) LocalDatabasePath() string {
+	return filepath.Join(s.DatabaseDir, "local.db")
+}
+
+// DatabasePatchPath returns the path of a file of SQL statements to run against the global database the next time
+// its schema is updated.
+func (s *OS) DatabasePatchPath() string {
+	return filepath.Join(s.StateDir, "patch.global.sql")
+}
+
+// JoinStatePath returns the path of the file recording a join that has not completed yet, so that a join interrupted by
+// a crash can be retried.
+func (s *OS) JoinStatePath() string {
+	return filepath.Join(s.StateDir, "join.yaml")
+}
+
+// CAPath returns the path of the bundle of external certificate authorities that issue the certificates of the cluster
+// members. If it exists, certificates are validated against it instead of trusted by fingerprint.
+func (s *OS) CAPath() string {
+	return filepath.Join(s.StateDir, "ca.crt")
+}
+
+// CRLPath returns the path of the certificate revocation lists of the external certificate authorities.
+func (s *OS) CRLPath() string {
+	return filepath.Join(s.StateDir, "ca.crl")
+}
+
 // ServerCert gets the local server certificate from the state directory.
-func (s *OS) ServerCert() (*shared.CertInfo, error) {
+func (s *OS) ServerCert() (*CertInfo, error) {
 	if !shared.PathExists(filepath.Join(s.StateDir, "server.crt")) {
 		return nil, fmt.Errorf("Failed to get server.crt from directory %q", s.StateDir)
 	}
 
-	cert, err := shared.KeyPairAndCA(s.StateDir, "server", shared.CertServer, true)
+	cert, err := s.LoadKeyPair("server")
 	if err != nil {
 		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
 	}
@@ -108,15 +150,36 @@ func (s *OS) ServerCert() (*shared.CertInfo, error) {
 }
 
 // ClusterCert gets the local cluster certificate from the state directory.
-func (s *OS) ClusterCert() (*shared.CertInfo, error) {
+func (s *OS) ClusterCert() (*CertInfo, error) {
 	if !shared.PathExists(filepath.Join(s.StateDir, "cluster.crt")) {
 		return nil, fmt.Errorf("Failed to get cluster.crt from directory %q", s.StateDir)
 	}
 
-	cert, err := shared.KeyPairAndCA(s.StateDir, "cluster", shared.CertServer, true)
+	cert, err := s.LoadKeyPair("cluster")
 	if err != nil {
 		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
 	}
 
 	return cert, nil
 }
+
+// PendingClusterCert gets the cluster certificate waiting to replace the current one during a cluster certificate
+// rotation from the state directory, or nil if there is none.
+func (s *OS) PendingClusterCert() (*CertInfo, error) {
+	return s.optionalCert("cluster-pending")
+}
+
+// PreviousClusterCert gets the cluster certificate replaced during a cluster certificate rotation that has not finished
+// yet from the state directory, or nil if there is none.
+func (s *OS) PreviousClusterCert() (*CertInfo, error) {
+	return s.optionalCert("cluster-previous")
+}
+
+// optionalCert gets the keypair with the given prefix from the state directory, or nil if it does not exist.
+func (s *OS) optionalCert(prefix string) (*CertInfo, error) {
+	if !shared.PathExists(filepath.Join(s.StateDir, prefix+".crt")) {
+		return nil, nil
+	}
+
+	return s.LoadKeyPair(prefix)
+}
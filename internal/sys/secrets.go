@@ -0,0 +1,144 @@
+package sys
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/canonical/lxd/shared"
+)
+
+// secretsKeySize is the size of the AES-256 key that cluster-wide secrets are encrypted with.
+const secretsKeySize = 32
+
+// SecretsKeyPath returns the path of the key that cluster-wide secrets are encrypted with.
+func (s *OS) SecretsKeyPath() string {
+	return filepath.Join(s.StateDir, "secrets.key")
+}
+
+// LoadSecretsKey loads the key that cluster-wide secrets are encrypted with from the state directory. If it does not
+// exist, a new key is generated when bootstrapping. Otherwise the key is derived from the given cluster private key,
+// which all cluster members of a cluster that predates the secrets key share.
+func (s *OS) LoadSecretsKey(bootstrap bool, clusterKey []byte) ([]byte, error) {
+	path := s.SecretsKeyPath()
+	if !shared.PathExists(path) {
+		var key []byte
+		if bootstrap {
+			key = make([]byte, secretsKeySize)
+			_, err := rand.Read(key)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to generate secrets key: %w", err)
+			}
+		} else {
+			if len(clusterKey) == 0 {
+				return nil, fmt.Errorf("No secrets key exists, and it can not be derived from a cluster key held by an external signer")
+			}
+
+			mac := hmac.New(sha256.New, clusterKey)
+			mac.Write([]byte("microcluster secrets"))
+			key = mac.Sum(nil)
+		}
+
+		err := s.WriteSecretsKey(key)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read secrets key: %w", err)
+	}
+
+	if isEncryptedKey(data) {
+		if len(KeyPassphrase) == 0 {
+			return nil, fmt.Errorf("Secrets key is encrypted, but no passphrase was given")
+		}
+
+		data, err = decryptKey(data, KeyPassphrase)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to decrypt secrets key: %w", err)
+		}
+	} else if len(KeyPassphrase) > 0 {
+		err = s.writeKey(path, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != secretsKeySize {
+		return nil, fmt.Errorf("Invalid secrets key %q", path)
+	}
+
+	return key, nil
+}
+
+// WriteSecretsKey writes the key that cluster-wide secrets are encrypted with to the state directory. If a passphrase
+// is set, the key is encrypted.
+func (s *OS) WriteSecretsKey(key []byte) error {
+	if len(key) != secretsKeySize {
+		return fmt.Errorf("Secrets key must be %d bytes long", secretsKeySize)
+	}
+
+	return s.writeKey(s.SecretsKeyPath(), []byte(hex.EncodeToString(key)+"\n"))
+}
+
+// EncryptSecret encrypts the value of the secret with the given name using the secrets key. The name is authenticated
+// along with the value, so that an encrypted value can not be moved to another secret.
+func EncryptSecret(key []byte, name string, value []byte) (string, error) {
+	gcm, err := secretsCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, value, []byte(name))), nil
+}
+
+// DecryptSecret decrypts the value of the secret with the given name using the secrets key.
+func DecryptSecret(key []byte, name string, encrypted string) ([]byte, error) {
+	gcm, err := secretsCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid encoding of secret %q: %w", name, err)
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("Secret %q is too short", name)
+	}
+
+	value, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decrypt secret %q: Wrong secrets key or corrupted value", name)
+	}
+
+	return value, nil
+}
+
+// secretsCipher returns the AES-GCM cipher for the secrets key.
+func secretsCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
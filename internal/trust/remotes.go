@@ -84,6 +84,45 @@ func (r *Remotes) Load(dir string) error {
 	return nil
 }
 
+// ReadDir reads the remote in each yaml file in the given directory, keyed by file name without the extension, without
+// loading them. The names of any other files, or of files that can not be parsed as a remote, are also returned.
+func ReadDir(dir string) (map[string]Remote, []string, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Unable to read trust directory: %q: %w", dir, err)
+	}
+
+	remotes := map[string]Remote{}
+	invalid := []string{}
+	for _, file := range files {
+		fileName := file.Name()
+		if file.IsDir() {
+			continue
+		}
+
+		if !strings.HasSuffix(fileName, ".yaml") {
+			invalid = append(invalid, fileName)
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, fileName))
+		if err != nil {
+			return nil, nil, fmt.Errorf("Unable to read file %q: %w", fileName, err)
+		}
+
+		remote := Remote{}
+		err = yaml.Unmarshal(content, &remote)
+		if err != nil || remote.Certificate.Certificate == nil {
+			invalid = append(invalid, fileName)
+			continue
+		}
+
+		remotes[strings.TrimSuffix(fileName, ".yaml")] = remote
+	}
+
+	return remotes, invalid, nil
+}
+
 // Add adds a new local cluster member record for the remotes.
 func (r *Remotes) Add(dir string, remotes ...Remote) error {
 	r.updateMu.Lock()
@@ -188,6 +227,29 @@ func (r *Remotes) Replace(dir string, newRemotes ...internalTypes.ClusterMember)
 	return nil
 }
 
+// Matches returns whether the remotes are exactly the given cluster members, with the same addresses and certificates.
+func (r *Remotes) Matches(members ...internalTypes.ClusterMember) bool {
+	r.updateMu.RLock()
+	defer r.updateMu.RUnlock()
+
+	if len(members) != len(r.data) {
+		return false
+	}
+
+	for _, member := range members {
+		remote, ok := r.data[member.Name]
+		if !ok {
+			return false
+		}
+
+		if remote.Address.String() != member.Address.String() || remote.Certificate.String() != member.Certificate.String() {
+			return false
+		}
+	}
+
+	return true
+}
+
 // SelectRandom returns a random remote.
 func (r *Remotes) SelectRandom() *Remote {
 	r.updateMu.RLock()
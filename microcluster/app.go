@@ -2,9 +2,12 @@ package microcluster
 
 import (
 	"context"
+	"crypto"
 	"crypto/x509"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -13,6 +16,7 @@ import (
 	"time"
 
 	"github.com/canonical/lxd/lxd/db/schema"
+	"github.com/canonical/lxd/shared"
 	"github.com/canonical/lxd/shared/api"
 	"github.com/canonical/lxd/shared/logger"
 	"golang.org/x/sys/unix"
@@ -20,8 +24,15 @@ import (
 	"github.com/canonical/microcluster/client"
 	"github.com/canonical/microcluster/cluster"
 	"github.com/canonical/microcluster/config"
+	"github.com/canonical/microcluster/internal/backup"
+	"github.com/canonical/microcluster/internal/cryptopolicy"
 	"github.com/canonical/microcluster/internal/daemon"
+	"github.com/canonical/microcluster/internal/db"
+	"github.com/canonical/microcluster/internal/endpoints"
+	"github.com/canonical/microcluster/internal/extensions"
+	internalREST "github.com/canonical/microcluster/internal/rest"
 	internalClient "github.com/canonical/microcluster/internal/rest/client"
+	"github.com/canonical/microcluster/internal/rest/resources"
 	internalTypes "github.com/canonical/microcluster/internal/rest/types"
 	"github.com/canonical/microcluster/internal/sys"
 	"github.com/canonical/microcluster/rest"
@@ -46,6 +57,226 @@ type Args struct {
 	ListenPort string
 	Client     *client.Client
 	Proxy      func(*http.Request) (*url.URL, error)
+
+	// MaxCollectionSize overrides the maximum number of entries returned by collection endpoints without pagination.
+	MaxCollectionSize int
+
+	// MaxCollectionBytes overrides the maximum size in bytes of the entries returned by collection endpoints without
+	// pagination. Defaults to 16MiB. A negative value disables the limit.
+	MaxCollectionBytes int
+
+	// SlowQueryThreshold enables logging of any database statement taking longer than the given duration.
+	SlowQueryThreshold time.Duration
+
+	// OnSlowQuery is optionally called with the SQL, duration, and caller of every slow database statement.
+	OnSlowQuery func(query string, duration time.Duration, caller string)
+
+	// OperationRetention overrides how long completed operations are kept in the operation history.
+	OperationRetention time.Duration
+
+	// EventRetention overrides how long cluster membership events are kept in the event history.
+	EventRetention time.Duration
+
+	// OfflineThreshold overrides the time since the last heartbeat after which a cluster member is reported as offline.
+	OfflineThreshold time.Duration
+
+	// OfflineDemotionThreshold enables automatic demotion of voters to spare once their last heartbeat is older than
+	// the given duration.
+	OfflineDemotionThreshold time.Duration
+
+	// LowDiskThreshold enables automatic demotion of this cluster member from voter to spare once the free space in
+	// the database directory drops below the given number of bytes. It is promoted back once space recovers.
+	LowDiskThreshold uint64
+
+	// SnapshotInterval overrides how often the cluster membership and configuration is recorded in the snapshot history.
+	SnapshotInterval time.Duration
+
+	// SnapshotRetention overrides how long snapshots are kept in the snapshot history.
+	SnapshotRetention time.Duration
+
+	// BackupCompression overrides the compression of scheduled database backups, and of those streamed to clients
+	// unless they ask for another one. Defaults to "gzip".
+	BackupCompression string
+
+	// Compressions are additional algorithms, such as zstd, that database backups can be compressed with.
+	Compressions []config.Compression
+
+	// BackupSchedule is a cron expression for when to back up the database. Backups are disabled if empty.
+	BackupSchedule string
+
+	// BackupDir overrides the directory in which backup archives are kept.
+	BackupDir string
+
+	// BackupTarget is an optional HTTP(S) URL to which backup archives are uploaded with a PUT request.
+	BackupTarget string
+
+	// BackupCount overrides the number of backup archives kept in the backup directory.
+	BackupCount int
+
+	// DebugEndpoints enables endpoints on the control socket for simulating failure scenarios, such as leader loss.
+	DebugEndpoints bool
+
+	// JoinTokenExpiry overrides the default lifetime of join tokens.
+	JoinTokenExpiry time.Duration
+
+	// JoinHostnames are host:port pairs added to every join token issued by this daemon, such as a DNS name resolving
+	// to the cluster members. Joining members resolve them again on each connection attempt.
+	JoinHostnames []string
+
+	// ListenAddress is the address and port the cluster API binds to, if it differs from the address advertised to
+	// other cluster members when bootstrapping or joining the cluster, such as behind NAT or a proxy.
+	ListenAddress string
+
+	// ListenAddresses are addresses and ports the cluster API binds to, such as "[::]:9000" to listen on all IPv4 and
+	// IPv6 addresses. If ListenAddress or ListenAddresses are set, the cluster API only binds to those addresses, so the
+	// advertised address must be covered by one of them unless it is not local, such as behind NAT. The advertised
+	// address is still the one used by other cluster members.
+	ListenAddresses []string
+
+	// HTTPReadTimeout and HTTPWriteTimeout bound the time to read a whole request and to write its response. Zero, the
+	// default, means no limit, as some requests stream large amounts of data.
+	HTTPReadTimeout  time.Duration
+	HTTPWriteTimeout time.Duration
+
+	// HTTPIdleTimeout overrides how long idle keep-alive connections are kept open. Defaults to 2 minutes.
+	HTTPIdleTimeout time.Duration
+
+	// HTTPMaxHeaderBytes overrides the maximum size of the headers of a request. Defaults to 1MB.
+	HTTPMaxHeaderBytes int
+
+	// ApplicationSocket is the path of a separate unix socket serving only the endpoints registered by the
+	// application, for local tools that should not need certificates. If empty, no application socket is created.
+	ApplicationSocket string
+
+	// ApplicationSocketOwner and ApplicationSocketGroup are the user and group names that own the application socket.
+	// If empty, the user and group of the daemon are used.
+	ApplicationSocketOwner string
+	ApplicationSocketGroup string
+
+	// ApplicationSocketMode is the file mode of the application socket. If zero, 0660 is used.
+	ApplicationSocketMode os.FileMode
+
+	// ConsumerAddress is the address and port of a separate listener for the consumer API, serving the public and
+	// application endpoints but not the cluster-internal ones, so that cluster traffic can be firewalled away from
+	// clients. If empty, the consumer API is only served on the cluster address.
+	ConsumerAddress string
+
+	// ConsumerCryptoPolicy is the crypto policy applied to TLS on the consumer API listener. Supported values are
+	// "" (default) and "fips".
+	ConsumerCryptoPolicy string
+
+	// CORSAllowedOrigins are the origins, such as "https://ui.example.com", of browser-based clients allowed to call
+	// the consumer API cross-origin. A "*" allows any other origin, without credentials. If empty, CORS is not enabled.
+	CORSAllowedOrigins []string
+
+	// CORSAllowedMethods overrides the HTTP methods allowed in cross-origin requests. Defaults to all methods used by
+	// the API.
+	CORSAllowedMethods []string
+
+	// CORSAllowedHeaders overrides the request headers allowed in cross-origin requests. Defaults to "Authorization"
+	// and "Content-Type".
+	CORSAllowedHeaders []string
+
+	// FailureDomain is the failure domain (such as a rack or availability zone) of this cluster member. Database
+	// voters are spread across cluster members in different failure domains.
+	FailureDomain uint64
+
+	// APIExtensions are the API extensions implemented by the application. They are recorded in the database so that
+	// new behavior can be gated until every cluster member supports it. New extensions should only be appended.
+	APIExtensions []string
+
+	// CryptoPolicy restricts the algorithms used for certificates and TLS. Set to "fips" to only allow FIPS 140-2
+	// approved algorithms, in which case the daemon refuses to start if any existing certificate violates the policy.
+	CryptoPolicy string
+
+	// Patches are one-time corrective actions applied once on each cluster member.
+	Patches []config.Patch
+
+	// CertificateExpiryWarning overrides how long before a certificate expires that a warning is reported for it.
+	CertificateExpiryWarning time.Duration
+
+	// Insecure disables verification by the daemon of the certificates presented by other cluster members, and of
+	// client certificates against the external certificate authority. Client certificates must still be trusted to
+	// access the API. This is only meant for local development, and must never be used in production.
+	Insecure bool
+
+	// KeyPassphrase optionally returns the passphrase used to encrypt the server and cluster private keys in the state
+	// directory. It is called when the daemon starts, and any unencrypted private key is encrypted with the passphrase.
+	// Use MachineKey to bind the private keys to this machine instead of prompting for a passphrase.
+	KeyPassphrase func() ([]byte, error)
+
+	// KeySigner optionally returns the signer for the private key of the keypair with the given prefix ("server" or
+	// "cluster"), for private keys held in a PKCS#11 token or TPM rather than in the state directory. If it returns
+	// nil, the private key is read from the state directory. As joining cluster members are not sent a cluster key held
+	// by a signer, every cluster member must then hold the same cluster key, and the cluster certificate can not be
+	// rotated.
+	KeySigner func(prefix string) (crypto.Signer, error)
+
+	// Authorizer optionally decides whether authenticated callers may access the application's endpoints, for access
+	// control beyond the certificate ACLs and bearer token rules. Requests over the local control socket are always
+	// allowed.
+	Authorizer rest.Authorizer
+
+	// ControlSocketUIDs and ControlSocketGIDs restrict the control socket to processes running as the given users or
+	// with the given primary groups, in addition to root and the user running the daemon. If both are empty, anyone
+	// who can open the socket may use it.
+	ControlSocketUIDs []uint32
+	ControlSocketGIDs []uint32
+
+	// ControlSocket overrides the path of the control socket, which is control.socket in the state directory by
+	// default. A path starting with "@" is a socket in the Linux abstract namespace, which has no file permissions, so
+	// it is restricted to ControlSocketUIDs and ControlSocketGIDs, and otherwise to root and the user of the daemon.
+	ControlSocket string
+
+	// DqliteSocket overrides the path of the socket used internally by dqlite, which is a random abstract unix socket
+	// by default. A path starting with "@" is a socket in the Linux abstract namespace.
+	DqliteSocket string
+
+	// TrustedProxies are the CIDRs of reverse proxies and load balancers in front of the daemon, whose X-Forwarded-For
+	// header is used to record the address of the client they forwarded a request for.
+	TrustedProxies []string
+
+	// ProxyProtocol requires connections from TrustedProxies to the network listeners to start with a PROXY protocol
+	// v1 or v2 header, such as sent by HAProxy, whose client address is used as the address of the connection.
+	ProxyProtocol bool
+
+	// RateLimit is the number of requests per second allowed from each client over the network, identified by its
+	// address, or by its certificate if issued by the external certificate authority. Cluster members are not limited.
+	// Zero disables rate limiting.
+	RateLimit float64
+
+	// RateLimitBurst overrides the number of requests a client can make in a burst above RateLimit. Defaults to 20.
+	RateLimitBurst int
+
+	// MaxRequestBodySize overrides the maximum size in bytes of the body of a request. Defaults to 32MiB. A negative
+	// value disables the limit.
+	MaxRequestBodySize int64
+
+	// HandlerTimeout is how long an endpoint handler may run before the request is answered with 503 Service
+	// Unavailable. Zero means no limit.
+	HandlerTimeout time.Duration
+
+	// Middleware wraps the endpoints registered by the application, such as for authentication, metrics, or to modify
+	// requests. The first middleware is outermost, and sees requests before the built-in authentication.
+	Middleware []rest.Middleware
+
+	// APIVersions are further versions of the application API, such as "2.0", served concurrently with the endpoints
+	// given to Start under "/1.0". A version "1.0" adds to those endpoints, and can be used to mark them deprecated.
+	APIVersions []rest.APIVersion
+
+	// NotFoundHandler overrides the handler of requests that match no endpoint, such as to serve a discovery document
+	// or redirect legacy paths. Defaults to a 404 Not Found error response.
+	NotFoundHandler http.Handler
+
+	// MethodNotAllowedHandler handles requests for methods an endpoint does not implement. Defaults to a 501 Not
+	// Implemented error response for the HTTP methods used by endpoints, and 404 Not Found for others.
+	MethodNotAllowedHandler http.Handler
+}
+
+// MachineKey returns a passphrase derived from the machine ID, for use as Args.KeyPassphrase. Private keys encrypted
+// with it can only be decrypted on the same machine.
+func MachineKey() ([]byte, error) {
+	return sys.MachineKey()
 }
 
 // App returns an instance of MicroCluster with a newly initialized filesystem if one does not exist.
@@ -62,6 +293,52 @@ func App(ctx context.Context, args Args) (*MicroCluster, error) {
 		return nil, err
 	}
 
+	cryptopolicy.Current, err = cryptopolicy.Parse(args.CryptoPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	sys.KeySigner = args.KeySigner
+	internalREST.Authorizer = args.Authorizer
+	internalREST.RateLimit = args.RateLimit
+	if args.RateLimitBurst > 0 {
+		internalREST.RateLimitBurst = args.RateLimitBurst
+	}
+
+	if args.MaxRequestBodySize != 0 {
+		internalREST.MaxRequestBodySize = args.MaxRequestBodySize
+	}
+
+	internalREST.HandlerTimeout = args.HandlerTimeout
+	internalREST.MethodNotAllowedHandler = args.MethodNotAllowedHandler
+
+	internalREST.CORSAllowedOrigins = args.CORSAllowedOrigins
+	if len(args.CORSAllowedMethods) > 0 {
+		internalREST.CORSAllowedMethods = args.CORSAllowedMethods
+	}
+
+	if len(args.CORSAllowedHeaders) > 0 {
+		internalREST.CORSAllowedHeaders = args.CORSAllowedHeaders
+	}
+	internalREST.ControlSocketUIDs = args.ControlSocketUIDs
+	internalREST.ControlSocketGIDs = args.ControlSocketGIDs
+	sys.ControlSocketPath = args.ControlSocket
+	db.DqliteSocket = args.DqliteSocket
+
+	internalREST.TrustedProxies = make([]*net.IPNet, 0, len(args.TrustedProxies))
+	for _, cidr := range args.TrustedProxies {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid trusted proxy %q: %w", cidr, err)
+		}
+
+		internalREST.TrustedProxies = append(internalREST.TrustedProxies, network)
+	}
+
+	if args.ProxyProtocol {
+		endpoints.ProxyProtocolSources = internalREST.TrustedProxies
+	}
+
 	return &MicroCluster{
 		FileSystem: os,
 		ctx:        ctx,
@@ -78,6 +355,145 @@ func (m *MicroCluster) Start(apiEndpoints []rest.Endpoint, schemaExtensions map[
 		return err
 	}
 
+	// Only the daemon skips certificate verification, for its own listeners and the clients it uses to reach other
+	// cluster members, so that clients of the application never do.
+	internalClient.Insecure = m.args.Insecure
+	if m.args.Insecure {
+		logger.Warn("INSECURE: Certificate verification is disabled, any certificate will be trusted. Do not use this in production")
+	}
+
+	if m.args.MaxCollectionSize != 0 {
+		rest.MaxCollectionSize = m.args.MaxCollectionSize
+	}
+
+	if m.args.MaxCollectionBytes != 0 {
+		rest.MaxCollectionBytes = m.args.MaxCollectionBytes
+	}
+
+	listenAddresses := m.args.ListenAddresses
+	if m.args.ListenAddress != "" {
+		listenAddresses = append([]string{m.args.ListenAddress}, listenAddresses...)
+	}
+
+	for _, address := range listenAddresses {
+		_, err = types.ParseAddrPort(address)
+		if err != nil {
+			return fmt.Errorf("Received invalid listen address %q: %w", address, err)
+		}
+	}
+
+	if m.args.ConsumerAddress != "" {
+		_, err = types.ParseAddrPort(m.args.ConsumerAddress)
+		if err != nil {
+			return fmt.Errorf("Received invalid consumer address %q: %w", m.args.ConsumerAddress, err)
+		}
+	}
+
+	daemon.ConsumerCryptoPolicy, err = cryptopolicy.Parse(m.args.ConsumerCryptoPolicy)
+	if err != nil {
+		return err
+	}
+
+	daemon.HTTPReadTimeout = m.args.HTTPReadTimeout
+	daemon.HTTPWriteTimeout = m.args.HTTPWriteTimeout
+	if m.args.HTTPIdleTimeout != 0 {
+		daemon.HTTPIdleTimeout = m.args.HTTPIdleTimeout
+	}
+
+	if m.args.HTTPMaxHeaderBytes != 0 {
+		daemon.HTTPMaxHeaderBytes = m.args.HTTPMaxHeaderBytes
+	}
+
+	daemon.ApplicationSocket = m.args.ApplicationSocket
+	daemon.ApplicationSocketOwner = m.args.ApplicationSocketOwner
+	daemon.ApplicationSocketGroup = m.args.ApplicationSocketGroup
+	if m.args.ApplicationSocketMode != 0 {
+		daemon.ApplicationSocketMode = m.args.ApplicationSocketMode
+	}
+
+	daemon.ListenAddresses = listenAddresses
+	internalREST.ListenAddresses = listenAddresses
+	daemon.Middleware = m.args.Middleware
+	daemon.APIVersions = m.args.APIVersions
+	daemon.NotFoundHandler = m.args.NotFoundHandler
+	daemon.ConsumerAddress = m.args.ConsumerAddress
+	db.SlowQueryThreshold = m.args.SlowQueryThreshold
+	db.SlowQueryHandler = m.args.OnSlowQuery
+	db.FailureDomain = m.args.FailureDomain
+	extensions.AppExtensions = m.args.APIExtensions
+
+	if m.args.OperationRetention != 0 {
+		daemon.OperationRetention = m.args.OperationRetention
+	}
+
+	if m.args.EventRetention != 0 {
+		daemon.EventRetention = m.args.EventRetention
+	}
+
+	if m.args.OfflineThreshold != 0 {
+		cluster.OfflineThreshold = m.args.OfflineThreshold
+	}
+
+	daemon.OfflineDemotionThreshold = m.args.OfflineDemotionThreshold
+	daemon.LowDiskThreshold = m.args.LowDiskThreshold
+
+	if m.args.SnapshotInterval != 0 {
+		daemon.SnapshotInterval = m.args.SnapshotInterval
+	}
+
+	if m.args.SnapshotRetention != 0 {
+		daemon.SnapshotRetention = m.args.SnapshotRetention
+	}
+
+	for _, compression := range m.args.Compressions {
+		err = backup.Register(compression)
+		if err != nil {
+			return err
+		}
+	}
+
+	if m.args.BackupCompression != "" {
+		_, err = backup.Lookup(m.args.BackupCompression)
+		if err != nil {
+			return fmt.Errorf("Invalid backup compression: %w", err)
+		}
+
+		backup.DefaultCompression = m.args.BackupCompression
+	}
+
+	daemon.BackupSchedule = m.args.BackupSchedule
+	daemon.BackupDir = m.args.BackupDir
+	daemon.BackupTarget = m.args.BackupTarget
+	if m.args.BackupCount != 0 {
+		daemon.BackupCount = m.args.BackupCount
+	}
+
+	if m.args.CertificateExpiryWarning != 0 {
+		resources.CertificateExpiryWarning = m.args.CertificateExpiryWarning
+	}
+
+	if m.args.JoinTokenExpiry != 0 {
+		resources.TokenExpiry = m.args.JoinTokenExpiry
+	}
+
+	for _, hostname := range m.args.JoinHostnames {
+		_, _, err = net.SplitHostPort(hostname)
+		if err != nil {
+			return fmt.Errorf("Invalid join hostname %q: %w", hostname, err)
+		}
+	}
+
+	resources.JoinHostnames = m.args.JoinHostnames
+
+	if m.args.DebugEndpoints {
+		resources.EnableDebugEndpoints()
+	}
+
+	err = m.unsealKeys()
+	if err != nil {
+		return err
+	}
+
 	// Start up a daemon with a basic control socket.
 	defer logger.Info("Daemon stopped")
 	d := daemon.NewDaemon(m.ctx, cluster.GetCallerProject())
@@ -91,7 +507,7 @@ func (m *MicroCluster) Start(apiEndpoints []rest.Endpoint, schemaExtensions map[
 	chIgnore := make(chan os.Signal, 1)
 	signal.Notify(chIgnore, unix.SIGHUP)
 
-	err = d.Init(m.args.ListenPort, m.FileSystem.StateDir, m.FileSystem.SocketGroup, apiEndpoints, schemaExtensions, hooks)
+	err = d.Init(m.args.ListenPort, m.FileSystem.StateDir, m.FileSystem.SocketGroup, apiEndpoints, schemaExtensions, hooks, m.args.Patches)
 	if err != nil {
 		return fmt.Errorf("Unable to start daemon: %w", err)
 	}
@@ -227,6 +643,51 @@ func (m *MicroCluster) JoinCluster(name string, address string, token string, in
 	return c.ControlDaemon(m.ctx, internalTypes.Control{JoinToken: token, Address: addr, Name: name, InitConfig: initConfig}, timeout)
 }
 
+// FetchClusterFingerprint contacts the join addresses of the given join token in order, and returns the address and
+// cluster certificate fingerprint of the first one that responds. The fingerprint should be confirmed by the operator
+// and pinned with PinJoinToken before joining, so that the cluster certificate is trusted on first use.
+func FetchClusterFingerprint(token string) (string, string, error) {
+	joinToken, err := internalTypes.DecodeToken(token)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to decode join token: %w", err)
+	}
+
+	var errLast error
+	for _, addr := range joinToken.Addresses() {
+		remoteURL := api.NewURL().Scheme("https").Host(addr)
+		cert, err := shared.GetRemoteCertificate(remoteURL.String(), "")
+		if err != nil {
+			errLast = err
+			continue
+		}
+
+		return addr, shared.CertFingerprint(cert), nil
+	}
+
+	if errLast == nil {
+		return "", "", fmt.Errorf("Join token %q has no join addresses", joinToken.Name)
+	}
+
+	return "", "", fmt.Errorf("Failed to get the cluster certificate from any join address: %w", errLast)
+}
+
+// PinJoinToken returns the given join token with the given cluster certificate fingerprint, so that joining fails if
+// any join address presents a different certificate. Tokens that already carry a different fingerprint are rejected.
+func PinJoinToken(token string, fingerprint string) (string, error) {
+	joinToken, err := internalTypes.DecodeToken(token)
+	if err != nil {
+		return "", fmt.Errorf("Failed to decode join token: %w", err)
+	}
+
+	if joinToken.Fingerprint != "" && joinToken.Fingerprint != fingerprint {
+		return "", fmt.Errorf("Join token %q expects cluster certificate %q, not %q", joinToken.Name, joinToken.Fingerprint, fingerprint)
+	}
+
+	joinToken.Fingerprint = fingerprint
+
+	return joinToken.String()
+}
+
 // NewJoinToken creates and records a new join token containing all the necessary credentials for joining a cluster.
 // Join tokens are tied to the server certificate of the joining node, and will be deleted once the node has joined the
 // cluster.
@@ -295,11 +756,30 @@ func (m *MicroCluster) LocalClient() (*client.Client, error) {
 	return c, nil
 }
 
+// ApplicationClient gets a client for the application API on the application socket.
+func (m *MicroCluster) ApplicationClient() (*client.Client, error) {
+	if m.args.ApplicationSocket == "" {
+		return nil, fmt.Errorf("No application socket is configured")
+	}
+
+	c, err := internalClient.NewUnix(m.args.ApplicationSocket)
+	if err != nil {
+		return nil, err
+	}
+
+	return &client.Client{Client: *c}, nil
+}
+
 // RemoteClient gets a client for the specified cluster member URL.
 // The filesystem will be parsed for the cluster and server certificates.
 func (m *MicroCluster) RemoteClient(address string) (*client.Client, error) {
 	c := m.args.Client
 	if c == nil {
+		err := m.unsealKeys()
+		if err != nil {
+			return nil, err
+		}
+
 		serverCert, err := m.FileSystem.ServerCert()
 		if err != nil {
 			return nil, err
@@ -366,3 +846,138 @@ func (m *MicroCluster) SQL(query string) (string, *internalTypes.SQLBatch, error
 
 	return "", batch, err
 }
+
+// IntegrityCheck runs an integrity check against the databases of the local cluster member. If all is true, the check
+// is also run on all other cluster members.
+func (m *MicroCluster) IntegrityCheck(all bool) ([]internalTypes.IntegrityCheck, error) {
+	c, err := m.LocalClient()
+	if err != nil {
+		return nil, err
+	}
+
+	return c.CheckDatabaseIntegrity(m.ctx, all)
+}
+
+// DatabaseBackup writes an archive of the global database to w, compressed with the first of the given algorithms
+// supported by the daemon, or with its backup compression if none are. It returns the name of the compression of the
+// archive, which is "identity" if it is not compressed.
+func (m *MicroCluster) DatabaseBackup(ctx context.Context, w io.Writer, compressions ...string) (string, error) {
+	c, err := m.LocalClient()
+	if err != nil {
+		return "", err
+	}
+
+	return c.GetDatabaseBackup(ctx, w, compressions...)
+}
+
+// ReadDatabaseBackup reads the files of a database backup archive with the given compression, as returned by
+// DatabaseBackup, and verifies them against the checksums held in the archive. The files are keyed by name.
+func (m *MicroCluster) ReadDatabaseBackup(r io.Reader, compression string) (map[string][]byte, error) {
+	var algorithm *config.Compression
+	for i, c := range m.args.Compressions {
+		if c.Name == compression {
+			algorithm = &m.args.Compressions[i]
+		}
+	}
+
+	if algorithm == nil {
+		builtin, err := backup.Lookup(compression)
+		if err != nil {
+			return nil, err
+		}
+
+		algorithm = &builtin
+	}
+
+	files, err := backup.ReadArchive(r, *algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string][]byte, len(files))
+	for _, file := range files {
+		data[file.Name] = file.Data
+	}
+
+	return data, nil
+}
+
+// TrustStoreCheck compares the trust store of the local cluster member against the cluster members in the database,
+// reporting missing, stale and orphaned entries. If repair is true, any trust store that differs is replaced with the
+// cluster members in the database. If all is true, the trust stores of all other cluster members are also checked.
+func (m *MicroCluster) TrustStoreCheck(all bool, repair bool) ([]internalTypes.TrustStoreCheck, error) {
+	c, err := m.LocalClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if repair {
+		return c.RepairTrustStore(m.ctx, all)
+	}
+
+	return c.CheckTrustStore(m.ctx, all)
+}
+
+// ReconfigureAddresses changes the addresses of the given cluster members, keyed by name. The daemon must be stopped,
+// and the same addresses must be given on every cluster member before any of them is started again. On the next start,
+// the daemon will listen on its new address.
+func (m *MicroCluster) ReconfigureAddresses(addresses map[string]string) error {
+	_, err := m.Status()
+	if err == nil {
+		return fmt.Errorf("Cannot reconfigure addresses while the daemon is running")
+	}
+
+	addrPorts := make(map[string]types.AddrPort, len(addresses))
+	for name, address := range addresses {
+		addrPort, err := types.ParseAddrPort(address)
+		if err != nil {
+			return fmt.Errorf("Failed to parse address %q of cluster member %q: %w", address, name, err)
+		}
+
+		addrPorts[name] = addrPort
+	}
+
+	return daemon.ReconfigureAddresses(m.FileSystem, addrPorts)
+}
+
+// EnableClustering moves a single-member cluster, typically bootstrapped on a loopback address, to the given network
+// address so that other cluster members can join it. The daemon must be stopped. On the next start, the daemon will
+// listen on the new address with regenerated certificates, and previously issued join tokens will no longer be valid.
+func (m *MicroCluster) EnableClustering(address string) error {
+	_, err := m.Status()
+	if err == nil {
+		return fmt.Errorf("Cannot enable clustering while the daemon is running")
+	}
+
+	addrPort, err := types.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("Failed to parse address %q: %w", address, err)
+	}
+
+	err = m.unsealKeys()
+	if err != nil {
+		return err
+	}
+
+	return daemon.EnableClustering(m.FileSystem, addrPort)
+}
+
+// unsealKeys sets the passphrase that the private keys in the state directory are encrypted with, if one is configured.
+func (m *MicroCluster) unsealKeys() error {
+	if m.args.KeyPassphrase == nil {
+		return nil
+	}
+
+	passphrase, err := m.args.KeyPassphrase()
+	if err != nil {
+		return fmt.Errorf("Failed to get private key passphrase: %w", err)
+	}
+
+	if len(passphrase) == 0 {
+		return fmt.Errorf("Private key passphrase is empty")
+	}
+
+	sys.KeyPassphrase = passphrase
+
+	return nil
+}
@@ -0,0 +1,292 @@
+package microcluster
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+	"github.com/canonical/lxd/shared/logger"
+	"gopkg.in/yaml.v2"
+
+	internalTypes "github.com/canonical/microcluster/internal/rest/types"
+	"github.com/canonical/microcluster/rest/types"
+)
+
+// Preseed describes the initial members of a cluster, so that the cluster can be formed without any interaction by
+// initializing each member's daemon with the same preseed.
+type Preseed struct {
+	// Members are the initial cluster members. The first member bootstraps the cluster, and the rest join it.
+	Members []PreseedMember `json:"members" yaml:"members"`
+
+	// Config is the configuration passed to the bootstrap and join hooks of every member.
+	Config map[string]string `json:"config" yaml:"config"`
+
+	// ClusterCertificate and ClusterKey are the PEM encoded cluster certificate and private key that the first member
+	// bootstraps the cluster with. The other members verify the first member against the certificate before sending it
+	// their join secret, and sign their join tokens with the key. They are required if the preseed has more than one
+	// member.
+	ClusterCertificate string `json:"cluster_certificate" yaml:"cluster_certificate"`
+	ClusterKey         string `json:"cluster_key" yaml:"cluster_key"`
+}
+
+// PreseedMember describes a single cluster member in a preseed.
+type PreseedMember struct {
+	Name    string `json:"name" yaml:"name"`
+	Address string `json:"address" yaml:"address"`
+
+	// Secret is the join token secret shared between the bootstrapping member and this member. It is required for
+	// every member but the first.
+	Secret string `json:"secret" yaml:"secret"`
+
+	// Config overrides the preseed configuration for this member.
+	Config map[string]string `json:"config" yaml:"config"`
+}
+
+// ParsePreseed parses and validates a YAML preseed.
+func ParsePreseed(data []byte) (*Preseed, error) {
+	preseed := &Preseed{}
+	err := yaml.UnmarshalStrict(data, preseed)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse preseed: %w", err)
+	}
+
+	err = preseed.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return preseed, nil
+}
+
+// Validate checks that every member has a unique name and address, that every joining member has a secret, and that
+// the cluster certificate is given if any member joins.
+func (p Preseed) Validate() error {
+	if len(p.Members) == 0 {
+		return fmt.Errorf("Preseed has no cluster members")
+	}
+
+	if len(p.Members) > 1 {
+		_, err := p.clusterSigner()
+		if err != nil {
+			return err
+		}
+	}
+
+	names := make(map[string]bool, len(p.Members))
+	addresses := make(map[string]bool, len(p.Members))
+	for i, member := range p.Members {
+		if member.Name == "" {
+			return fmt.Errorf("Preseed cluster member %d has no name", i)
+		}
+
+		if names[member.Name] {
+			return fmt.Errorf("Preseed has duplicate cluster member %q", member.Name)
+		}
+
+		addr, err := types.ParseAddrPort(member.Address)
+		if err != nil {
+			return fmt.Errorf("Preseed cluster member %q has invalid address %q: %w", member.Name, member.Address, err)
+		}
+
+		if addresses[addr.String()] {
+			return fmt.Errorf("Preseed has duplicate address %q", addr.String())
+		}
+
+		if i > 0 && member.Secret == "" {
+			return fmt.Errorf("Preseed cluster member %q has no join secret", member.Name)
+		}
+
+		names[member.Name] = true
+		addresses[addr.String()] = true
+	}
+
+	return nil
+}
+
+// clusterFingerprint returns the fingerprint of the preseed cluster certificate.
+func (p Preseed) clusterFingerprint() (string, error) {
+	if p.ClusterCertificate == "" {
+		return "", fmt.Errorf("Preseed has no cluster certificate")
+	}
+
+	block, _ := pem.Decode([]byte(p.ClusterCertificate))
+	if block == nil {
+		return "", fmt.Errorf("Preseed cluster certificate is not PEM encoded")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("Failed to parse preseed cluster certificate: %w", err)
+	}
+
+	return shared.CertFingerprint(cert), nil
+}
+
+// clusterSigner returns the preseed cluster key, after checking that it matches the preseed cluster certificate.
+func (p Preseed) clusterSigner() (crypto.Signer, error) {
+	if p.ClusterKey == "" {
+		return nil, fmt.Errorf("Preseed has no cluster key")
+	}
+
+	keyPair, err := tls.X509KeyPair([]byte(p.ClusterCertificate), []byte(p.ClusterKey))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to load preseed cluster certificate and key: %w", err)
+	}
+
+	signer, ok := keyPair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("Preseed cluster key can not be used for signing")
+	}
+
+	return signer, nil
+}
+
+// memberConfig returns the preseed configuration merged with the overrides of the given member.
+func (p Preseed) memberConfig(member PreseedMember) map[string]string {
+	config := make(map[string]string, len(p.Config)+len(member.Config))
+	for key, value := range p.Config {
+		config[key] = value
+	}
+
+	for key, value := range member.Config {
+		config[key] = value
+	}
+
+	return config
+}
+
+// Preseed initializes the local daemon as the cluster member with the given name in the preseed. The first member
+// bootstraps the cluster and records a join token for each other member from their secrets. The other members join the
+// first member with those secrets, retrying until the first member has bootstrapped or the timeout is reached, so the
+// daemons can be initialized in any order.
+func (m *MicroCluster) Preseed(name string, preseed Preseed, timeout time.Duration) error {
+	err := preseed.Validate()
+	if err != nil {
+		return err
+	}
+
+	var local *PreseedMember
+	for i, member := range preseed.Members {
+		if member.Name == name {
+			local = &preseed.Members[i]
+			break
+		}
+	}
+
+	if local == nil {
+		return fmt.Errorf("Cluster member %q is not in the preseed", name)
+	}
+
+	c, err := m.LocalClient()
+	if err != nil {
+		return err
+	}
+
+	addr, err := types.ParseAddrPort(local.Address)
+	if err != nil {
+		return fmt.Errorf("Received invalid address %q: %w", local.Address, err)
+	}
+
+	first := preseed.Members[0]
+	if first.Name == name {
+		if preseed.ClusterCertificate != "" {
+			err = m.writePreseedClusterCert(preseed)
+			if err != nil {
+				return err
+			}
+		}
+
+		tokens := make([]internalTypes.TokenRecord, 0, len(preseed.Members)-1)
+		for _, member := range preseed.Members[1:] {
+			tokens = append(tokens, internalTypes.TokenRecord{Name: member.Name, Token: member.Secret})
+		}
+
+		return c.ControlDaemon(m.ctx, internalTypes.Control{Bootstrap: true, Address: addr, Name: name, InitConfig: preseed.memberConfig(*local), Tokens: tokens}, timeout)
+	}
+
+	joinAddr, err := types.ParseAddrPort(first.Address)
+	if err != nil {
+		return fmt.Errorf("Received invalid address %q: %w", first.Address, err)
+	}
+
+	fingerprint, err := preseed.clusterFingerprint()
+	if err != nil {
+		return err
+	}
+
+	signer, err := preseed.clusterSigner()
+	if err != nil {
+		return err
+	}
+
+	// Sign the token like the cluster would, as clusters whose members all sign join tokens reject unsigned ones.
+	joinToken := internalTypes.Token{Name: name, Secret: local.Secret, Fingerprint: fingerprint, JoinAddresses: []types.AddrPort{joinAddr}}
+	err = joinToken.Sign(signer)
+	if err != nil {
+		return err
+	}
+
+	token, err := joinToken.String()
+	if err != nil {
+		return fmt.Errorf("Failed to encode join token: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err = c.ControlDaemon(m.ctx, internalTypes.Control{JoinToken: token, Address: addr, Name: name, InitConfig: preseed.memberConfig(*local)}, timeout)
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Failed to join cluster member %q: %w", first.Name, err)
+		}
+
+		logger.Info("Waiting for the preseed cluster to be bootstrapped", logger.Ctx{"bootstrap": first.Name, "error": err})
+
+		select {
+		case <-m.ctx.Done():
+			return m.ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+// writePreseedClusterCert writes the preseed cluster certificate and key to the state directory, so that the cluster is
+// bootstrapped with them. A cluster certificate that is already in the state directory must match the preseed.
+func (m *MicroCluster) writePreseedClusterCert(preseed Preseed) error {
+	_, err := preseed.clusterSigner()
+	if err != nil {
+		return err
+	}
+
+	fingerprint, err := preseed.clusterFingerprint()
+	if err != nil {
+		return err
+	}
+
+	if shared.PathExists(filepath.Join(m.FileSystem.StateDir, "cluster.crt")) {
+		current, err := m.FileSystem.ClusterCert()
+		if err != nil {
+			return err
+		}
+
+		currentCert, err := current.PublicKeyX509()
+		if err != nil {
+			return fmt.Errorf("Failed to parse cluster certificate: %w", err)
+		}
+
+		if shared.CertFingerprint(currentCert) != fingerprint {
+			return fmt.Errorf("State directory already has a cluster certificate that does not match the preseed")
+		}
+
+		return nil
+	}
+
+	return m.FileSystem.WriteKeyPair("cluster", []byte(preseed.ClusterCertificate), []byte(preseed.ClusterKey))
+}
@@ -0,0 +1,129 @@
+// Package probes exposes the liveness, readiness and leadership of a MicroCluster daemon as Kubernetes-compatible HTTP
+// probe endpoints, and as a file in the format of the Kubernetes downward API, for daemons packaged in pods or run as
+// host services managed by kubelet.
+package probes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/google/renameio"
+
+	"github.com/canonical/microcluster/microcluster"
+)
+
+// AnnotationPrefix is the prefix of the keys written by WriteFile.
+const AnnotationPrefix = "microcluster.canonical.com/"
+
+// Status is the state of the daemon as reported to Kubernetes.
+type Status struct {
+	// Name is the name of the cluster member, if it has been set up.
+	Name string
+
+	// Live is whether the daemon is responding to requests.
+	Live bool
+
+	// Ready is whether the daemon has joined a cluster and its database is open.
+	Ready bool
+
+	// Leader is whether the cluster member is the dqlite leader.
+	Leader bool
+}
+
+// Prober checks the status of the MicroCluster daemon over its control socket.
+type Prober struct {
+	app *microcluster.MicroCluster
+}
+
+// New returns a Prober for the daemon of the given MicroCluster app.
+func New(app *microcluster.MicroCluster) *Prober {
+	return &Prober{app: app}
+}
+
+// Status returns the current status of the daemon. The daemon is reported as not live if it cannot be reached.
+func (p *Prober) Status() Status {
+	server, err := p.app.Status()
+	if err != nil {
+		logger.Debug("Failed to get daemon status for probe", logger.Ctx{"error": err})
+		return Status{}
+	}
+
+	return Status{
+		Name:   server.Name,
+		Live:   true,
+		Ready:  server.Ready && server.BlockingMember == "",
+		Leader: server.Leader,
+	}
+}
+
+// Handler returns an HTTP handler serving the probe endpoints:
+//   - /livez responds 200 while the daemon is responding to requests.
+//   - /readyz responds 200 while the daemon is part of a cluster and its database is open.
+//   - /leaderz responds 200 while the cluster member is the dqlite leader.
+//
+// Every other case responds 503.
+func (p *Prober) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/livez", p.probe(func(s Status) bool { return s.Live }))
+	mux.HandleFunc("/readyz", p.probe(func(s Status) bool { return s.Ready }))
+	mux.HandleFunc("/leaderz", p.probe(func(s Status) bool { return s.Leader }))
+
+	return mux
+}
+
+func (p *Prober) probe(check func(Status) bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if !check(p.Status()) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("fail\n"))
+			return
+		}
+
+		_, _ = w.Write([]byte("ok\n"))
+	}
+}
+
+// WriteFile atomically writes the current status of the daemon to the given path, one `key="value"` line per field as
+// in the annotations file of the Kubernetes downward API.
+func (p *Prober) WriteFile(path string) error {
+	status := p.Status()
+	content := fmt.Sprintf("%sname=%q\n%slive=\"%t\"\n%sready=\"%t\"\n%sleader=\"%t\"\n",
+		AnnotationPrefix, status.Name,
+		AnnotationPrefix, status.Live,
+		AnnotationPrefix, status.Ready,
+		AnnotationPrefix, status.Leader)
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("Failed to create directory for status file: %w", err)
+	}
+
+	err = renameio.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		return fmt.Errorf("Failed to write status file: %w", err)
+	}
+
+	return nil
+}
+
+// WatchFile rewrites the status file at the given path every interval until the context is cancelled.
+func (p *Prober) WatchFile(ctx context.Context, path string, interval time.Duration) {
+	for {
+		err := p.WriteFile(path)
+		if err != nil {
+			logger.Warn("Failed to update probe status file", logger.Ctx{"path": path, "error": err})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+)
+
+// IdentityType is the kind of caller making an API request.
+type IdentityType string
+
+const (
+	// IdentityLocal is a caller on the local control socket.
+	IdentityLocal IdentityType = "local"
+
+	// IdentityClusterMember is a cluster member, authenticated by its certificate in the trust store.
+	IdentityClusterMember IdentityType = "cluster-member"
+
+	// IdentityCertificate is a client authenticated by a trusted certificate that does not belong to a cluster member.
+	IdentityCertificate IdentityType = "certificate"
+
+	// IdentityBearerToken is a client authenticated by a bearer token.
+	IdentityBearerToken IdentityType = "bearer-token"
+
+	// IdentityAnonymous is a caller that presented no credentials.
+	IdentityAnonymous IdentityType = "anonymous"
+)
+
+// Identity describes the caller of an API request.
+type Identity struct {
+	Type IdentityType
+
+	// Name is the name of the cluster member, certificate or bearer token. For certificates without a name, it is
+	// the certificate fingerprint.
+	Name string
+
+	// Fingerprint is the fingerprint of the certificate presented by the caller, if any.
+	Fingerprint string
+
+	// Address is the address of the remote caller. Behind a trusted proxy, it is the address of the client the proxy
+	// forwarded the request for.
+	Address string
+
+	// Credentials are the credentials of the process calling over the local control socket, if known.
+	Credentials *Credentials
+}
+
+// Credentials are the user, group and process IDs of a process calling over the local control socket.
+type Credentials struct {
+	UID uint32
+	GID uint32
+	PID int32
+}
+
+// String returns a description of the identity for logs and error messages.
+func (i Identity) String() string {
+	switch i.Type {
+	case IdentityLocal:
+		if i.Credentials != nil {
+			return fmt.Sprintf("Local control socket (uid %d, gid %d, pid %d)", i.Credentials.UID, i.Credentials.GID, i.Credentials.PID)
+		}
+
+		return "Local control socket"
+	case IdentityClusterMember:
+		return fmt.Sprintf("Cluster member %q", i.Name)
+	case IdentityCertificate:
+		return fmt.Sprintf("Certificate %q", i.Name)
+	case IdentityBearerToken:
+		return fmt.Sprintf("Bearer token %q", i.Name)
+	}
+
+	return "Anonymous"
+}
+
+// Authorizer decides whether the caller of an API request may perform it. Applications can register an Authorizer to
+// implement access control, such as RBAC, for their endpoints. It is called for every authenticated request to an
+// application endpoint, after the built-in authentication and certificate ACLs have allowed it.
+type Authorizer interface {
+	// Authorize returns whether the given identity may call the given HTTP method on the endpoint with the given
+	// path, such as "/1.0/widgets/{name}". An error is returned to the caller as a server error.
+	Authorize(ctx context.Context, identity Identity, endpoint string, method string) (bool, error)
+}
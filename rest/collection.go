@@ -0,0 +1,255 @@
+package rest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/response"
+)
+
+// MaxCollectionSize is the maximum number of entries a collection endpoint will return in a single response.
+// Clients requesting an unbounded listing of a larger collection must paginate using the "limit" and "offset" query
+// parameters. A value of 0 or less disables the limit.
+var MaxCollectionSize = 1000
+
+// MaxCollectionBytes is the maximum size in bytes of the JSON encoding of the entries a collection endpoint will return
+// in a single response, so that collections of large entries also have to be paginated. A value of 0 or less disables
+// the limit.
+var MaxCollectionBytes = 16 * 1024 * 1024
+
+// CollectionResponse returns a sync response containing the page of items requested with the "limit" and "offset"
+// query parameters, or "limit" and "cursor" to continue from a previous page. Items are first narrowed down by any
+// "filter" query parameters, see FilterCollection. If no limit is given and the number of remaining items exceeds
+// MaxCollectionSize, or the requested limit itself exceeds MaxCollectionSize, a 400 response is returned instead. The
+// same applies if the entries of the response would exceed MaxCollectionBytes. The items are encoded in the format returned by RequestFormat.
+func CollectionResponse[T any](r *http.Request, items []T) response.Response {
+	items, err := FilterCollection(r, items)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	return pageResponse(r, items)
+}
+
+// CollectionKeysResponse returns the same response as CollectionResponse, unless "recursion=0" is requested, in which
+// case only the keys of the matching items are returned, as given by the key function.
+func CollectionKeysResponse[T any](r *http.Request, items []T, key func(item T) string) response.Response {
+	recursion, err := Recursion(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if recursion > 0 {
+		return CollectionResponse(r, items)
+	}
+
+	items, err = FilterCollection(r, items)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, key(item))
+	}
+
+	return pageResponse(r, keys)
+}
+
+// Recursion returns the level of detail requested with the "recursion" query parameter. Level 0 requests only the
+// keys of a collection, and level 1, the default, the full items.
+func Recursion(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("recursion")
+	switch value {
+	case "":
+		return 1, nil
+	case "0", "1":
+		return strconv.Atoi(value)
+	}
+
+	return 0, fmt.Errorf("Invalid recursion level %q", value)
+}
+
+// FilterCollection returns the items matching all "filter" query parameters. Each filter has the form "field=value",
+// and matches items whose top-level JSON field of that name has the given value. String values are compared as-is,
+// and all other values by their JSON encoding.
+func FilterCollection[T any](r *http.Request, items []T) ([]T, error) {
+	query := r.URL.Query()["filter"]
+	if len(query) == 0 {
+		return items, nil
+	}
+
+	filters := make(map[string]string, len(query))
+	for _, filter := range query {
+		field, value, ok := strings.Cut(filter, "=")
+		if !ok || field == "" {
+			return nil, fmt.Errorf("Invalid %q query parameter %q", "filter", filter)
+		}
+
+		filters[field] = value
+	}
+
+	filtered := make([]T, 0, len(items))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to encode collection item: %w", err)
+		}
+
+		_, values, err := decodeJSONObject(data)
+		if err != nil {
+			return nil, fmt.Errorf("Collection does not support filtering: %w", err)
+		}
+
+		match := true
+		for field, value := range filters {
+			actual, ok := values[field]
+			if !ok || actual != value {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered, nil
+}
+
+// pageResponse returns a response with the page of items requested with the "limit", and "offset" or "cursor" query
+// parameters. The total number of items is returned in the X-Total-Count header, and if more items remain, the cursor
+// of the next page in the X-Next-Cursor header.
+func pageResponse[T any](r *http.Request, items []T) response.Response {
+	format, err := RequestFormat(r)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	offset, err := queryInt(r, "offset")
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	cursor := r.URL.Query().Get("cursor")
+	if cursor != "" {
+		if offset > 0 {
+			return response.BadRequest(fmt.Errorf("The %q and %q query parameters are mutually exclusive", "offset", "cursor"))
+		}
+
+		offset, err = decodeCursor(cursor)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+	}
+
+	limit, err := queryInt(r, "limit")
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	if offset > len(items) {
+		offset = len(items)
+	}
+
+	remaining := len(items) - offset
+	if MaxCollectionSize > 0 {
+		if limit > MaxCollectionSize {
+			return response.BadRequest(fmt.Errorf("Requested limit %d exceeds the maximum collection size of %d", limit, MaxCollectionSize))
+		}
+
+		if limit == 0 && remaining > MaxCollectionSize {
+			return response.BadRequest(fmt.Errorf("Collection has %d entries, exceeding the maximum of %d per response. Use the \"limit\" and \"offset\" query parameters to paginate", remaining, MaxCollectionSize))
+		}
+	}
+
+	end := len(items)
+	if limit > 0 && limit < remaining {
+		end = offset + limit
+	}
+
+	err = checkCollectionBytes(items[offset:end], limit)
+	if err != nil {
+		return response.BadRequest(err)
+	}
+
+	headers := map[string]string{"X-Total-Count": strconv.Itoa(len(items))}
+	if end < len(items) {
+		headers["X-Next-Cursor"] = encodeCursor(end)
+	}
+
+	return formattedResponse(format, items[offset:end], headers)
+}
+
+// checkCollectionBytes returns an error if the JSON encoding of the given page of a collection, requested with the
+// given limit, exceeds MaxCollectionBytes.
+func checkCollectionBytes[T any](page []T, limit int) error {
+	if MaxCollectionBytes <= 0 {
+		return nil
+	}
+
+	size := 0
+	for i, item := range page {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("Failed to encode collection entry: %w", err)
+		}
+
+		size += len(data)
+		if size <= MaxCollectionBytes {
+			continue
+		}
+
+		if limit > 0 && i > 0 {
+			return fmt.Errorf("Requested page exceeds the maximum response size of %d bytes. Use a \"limit\" of at most %d", MaxCollectionBytes, i)
+		}
+
+		if i == 0 {
+			return fmt.Errorf("Collection entry exceeds the maximum response size of %d bytes", MaxCollectionBytes)
+		}
+
+		return fmt.Errorf("Collection exceeds the maximum response size of %d bytes. Use the \"limit\" and \"offset\" query parameters to paginate", MaxCollectionBytes)
+	}
+
+	return nil
+}
+
+// encodeCursor returns an opaque cursor for the page of a collection starting at the given offset.
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset of the page of a collection identified by the given cursor.
+func decodeCursor(cursor string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid %q query parameter %q", "cursor", cursor)
+	}
+
+	offset, err := strconv.Atoi(string(data))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("Invalid %q query parameter %q", "cursor", cursor)
+	}
+
+	return offset, nil
+}
+
+// queryInt parses the non-negative integer query parameter with the given key. Returns 0 if the key is not set.
+func queryInt(r *http.Request, key string) (int, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("Invalid %q query parameter %q", key, value)
+	}
+
+	return n, nil
+}
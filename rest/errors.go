@@ -0,0 +1,89 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/go-dqlite/driver"
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/api"
+
+	"github.com/canonical/microcluster/rest/types"
+)
+
+// Error is an error with a machine-readable code, which SmartError returns to clients in the error response. It
+// unwraps to an api.StatusError, so it can be checked with api.StatusErrorCheck like other errors.
+type Error struct {
+	StatusCode int
+	Code       types.ErrorCode
+	Message    string
+}
+
+// Errorf returns an Error with the given status code, error code and formatted message.
+func Errorf(statusCode int, code types.ErrorCode, format string, args ...any) error {
+	return &Error{StatusCode: statusCode, Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the error as an api.StatusError.
+func (e *Error) Unwrap() error {
+	return api.StatusErrorf(e.StatusCode, "%s", e.Message)
+}
+
+// ErrorResponse returns an error response with the given status code, error code and message.
+func ErrorResponse(statusCode int, code types.ErrorCode, message string) response.Response {
+	return &errorResponse{statusCode: statusCode, code: code, message: message}
+}
+
+// SmartError returns the error response for the given error like response.SmartError, with the error code of any
+// Error it wraps. Errors without one are given the code matching their cause where it is known.
+func SmartError(err error) response.Response {
+	var codedErr *Error
+	if errors.As(err, &codedErr) {
+		return ErrorResponse(codedErr.StatusCode, codedErr.Code, err.Error())
+	}
+
+	if errors.Is(err, driver.ErrNoAvailableLeader) {
+		return ErrorResponse(http.StatusServiceUnavailable, types.ErrorCodeQuorumLost, err.Error())
+	}
+
+	if api.StatusErrorCheck(err, http.StatusNotFound) {
+		return ErrorResponse(http.StatusNotFound, types.ErrorCodeNotFound, err.Error())
+	}
+
+	return response.SmartError(err)
+}
+
+// errorResponse is an error response whose metadata carries an error code.
+type errorResponse struct {
+	statusCode int
+	code       types.ErrorCode
+	message    string
+}
+
+// Render writes the error response, in the same format as response.ErrorResponse with the addition of its metadata.
+func (r *errorResponse) Render(w http.ResponseWriter) error {
+	resp := api.ResponseRaw{
+		Type:     api.ErrorResponse,
+		Error:    r.message,
+		Code:     r.statusCode,
+		Metadata: types.ErrorMetadata{Code: r.code},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(r.statusCode)
+
+	return json.NewEncoder(w).Encode(resp)
+}
+
+// String returns the error code and message.
+func (r *errorResponse) String() string {
+	return fmt.Sprintf("%s: %s", r.code, r.message)
+}
@@ -0,0 +1,195 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/response"
+	"gopkg.in/yaml.v2"
+)
+
+// Format is an encoding in which collection endpoints can return their items.
+type Format string
+
+const (
+	// FormatJSON returns the items in a standard JSON sync response.
+	FormatJSON Format = "json"
+
+	// FormatYAML returns the bare list of items as YAML.
+	FormatYAML Format = "yaml"
+
+	// FormatCSV returns the items as CSV, with a header row of field names. Nested fields are encoded as JSON.
+	FormatCSV Format = "csv"
+)
+
+// formatContentTypes maps each format to the content type of its responses.
+var formatContentTypes = map[Format]string{
+	FormatJSON: "application/json",
+	FormatYAML: "application/yaml",
+	FormatCSV:  "text/csv",
+}
+
+// RequestFormat returns the format requested with the "format" query parameter, or failing that, the Accept header.
+// Defaults to FormatJSON.
+func RequestFormat(r *http.Request) (Format, error) {
+	value := r.URL.Query().Get("format")
+	if value != "" {
+		format := Format(value)
+		_, ok := formatContentTypes[format]
+		if !ok {
+			return "", fmt.Errorf("Invalid %q query parameter %q", "format", value)
+		}
+
+		return format, nil
+	}
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case "application/json":
+			return FormatJSON, nil
+		case "application/yaml", "application/x-yaml", "text/yaml":
+			return FormatYAML, nil
+		case "text/csv":
+			return FormatCSV, nil
+		}
+	}
+
+	return FormatJSON, nil
+}
+
+// formattedResponse returns a response encoding the given items in the given format.
+func formattedResponse[T any](format Format, items []T, headers map[string]string) response.Response {
+	if format == FormatJSON {
+		return response.SyncResponseHeaders(true, items, headers)
+	}
+
+	var data []byte
+	var err error
+	switch format {
+	case FormatYAML:
+		data, err = yaml.Marshal(items)
+	case FormatCSV:
+		data, err = encodeCSV(items)
+	}
+
+	if err != nil {
+		return response.InternalError(fmt.Errorf("Failed to encode response as %s: %w", format, err))
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		for key, value := range headers {
+			w.Header().Set(key, value)
+		}
+
+		w.Header().Set("Content-Type", formatContentTypes[format])
+		w.WriteHeader(http.StatusOK)
+
+		_, err := w.Write(data)
+
+		return err
+	})
+}
+
+// encodeCSV encodes the given items as CSV. The columns are the top-level JSON fields of the items, in the order they
+// first appear. String values are written as-is, and all other values as JSON.
+func encodeCSV[T any](items []T) ([]byte, error) {
+	columns := []string{}
+	seen := map[string]bool{}
+	rows := make([]map[string]string, 0, len(items))
+	for _, item := range items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, err
+		}
+
+		keys, values, err := decodeJSONObject(data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			if !seen[key] {
+				seen[key] = true
+				columns = append(columns, key)
+			}
+		}
+
+		rows = append(rows, values)
+	}
+
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	err := writer.Write(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		record := make([]string, 0, len(columns))
+		for _, column := range columns {
+			record = append(record, row[column])
+		}
+
+		err = writer.Write(record)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+
+	return buf.Bytes(), writer.Error()
+}
+
+// decodeJSONObject returns the keys of the given JSON object in order, and the CSV cell value of each key.
+func decodeJSONObject(data []byte) ([]string, map[string]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if token != json.Delim('{') {
+		return nil, nil, fmt.Errorf("Expected a JSON object")
+	}
+
+	keys := []string{}
+	values := map[string]string{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("Expected a JSON object key")
+		}
+
+		var raw json.RawMessage
+		err = decoder.Decode(&raw)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var value string
+		if string(raw) != "null" && json.Unmarshal(raw, &value) != nil {
+			value = string(raw)
+		}
+
+		keys = append(keys, key)
+		values[key] = value
+	}
+
+	return keys, values, nil
+}
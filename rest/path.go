@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// PathParam is the type of a path variable of an endpoint, which is parsed and validated before the handler runs.
+type PathParam int
+
+const (
+	// PathParamString is any string. Path variables that are not declared are parsed as strings.
+	PathParamString PathParam = iota
+
+	// PathParamName is the name of a resource, such as a cluster member. It must not be empty, contain slashes, or
+	// be "." or "..".
+	PathParamName
+
+	// PathParamUUID is a UUID, parsed as a uuid.UUID.
+	PathParamUUID
+
+	// PathParamInt is a base 10 integer, parsed as an int64.
+	PathParamInt
+)
+
+// pathParamsKey is the key of the parsed path variables in the context of a request.
+type pathParamsKey struct{}
+
+// ParsePathParams unescapes and parses the path variables of the request according to their declared types, and
+// returns the request with the parsed values in its context, for use with PathValue.
+func ParsePathParams(r *http.Request, params map[string]PathParam) (*http.Request, error) {
+	vars := mux.Vars(r)
+	if len(vars) == 0 {
+		return r, nil
+	}
+
+	values := make(map[string]any, len(vars))
+	for name, raw := range vars {
+		value, err := url.PathUnescape(raw)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid path variable %q: %w", name, err)
+		}
+
+		values[name], err = parsePathParam(params[name], value)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid path variable %q value %q: %w", name, value, err)
+		}
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), pathParamsKey{}, values)), nil
+}
+
+// parsePathParam parses the unescaped value of a path variable of the given type.
+func parsePathParam(param PathParam, value string) (any, error) {
+	switch param {
+	case PathParamName:
+		if value == "" || value == "." || value == ".." || strings.Contains(value, "/") {
+			return nil, fmt.Errorf("Not a valid name")
+		}
+
+		return value, nil
+	case PathParamUUID:
+		return uuid.Parse(value)
+	case PathParamInt:
+		return strconv.ParseInt(value, 10, 64)
+	}
+
+	return value, nil
+}
+
+// PathValue returns the parsed value of the path variable with the given name, which is a string, uuid.UUID or int64
+// according to its declared type. Returns the zero value if the variable is not set, or is of another type.
+func PathValue[T any](r *http.Request, name string) T {
+	var zero T
+	values, ok := r.Context().Value(pathParamsKey{}).(map[string]any)
+	if !ok {
+		return zero
+	}
+
+	value, ok := values[name].(T)
+	if !ok {
+		return zero
+	}
+
+	return value
+}
@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/shared"
+
+	"github.com/canonical/microcluster/state"
+)
+
+// PeerCertificate holds details about the verified TLS certificate presented by the peer of a request.
+type PeerCertificate struct {
+	// Certificate is the parsed leaf certificate presented by the peer.
+	Certificate *x509.Certificate
+
+	// Fingerprint is the SHA256 fingerprint of the certificate.
+	Fingerprint string
+
+	// DNSNames and IPAddresses are the subject alternative names of the certificate.
+	DNSNames    []string
+	IPAddresses []net.IP
+
+	NotBefore time.Time
+	NotAfter  time.Time
+
+	// ClusterMember is true if the certificate belongs to a member of the cluster, in which case MemberName holds
+	// that member's name. Otherwise, the certificate belongs to a consumer of the API.
+	ClusterMember bool
+	MemberName    string
+}
+
+// Expired returns whether the certificate has expired, or is not yet valid.
+func (p PeerCertificate) Expired() bool {
+	now := time.Now()
+
+	return now.Before(p.NotBefore) || now.After(p.NotAfter)
+}
+
+// PeerCertificateFromRequest returns details about the TLS certificate presented by the peer of the given request.
+// Returns an error if the request was not made over TLS, for example over the control socket.
+func PeerCertificateFromRequest(s *state.State, r *http.Request) (*PeerCertificate, error) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return nil, fmt.Errorf("Request did not present a TLS certificate")
+	}
+
+	cert := r.TLS.PeerCertificates[0]
+	peer := &PeerCertificate{
+		Certificate: cert,
+		Fingerprint: shared.CertFingerprint(cert),
+		DNSNames:    cert.DNSNames,
+		IPAddresses: cert.IPAddresses,
+		NotBefore:   cert.NotBefore,
+		NotAfter:    cert.NotAfter,
+	}
+
+	remote := s.Remotes().RemoteByCertificateFingerprint(peer.Fingerprint)
+	if remote != nil {
+		peer.ClusterMember = true
+		peer.MemberName = remote.Name
+	}
+
+	return peer, nil
+}
@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/gorilla/mux"
+
+	"github.com/canonical/microcluster/cluster"
+	internalState "github.com/canonical/microcluster/internal/state"
+	"github.com/canonical/microcluster/state"
+)
+
+// ProjectEndpoint returns a copy of the given endpoint scoped by project, under the path "projects/{project}/<path>".
+// Requests to the endpoint are rejected if the project does not exist, or if the ProjectAccess hook returns an error.
+// The project can be retrieved by the endpoint handlers with ProjectFromRequest.
+func ProjectEndpoint(e Endpoint) Endpoint {
+	e.Path = "projects/{project}/" + strings.TrimPrefix(e.Path, "/")
+
+	aliases := make([]EndpointAlias, 0, len(e.Aliases))
+	for _, alias := range e.Aliases {
+		alias.Path = "projects/{project}/" + strings.TrimPrefix(alias.Path, "/")
+		aliases = append(aliases, alias)
+	}
+
+	e.Aliases = aliases
+	e.Get = projectAction(e.Get)
+	e.Put = projectAction(e.Put)
+	e.Post = projectAction(e.Post)
+	e.Delete = projectAction(e.Delete)
+	e.Patch = projectAction(e.Patch)
+
+	return e
+}
+
+// ProjectFromRequest returns the name of the project the request is scoped to.
+// Returns the default project if the request path is not scoped to a project.
+func ProjectFromRequest(r *http.Request) (string, error) {
+	project, ok := mux.Vars(r)["project"]
+	if !ok || project == "" {
+		return cluster.DefaultProject, nil
+	}
+
+	return url.PathUnescape(project)
+}
+
+// projectAction wraps the access handler of the given action to verify that the requested project exists, and that
+// access to it is allowed by the ProjectAccess hook.
+func projectAction(action EndpointAction) EndpointAction {
+	if action.Handler == nil {
+		return action
+	}
+
+	accessHandler := action.AccessHandler
+	action.AccessHandler = func(s *state.State, r *http.Request) response.Response {
+		project, err := ProjectFromRequest(r)
+		if err != nil {
+			return response.BadRequest(err)
+		}
+
+		err = s.Database.Transaction(r.Context(), func(ctx context.Context, tx *sql.Tx) error {
+			_, err := cluster.GetInternalProject(ctx, tx, project)
+			return err
+		})
+		if err != nil {
+			return response.SmartError(err)
+		}
+
+		err = internalState.ProjectAccessHook(s, r, project)
+		if err != nil {
+			return response.Forbidden(err)
+		}
+
+		if accessHandler != nil {
+			return accessHandler(s, r)
+		}
+
+		return response.EmptySyncResponse
+	}
+
+	return action
+}
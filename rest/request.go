@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/canonical/lxd/lxd/response"
+)
+
+// Validator is implemented by request payloads that check their own fields once decoded.
+type Validator interface {
+	Validate() error
+}
+
+// DecodeRequest decodes the JSON body of the request into a value of type T, and validates it. Fields tagged with
+// `validate:"required"` must not be left at their zero value. Then the payload is checked by its Validate method, if
+// it implements Validator, and by each of the given validate functions in order. A 400 response is returned for the
+// first problem found, and nil otherwise.
+func DecodeRequest[T any](r *http.Request, validate ...func(req T) error) (T, response.Response) {
+	var req T
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return req, response.BadRequest(fmt.Errorf("Failed to parse request: %w", err))
+	}
+
+	err = ValidateRequest(req, validate...)
+	if err != nil {
+		return req, response.BadRequest(err)
+	}
+
+	return req, nil
+}
+
+// ValidateRequest checks the fields of a request payload decoded by other means the same way as DecodeRequest.
+func ValidateRequest[T any](req T, validate ...func(req T) error) error {
+	err := checkRequired(reflect.ValueOf(req))
+	if err != nil {
+		return err
+	}
+
+	validator, ok := any(req).(Validator)
+	if !ok {
+		validator, ok = any(&req).(Validator)
+	}
+
+	if ok {
+		err = validator.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, f := range validate {
+		err = f(req)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkRequired returns an error for the first field of the given struct, or of its embedded structs, that is tagged
+// with `validate:"required"` but has its zero value.
+func checkRequired(value reflect.Value) error {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		if field.Anonymous {
+			err := checkRequired(value.Field(i))
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if field.Tag.Get("validate") != "required" || !value.Field(i).IsZero() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+
+		return fmt.Errorf("Missing required field %q", name)
+	}
+
+	return nil
+}
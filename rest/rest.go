@@ -2,24 +2,46 @@ package rest
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/canonical/lxd/lxd/response"
 
 	"github.com/canonical/microcluster/state"
 )
 
+// Middleware wraps the HTTP handler of an endpoint, to run before and after it, such as for authentication, metrics,
+// or to modify the request. It sees every request to the endpoint, before the built-in authentication.
+type Middleware func(next http.Handler) http.Handler
+
 // EndpointAlias represents an alias URL of and Endpoint in our API.
 type EndpointAlias struct {
-	Name string // Name for this alias.
-	Path string // Path pattern for this alias.
+	Name       string // Name for this alias.
+	Path       string // Path pattern for this alias.
+	Deprecated bool   // Whether responses should carry Deprecation and Warning headers, to move clients to the endpoint path.
 }
 
+// AccessPolicy is the set of clients allowed to access an endpoint, enforced before any AccessHandler.
+type AccessPolicy string
+
+const (
+	// AccessTrusted allows only clients trusted by the cluster, unless an action sets AllowUntrusted. This is the
+	// default.
+	AccessTrusted AccessPolicy = ""
+
+	// AccessAny allows any client, trusted or not, such as for health checks or service discovery.
+	AccessAny AccessPolicy = "any"
+
+	// AccessControlSocket allows only local clients connected to the control socket.
+	AccessControlSocket AccessPolicy = "control-socket"
+)
+
 // EndpointAction represents an action on an API endpoint.
 type EndpointAction struct {
 	Handler        func(state *state.State, r *http.Request) response.Response
 	AccessHandler  func(state *state.State, r *http.Request) response.Response
 	AllowUntrusted bool
 	ProxyTarget    bool // Allow forwarding of the request to a target if ?target=name is specified.
+	LeaderOnly     bool // Forward the request to the dqlite leader if received by another cluster member.
 }
 
 // Endpoint represents a URL in our API.
@@ -35,4 +57,20 @@ type Endpoint struct {
 
 	AllowedDuringShutdown bool // Whether we should return Unavailable Error (503) if daemon is shutting down.
 	AllowedBeforeInit     bool // Whether we should return Unavailabel Error (503) if the daemon has not been initialized (is not yet part of a cluster).
+	Deprecated            bool // Whether responses should carry a Deprecation header, to move clients off this endpoint.
+
+	Access     AccessPolicy         // Clients allowed to access all actions of this endpoint.
+	PathParams map[string]PathParam // Types of the path variables, parsed before the handler runs. See PathValue.
+}
+
+// APIVersion represents a version of the application API, served alongside the others under its own path prefix.
+type APIVersion struct {
+	Version   string     // Version used as the path prefix, such as "2.0" for "/2.0".
+	Endpoints []Endpoint // Endpoints served by this version.
+
+	// Deprecated marks all endpoints of the version as deprecated, so their responses carry a Deprecation header.
+	Deprecated bool
+
+	// Sunset is the time after which the version may no longer be served, sent to clients in a Sunset header.
+	Sunset time.Time
 }
@@ -0,0 +1,128 @@
+package rest
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/canonical/lxd/lxd/response"
+
+	"github.com/canonical/microcluster/state"
+)
+
+// txContextKey is the context key under which the request-scoped transaction is stored.
+type txContextKey struct{}
+
+// TxFromContext returns the database transaction associated with a request wrapped by TransactionHandler.
+func TxFromContext(ctx context.Context) (*sql.Tx, error) {
+	tx, ok := ctx.Value(txContextKey{}).(*sql.Tx)
+	if !ok || tx == nil {
+		return nil, fmt.Errorf("No database transaction found in request context")
+	}
+
+	return tx, nil
+}
+
+// TransactionHandler wraps an endpoint handler so that it runs within a database transaction tied to the request
+// context. The transaction can be retrieved by the handler, or any code it calls, with TxFromContext.
+// The transaction is committed if the handler returns a 2xx response, and rolled back if the handler returns any
+// other response or panics.
+//
+// The handler runs at most once, as it may have side effects outside of the transaction that must not be repeated. Only
+// failures to begin the transaction are retried.
+//
+// The response is rendered in full before the transaction is committed, so the wrapped handler should not return
+// streaming responses, or responses that hijack the connection.
+func TransactionHandler(handler func(s *state.State, r *http.Request) response.Response) func(s *state.State, r *http.Request) response.Response {
+	return func(s *state.State, r *http.Request) response.Response {
+		var recorder *responseRecorder
+		var panicValue any
+		err := s.Database.TransactionOnce(r.Context(), func(ctx context.Context, tx *sql.Tx) (err error) {
+			defer func() {
+				panicValue = recover()
+				if panicValue != nil {
+					err = fmt.Errorf("Request handler panicked: %v", panicValue)
+				}
+			}()
+
+			recorder = newResponseRecorder()
+			resp := handler(s, r.WithContext(context.WithValue(ctx, txContextKey{}, tx)))
+			err = resp.Render(recorder)
+			if err != nil {
+				return err
+			}
+
+			if recorder.status < 200 || recorder.status > 299 {
+				return errResponseNotSuccessful
+			}
+
+			return nil
+		})
+
+		// Propagate the panic now that the transaction has been rolled back.
+		if panicValue != nil {
+			panic(panicValue)
+		}
+
+		if err != nil && !errors.Is(err, errResponseNotSuccessful) {
+			return response.SmartError(err)
+		}
+
+		return response.ManualResponse(recorder.replay)
+	}
+}
+
+// errResponseNotSuccessful is returned within a request-scoped transaction to roll it back when the handler
+// returned a non-2xx response.
+var errResponseNotSuccessful = fmt.Errorf("Request handler returned a non-successful response")
+
+// responseRecorder is an http.ResponseWriter that buffers a rendered response so that it can be inspected before
+// being written to the client.
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: http.Header{}}
+}
+
+// Header implements http.ResponseWriter.
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+// Write implements http.ResponseWriter.
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	return r.body.Write(b)
+}
+
+// replay writes the buffered response to the given http.ResponseWriter.
+func (r *responseRecorder) replay(w http.ResponseWriter) error {
+	for k, v := range r.header {
+		w.Header()[k] = v
+	}
+
+	if r.status != 0 {
+		w.WriteHeader(r.status)
+	}
+
+	_, err := w.Write(r.body.Bytes())
+
+	return err
+}
@@ -0,0 +1,43 @@
+package types
+
+// ErrorCode is a machine-readable code identifying the cause of an error response, so clients can handle errors
+// without matching on their messages.
+type ErrorCode string
+
+const (
+	// ErrorCodeNotFound is returned for requests to resources that do not exist.
+	ErrorCodeNotFound ErrorCode = "NotFound"
+
+	// ErrorCodeNotTrusted is returned for requests that could not be authenticated, or are not allowed for the
+	// authenticated identity.
+	ErrorCodeNotTrusted ErrorCode = "NotTrusted"
+
+	// ErrorCodeNotLeader is returned for requests that must be handled by the dqlite leader, made to another member.
+	ErrorCodeNotLeader ErrorCode = "NotLeader"
+
+	// ErrorCodeQuorumLost is returned when the cluster has no dqlite leader, usually because too many voters are
+	// offline.
+	ErrorCodeQuorumLost ErrorCode = "QuorumLost"
+
+	// ErrorCodeSchemaMismatch is returned when cluster members have different schema versions, such as during an
+	// upgrade.
+	ErrorCodeSchemaMismatch ErrorCode = "SchemaMismatch"
+
+	// ErrorCodeAlreadyMember is returned when joining a cluster member whose name, address or certificate is already
+	// in use by another member.
+	ErrorCodeAlreadyMember ErrorCode = "AlreadyMember"
+
+	// ErrorCodeNotInitialized is returned for requests that need the database before the daemon has joined a cluster.
+	ErrorCodeNotInitialized ErrorCode = "NotInitialized"
+
+	// ErrorCodeShuttingDown is returned for requests made while the daemon is shutting down.
+	ErrorCodeShuttingDown ErrorCode = "ShuttingDown"
+
+	// ErrorCodeRateLimited is returned for requests from clients exceeding their rate limit.
+	ErrorCodeRateLimited ErrorCode = "RateLimited"
+)
+
+// ErrorMetadata is the metadata of an error response carrying an error code.
+type ErrorMetadata struct {
+	Code ErrorCode `json:"code" yaml:"code"`
+}
@@ -0,0 +1,40 @@
+package types
+
+// HealthComponent is a component of the daemon whose state is reported by the health and readiness endpoints.
+type HealthComponent string
+
+const (
+	// HealthComponentDatabase is whether the database is open.
+	HealthComponentDatabase HealthComponent = "database"
+
+	// HealthComponentLeader is whether the dqlite leader can be reached.
+	HealthComponentLeader HealthComponent = "leader"
+
+	// HealthComponentTrustStore is whether the trust store is loaded with the cluster members.
+	HealthComponentTrustStore HealthComponent = "truststore"
+
+	// HealthComponentEndpoints is whether all API listeners are serving.
+	HealthComponentEndpoints HealthComponent = "endpoints"
+
+	// HealthComponentSchema is whether the database schema is current on all cluster members.
+	HealthComponentSchema HealthComponent = "schema"
+)
+
+// Health represents the state of the daemon and its components.
+type Health struct {
+	// Healthy is whether all components are healthy.
+	Healthy bool `json:"healthy" yaml:"healthy"`
+
+	// Ready is whether the daemon has finished starting up.
+	Ready bool `json:"ready" yaml:"ready"`
+
+	// Components are the states of the components of the daemon, in a fixed order.
+	Components []HealthCheck `json:"components" yaml:"components"`
+}
+
+// HealthCheck represents the state of a component of the daemon.
+type HealthCheck struct {
+	Name    HealthComponent `json:"name"              yaml:"name"`
+	Healthy bool            `json:"healthy"           yaml:"healthy"`
+	Message string          `json:"message,omitempty" yaml:"message,omitempty"`
+}
@@ -0,0 +1,66 @@
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/canonical/lxd/lxd/response"
+	"github.com/canonical/lxd/shared/logger"
+	"github.com/gorilla/websocket"
+)
+
+// webSocketCloseTimeout is how long to wait for the close message to be sent when a WebSocket handler returns.
+const webSocketCloseTimeout = 5 * time.Second
+
+// webSocketUpgrader upgrades requests to WebSocket connections. Browsers are only allowed to connect from the same
+// origin as the daemon.
+var webSocketUpgrader = websocket.Upgrader{}
+
+// WebSocketResponse returns a response that upgrades a GET request to a WebSocket connection and passes it to the
+// given handler, so that endpoints can stream data such as logs or the output of commands through the daemon. Like
+// the database endpoint, the connection is hijacked from the HTTP server, so its timeouts no longer apply. The
+// connection is closed once the handler returns, with an error close message if the handler returned an error.
+//
+// The handler runs after the endpoint returned, so it should not rely on the request context, which is cancelled
+// after the handler timeout.
+func WebSocketResponse(r *http.Request, handler func(conn *websocket.Conn) error) response.Response {
+	if !websocket.IsWebSocketUpgrade(r) {
+		return response.BadRequest(fmt.Errorf("Endpoint only supports WebSocket connections"))
+	}
+
+	return response.ManualResponse(func(w http.ResponseWriter) error {
+		// On failure, the upgrader has already replied to the client.
+		conn, err := webSocketUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			logger.Warn("Failed to upgrade to WebSocket connection", logger.Ctx{"url": r.URL.String(), "error": err})
+			return nil
+		}
+
+		defer conn.Close()
+
+		err = conn.UnderlyingConn().SetDeadline(time.Time{})
+		if err != nil {
+			return nil
+		}
+
+		closeCode := websocket.CloseNormalClosure
+		closeText := ""
+		err = handler(conn)
+		if err != nil {
+			logger.Error("WebSocket handler failed", logger.Ctx{"url": r.URL.String(), "error": err})
+
+			closeCode = websocket.CloseInternalServerErr
+			closeText = err.Error()
+
+			// Control frames can carry at most 125 bytes, including the close code.
+			if len(closeText) > 123 {
+				closeText = closeText[:123]
+			}
+		}
+
+		_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, closeText), time.Now().Add(webSocketCloseTimeout))
+
+		return nil
+	})
+}